@@ -1,17 +1,31 @@
-package main
-
-import (
-	"context"
-	"os"
-	"os/signal"
-	
-	"archive_bot/internal/app"
-)
-
-func main() {
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
-	defer cancel()
-
-	telegramBot := app.New(ctx, 0)
-	telegramBot.Run(ctx)
-}
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"archive_bot/internal/app"
+)
+
+// Usage: archive_bot [-config file] [-print-config] [migrate up|down|status]
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	flag.Parse()
+	if flag.Arg(0) == "migrate" {
+		if err := app.Migrate(ctx, flag.Arg(1), os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			cancel()
+			os.Exit(1)
+		}
+		return
+	}
+
+	telegramBot := app.New(ctx, 0)
+	telegramBot.Run(ctx)
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"archive_bot/internal/entities"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/go-telegram/bot"
+)
+
+// downloadFile streams the media file of the note. Telegram download links
+// contain the bot token, so files are proxied instead of redirected.
+func (s *server) downloadFile(w http.ResponseWriter, r *http.Request) {
+	log := s.log.With(logger.String("operation", "api.downloadFile"))
+
+	n, ok := s.findNote(w, r)
+	if !ok {
+		return
+	}
+
+	index, ok := pathID(r, "index")
+	fileIDs := s.files.NoteFiles(r.Context(), entities.ParseType(n.Type), n.ID)
+	if !ok || index >= len(fileIDs) {
+		WriteError(w, http.StatusNotFound, "file not found")
+		return
+	}
+
+	file, err := s.downloader.GetFile(r.Context(), &bot.GetFileParams{FileID: fileIDs[index]})
+	if err != nil {
+		log.Error("failed to get file", logger.ErrAttr(err))
+		WriteError(w, http.StatusBadGateway, "file is unavailable")
+		return
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, s.downloader.FileDownloadLink(file), nil)
+	if err != nil {
+		s.internalError(w, "api.downloadFile", err)
+		return
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		log.Error("failed to download file", logger.ErrAttr(err))
+		WriteError(w, http.StatusBadGateway, "file is unavailable")
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		WriteError(w, http.StatusBadGateway, "file is unavailable")
+		return
+	}
+
+	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	if file.FileSize > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(file.FileSize, 10))
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		log.Error("failed to send file", logger.ErrAttr(err))
+	}
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"net/http"
+
+	"archive_bot/internal/folder"
+	"archive_bot/internal/user"
+
+	"archive_bot/pkg/logger"
+)
+
+type folderRequest struct {
+	Name string `json:"name"`
+}
+
+func (s *server) listFolders(w http.ResponseWriter, r *http.Request) {
+	folders, err := s.folders.List(r.Context(), userID(r))
+	if err != nil {
+		s.internalError(w, "api.listFolders", err)
+		return
+	}
+
+	res := make([]folderResponse, 0, len(folders))
+	for _, f := range folders {
+		res = append(res, newFolderResponse(f))
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{"folders": res})
+}
+
+func (s *server) createFolder(w http.ResponseWriter, r *http.Request) {
+	var req folderRequest
+	if !decode(r, &req) {
+		WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	if !s.withinLimits(w, s.limits.CheckFolders(r.Context(), userID(r))) {
+		return
+	}
+
+	f, err := s.folders.Create(r.Context(), userID(r), req.Name)
+	if err != nil {
+		if err == folder.ErrInvalidName {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.internalError(w, "api.createFolder", err)
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, newFolderResponse(f))
+}
+
+func (s *server) renameFolder(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid folder id")
+		return
+	}
+
+	var req folderRequest
+	if !decode(r, &req) {
+		WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	if err := s.folders.Rename(r.Context(), userID(r), id, req.Name); err != nil {
+		switch err {
+		case folder.ErrInvalidName:
+			WriteError(w, http.StatusBadRequest, err.Error())
+		case folder.ErrNoFolder:
+			WriteError(w, http.StatusNotFound, err.Error())
+		case folder.ErrNameTaken:
+			WriteError(w, http.StatusConflict, err.Error())
+		default:
+			s.internalError(w, "api.renameFolder", err)
+		}
+		return
+	}
+	s.rules.Forget(userID(r))
+
+	f, err := s.folders.Get(r.Context(), userID(r), id)
+	if err != nil {
+		s.internalError(w, "api.renameFolder", err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, newFolderResponse(f))
+}
+
+func (s *server) removeFolder(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid folder id")
+		return
+	}
+
+	if err := s.folders.Remove(r.Context(), userID(r), id); err != nil {
+		if err == folder.ErrNoFolder {
+			WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		s.internalError(w, "api.removeFolder", err)
+		return
+	}
+	s.rules.Forget(userID(r))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) internalError(w http.ResponseWriter, op string, err error) {
+	s.log.Error("request failed", logger.String("operation", op), logger.ErrAttr(err))
+	WriteError(w, http.StatusInternalServerError, "internal error")
+}
+
+// withinLimits writes the error of the exceeded limit. The request goes on
+// when the limits can't be checked.
+func (s *server) withinLimits(w http.ResponseWriter, err error) bool {
+	switch err {
+	case nil:
+		return true
+	case user.ErrQuotaNotes, user.ErrQuotaDaily, user.ErrQuotaMedia, user.ErrQuotaFolders:
+		WriteError(w, http.StatusForbidden, err.Error())
+		return false
+	default:
+		s.log.Error("failed to check limits", logger.ErrAttr(err))
+		return true
+	}
+}
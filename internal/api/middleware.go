@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"archive_bot/internal/token"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const rateKeyPrefix string = "api-rate:"
+
+type tokenCtx struct{}
+
+// authenticate resolves the bearer token and puts it into the request
+// context. The banned users are refused.
+func (s *server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		plain, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || plain == "" {
+			WriteError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		t, err := s.tokens.Authenticate(r.Context(), strings.TrimSpace(plain))
+		if err != nil {
+			if err != token.ErrNoToken {
+				s.log.Error("failed to authenticate", logger.ErrAttr(err))
+				WriteError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+			WriteError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		if s.bans.IsBanned(r.Context(), t.UserID) {
+			WriteError(w, http.StatusForbidden, "user is banned")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), tokenCtx{}, t)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// rateLimit limits the requests of every user, the tokens of the user
+// share the limit.
+func (s *server) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if retryAfter, ok := s.limiter.allow(r.Context(), strconv.FormatInt(userID(r), 10)); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			WriteError(w, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func tokenFrom(ctx context.Context) *token.Token {
+	t, _ := ctx.Value(tokenCtx{}).(*token.Token)
+	return t
+}
+
+func userID(r *http.Request) int64 {
+	return tokenFrom(r.Context()).UserID
+}
+
+// limiter is a fixed window rate limiter stored in redis.
+type limiter struct {
+	log    *logger.Logger
+	db     *redis.Client
+	limit  atomic.Int64
+	window time.Duration
+}
+
+func newLimiter(log *logger.Logger, db *redis.Client, limit int, window time.Duration) *limiter {
+	l := &limiter{log: log, db: db, window: window}
+	l.limit.Store(int64(limit))
+	return l
+}
+
+// allow counts the request and reports whether it fits into the limit.
+// Requests are allowed when redis is unavailable.
+func (l *limiter) allow(ctx context.Context, key string) (time.Duration, bool) {
+	log := l.log.With(logger.String("operation", "api.limiter.allow"))
+
+	window := time.Now().UnixNano() / int64(l.window)
+	redisKey := rateKeyPrefix + key + ":" + strconv.FormatInt(window, 10)
+
+	pipe := l.db.TxPipeline()
+	count := pipe.Incr(ctx, redisKey)
+	pipe.Expire(ctx, redisKey, l.window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Error("failed to count request", logger.ErrAttr(err))
+		return 0, true
+	}
+
+	if count.Val() > l.limit.Load() {
+		next := time.Unix(0, (window+1)*int64(l.window))
+		return time.Until(next), false
+	}
+
+	return 0, true
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"archive_bot/internal/entities"
+	"archive_bot/internal/notes/texts"
+)
+
+type createNoteRequest struct {
+	FolderID int    `json:"folder_id"`
+	Text     string `json:"text"`
+}
+
+type moveNoteRequest struct {
+	FolderID int `json:"folder_id"`
+}
+
+// listNotes lists notes, optionally filtered by folder_id and searched by q.
+func (s *server) listNotes(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	f := &texts.Filter{UserID: userID(r), Query: query.Get("q")}
+
+	for name, dst := range map[string]*int{
+		"folder_id": &f.FolderID,
+		"limit":     &f.Limit,
+		"offset":    &f.Offset,
+	} {
+		if raw := query.Get(name); raw != "" {
+			val, err := strconv.Atoi(raw)
+			if err != nil {
+				WriteError(w, http.StatusBadRequest, "invalid "+name)
+				return
+			}
+			*dst = val
+		}
+	}
+
+	notes, err := s.notes.List(r.Context(), f)
+	if err != nil {
+		s.internalError(w, "api.listNotes", err)
+		return
+	}
+
+	res := make([]noteResponse, 0, len(notes))
+	for _, n := range notes {
+		res = append(res, s.noteResponse(r, n))
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{
+		"notes":  res,
+		"limit":  f.Limit,
+		"offset": f.Offset,
+	})
+}
+
+func (s *server) getNote(w http.ResponseWriter, r *http.Request) {
+	n, ok := s.findNote(w, r)
+	if !ok {
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, s.noteResponse(r, n))
+}
+
+func (s *server) createNote(w http.ResponseWriter, r *http.Request) {
+	var req createNoteRequest
+	if !decode(r, &req) {
+		WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	if !s.withinLimits(w, s.limits.CheckNote(r.Context(), userID(r), 0)) {
+		return
+	}
+
+	id, err := s.notes.Create(r.Context(), &texts.TextNote{
+		UserID:      userID(r),
+		FolderID:    req.FolderID,
+		Description: req.Text,
+	})
+	if err != nil {
+		switch err {
+		case texts.ErrEmptyNote:
+			WriteError(w, http.StatusBadRequest, err.Error())
+		case texts.ErrNoFolder:
+			WriteError(w, http.StatusNotFound, err.Error())
+		default:
+			s.internalError(w, "api.createNote", err)
+		}
+		return
+	}
+
+	n, err := s.notes.Get(r.Context(), userID(r), id)
+	if err != nil {
+		s.internalError(w, "api.createNote", err)
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, s.noteResponse(r, n))
+}
+
+func (s *server) moveNote(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	var req moveNoteRequest
+	if !decode(r, &req) {
+		WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	if err := s.notes.MoveTo(r.Context(), userID(r), id, req.FolderID); err != nil {
+		if err == texts.ErrNoTextNote {
+			WriteError(w, http.StatusNotFound, "note or folder not found")
+			return
+		}
+		s.internalError(w, "api.moveNote", err)
+		return
+	}
+
+	s.getNote(w, r)
+}
+
+func (s *server) removeNote(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	if err := s.notes.Remove(r.Context(), userID(r), id); err != nil {
+		if err == texts.ErrNoTextNote {
+			WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		s.internalError(w, "api.removeNote", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findNote finds the note from the path and writes the error response if needed.
+func (s *server) findNote(w http.ResponseWriter, r *http.Request) (*texts.TextNote, bool) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return nil, false
+	}
+
+	n, err := s.notes.Get(r.Context(), userID(r), id)
+	if err != nil {
+		if err == texts.ErrNoTextNote {
+			WriteError(w, http.StatusNotFound, err.Error())
+			return nil, false
+		}
+		s.internalError(w, "api.findNote", err)
+		return nil, false
+	}
+
+	return n, true
+}
+
+func (s *server) noteResponse(r *http.Request, n *texts.TextNote) noteResponse {
+	return newNoteResponse(n, s.files.NoteFiles(r.Context(), entities.ParseType(n.Type), n.ID))
+}
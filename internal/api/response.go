@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"archive_bot/internal/folder"
+	"archive_bot/internal/notes/texts"
+)
+
+type folderResponse struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Icon      string `json:"icon"`
+	Hidden    bool   `json:"hidden"`
+	IsDefault bool   `json:"is_default"`
+}
+
+type fileResponse struct {
+	FileID string `json:"file_id"`
+	URL    string `json:"url"`
+}
+
+type noteResponse struct {
+	ID         int            `json:"id"`
+	FolderID   int            `json:"folder_id"`
+	Type       string         `json:"type"`
+	Text       string         `json:"text"`
+	Transcript string         `json:"transcript,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Files      []fileResponse `json:"files"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func newFolderResponse(f *folder.Folder) folderResponse {
+	return folderResponse{
+		ID: f.ID, Name: f.Name, Icon: f.Icon, Hidden: f.Hidden, IsDefault: f.IsDefault(),
+	}
+}
+
+func newNoteResponse(n *texts.TextNote, fileIDs []string) noteResponse {
+	files := make([]fileResponse, 0, len(fileIDs))
+	for i, fileID := range fileIDs {
+		files = append(files, fileResponse{
+			FileID: fileID,
+			URL:    "/api/v1/notes/" + strconv.Itoa(n.ID) + "/files/" + strconv.Itoa(i),
+		})
+	}
+
+	return noteResponse{
+		ID:         n.ID,
+		FolderID:   n.FolderID,
+		Type:       n.Type,
+		Text:       n.Description,
+		Transcript: n.Transcript,
+		CreatedAt:  n.CreatedAt,
+		Files:      files,
+	}
+}
+
+// WriteJSON writes v as the JSON body of the response with the status.
+func WriteJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// WriteError writes the error message as {"error": message}.
+func WriteError(w http.ResponseWriter, status int, message string) {
+	WriteJSON(w, status, errorResponse{Error: message})
+}
+
+func decode(r *http.Request, v any) bool {
+	return json.NewDecoder(r.Body).Decode(v) == nil
+}
+
+func pathID(r *http.Request, name string) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	return id, err == nil && id >= 0
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"archive_bot/internal/entities"
+	"archive_bot/internal/folder"
+	"archive_bot/internal/notes/texts"
+	"archive_bot/internal/token"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	Prefix string = "/api/"
+
+	defaultRateLimit int           = 60
+	downloadTimeout  time.Duration = time.Minute
+)
+
+type TokenService interface {
+	Authenticate(ctx context.Context, plain string) (*token.Token, error)
+}
+
+type BanService interface {
+	IsBanned(ctx context.Context, userID int64) bool
+}
+
+type FolderService interface {
+	List(ctx context.Context, userID int64) ([]*folder.Folder, error)
+	Get(ctx context.Context, userID int64, id int) (*folder.Folder, error)
+	Create(ctx context.Context, userID int64, name string) (*folder.Folder, error)
+	Rename(ctx context.Context, userID int64, id int, name string) error
+	Remove(ctx context.Context, userID int64, id int) error
+}
+
+// RuleCache drops the cached rules of the user, the rules show the name of
+// their folder and are removed with it.
+type RuleCache interface {
+	Forget(userID int64)
+}
+
+type NoteService interface {
+	List(ctx context.Context, f *texts.Filter) ([]*texts.TextNote, error)
+	Get(ctx context.Context, userID int64, id int) (*texts.TextNote, error)
+	Create(ctx context.Context, n *texts.TextNote) (int, error)
+	MoveTo(ctx context.Context, userID int64, id int, folderID int) error
+	Remove(ctx context.Context, userID int64, id int) error
+}
+
+// LimitService checks the limits of the user before the note or the
+// folder is created.
+type LimitService interface {
+	CheckNote(ctx context.Context, userID int64, size int64) error
+	CheckFolders(ctx context.Context, userID int64) error
+}
+
+type FileService interface {
+	NoteFiles(ctx context.Context, noteType entities.Type, textsID int) []string
+}
+
+type FileDownloader interface {
+	GetFile(ctx context.Context, params *bot.GetFileParams) (*models.File, error)
+	FileDownloadLink(f *models.File) string
+}
+
+type server struct {
+	log        *logger.Logger
+	limiter    *limiter
+	tokens     TokenService
+	bans       BanService
+	folders    FolderService
+	rules      RuleCache
+	notes      NoteService
+	limits     LimitService
+	files      FileService
+	downloader FileDownloader
+	client     *http.Client
+}
+
+func New(
+	log *logger.Logger,
+	redis *redis.Client,
+	rateLimit int,
+	tokens TokenService,
+	bans BanService,
+	folders FolderService,
+	rules RuleCache,
+	notes NoteService,
+	limits LimitService,
+	files FileService,
+	downloader FileDownloader,
+) *server {
+	if rateLimit <= 0 {
+		rateLimit = defaultRateLimit
+	}
+
+	return &server{
+		log:        log,
+		limiter:    newLimiter(log, redis, rateLimit, time.Minute),
+		tokens:     tokens,
+		bans:       bans,
+		folders:    folders,
+		rules:      rules,
+		notes:      notes,
+		limits:     limits,
+		files:      files,
+		downloader: downloader,
+		client:     &http.Client{Timeout: downloadTimeout},
+	}
+}
+
+// SetRateLimit changes the number of requests allowed per user per minute.
+func (s *server) SetRateLimit(rateLimit int) {
+	if rateLimit <= 0 {
+		rateLimit = defaultRateLimit
+	}
+	s.limiter.limit.Store(int64(rateLimit))
+}
+
+// Handler returns the handler serving the API under Prefix.
+func (s *server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/v1/folders", s.listFolders)
+	mux.HandleFunc("POST /api/v1/folders", s.createFolder)
+	mux.HandleFunc("PATCH /api/v1/folders/{id}", s.renameFolder)
+	mux.HandleFunc("DELETE /api/v1/folders/{id}", s.removeFolder)
+
+	mux.HandleFunc("GET /api/v1/notes", s.listNotes)
+	mux.HandleFunc("POST /api/v1/notes", s.createNote)
+	mux.HandleFunc("GET /api/v1/notes/{id}", s.getNote)
+	mux.HandleFunc("PATCH /api/v1/notes/{id}", s.moveNote)
+	mux.HandleFunc("DELETE /api/v1/notes/{id}", s.removeNote)
+	mux.HandleFunc("GET /api/v1/notes/{id}/files/{index}", s.downloadFile)
+
+	return s.authenticate(s.rateLimit(mux))
+}
@@ -0,0 +1,330 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"archive_bot/internal/entities"
+	"archive_bot/internal/folder"
+	"archive_bot/internal/notes/texts"
+	"archive_bot/internal/token"
+	"archive_bot/internal/user"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+type testTokens struct {
+	tokens map[string]*token.Token
+	err    error
+}
+
+func (ts *testTokens) Authenticate(ctx context.Context, plain string) (*token.Token, error) {
+	if ts.err != nil {
+		return nil, ts.err
+	}
+	t, ok := ts.tokens[plain]
+	if !ok {
+		return nil, token.ErrNoToken
+	}
+	return t, nil
+}
+
+type testBans map[int64]bool
+
+func (bs testBans) IsBanned(ctx context.Context, userID int64) bool {
+	return bs[userID]
+}
+
+type testFolders struct {
+	folders []*folder.Folder
+}
+
+func (fs *testFolders) List(ctx context.Context, userID int64) ([]*folder.Folder, error) {
+	res := []*folder.Folder{}
+	for _, f := range fs.folders {
+		if f.UserID == userID {
+			res = append(res, f)
+		}
+	}
+	return res, nil
+}
+
+func (fs *testFolders) Get(ctx context.Context, userID int64, id int) (*folder.Folder, error) {
+	for _, f := range fs.folders {
+		if f.ID == id && f.UserID == userID {
+			return f, nil
+		}
+	}
+	return nil, folder.ErrNoFolder
+}
+
+func (fs *testFolders) Create(ctx context.Context, userID int64, name string) (*folder.Folder, error) {
+	f := &folder.Folder{ID: len(fs.folders) + 1, UserID: userID, Name: name}
+	fs.folders = append(fs.folders, f)
+	return f, nil
+}
+
+func (fs *testFolders) Rename(ctx context.Context, userID int64, id int, name string) error {
+	f, err := fs.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	for _, other := range fs.folders {
+		if other.UserID == userID && other.ID != id && other.Name == name {
+			return folder.ErrNameTaken
+		}
+	}
+	f.Name = name
+	return nil
+}
+
+func (fs *testFolders) Remove(ctx context.Context, userID int64, id int) error {
+	_, err := fs.Get(ctx, userID, id)
+	return err
+}
+
+type testRules map[int64]bool
+
+func (rs testRules) Forget(userID int64) {
+	rs[userID] = true
+}
+
+type testNotes struct {
+	notes map[int]*texts.TextNote
+}
+
+func (ns *testNotes) List(ctx context.Context, f *texts.Filter) ([]*texts.TextNote, error) {
+	res := []*texts.TextNote{}
+	for _, n := range ns.notes {
+		if n.UserID == f.UserID {
+			res = append(res, n)
+		}
+	}
+	return res, nil
+}
+
+func (ns *testNotes) Get(ctx context.Context, userID int64, id int) (*texts.TextNote, error) {
+	n, ok := ns.notes[id]
+	if !ok || n.UserID != userID {
+		return nil, texts.ErrNoTextNote
+	}
+	return n, nil
+}
+
+func (ns *testNotes) Create(ctx context.Context, n *texts.TextNote) (int, error) {
+	n.ID = len(ns.notes) + 1
+	ns.notes[n.ID] = n
+	return n.ID, nil
+}
+
+func (ns *testNotes) MoveTo(ctx context.Context, userID int64, id int, folderID int) error {
+	n, err := ns.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	n.FolderID = folderID
+	return nil
+}
+
+func (ns *testNotes) Remove(ctx context.Context, userID int64, id int) error {
+	_, err := ns.Get(ctx, userID, id)
+	return err
+}
+
+type testLimits struct {
+	folders error
+}
+
+func (ls *testLimits) CheckNote(ctx context.Context, userID int64, size int64) error {
+	return nil
+}
+
+func (ls *testLimits) CheckFolders(ctx context.Context, userID int64) error {
+	return ls.folders
+}
+
+type testFiles struct{}
+
+func (testFiles) NoteFiles(ctx context.Context, noteType entities.Type, textsID int) []string {
+	return nil
+}
+
+// counterHook serves INCR and EXPIRE of the limiter instead of redis.
+type counterHook struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func (h *counterHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, errors.New("no redis in tests")
+	}
+}
+
+func (h *counterHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return next
+}
+
+func (h *counterHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		for _, cmd := range cmds {
+			switch c := cmd.(type) {
+			case *redis.IntCmd:
+				key := c.Args()[1].(string)
+				h.counts[key]++
+				c.SetVal(h.counts[key])
+			case *redis.BoolCmd:
+				c.SetVal(true)
+			}
+		}
+		return nil
+	}
+}
+
+func newTestServer(rateLimit int, tokens *testTokens, limits *testLimits, rules testRules) http.Handler {
+	log := logger.NewLogger(logger.WithWriter(io.Discard), logger.WithSetDefault(false))
+	db := redis.NewClient(&redis.Options{})
+	db.AddHook(&counterHook{counts: make(map[string]int64)})
+
+	folders := &testFolders{folders: []*folder.Folder{
+		{ID: 1, UserID: 1, Name: "Mine"},
+		{ID: 2, UserID: 2, Name: "Theirs"},
+		{ID: 3, UserID: 1, Name: "Work"},
+	}}
+	notes := &testNotes{notes: map[int]*texts.TextNote{
+		1: {ID: 1, UserID: 1, FolderID: 1, Type: "message", Description: "mine"},
+		2: {ID: 2, UserID: 2, FolderID: 2, Type: "message", Description: "theirs"},
+	}}
+
+	return New(log, db, rateLimit, tokens, testBans{3: true}, folders, rules, notes, limits, testFiles{}, nil).Handler()
+}
+
+func testTokenSet() *testTokens {
+	return &testTokens{tokens: map[string]*token.Token{
+		"one":   {ID: 1, UserID: 1},
+		"one-2": {ID: 2, UserID: 1},
+		"two":   {ID: 3, UserID: 2},
+		"three": {ID: 4, UserID: 3},
+	}}
+}
+
+func serve(h http.Handler, method string, path string, plain string, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if plain != "" {
+		r.Header.Set("Authorization", "Bearer "+plain)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		title  string
+		tokens *testTokens
+		plain  string
+		want   int
+	}{
+		{"no token", testTokenSet(), "", http.StatusUnauthorized},
+		{"unknown token", testTokenSet(), "nope", http.StatusUnauthorized},
+		{"failed lookup", &testTokens{err: errors.New("db is down")}, "one", http.StatusInternalServerError},
+		{"banned user", testTokenSet(), "three", http.StatusForbidden},
+		{"valid token", testTokenSet(), "one", http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			h := newTestServer(10, tc.tokens, &testLimits{}, testRules{})
+			assert.Equal(t, tc.want, serve(h, http.MethodGet, "/api/v1/folders", tc.plain, "").Code)
+		})
+	}
+}
+
+func TestRateLimitPerUser(t *testing.T) {
+	t.Parallel()
+	h := newTestServer(2, testTokenSet(), &testLimits{}, testRules{})
+
+	assert.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/api/v1/folders", "one", "").Code)
+	assert.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/api/v1/folders", "one-2", "").Code)
+
+	// another token of the same user shares the limit
+	w := serve(h, http.MethodGet, "/api/v1/folders", "one-2", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/api/v1/folders", "two", "").Code)
+}
+
+func TestOwnership(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		title  string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"own note", http.MethodGet, "/api/v1/notes/1", "", http.StatusOK},
+		{"other note", http.MethodGet, "/api/v1/notes/2", "", http.StatusNotFound},
+		{"move other note", http.MethodPatch, "/api/v1/notes/2", `{"folder_id":1}`, http.StatusNotFound},
+		{"remove other note", http.MethodDelete, "/api/v1/notes/2", "", http.StatusNotFound},
+		{"other note files", http.MethodGet, "/api/v1/notes/2/files/0", "", http.StatusNotFound},
+		{"rename own folder", http.MethodPatch, "/api/v1/folders/1", `{"name":"Ideas"}`, http.StatusOK},
+		{"rename to taken name", http.MethodPatch, "/api/v1/folders/1", `{"name":"Work"}`, http.StatusConflict},
+		{"rename other folder", http.MethodPatch, "/api/v1/folders/2", `{"name":"Work"}`, http.StatusNotFound},
+		{"remove other folder", http.MethodDelete, "/api/v1/folders/2", "", http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			h := newTestServer(10, testTokenSet(), &testLimits{}, testRules{})
+			assert.Equal(t, tc.want, serve(h, tc.method, tc.path, "one", tc.body).Code)
+		})
+	}
+}
+
+func TestFoldersQuota(t *testing.T) {
+	t.Parallel()
+	h := newTestServer(10, testTokenSet(), &testLimits{folders: user.ErrQuotaFolders}, testRules{})
+
+	w := serve(h, http.MethodPost, "/api/v1/folders", "one", `{"name":"Work"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestForgetRules(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		title  string
+		method string
+		path   string
+		body   string
+		forgot bool
+	}{
+		{"rename folder", http.MethodPatch, "/api/v1/folders/1", `{"name":"Ideas"}`, true},
+		{"remove folder", http.MethodDelete, "/api/v1/folders/1", "", true},
+		{"remove other folder", http.MethodDelete, "/api/v1/folders/2", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			rules := testRules{}
+			h := newTestServer(10, testTokenSet(), &testLimits{}, rules)
+			serve(h, tc.method, tc.path, "one", tc.body)
+			assert.Equal(t, tc.forgot, rules[1])
+		})
+	}
+}
@@ -1,117 +1,303 @@
-package app
-
-import (
-	"context"
-	"archive_bot/pkg/closer"
-	"archive_bot/pkg/er"
-	"archive_bot/pkg/logger"
-	"net/http"
-	"runtime"
-
-	"github.com/go-telegram/bot"
-)
-
-type app struct {
-	dp      *dependencyProvider
-	bot     *bot.Bot
-	workers int
-}
-
-func New(ctx context.Context, workers int) *app {
-	if workers == 0 {
-		workers = runtime.NumCPU()
-	}
-
-	a := &app{workers: workers}
-	a.initBot(ctx)
-
-	return a
-}
-
-func (a *app) initBot(ctx context.Context) {
-	a.dp = newDependencyProvider()
-
-	ctx = logger.ContextWithLogger(ctx, a.dp.Logger())
-
-	opts := []bot.Option{
-		bot.WithWorkers(a.workers),
-		bot.WithDefaultHandler(
-			a.dp.Router(ctx).RouteMessage,
-		),
-		bot.WithCallbackQueryDataHandler(
-			"btn_", bot.MatchTypePrefix,
-			a.dp.Router(ctx).RouteCallbackQuery,
-		),
-		bot.WithMessageTextHandler(
-			"adm", bot.MatchTypeContains,
-			a.dp.Router(ctx).RouteAdminMessage,
-		),
-		bot.WithCallbackQueryDataHandler(
-			"adm", bot.MatchTypeContains,
-			a.dp.Router(ctx).RouteAdminCallback),
-	}
-
-	b, err := bot.New(a.dp.Config().Bot.Token, opts...)
-	if err != nil {
-		panic(er.New("failed to initialize the bot", "app.initBot", err))
-	}
-
-	a.bot = b
-}
-
-func (a *app) Run(ctx context.Context) {
-	defer func() {
-		closer.CloseAll()
-		closer.Wait()
-	}()
-
-	a.selectConnection(ctx)
-
-	go func() {
-		if err := http.ListenAndServe(
-			":"+a.dp.Config().Bot.Port,
-			a.bot.WebhookHandler(),
-		); err != nil {
-			a.dp.Logger().Error("Bot stopped due error", logger.ErrAttr(err))
-			return
-		}
-	}()
-
-	a.dp.Logger().Info(
-		"Work",
-		logger.String("port", a.dp.Config().Bot.Port),
-		logger.Int("workers", a.workers),
-	)
-	a.dp.Logger().Info("✓ Bot started!")
-	if a.dp.Config().IsWebhook == 1 {
-		a.startWebhook(ctx)
-	} else {
-		a.bot.Start(ctx)
-	}
-	a.dp.Logger().Info("𐄂 Bot stopped!")
-}
-
-func (a *app) startWebhook(ctx context.Context) {
-	a.bot.StartWebhook(ctx)
-}
-
-func (a *app) selectConnection(ctx context.Context) {
-	if a.dp.Config().IsWebhook == 1 {
-		if _, err := a.bot.SetWebhook(ctx, &bot.SetWebhookParams{
-			URL:                a.dp.Config().Bot.WebhookURL,
-			DropPendingUpdates: true,
-		}); err != nil {
-			a.dp.Logger().Error("SetWebhook error:", logger.ErrAttr(err))
-			panic("SetWebhook error:" + err.Error())
-		}
-		a.dp.Logger().Info("webhook is set", logger.String("url", a.dp.Config().Bot.WebhookURL))
-	} else {
-		if _, err := a.bot.DeleteWebhook(ctx, &bot.DeleteWebhookParams{
-			DropPendingUpdates: true,
-		}); err != nil {
-			a.dp.Logger().Error("DeleteWebhook error:", logger.ErrAttr(err))
-			panic("DeleteWebhook error:" + err.Error())
-		}
-		a.dp.Logger().Info("polling is set")
-	}
-}
+package app
+
+import (
+	"context"
+	"errors"
+	"archive_bot/internal/api"
+	"archive_bot/internal/health"
+	"archive_bot/internal/metrics"
+	"archive_bot/internal/router"
+	"archive_bot/internal/webapp"
+	"archive_bot/pkg/closer"
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/executor"
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	defaultMenuButtonText string        = "Архив"
+	botAPIMaxAge          time.Duration = 2 * time.Minute
+)
+
+// Shutdown steps run in this order, the ones of one step concurrently.
+const (
+	closeServer int = iota
+	closeIntake
+	closeHandlers
+	closeOutbound
+	closeCache
+	closeDB
+	closeTelemetry
+)
+
+type app struct {
+	dp       *dependencyProvider
+	bot      *bot.Bot
+	executor *executor.Executor
+	workers  int
+}
+
+// New creates the bot. The workers bound the number of updates handled at
+// once, 0 takes router.workers from the config or the number of CPUs.
+func New(ctx context.Context, workers int) *app {
+	a := &app{workers: workers}
+	a.initBot(ctx)
+
+	return a
+}
+
+func (a *app) initBot(ctx context.Context) {
+	a.dp = newDependencyProvider()
+
+	ctx = logger.ContextWithLogger(ctx, a.dp.Logger())
+	a.dp.Tracing(ctx)
+	a.checkSchema(ctx)
+
+	if a.workers == 0 {
+		a.workers = a.dp.Config().Router.Workers
+	}
+	if a.workers == 0 {
+		a.workers = runtime.NumCPU()
+	}
+	a.executor = executor.New(a.workers, a.dp.Config().Router.QueueSize)
+
+	opts := []bot.Option{
+		// a single intake worker keeps the order of updates, the middlewares
+		// pass them to the executor which handles them concurrently.
+		bot.WithWorkers(1),
+		bot.WithNotAsyncHandlers(),
+		bot.WithMiddlewares(a.dp.Router(ctx).Middlewares(a.executor)...),
+		bot.WithHTTPClient(
+			a.dp.Config().Bot.PollTimeout,
+			tracing.NewBotAPIClient(a.dp.BotAPIMonitor(), "getUpdates"),
+		),
+		bot.WithDefaultHandler(
+			a.dp.Router(ctx).RouteMessage,
+		),
+		bot.WithCallbackQueryDataHandler(
+			"btn_", bot.MatchTypePrefix,
+			a.dp.Router(ctx).RouteCallbackQuery,
+		),
+		bot.WithCallbackQueryDataHandler(
+			router.AdminCallbackPrefix, bot.MatchTypePrefix,
+			a.dp.Router(ctx).RouteAdminCallback),
+	}
+
+	b, err := bot.New(a.dp.Config().Bot.Token, opts...)
+	if err != nil {
+		panic(er.New("failed to initialize the bot", "app.initBot", err))
+	}
+	b.RegisterHandlerMatchFunc(router.IsAdminCommand, a.dp.Router(ctx).RouteAdminMessage)
+
+	a.bot = b
+}
+
+// Run serves until ctx is done, then shuts down: the server and the intake
+// stop first, the accepted updates and the outbound queue are drained and
+// the connections are closed last, all within the shutdown timeout.
+// SIGHUP reloads the config fields which are safe to change at runtime.
+func (a *app) Run(ctx context.Context) {
+	a.selectConnection(ctx)
+	a.setMenuButton(ctx)
+	a.addHealthChecks(ctx)
+
+	go a.dp.Sender(ctx).Start(ctx, a.bot)
+	go a.dp.Broadcast(ctx).Start(ctx, a.bot)
+	go a.dp.Transcriber(ctx).Start(ctx, a.bot)
+
+	mux := http.NewServeMux()
+	mux.Handle(api.Prefix, a.dp.API(ctx, a.bot))
+	mux.Handle(webapp.Prefix, a.dp.WebApp(ctx))
+	mux.Handle(metrics.Path, metrics.Handler())
+	mux.HandleFunc(health.LivenessPath, a.dp.Health().Liveness)
+	mux.HandleFunc(health.ReadinessPath, a.dp.Health().Readiness)
+	mux.Handle("/", a.bot.WebhookHandler())
+
+	server := &http.Server{
+		Addr:    ":" + a.dp.Config().Bot.Port,
+		Handler: mux,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.dp.Intake().Set(intakeMode(bool(a.dp.Config().IsWebhook)), false)
+			a.dp.Logger().Error("Bot stopped due error", logger.ErrAttr(err))
+			return
+		}
+	}()
+
+	// the intake outlives ctx, so the webhook requests accepted
+	// by the server before its shutdown still reach the handlers.
+	intake, stopIntake := context.WithCancel(context.WithoutCancel(ctx))
+	stopped := make(chan struct{})
+	a.addClosers(ctx, server, stopIntake, stopped)
+
+	a.dp.Logger().Info(
+		"Work",
+		logger.String("port", a.dp.Config().Bot.Port),
+		logger.Int("workers", a.workers),
+	)
+	a.dp.Logger().Info("✓ Bot started!")
+	a.dp.Intake().Set(intakeMode(bool(a.dp.Config().IsWebhook)), true)
+	go a.watchReload(ctx)
+	go func() {
+		defer close(stopped)
+		if a.dp.Config().IsWebhook {
+			a.startWebhook(intake)
+		} else {
+			a.bot.Start(intake)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+	case <-stopped:
+	}
+	timeout := a.dp.Config().ShutdownTimeout
+	a.dp.Logger().Info("shutting down", logger.Duration("timeout", timeout))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := closer.CloseAll(shutdownCtx); err != nil {
+		a.dp.Logger().Error("shutdown is not clean", logger.ErrAttr(err))
+	}
+	a.dp.Logger().Info("𐄂 Bot stopped!")
+}
+
+// watchReload reloads the config on SIGHUP until ctx is done.
+func (a *app) watchReload(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		}
+
+		cfg, err := a.dp.Config().Reload()
+		if err != nil {
+			a.dp.Logger().Error("config is not reloaded", logger.ErrAttr(err))
+			continue
+		}
+		logWarnings(a.dp.Logger(), cfg)
+		a.dp.Reload(cfg)
+		a.dp.Logger().Info(
+			"config reloaded",
+			logger.String("log_level", cfg.LogLevel),
+			logger.Any("admin_ids", cfg.AdminIDs),
+			logger.Int("router_rate_limit", cfg.Router.RateLimit),
+			logger.Int("api_rate_limit", cfg.API.RateLimit),
+		)
+	}
+}
+
+// addClosers registers the shutdown steps which are not connections.
+func (a *app) addClosers(ctx context.Context, server *http.Server, stopIntake context.CancelFunc, stopped <-chan struct{}) {
+	const op = "app.addClosers"
+
+	closer.Add(closeServer, "http server", server.Shutdown)
+	closer.Add(closeIntake, "intake", func(ctx context.Context) error {
+		stopIntake()
+		defer a.dp.Intake().Set(intakeMode(bool(a.dp.Config().IsWebhook)), false)
+
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	closer.Add(closeHandlers, "update handlers", func(ctx context.Context) error {
+		if err := a.executor.Shutdown(ctx); err != nil {
+			return er.New(strconv.Itoa(a.executor.Pending())+" updates left unhandled", op, err)
+		}
+		return nil
+	})
+	closer.Add(closeHandlers, "broadcasts", a.dp.Broadcast(ctx).Wait)
+	closer.Add(closeHandlers, "transcriptions", a.dp.Transcriber(ctx).Wait)
+	closer.Add(closeOutbound, "outbound queue", a.dp.Sender(ctx).Drain)
+}
+
+func (a *app) startWebhook(ctx context.Context) {
+	a.bot.StartWebhook(ctx)
+}
+
+func (a *app) selectConnection(ctx context.Context) {
+	if a.dp.Config().IsWebhook {
+		if _, err := a.bot.SetWebhook(ctx, &bot.SetWebhookParams{
+			URL:                a.dp.Config().Bot.WebhookURL,
+			DropPendingUpdates: true,
+		}); err != nil {
+			a.dp.Logger().Error("SetWebhook error:", logger.ErrAttr(err))
+			panic("SetWebhook error:" + err.Error())
+		}
+		a.dp.Logger().Info("webhook is set", logger.String("url", a.dp.Config().Bot.WebhookURL))
+	} else {
+		if _, err := a.bot.DeleteWebhook(ctx, &bot.DeleteWebhookParams{
+			DropPendingUpdates: true,
+		}); err != nil {
+			a.dp.Logger().Error("DeleteWebhook error:", logger.ErrAttr(err))
+			panic("DeleteWebhook error:" + err.Error())
+		}
+		a.dp.Logger().Info("polling is set")
+	}
+}
+
+func (a *app) setMenuButton(ctx context.Context) {
+	cfg := a.dp.Config().WebApp
+	if cfg.URL == "" {
+		return
+	}
+
+	text := cfg.ButtonText
+	if text == "" {
+		text = defaultMenuButtonText
+	}
+
+	if _, err := a.bot.SetChatMenuButton(ctx, &bot.SetChatMenuButtonParams{
+		MenuButton: models.MenuButtonWebApp{
+			Type:   models.MenuButtonTypeWebApp,
+			Text:   text,
+			WebApp: models.WebAppInfo{URL: cfg.URL},
+		},
+	}); err != nil {
+		a.dp.Logger().Error("SetChatMenuButton error:", logger.ErrAttr(err))
+		return
+	}
+	a.dp.Logger().Info("menu button is set", logger.String("url", cfg.URL))
+}
+
+func (a *app) addHealthChecks(ctx context.Context) {
+	a.dp.Health().Add("postgres", a.dp.DB(ctx).Ping)
+	a.dp.Health().Add("redis", func(ctx context.Context) error {
+		return a.dp.Redis(ctx).Ping(ctx).Err()
+	})
+	a.dp.Health().Add("telegram", a.dp.BotAPIMonitor().Check(
+		botAPIMaxAge,
+		func(ctx context.Context) error {
+			_, err := a.bot.GetMe(ctx)
+			return err
+		},
+	))
+	a.dp.Health().Add("intake", a.dp.Intake().Check)
+}
+
+func intakeMode(isWebhook bool) string {
+	if isWebhook {
+		return "webhook"
+	}
+	return "polling"
+}
@@ -1,372 +1,847 @@
-package app
-
-import (
-	"context"
-
-	"archive_bot/internal/config"
-	"archive_bot/internal/folder"
-	"archive_bot/internal/notes/animations"
-	"archive_bot/internal/notes/audios"
-	"archive_bot/internal/notes/documents"
-	"archive_bot/internal/notes/photos"
-	"archive_bot/internal/notes/texts"
-	"archive_bot/internal/notes/videos"
-	"archive_bot/internal/notes/voices"
-	"archive_bot/internal/processor"
-	"archive_bot/internal/router"
-	"archive_bot/internal/user"
-
-	"archive_bot/pkg/closer"
-	"archive_bot/pkg/database/postgres"
-	storage "archive_bot/pkg/database/redis"
-	"archive_bot/pkg/er"
-	"archive_bot/pkg/logger"
-
-	"github.com/go-telegram/bot"
-	"github.com/go-telegram/bot/models"
-	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/redis/go-redis/v9"
-)
-
-type Router interface {
-	RouteMessage(ctx context.Context, b *bot.Bot, update *models.Update)
-	RouteCallbackQuery(ctx context.Context, b *bot.Bot, update *models.Update)
-	RouteAdminMessage(ctx context.Context, b *bot.Bot, update *models.Update)
-	RouteAdminCallback(ctx context.Context, b *bot.Bot, update *models.Update)
-}
-
-type dependencyProvider struct {
-	config *config.Config
-	logger *logger.Logger
-
-	redis *redis.Client
-
-	db               *pgxpool.Pool
-	userRepository   user.Repository
-	folderRepository folder.Repository
-	textRepository   texts.Repository
-	photoRepository  photos.Repository
-	docsRepository   documents.Repository
-	videoRepository  videos.Repository
-	audioRepository  audios.Repository
-	aniRepository    animations.Repository
-	voiceRepository  voices.Repository
-
-	userService   processor.UserService
-	folderService processor.FolderService
-	textService   processor.TextNoteService
-	photoService  processor.PhotoNoteService
-	docsService   processor.DocsNoteService
-	videoService  processor.VideoNoteService
-	audioService  processor.VideoNoteService
-	aniService    processor.AniNoteService
-	voiceService  processor.VoiceNoteService
-
-	processor router.Processor
-
-	router Router
-}
-
-func newDependencyProvider() *dependencyProvider {
-	return &dependencyProvider{}
-}
-
-func (dp *dependencyProvider) Config() *config.Config {
-	const op = "app.Config"
-
-	if dp.config == nil {
-		cfg, err := config.New()
-		if err != nil {
-			panic(er.New("failed to get config", op, err))
-		}
-
-		dp.config = cfg
-	}
-
-	return dp.config
-}
-
-// TODO: log level from config or flag
-func (dp *dependencyProvider) Logger() *logger.Logger {
-	if dp.logger == nil {
-		dp.logger = logger.NewLogger(
-			logger.WithLevel(dp.Config().LogLevel),
-		)
-	}
-
-	return dp.logger
-}
-
-func (dp *dependencyProvider) Redis(ctx context.Context) *redis.Client {
-	const op = "app.Redis"
-
-	if dp.redis == nil {
-		db, err := storage.NewConnect(ctx, dp.Config().Redis.Options())
-		if err != nil {
-			panic(er.New("failed to connect to redis", op, err))
-		}
-		closer.Add(db.Close)
-		dp.Logger().Debug("✓ connected to redis")
-
-		dp.redis = db
-	}
-
-	return dp.redis
-}
-
-func (dp *dependencyProvider) DB(ctx context.Context) *pgxpool.Pool {
-	const op = "app.DB"
-
-	if dp.db == nil {
-		db, err := postgres.NewConnect(ctx, dp.Config().PostgresURL)
-		if err != nil {
-			panic(er.New("failed to connect to the database", op, err))
-		}
-		closer.Add(func() error {
-			db.Close()
-			return nil
-		})
-		dp.Logger().Debug("✓ connected to folder_holder_db db")
-
-		dp.db = db
-	}
-
-	return dp.db
-}
-
-func (dp *dependencyProvider) UserRepository(ctx context.Context) user.Repository {
-	const op = "app.UserRepository"
-
-	if dp.userRepository == nil {
-		repo, err := user.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
-		if err != nil {
-			panic(er.New("failed to create user repository", op, err))
-		}
-
-		dp.userRepository = repo
-	}
-
-	return dp.userRepository
-}
-
-func (dp *dependencyProvider) FolderRepository(ctx context.Context) folder.Repository {
-	const op = "app.FolderRepository"
-
-	if dp.folderRepository == nil {
-		repo, err := folder.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
-		if err != nil {
-			panic(er.New("failed to create folder repository", op, err))
-		}
-
-		dp.folderRepository = repo
-	}
-
-	return dp.folderRepository
-}
-
-func (dp *dependencyProvider) TextNoteRepository(ctx context.Context) texts.Repository {
-	const op = "app.TextNoteRepository"
-
-	if dp.textRepository == nil {
-		repo, err := texts.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
-		if err != nil {
-			panic(er.New("failed to create text note repository", op, err))
-		}
-
-		dp.textRepository = repo
-	}
-
-	return dp.textRepository
-}
-
-func (dp *dependencyProvider) PhotoNoteRepository(ctx context.Context) photos.Repository {
-	const op = "app.PhotoNoteRepository"
-
-	if dp.photoRepository == nil {
-		repo, err := photos.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
-		if err != nil {
-			panic(er.New("failed to create photo note repository", op, err))
-		}
-
-		dp.photoRepository = repo
-	}
-
-	return dp.photoRepository
-}
-
-func (dp *dependencyProvider) DocsNoteRepository(ctx context.Context) documents.Repository {
-	const op = "app.DocsNoteRepository"
-
-	if dp.docsRepository == nil {
-		repo, err := documents.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
-		if err != nil {
-			panic(er.New("failed to create documents note repository", op, err))
-		}
-
-		dp.docsRepository = repo
-	}
-
-	return dp.docsRepository
-}
-
-func (dp *dependencyProvider) VideoNoteRepository(ctx context.Context) videos.Repository {
-	const op = "app.VideoNoteRepository"
-
-	if dp.videoRepository == nil {
-		repo, err := videos.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
-		if err != nil {
-			panic(er.New("failed to create video note repository", op, err))
-		}
-
-		dp.videoRepository = repo
-	}
-
-	return dp.videoRepository
-}
-
-func (dp *dependencyProvider) AudioNoteRepository(ctx context.Context) audios.Repository {
-	const op = "app.AudioNoteRepository"
-
-	if dp.audioRepository == nil {
-		repo, err := audios.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
-		if err != nil {
-			panic(er.New("failed to create audio note repository", op, err))
-		}
-
-		dp.audioRepository = repo
-	}
-
-	return dp.audioRepository
-}
-
-func (dp *dependencyProvider) AniNoteRepository(ctx context.Context) animations.Repository {
-	const op = "app.AniNoteRepository"
-
-	if dp.aniRepository == nil {
-		repo, err := animations.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
-		if err != nil {
-			panic(er.New("failed to create animation note repository", op, err))
-		}
-
-		dp.aniRepository = repo
-	}
-
-	return dp.aniRepository
-}
-
-func (dp *dependencyProvider) VoiceNoteRepository(ctx context.Context) voices.Repository {
-	const op = "app.VoicesNoteRepository"
-
-	if dp.voiceRepository == nil {
-		repo, err := voices.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
-		if err != nil {
-			panic(er.New("failed to create voice note repository", op, err))
-		}
-
-		dp.voiceRepository = repo
-	}
-
-	return dp.voiceRepository
-}
-
-func (dp *dependencyProvider) UserService(ctx context.Context) processor.UserService {
-	if dp.userService == nil {
-		dp.userService = user.NewService(ctx, dp.Logger(), dp.UserRepository(ctx))
-	}
-
-	return dp.userService
-}
-
-func (dp *dependencyProvider) FolderService(ctx context.Context) processor.FolderService {
-	if dp.folderService == nil {
-		dp.folderService = folder.NewService(ctx, dp.Logger(), dp.FolderRepository(ctx))
-	}
-
-	return dp.folderService
-}
-
-func (dp *dependencyProvider) TextNoteService(ctx context.Context) processor.TextNoteService {
-	if dp.textService == nil {
-		dp.textService = texts.NewService(ctx, dp.Logger(), dp.TextNoteRepository(ctx))
-	}
-
-	return dp.textService
-}
-
-func (dp *dependencyProvider) PhotoNoteService(ctx context.Context) processor.PhotoNoteService {
-	if dp.photoService == nil {
-		dp.photoService = photos.NewService(ctx, dp.Logger(), dp.PhotoNoteRepository(ctx))
-	}
-
-	return dp.photoService
-}
-
-func (dp *dependencyProvider) DocsNoteService(ctx context.Context) processor.DocsNoteService {
-	if dp.docsService == nil {
-		dp.docsService = documents.NewService(ctx, dp.Logger(), dp.DocsNoteRepository(ctx))
-	}
-
-	return dp.docsService
-}
-
-func (dp *dependencyProvider) VideoNoteService(ctx context.Context) processor.VideoNoteService {
-	if dp.videoService == nil {
-		dp.videoService = videos.NewService(ctx, dp.Logger(), dp.VideoNoteRepository(ctx))
-	}
-
-	return dp.videoService
-}
-
-func (dp *dependencyProvider) AudioNoteService(ctx context.Context) processor.AudioNoteService {
-	if dp.audioService == nil {
-		dp.audioService = audios.NewService(ctx, dp.Logger(), dp.AudioNoteRepository(ctx))
-	}
-
-	return dp.audioService
-}
-func (dp *dependencyProvider) AniNoteService(ctx context.Context) processor.AniNoteService {
-	if dp.aniService == nil {
-		dp.aniService = animations.NewService(ctx, dp.Logger(), dp.AniNoteRepository(ctx))
-	}
-
-	return dp.aniService
-}
-func (dp *dependencyProvider) VoiceNoteService(ctx context.Context) processor.VoiceNoteService {
-	if dp.voiceService == nil {
-		dp.voiceService = voices.NewService(ctx, dp.Logger(), dp.VoiceNoteRepository(ctx))
-	}
-
-	return dp.voiceService
-}
-
-func (dp *dependencyProvider) Processor(ctx context.Context) router.Processor {
-	if dp.processor == nil {
-		dp.processor = processor.New(
-			dp.Logger(),
-			dp.Redis(ctx),
-			dp.UserService(ctx),
-			dp.FolderService(ctx),
-			dp.TextNoteService(ctx),
-			dp.PhotoNoteService(ctx),
-			dp.DocsNoteService(ctx),
-			dp.VideoNoteService(ctx),
-			dp.AudioNoteService(ctx),
-			dp.AniNoteService(ctx),
-			dp.VoiceNoteService(ctx),
-		)
-	}
-
-	return dp.processor
-}
-
-func (dp *dependencyProvider) Router(ctx context.Context) Router {
-	if dp.router == nil {
-		dp.router = router.New(
-			dp.Logger(),
-			dp.Config().AdminID,
-			dp.Processor(ctx),
-		)
-	}
-
-	return dp.router
-}
+package app
+
+import (
+	"context"
+	"net/http"
+
+	"archive_bot/internal/api"
+	"archive_bot/internal/audit"
+	"archive_bot/internal/broadcast"
+	"archive_bot/internal/channel"
+	"archive_bot/internal/config"
+	"archive_bot/internal/folder"
+	"archive_bot/internal/health"
+	"archive_bot/internal/metrics"
+	"archive_bot/internal/notes/animations"
+	"archive_bot/internal/notes/audios"
+	"archive_bot/internal/notes/documents"
+	"archive_bot/internal/notes/photos"
+	"archive_bot/internal/notes/texts"
+	"archive_bot/internal/notes/videos"
+	"archive_bot/internal/notes/voices"
+	"archive_bot/internal/processor"
+	"archive_bot/internal/role"
+	"archive_bot/internal/router"
+	"archive_bot/internal/rule"
+	"archive_bot/internal/sender"
+	"archive_bot/internal/stats"
+	"archive_bot/internal/token"
+	"archive_bot/internal/transcription"
+	"archive_bot/internal/user"
+	"archive_bot/internal/webapp"
+
+	"archive_bot/pkg/closer"
+	"archive_bot/pkg/database/postgres"
+	storage "archive_bot/pkg/database/redis"
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+type Router interface {
+	RouteMessage(ctx context.Context, b *bot.Bot, update *models.Update)
+	RouteCallbackQuery(ctx context.Context, b *bot.Bot, update *models.Update)
+	RouteAdminMessage(ctx context.Context, b *bot.Bot, update *models.Update)
+	RouteAdminCallback(ctx context.Context, b *bot.Bot, update *models.Update)
+	Middlewares(exec router.Executor) []bot.Middleware
+}
+
+type userService interface {
+	processor.UserService
+	SetLimits(l user.Limits)
+}
+
+type roleService interface {
+	router.Roles
+	SetSuperadmins(ids []int64)
+}
+
+type rateLimiter interface {
+	router.Limiter
+	SetLimit(perMinute int, burst int)
+}
+
+type apiServer interface {
+	Handler() http.Handler
+	SetRateLimit(rateLimit int)
+}
+
+type Sender interface {
+	router.Sender
+	Start(ctx context.Context, api sender.API)
+	Drain(ctx context.Context) error
+}
+
+type Broadcaster interface {
+	router.Broadcaster
+	Start(ctx context.Context, api broadcast.API)
+	Wait(ctx context.Context) error
+}
+
+type Transcriber interface {
+	processor.TranscriptionService
+	Start(ctx context.Context, api transcription.API)
+	Wait(ctx context.Context) error
+}
+
+type Health interface {
+	Add(name string, check health.Check)
+	Liveness(w http.ResponseWriter, r *http.Request)
+	Readiness(w http.ResponseWriter, r *http.Request)
+}
+
+// Services shared by the bot and the API.
+type (
+	folderService interface {
+		processor.FolderService
+		api.FolderService
+	}
+	textService interface {
+		processor.TextNoteService
+		api.NoteService
+		webapp.NoteService
+	}
+	tokenService interface {
+		processor.TokenService
+		api.TokenService
+	}
+	botProcessor interface {
+		router.Processor
+		sender.Tracker
+		api.FileService
+	}
+)
+
+const (
+	serviceName string = "archive_bot"
+)
+
+type dependencyProvider struct {
+	config   *config.Config
+	logger   *logger.Logger
+	logLevel *logger.LevelVar
+	tracing  bool
+
+	redis *redis.Client
+
+	db                *pgxpool.Pool
+	userRepository    user.Repository
+	folderRepository  folder.Repository
+	textRepository    texts.Repository
+	photoRepository   photos.Repository
+	docsRepository    documents.Repository
+	videoRepository   videos.Repository
+	audioRepository   audios.Repository
+	aniRepository     animations.Repository
+	voiceRepository   voices.Repository
+	channelRepository channel.Repository
+	tokenRepository   token.Repository
+	ruleRepository    rule.Repository
+	broadcastRepo     broadcast.Repository
+	transcriptionRepo transcription.Repository
+	statsRepository   stats.Repository
+	auditRepository   audit.Repository
+	roleRepository    role.Repository
+
+	userService    userService
+	folderService  folderService
+	textService    textService
+	photoService   processor.PhotoNoteService
+	docsService    processor.DocsNoteService
+	videoService   processor.VideoNoteService
+	audioService   processor.VideoNoteService
+	aniService     processor.AniNoteService
+	voiceService   processor.VoiceNoteService
+	channelService processor.ChannelService
+	tokenService   tokenService
+	ruleService    processor.RuleService
+
+	processor   botProcessor
+	sender      Sender
+	broadcast   Broadcaster
+	transcriber Transcriber
+	stats       router.Stats
+	audit       router.Auditor
+	roles       roleService
+
+	router    Router
+	limiter   rateLimiter
+	apiServer apiServer
+	api       http.Handler
+	webapp    http.Handler
+
+	health        Health
+	botAPIMonitor *health.BotAPIMonitor
+	intake        *health.Intake
+}
+
+func newDependencyProvider() *dependencyProvider {
+	return &dependencyProvider{}
+}
+
+func (dp *dependencyProvider) Config() *config.Config {
+	const op = "app.Config"
+
+	if dp.config == nil {
+		cfg, err := config.New()
+		if err != nil {
+			panic(er.New("failed to get config", op, err))
+		}
+
+		dp.config = cfg
+	}
+
+	return dp.config
+}
+
+func (dp *dependencyProvider) Logger() *logger.Logger {
+	if dp.logger == nil {
+		level, _ := logger.ParseLevel(dp.Config().LogLevel)
+		dp.logLevel = new(logger.LevelVar)
+		dp.logLevel.Set(level)
+
+		dp.logger = logger.NewLogger(
+			logger.WithLevelVar(dp.logLevel),
+		)
+		logWarnings(dp.logger, dp.Config())
+	}
+
+	return dp.logger
+}
+
+func logWarnings(log *logger.Logger, cfg *config.Config) {
+	for _, w := range cfg.Warnings() {
+		log.Warn("config: "+w, logger.String("hint", "remove the key from the config file"))
+	}
+}
+
+// Reload applies the fields of the reloaded config which are safe to change
+// at runtime: the log level, the admins, the rate limits and the user
+// limits. The others take effect after the restart.
+func (dp *dependencyProvider) Reload(cfg *config.Config) {
+	if level, err := logger.ParseLevel(cfg.LogLevel); err == nil && dp.logLevel != nil {
+		dp.logLevel.Set(level)
+	}
+	if dp.roles != nil {
+		dp.roles.SetSuperadmins(cfg.AdminIDs)
+	}
+	if dp.userService != nil {
+		dp.userService.SetLimits(userLimits(cfg))
+	}
+	if dp.limiter != nil {
+		dp.limiter.SetLimit(cfg.Router.RateLimit, cfg.Router.Burst)
+	}
+	if dp.apiServer != nil {
+		dp.apiServer.SetRateLimit(cfg.API.RateLimit)
+	}
+}
+
+// Tracing sets the global tracer provider, it must be called before
+// the connections are created.
+func (dp *dependencyProvider) Tracing(ctx context.Context) {
+	const op = "app.Tracing"
+
+	if dp.tracing {
+		return
+	}
+
+	cfg := dp.Config().Tracing
+	shutdown, err := tracing.Init(ctx, tracing.Config{
+		Exporter:    cfg.Exporter,
+		Endpoint:    cfg.Endpoint,
+		ServiceName: serviceName,
+		SampleRatio: cfg.SampleRatio,
+	})
+	if err != nil {
+		panic(er.New("failed to initialize tracing", op, err))
+	}
+	closer.Add(closeTelemetry, "tracing", shutdown)
+	if cfg.Exporter != "" {
+		dp.Logger().Debug("✓ tracing enabled", logger.String("exporter", cfg.Exporter))
+	}
+
+	dp.tracing = true
+}
+
+func (dp *dependencyProvider) Redis(ctx context.Context) *redis.Client {
+	const op = "app.Redis"
+
+	if dp.redis == nil {
+		db, err := storage.NewConnect(ctx, dp.Config().Redis.Options())
+		if err != nil {
+			panic(er.New("failed to connect to redis", op, err))
+		}
+		closer.Add(closeCache, "redis", func(context.Context) error {
+			return db.Close()
+		})
+		metrics.RegisterRedisPool(db)
+		dp.Logger().Debug("✓ connected to redis")
+
+		dp.redis = db
+	}
+
+	return dp.redis
+}
+
+func (dp *dependencyProvider) DB(ctx context.Context) *pgxpool.Pool {
+	const op = "app.DB"
+
+	if dp.db == nil {
+		db, err := postgres.NewConnect(ctx, dp.Config().PostgresURL)
+		if err != nil {
+			panic(er.New("failed to connect to the database", op, err))
+		}
+		closer.Add(closeDB, "postgres", func(context.Context) error {
+			db.Close()
+			return nil
+		})
+		metrics.RegisterDBPool(db)
+		dp.Logger().Debug("✓ connected to folder_holder_db db")
+
+		dp.db = db
+	}
+
+	return dp.db
+}
+
+func (dp *dependencyProvider) UserRepository(ctx context.Context) user.Repository {
+	const op = "app.UserRepository"
+
+	if dp.userRepository == nil {
+		repo, err := user.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create user repository", op, err))
+		}
+
+		dp.userRepository = repo
+	}
+
+	return dp.userRepository
+}
+
+func (dp *dependencyProvider) FolderRepository(ctx context.Context) folder.Repository {
+	const op = "app.FolderRepository"
+
+	if dp.folderRepository == nil {
+		repo, err := folder.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create folder repository", op, err))
+		}
+
+		dp.folderRepository = repo
+	}
+
+	return dp.folderRepository
+}
+
+func (dp *dependencyProvider) TextNoteRepository(ctx context.Context) texts.Repository {
+	const op = "app.TextNoteRepository"
+
+	if dp.textRepository == nil {
+		repo, err := texts.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create text note repository", op, err))
+		}
+
+		dp.textRepository = repo
+	}
+
+	return dp.textRepository
+}
+
+func (dp *dependencyProvider) PhotoNoteRepository(ctx context.Context) photos.Repository {
+	const op = "app.PhotoNoteRepository"
+
+	if dp.photoRepository == nil {
+		repo, err := photos.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create photo note repository", op, err))
+		}
+
+		dp.photoRepository = repo
+	}
+
+	return dp.photoRepository
+}
+
+func (dp *dependencyProvider) DocsNoteRepository(ctx context.Context) documents.Repository {
+	const op = "app.DocsNoteRepository"
+
+	if dp.docsRepository == nil {
+		repo, err := documents.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create documents note repository", op, err))
+		}
+
+		dp.docsRepository = repo
+	}
+
+	return dp.docsRepository
+}
+
+func (dp *dependencyProvider) VideoNoteRepository(ctx context.Context) videos.Repository {
+	const op = "app.VideoNoteRepository"
+
+	if dp.videoRepository == nil {
+		repo, err := videos.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create video note repository", op, err))
+		}
+
+		dp.videoRepository = repo
+	}
+
+	return dp.videoRepository
+}
+
+func (dp *dependencyProvider) AudioNoteRepository(ctx context.Context) audios.Repository {
+	const op = "app.AudioNoteRepository"
+
+	if dp.audioRepository == nil {
+		repo, err := audios.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create audio note repository", op, err))
+		}
+
+		dp.audioRepository = repo
+	}
+
+	return dp.audioRepository
+}
+
+func (dp *dependencyProvider) AniNoteRepository(ctx context.Context) animations.Repository {
+	const op = "app.AniNoteRepository"
+
+	if dp.aniRepository == nil {
+		repo, err := animations.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create animation note repository", op, err))
+		}
+
+		dp.aniRepository = repo
+	}
+
+	return dp.aniRepository
+}
+
+func (dp *dependencyProvider) VoiceNoteRepository(ctx context.Context) voices.Repository {
+	const op = "app.VoicesNoteRepository"
+
+	if dp.voiceRepository == nil {
+		repo, err := voices.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create voice note repository", op, err))
+		}
+
+		dp.voiceRepository = repo
+	}
+
+	return dp.voiceRepository
+}
+
+func (dp *dependencyProvider) ChannelRepository(ctx context.Context) channel.Repository {
+	const op = "app.ChannelRepository"
+
+	if dp.channelRepository == nil {
+		repo, err := channel.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create channel repository", op, err))
+		}
+
+		dp.channelRepository = repo
+	}
+
+	return dp.channelRepository
+}
+
+func (dp *dependencyProvider) TokenRepository(ctx context.Context) token.Repository {
+	const op = "app.TokenRepository"
+
+	if dp.tokenRepository == nil {
+		repo, err := token.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create token repository", op, err))
+		}
+
+		dp.tokenRepository = repo
+	}
+
+	return dp.tokenRepository
+}
+
+func (dp *dependencyProvider) RuleRepository(ctx context.Context) rule.Repository {
+	const op = "app.RuleRepository"
+
+	if dp.ruleRepository == nil {
+		repo, err := rule.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create rule repository", op, err))
+		}
+
+		dp.ruleRepository = repo
+	}
+
+	return dp.ruleRepository
+}
+
+func (dp *dependencyProvider) BroadcastRepository(ctx context.Context) broadcast.Repository {
+	const op = "app.BroadcastRepository"
+
+	if dp.broadcastRepo == nil {
+		repo, err := broadcast.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create broadcast repository", op, err))
+		}
+
+		dp.broadcastRepo = repo
+	}
+
+	return dp.broadcastRepo
+}
+
+func (dp *dependencyProvider) TranscriptionRepository(ctx context.Context) transcription.Repository {
+	const op = "app.TranscriptionRepository"
+
+	if dp.transcriptionRepo == nil {
+		repo, err := transcription.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create transcription repository", op, err))
+		}
+
+		dp.transcriptionRepo = repo
+	}
+
+	return dp.transcriptionRepo
+}
+
+func (dp *dependencyProvider) StatsRepository(ctx context.Context) stats.Repository {
+	const op = "app.StatsRepository"
+
+	if dp.statsRepository == nil {
+		repo, err := stats.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create stats repository", op, err))
+		}
+
+		dp.statsRepository = repo
+	}
+
+	return dp.statsRepository
+}
+
+func (dp *dependencyProvider) AuditRepository(ctx context.Context) audit.Repository {
+	const op = "app.AuditRepository"
+
+	if dp.auditRepository == nil {
+		repo, err := audit.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create audit repository", op, err))
+		}
+
+		dp.auditRepository = repo
+	}
+
+	return dp.auditRepository
+}
+
+func (dp *dependencyProvider) RoleRepository(ctx context.Context) role.Repository {
+	const op = "app.RoleRepository"
+
+	if dp.roleRepository == nil {
+		repo, err := role.NewRepository(ctx, dp.Logger(), dp.DB(ctx))
+		if err != nil {
+			panic(er.New("failed to create role repository", op, err))
+		}
+
+		dp.roleRepository = repo
+	}
+
+	return dp.roleRepository
+}
+
+func (dp *dependencyProvider) UserService(ctx context.Context) userService {
+	if dp.userService == nil {
+		dp.userService = user.NewService(ctx, dp.Logger(), dp.UserRepository(ctx), userLimits(dp.Config()))
+	}
+
+	return dp.userService
+}
+
+func userLimits(cfg *config.Config) user.Limits {
+	return user.Limits{
+		NotesPerDay:   cfg.Limits.NotesPerDay,
+		MaxNotes:      cfg.Limits.MaxNotes,
+		MaxFolders:    cfg.Limits.MaxFolders,
+		MaxMediaBytes: cfg.Limits.MaxMediaMB << 20,
+	}
+}
+
+func (dp *dependencyProvider) FolderService(ctx context.Context) folderService {
+	if dp.folderService == nil {
+		dp.folderService = folder.NewService(ctx, dp.Logger(), dp.FolderRepository(ctx))
+	}
+
+	return dp.folderService
+}
+
+func (dp *dependencyProvider) TextNoteService(ctx context.Context) textService {
+	if dp.textService == nil {
+		dp.textService = texts.NewService(ctx, dp.Logger(), dp.TextNoteRepository(ctx))
+	}
+
+	return dp.textService
+}
+
+func (dp *dependencyProvider) PhotoNoteService(ctx context.Context) processor.PhotoNoteService {
+	if dp.photoService == nil {
+		dp.photoService = photos.NewService(ctx, dp.Logger(), dp.PhotoNoteRepository(ctx))
+	}
+
+	return dp.photoService
+}
+
+func (dp *dependencyProvider) DocsNoteService(ctx context.Context) processor.DocsNoteService {
+	if dp.docsService == nil {
+		dp.docsService = documents.NewService(ctx, dp.Logger(), dp.DocsNoteRepository(ctx))
+	}
+
+	return dp.docsService
+}
+
+func (dp *dependencyProvider) VideoNoteService(ctx context.Context) processor.VideoNoteService {
+	if dp.videoService == nil {
+		dp.videoService = videos.NewService(ctx, dp.Logger(), dp.VideoNoteRepository(ctx))
+	}
+
+	return dp.videoService
+}
+
+func (dp *dependencyProvider) AudioNoteService(ctx context.Context) processor.AudioNoteService {
+	if dp.audioService == nil {
+		dp.audioService = audios.NewService(ctx, dp.Logger(), dp.AudioNoteRepository(ctx))
+	}
+
+	return dp.audioService
+}
+func (dp *dependencyProvider) AniNoteService(ctx context.Context) processor.AniNoteService {
+	if dp.aniService == nil {
+		dp.aniService = animations.NewService(ctx, dp.Logger(), dp.AniNoteRepository(ctx))
+	}
+
+	return dp.aniService
+}
+func (dp *dependencyProvider) VoiceNoteService(ctx context.Context) processor.VoiceNoteService {
+	if dp.voiceService == nil {
+		dp.voiceService = voices.NewService(ctx, dp.Logger(), dp.VoiceNoteRepository(ctx))
+	}
+
+	return dp.voiceService
+}
+
+func (dp *dependencyProvider) ChannelService(ctx context.Context) processor.ChannelService {
+	if dp.channelService == nil {
+		dp.channelService = channel.NewService(ctx, dp.Logger(), dp.ChannelRepository(ctx))
+	}
+
+	return dp.channelService
+}
+
+func (dp *dependencyProvider) TokenService(ctx context.Context) tokenService {
+	if dp.tokenService == nil {
+		dp.tokenService = token.NewService(ctx, dp.Logger(), dp.TokenRepository(ctx))
+	}
+
+	return dp.tokenService
+}
+
+func (dp *dependencyProvider) RuleService(ctx context.Context) processor.RuleService {
+	if dp.ruleService == nil {
+		dp.ruleService = rule.NewService(ctx, dp.Logger(), dp.RuleRepository(ctx))
+	}
+
+	return dp.ruleService
+}
+
+func (dp *dependencyProvider) Processor(ctx context.Context) botProcessor {
+	if dp.processor == nil {
+		p := processor.New(
+			dp.Logger(),
+			dp.Redis(ctx),
+			dp.UserService(ctx),
+			dp.FolderService(ctx),
+			dp.TextNoteService(ctx),
+			dp.PhotoNoteService(ctx),
+			dp.DocsNoteService(ctx),
+			dp.VideoNoteService(ctx),
+			dp.AudioNoteService(ctx),
+			dp.AniNoteService(ctx),
+			dp.VoiceNoteService(ctx),
+			dp.ChannelService(ctx),
+			dp.TokenService(ctx),
+			dp.RuleService(ctx),
+			dp.Transcriber(ctx),
+		)
+		metrics.RegisterActiveFlows(p.ActiveFlows)
+
+		dp.processor = p
+	}
+
+	return dp.processor
+}
+
+func (dp *dependencyProvider) Sender(ctx context.Context) Sender {
+	if dp.sender == nil {
+		cfg := dp.Config().Sender
+		dp.sender = sender.New(dp.Logger(), dp.Redis(ctx), dp.Processor(ctx), sender.Config{
+			GlobalRate:  cfg.GlobalRate,
+			ChatRate:    cfg.ChatRate,
+			ChatBurst:   cfg.ChatBurst,
+			Workers:     cfg.Workers,
+			MaxAttempts: cfg.MaxAttempts,
+		})
+	}
+
+	return dp.sender
+}
+
+func (dp *dependencyProvider) Broadcast(ctx context.Context) Broadcaster {
+	if dp.broadcast == nil {
+		dp.broadcast = broadcast.NewService(
+			ctx, dp.Logger(), dp.BroadcastRepository(ctx), dp.Config().Broadcast.Rate,
+		)
+	}
+
+	return dp.broadcast
+}
+
+// Transcriber transcribes the voice and audio notes, it does nothing
+// without transcription.url in the config.
+func (dp *dependencyProvider) Transcriber(ctx context.Context) Transcriber {
+	if dp.transcriber == nil {
+		cfg := dp.Config().Transcription
+
+		var backend transcription.Transcriber
+		if cfg.URL != "" {
+			backend = transcription.NewWhisper(cfg.URL, cfg.Model, cfg.Language, cfg.Timeout)
+		}
+		dp.transcriber = transcription.NewService(
+			ctx, dp.Logger(), dp.TranscriptionRepository(ctx), backend, cfg.MaxAttempts,
+		)
+	}
+
+	return dp.transcriber
+}
+
+func (dp *dependencyProvider) Stats(ctx context.Context) router.Stats {
+	if dp.stats == nil {
+		dp.stats = stats.NewService(ctx, dp.Logger(), dp.StatsRepository(ctx))
+	}
+
+	return dp.stats
+}
+
+func (dp *dependencyProvider) Audit(ctx context.Context) router.Auditor {
+	if dp.audit == nil {
+		dp.audit = audit.NewService(ctx, dp.Logger(), dp.AuditRepository(ctx))
+	}
+
+	return dp.audit
+}
+
+// Roles knows the admins, the admins from the config are superadmins.
+func (dp *dependencyProvider) Roles(ctx context.Context) roleService {
+	if dp.roles == nil {
+		dp.roles = role.NewService(ctx, dp.Logger(), dp.RoleRepository(ctx), dp.Config().AdminIDs)
+	}
+
+	return dp.roles
+}
+
+func (dp *dependencyProvider) Router(ctx context.Context) Router {
+	if dp.router == nil {
+		dp.limiter = router.NewRedisLimiter(
+			dp.Logger(),
+			dp.Redis(ctx),
+			dp.Config().Router.RateLimit,
+			dp.Config().Router.Burst,
+		)
+		dp.router = router.New(
+			dp.Logger(),
+			dp.Roles(ctx),
+			dp.Config().Group.Keyword,
+			dp.Config().Router.FolderColumns,
+			dp.Processor(ctx),
+			dp.Sender(ctx),
+			dp.limiter,
+			dp.Broadcast(ctx),
+			dp.Stats(ctx),
+			dp.Audit(ctx),
+		)
+	}
+
+	return dp.router
+}
+
+func (dp *dependencyProvider) API(ctx context.Context, downloader api.FileDownloader) http.Handler {
+	if dp.api == nil {
+		dp.apiServer = api.New(
+			dp.Logger(),
+			dp.Redis(ctx),
+			dp.Config().API.RateLimit,
+			dp.TokenService(ctx),
+			dp.UserService(ctx),
+			dp.FolderService(ctx),
+			dp.RuleService(ctx),
+			dp.TextNoteService(ctx),
+			dp.UserService(ctx),
+			dp.Processor(ctx),
+			downloader,
+		)
+		dp.api = dp.apiServer.Handler()
+	}
+
+	return dp.api
+}
+
+func (dp *dependencyProvider) WebApp(ctx context.Context) http.Handler {
+	if dp.webapp == nil {
+		dp.webapp = webapp.New(
+			dp.Logger(),
+			dp.Config().Bot.Token,
+			dp.FolderService(ctx),
+			dp.TextNoteService(ctx),
+			dp.UserService(ctx),
+		).Handler()
+	}
+
+	return dp.webapp
+}
+
+func (dp *dependencyProvider) Health() Health {
+	if dp.health == nil {
+		dp.health = health.New()
+	}
+
+	return dp.health
+}
+
+func (dp *dependencyProvider) BotAPIMonitor() *health.BotAPIMonitor {
+	if dp.botAPIMonitor == nil {
+		dp.botAPIMonitor = health.NewBotAPIMonitor(&http.Client{Timeout: dp.Config().Bot.PollTimeout})
+	}
+
+	return dp.botAPIMonitor
+}
+
+func (dp *dependencyProvider) Intake() *health.Intake {
+	if dp.intake == nil {
+		dp.intake = &health.Intake{}
+	}
+
+	return dp.intake
+}
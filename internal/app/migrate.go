@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"archive_bot/migrations"
+
+	"archive_bot/pkg/closer"
+	"archive_bot/pkg/database/postgres"
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+)
+
+const (
+	migrateUp     string = "up"
+	migrateDown   string = "down"
+	migrateStatus string = "status"
+)
+
+// Migrate runs the migrate subcommand: "up" applies the pending migrations,
+// "down" rolls back the latest one and "status" lists them.
+func Migrate(ctx context.Context, command string, w io.Writer) error {
+	const op = "app.Migrate"
+
+	switch command {
+	case migrateUp, migrateDown, migrateStatus:
+	default:
+		return er.New("unknown migrate command "+strconv.Quote(command)+", use up, down or status", op, nil)
+	}
+
+	dp := newDependencyProvider()
+	defer closer.CloseAll(context.WithoutCancel(ctx))
+
+	m, err := postgres.NewMigrator(dp.DB(ctx), migrations.FS)
+	if err != nil {
+		return er.New("failed to create migrator", op, err)
+	}
+	defer m.Close()
+
+	switch command {
+	case migrateUp:
+		applied, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "applied %d migrations\n", len(applied))
+	case migrateDown:
+		version, err := m.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if version == 0 {
+			fmt.Fprintln(w, "no migrations to roll back")
+			return nil
+		}
+		fmt.Fprintln(w, "rolled back "+strconv.FormatInt(version, 10))
+	case migrateStatus:
+		return m.Status(ctx, w)
+	}
+
+	return nil
+}
+
+// checkSchema applies the pending migrations when auto_migrate is set and
+// refuses to start when the schema is behind the embedded migrations.
+func (a *app) checkSchema(ctx context.Context) {
+	const op = "app.checkSchema"
+
+	m, err := postgres.NewMigrator(a.dp.DB(ctx), migrations.FS)
+	if err != nil {
+		panic(er.New("failed to create migrator", op, err))
+	}
+	defer m.Close()
+
+	if a.dp.Config().AutoMigrate {
+		applied, err := m.Up(ctx)
+		if err != nil {
+			panic(er.New("failed to migrate the database", op, err))
+		}
+		if len(applied) > 0 {
+			a.dp.Logger().Info("✓ database migrated", logger.Int("applied", len(applied)))
+		}
+	}
+
+	current, latest, err := m.Check(ctx)
+	if errors.Is(err, postgres.ErrSchemaBehind) {
+		panic(er.New(
+			"database schema version "+strconv.FormatInt(current, 10)+
+				" is behind "+strconv.FormatInt(latest, 10)+
+				", run \"migrate up\" or set auto_migrate", op, err,
+		))
+	}
+	if err != nil {
+		panic(er.New("failed to check the database schema", op, err))
+	}
+	a.dp.Logger().Debug("✓ database schema is up to date", logger.Int64("version", current))
+}
@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"context"
+	"sync"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Entry is an action of an admin.
+type Entry struct {
+	AdminID int64
+	Action  string
+	Details string
+}
+
+var (
+	instance *pgRepository
+	once     sync.Once
+)
+
+type pgRepository struct {
+	log *logger.Logger
+	db  *pgxpool.Pool
+}
+
+// NewRepository creates new audit repository.
+func NewRepository(ctx context.Context, log *logger.Logger, db *pgxpool.Pool) (*pgRepository, error) {
+	once.Do(func() {
+		instance = &pgRepository{log: log, db: db}
+	})
+
+	return instance, nil
+}
+
+func (repo *pgRepository) Save(ctx context.Context, e *Entry) error {
+	const op string = "audit.repository.Save"
+
+	if _, err := repo.db.Exec(ctx,
+		`INSERT INTO admin_audit (admin_id, action, details)
+		VALUES ($1, $2, $3);`,
+		e.AdminID, e.Action, e.Details); err != nil {
+		return er.New("unable to save audit entry", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"context"
+
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+type Repository interface {
+	Save(ctx context.Context, e *Entry) error
+}
+
+type service struct {
+	log  *logger.Logger
+	repo Repository
+}
+
+func NewService(ctx context.Context, log *logger.Logger, repo Repository) *service {
+	return &service{log: log, repo: repo}
+}
+
+// Log records the action, a failure is logged and does not stop the action.
+func (s *service) Log(ctx context.Context, adminID int64, action string, details string) {
+	ctx, span := tracing.Start(ctx, "audit.service.Log")
+	defer span.End()
+
+	e := &Entry{AdminID: adminID, Action: action, Details: details}
+	if err := s.repo.Save(ctx, e); err != nil {
+		s.log.Error(
+			"failed to save audit entry",
+			logger.Int64("admin_id", adminID),
+			logger.String("action", action),
+			logger.ErrAttr(err),
+		)
+	}
+}
@@ -0,0 +1,69 @@
+package broadcast
+
+import (
+	"strconv"
+	"strings"
+
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/i18n"
+)
+
+type Status string
+
+const (
+	StatusDraft     Status = "draft"
+	StatusRunning   Status = "running"
+	StatusCancelled Status = "cancelled"
+	StatusDone      Status = "done"
+)
+
+// Broadcast copies the message of the admin to every user. Users are sent
+// in the order of their IDs, LastUserID is the last one processed, so the
+// job resumes from it after a restart. The report is in the Language of
+// the admin.
+type Broadcast struct {
+	ID         int64
+	AdminID    int64
+	FromChatID int64
+	MessageID  int
+	Status     Status
+	LastUserID int64
+	Delivered  int
+	Failed     int
+	Blocked    int
+	Language   i18n.Lang
+}
+
+// Report is the summary sent to the admin when the job is over.
+func (b *Broadcast) Report() string {
+	title := messages.BroadcastFinished
+	if b.Status == StatusCancelled {
+		title = messages.BroadcastCancelled
+	}
+
+	return i18n.T(b.Language, messages.BroadcastReport,
+		i18n.T(b.Language, title, b.ID), b.Delivered, b.Failed, b.Blocked,
+	)
+}
+
+func (b *Broadcast) String() string {
+	sb := &strings.Builder{}
+
+	sb.WriteString("Broadcast{ID: ")
+	sb.WriteString(strconv.FormatInt(b.ID, 10))
+	sb.WriteString(", AdminID: ")
+	sb.WriteString(strconv.FormatInt(b.AdminID, 10))
+	sb.WriteString(", Status: ")
+	sb.WriteString(string(b.Status))
+	sb.WriteString(", LastUserID: ")
+	sb.WriteString(strconv.FormatInt(b.LastUserID, 10))
+	sb.WriteString(", Delivered: ")
+	sb.WriteString(strconv.Itoa(b.Delivered))
+	sb.WriteString(", Failed: ")
+	sb.WriteString(strconv.Itoa(b.Failed))
+	sb.WriteString(", Blocked: ")
+	sb.WriteString(strconv.Itoa(b.Blocked))
+	sb.WriteRune('}')
+
+	return sb.String()
+}
@@ -0,0 +1,183 @@
+package broadcast
+
+import (
+	"context"
+	"sync"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrNoBroadcast = er.New("the broadcast is not found or is in another status", "", nil)
+
+var (
+	instance *pgRepository
+	once     sync.Once
+)
+
+type pgRepository struct {
+	log *logger.Logger
+	db  *pgxpool.Pool
+}
+
+// NewRepository creates new broadcast repository.
+func NewRepository(ctx context.Context, log *logger.Logger, db *pgxpool.Pool) (*pgRepository, error) {
+	once.Do(func() {
+		instance = &pgRepository{log: log, db: db}
+	})
+
+	return instance, nil
+}
+
+func (repo *pgRepository) Save(ctx context.Context, b *Broadcast) (int64, error) {
+	const op string = "broadcast.repository.Save"
+
+	var id int64
+	if err := repo.db.QueryRow(ctx,
+		`INSERT INTO broadcasts (admin_id, from_chat_id, message_id, status, language)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id;`,
+		b.AdminID, b.FromChatID, b.MessageID, b.Status, b.Language).Scan(&id); err != nil {
+		return 0, er.New("unable to save broadcast", op, err)
+	}
+
+	return id, nil
+}
+
+func (repo *pgRepository) Find(ctx context.Context, id int64) (*Broadcast, error) {
+	const op string = "broadcast.repository.Find"
+
+	b, err := scan(repo.db.QueryRow(ctx,
+		`SELECT id, admin_id, from_chat_id, message_id, status,
+		last_user_id, delivered, failed, blocked, language
+		FROM broadcasts WHERE id = $1;`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNoBroadcast
+		}
+		return nil, er.New("unable to find broadcast", op, err)
+	}
+
+	return b, nil
+}
+
+// Running returns the jobs interrupted by the restart.
+func (repo *pgRepository) Running(ctx context.Context) ([]*Broadcast, error) {
+	const op string = "broadcast.repository.Running"
+
+	rows, err := repo.db.Query(ctx,
+		`SELECT id, admin_id, from_chat_id, message_id, status,
+		last_user_id, delivered, failed, blocked, language
+		FROM broadcasts WHERE status = $1
+		ORDER BY id;`, StatusRunning)
+	if err != nil {
+		return nil, er.New("unable to get running broadcasts", op, err)
+	}
+	defer rows.Close()
+
+	var res []*Broadcast
+	for rows.Next() {
+		b, err := scan(rows)
+		if err != nil {
+			return nil, er.New("unable to scan broadcast", op, err)
+		}
+		res = append(res, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, er.New("unable to get running broadcasts", op, err)
+	}
+
+	return res, nil
+}
+
+// SetStatus moves the broadcast to the status if it is in one of the from statuses.
+func (repo *pgRepository) SetStatus(ctx context.Context, id int64, status Status, from ...Status) error {
+	const op string = "broadcast.repository.SetStatus"
+
+	statuses := make([]string, 0, len(from))
+	for _, s := range from {
+		statuses = append(statuses, string(s))
+	}
+
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE broadcasts SET status = $2,
+		finished_at = CASE WHEN $2 IN ('cancelled', 'done') THEN CURRENT_TIMESTAMP END
+		WHERE id = $1 AND status = ANY($3);`, id, status, statuses)
+	if err != nil {
+		return er.New("unable to set broadcast status", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoBroadcast
+	}
+
+	return nil
+}
+
+// SaveProgress stores the counters and the last processed user and returns
+// the current status, which is changed by the admin cancelling the job.
+func (repo *pgRepository) SaveProgress(ctx context.Context, b *Broadcast) (Status, error) {
+	const op string = "broadcast.repository.SaveProgress"
+
+	var status Status
+	if err := repo.db.QueryRow(ctx,
+		`UPDATE broadcasts
+		SET last_user_id = $2, delivered = $3, failed = $4, blocked = $5
+		WHERE id = $1
+		RETURNING status;`,
+		b.ID, b.LastUserID, b.Delivered, b.Failed, b.Blocked).Scan(&status); err != nil {
+		return "", er.New("unable to save broadcast progress", op, err)
+	}
+
+	return status, nil
+}
+
+// Recipients returns the next users after the given one in the order of IDs.
+// The users who blocked the bot and the banned ones are skipped.
+func (repo *pgRepository) Recipients(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	const op string = "broadcast.repository.Recipients"
+
+	rows, err := repo.db.Query(ctx,
+		`SELECT id FROM users
+		WHERE id > $1 AND blocked_at IS NULL AND banned_at IS NULL
+		ORDER BY id
+		LIMIT $2;`, afterID, limit)
+	if err != nil {
+		return nil, er.New("unable to get recipients", op, err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, er.New("unable to scan recipients", op, err)
+	}
+
+	return ids, nil
+}
+
+// SetBlocked marks the user who blocked the bot, the mark is removed when
+// the user writes to the bot again.
+func (repo *pgRepository) SetBlocked(ctx context.Context, userID int64) error {
+	const op string = "broadcast.repository.SetBlocked"
+
+	if _, err := repo.db.Exec(ctx,
+		`UPDATE users SET blocked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND blocked_at IS NULL;`, userID); err != nil {
+		return er.New("unable to mark blocked user", op, err)
+	}
+
+	return nil
+}
+
+func scan(row pgx.Row) (*Broadcast, error) {
+	var b Broadcast
+	if err := row.Scan(
+		&b.ID, &b.AdminID, &b.FromChatID, &b.MessageID, &b.Status,
+		&b.LastUserID, &b.Delivered, &b.Failed, &b.Blocked, &b.Language,
+	); err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
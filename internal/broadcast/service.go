@@ -0,0 +1,345 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/metrics"
+
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/time/rate"
+)
+
+const (
+	batchSize   int     = 100
+	defaultRate float64 = 10
+
+	firstRetry time.Duration = time.Second
+	maxRetry   time.Duration = time.Minute
+)
+
+type Repository interface {
+	Save(ctx context.Context, b *Broadcast) (int64, error)
+	Find(ctx context.Context, id int64) (*Broadcast, error)
+	Running(ctx context.Context) ([]*Broadcast, error)
+	SetStatus(ctx context.Context, id int64, status Status, from ...Status) error
+	SaveProgress(ctx context.Context, b *Broadcast) (Status, error)
+	Recipients(ctx context.Context, afterID int64, limit int) ([]int64, error)
+	SetBlocked(ctx context.Context, userID int64) error
+}
+
+// API is the part of the Bot API used by the jobs.
+type API interface {
+	CopyMessage(ctx context.Context, params *bot.CopyMessageParams) (*models.MessageID, error)
+	SendMessage(ctx context.Context, params *bot.SendMessageParams) (*models.Message, error)
+}
+
+// service runs the broadcast jobs. A job copies the message to the users
+// one by one at the configured rate and saves its progress after every
+// batch, the jobs interrupted by a shutdown are resumed on the next start.
+type service struct {
+	log     *logger.Logger
+	repo    Repository
+	limiter *rate.Limiter
+	wake    chan struct{}
+	running sync.WaitGroup
+
+	mu   sync.Mutex
+	api  API
+	jobs map[int64]context.CancelFunc
+}
+
+// NewService creates the broadcast service, rate is the number of messages
+// per second. It is shared by all the jobs and should leave a part of the
+// Telegram limit of about 30 messages per second to the answers.
+func NewService(ctx context.Context, log *logger.Logger, repo Repository, perSecond float64) *service {
+	if perSecond <= 0 {
+		perSecond = defaultRate
+	}
+
+	return &service{
+		log:     log,
+		repo:    repo,
+		limiter: rate.NewLimiter(rate.Limit(perSecond), 1),
+		wake:    make(chan struct{}, 1),
+		jobs:    make(map[int64]context.CancelFunc),
+	}
+}
+
+// Draft saves the message of the admin as a new broadcast waiting for the confirmation,
+// the report is sent in the language of the admin.
+func (s *service) Draft(ctx context.Context, adminID int64, chatID int64, messageID int, lang i18n.Lang) (int64, error) {
+	ctx, span := tracing.Start(ctx, "broadcast.service.Draft")
+	defer span.End()
+
+	return s.repo.Save(ctx, &Broadcast{
+		AdminID:    adminID,
+		FromChatID: chatID,
+		MessageID:  messageID,
+		Status:     StatusDraft,
+		Language:   lang,
+	})
+}
+
+// Confirm starts the draft.
+func (s *service) Confirm(ctx context.Context, id int64) error {
+	ctx, span := tracing.Start(ctx, "broadcast.service.Confirm")
+	defer span.End()
+
+	if err := s.repo.SetStatus(ctx, id, StatusRunning, StatusDraft); err != nil {
+		return err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Cancel discards the draft or stops the running job, the job sends
+// the report of the messages delivered before the cancellation.
+func (s *service) Cancel(ctx context.Context, id int64) error {
+	ctx, span := tracing.Start(ctx, "broadcast.service.Cancel")
+	defer span.End()
+
+	if err := s.repo.SetStatus(ctx, id, StatusCancelled, StatusDraft, StatusRunning); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if cancel, ok := s.jobs[id]; ok {
+		cancel()
+	}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Start runs the confirmed jobs until ctx is done.
+func (s *service) Start(ctx context.Context, api API) {
+	s.mu.Lock()
+	s.api = api
+	s.mu.Unlock()
+
+	for {
+		s.launch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		}
+	}
+}
+
+// Wait waits for the jobs stopped by the end of Start to save their progress.
+func (s *service) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// launch starts the running jobs which have no goroutine yet.
+func (s *service) launch(ctx context.Context) {
+	log := s.log.With(logger.String("operation", "broadcast.service.launch"))
+
+	broadcasts, err := s.repo.Running(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error("failed to get running broadcasts", logger.ErrAttr(err))
+		}
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, b := range broadcasts {
+		if _, ok := s.jobs[b.ID]; ok {
+			continue
+		}
+
+		jobCtx, cancel := context.WithCancel(ctx)
+		s.jobs[b.ID] = cancel
+		s.running.Add(1)
+		go func() {
+			defer s.running.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.jobs, b.ID)
+				s.mu.Unlock()
+				cancel()
+			}()
+			s.run(jobCtx, b)
+		}()
+	}
+}
+
+func (s *service) run(ctx context.Context, b *Broadcast) {
+	log := s.log.With(
+		logger.String("operation", "broadcast.service.run"),
+		logger.Int64("broadcast_id", b.ID),
+	)
+	log.Info("broadcast started", logger.String("broadcast", b.String()))
+
+	for {
+		// fails only when the job is stopped, the cancellation is
+		// reported after the progress is saved
+		ids, err := s.recipients(ctx, b)
+		finished := err == nil && len(ids) == 0
+
+		s.send(ctx, b, ids)
+
+		status, saveErr := s.repo.SaveProgress(context.WithoutCancel(ctx), b)
+		if saveErr != nil {
+			log.Error("failed to save progress", logger.ErrAttr(saveErr))
+			return
+		}
+
+		switch {
+		case status == StatusCancelled:
+			b.Status = status
+		case finished:
+			if err := s.repo.SetStatus(ctx, b.ID, StatusDone, StatusRunning); err != nil {
+				log.Error("failed to finish broadcast", logger.ErrAttr(err))
+			}
+			b.Status = StatusDone
+		case ctx.Err() != nil:
+			// stays running and is resumed after the restart
+			log.Info("broadcast interrupted", logger.String("broadcast", b.String()))
+			return
+		default:
+			continue
+		}
+
+		log.Info("broadcast is over", logger.String("broadcast", b.String()))
+		s.report(context.WithoutCancel(ctx), b)
+		return
+	}
+}
+
+// recipients returns the next users of the job. The failed queries are
+// retried with a growing delay, an error is returned only when ctx is done.
+func (s *service) recipients(ctx context.Context, b *Broadcast) ([]int64, error) {
+	delay := firstRetry
+	for {
+		ids, err := s.repo.Recipients(ctx, b.LastUserID, batchSize)
+		if err == nil {
+			return ids, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		s.log.Error(
+			"failed to get recipients",
+			logger.Int64("broadcast_id", b.ID),
+			logger.Duration("retry_in", delay),
+			logger.ErrAttr(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRetry)
+	}
+}
+
+// send copies the message to the users until ctx is done.
+func (s *service) send(ctx context.Context, b *Broadcast, userIDs []int64) {
+	for _, userID := range userIDs {
+		if err := s.copy(ctx, b, userID); err != nil {
+			return
+		}
+		b.LastUserID = userID
+	}
+}
+
+// copy delivers the message to the user and counts the result,
+// an error is returned only when ctx is done before the delivery.
+func (s *service) copy(ctx context.Context, b *Broadcast, userID int64) error {
+	s.mu.Lock()
+	api := s.api
+	s.mu.Unlock()
+
+	for {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		_, err := api.CopyMessage(ctx, &bot.CopyMessageParams{
+			ChatID:     userID,
+			FromChatID: b.FromChatID,
+			MessageID:  b.MessageID,
+		})
+		metrics.TelegramRequest("CopyMessage", err)
+
+		var tooMany *bot.TooManyRequestsError
+		switch {
+		case err == nil:
+			b.Delivered++
+			metrics.BroadcastMessage("delivered")
+		case errors.As(err, &tooMany):
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(tooMany.RetryAfter) * time.Second):
+			}
+			continue
+		case errors.Is(err, bot.ErrorForbidden):
+			// the user blocked the bot or deleted the account
+			b.Blocked++
+			metrics.BroadcastMessage("blocked")
+			if err := s.repo.SetBlocked(context.WithoutCancel(ctx), userID); err != nil {
+				s.log.Error("failed to mark blocked user", logger.ErrAttr(err))
+			}
+		case ctx.Err() != nil:
+			return ctx.Err()
+		default:
+			b.Failed++
+			metrics.BroadcastMessage("failed")
+			s.log.Debug(
+				"broadcast message failed",
+				logger.Int64("broadcast_id", b.ID),
+				logger.Int64("user_id", userID),
+				logger.ErrAttr(err),
+			)
+		}
+
+		return nil
+	}
+}
+
+func (s *service) report(ctx context.Context, b *Broadcast) {
+	s.mu.Lock()
+	api := s.api
+	s.mu.Unlock()
+
+	_, err := api.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: b.AdminID,
+		Text:   b.Report(),
+	})
+	metrics.TelegramRequest("SendMessage", err)
+	if err != nil {
+		s.log.Error("failed to send broadcast report", logger.ErrAttr(err))
+	}
+}
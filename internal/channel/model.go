@@ -0,0 +1,53 @@
+package channel
+
+import (
+	"strconv"
+	"strings"
+)
+
+type Channel struct {
+	ID       int64
+	UserID   int64
+	FolderID int
+	Title    string
+	Username string
+}
+
+func (c *Channel) String() string {
+	b := &strings.Builder{}
+
+	b.WriteString("Channel{ID: ")
+	b.WriteString(strconv.FormatInt(c.ID, 10))
+	b.WriteString(", UserID: ")
+	b.WriteString(strconv.FormatInt(c.UserID, 10))
+	b.WriteString(", FolderID: ")
+	b.WriteString(strconv.Itoa(c.FolderID))
+	b.WriteString(", Title: ")
+	b.WriteString(c.Title)
+	b.WriteString(", Username: ")
+	b.WriteString(c.Username)
+	b.WriteRune('}')
+
+	return b.String()
+}
+
+// Post links a channel post to the note it was saved as.
+type Post struct {
+	ChannelID int64
+	MessageID int
+	TextsID   int
+}
+
+func (p *Post) String() string {
+	b := &strings.Builder{}
+
+	b.WriteString("Post{ChannelID: ")
+	b.WriteString(strconv.FormatInt(p.ChannelID, 10))
+	b.WriteString(", MessageID: ")
+	b.WriteString(strconv.Itoa(p.MessageID))
+	b.WriteString(", TextsID: ")
+	b.WriteString(strconv.Itoa(p.TextsID))
+	b.WriteRune('}')
+
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package channel
+
+import (
+	"context"
+	"sync"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNoChannel = er.New("the channel is not linked", "", nil)
+	ErrNoPost    = er.New("the post is not saved", "", nil)
+)
+
+var (
+	instance *pgRepository
+	once     sync.Once
+)
+
+type pgRepository struct {
+	log *logger.Logger
+	db  *pgxpool.Pool
+}
+
+// NewRepository creates new channel repository.
+func NewRepository(ctx context.Context, log *logger.Logger, db *pgxpool.Pool) (*pgRepository, error) {
+	once.Do(func() {
+		instance = &pgRepository{log: log, db: db}
+	})
+
+	return instance, nil
+}
+
+// Save links the channel to the folder of the user.
+// A channel has one owner, linking it again moves it to the new owner.
+func (repo *pgRepository) Save(ctx context.Context, c *Channel) error {
+	const op string = "channel.repository.Save"
+
+	if _, err := repo.db.Exec(ctx,
+		`INSERT INTO channels (id, user_id, folder_id, title, username)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE
+		SET user_id = $2, folder_id = $3, title = $4, username = $5;`,
+		c.ID, c.UserID, c.FolderID, c.Title, c.Username); err != nil {
+		return er.New("unable to link channel", op, err)
+	}
+
+	return nil
+}
+
+func (repo *pgRepository) Find(ctx context.Context, id int64) (*Channel, error) {
+	const op string = "channel.repository.Find"
+
+	c := Channel{ID: id}
+	if err := repo.db.QueryRow(ctx,
+		`SELECT user_id, folder_id, title, username
+		FROM channels WHERE id = $1;`,
+		id).Scan(&c.UserID, &c.FolderID, &c.Title, &c.Username); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNoChannel
+		}
+		return nil, er.New("unable to find channel", op, err)
+	}
+
+	return &c, nil
+}
+
+func (repo *pgRepository) Remove(ctx context.Context, c *Channel) error {
+	const op string = "channel.repository.Remove"
+
+	tag, err := repo.db.Exec(ctx,
+		`DELETE FROM channels WHERE id = $1 AND user_id = $2;`,
+		c.ID, c.UserID)
+	if err != nil {
+		return er.New("the channel could not be unlinked", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoChannel
+	}
+
+	return nil
+}
+
+func (repo *pgRepository) SavePost(ctx context.Context, p *Post) error {
+	const op string = "channel.repository.SavePost"
+
+	if _, err := repo.db.Exec(ctx,
+		`INSERT INTO channel_posts (channel_id, message_id, texts_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (channel_id, message_id) DO UPDATE
+		SET texts_id = $3;`,
+		p.ChannelID, p.MessageID, p.TextsID); err != nil {
+		return er.New("unable to save post", op, err)
+	}
+
+	return nil
+}
+
+func (repo *pgRepository) FindPost(ctx context.Context, p *Post) (int, error) {
+	const op string = "channel.repository.FindPost"
+
+	var textsID int
+	if err := repo.db.QueryRow(ctx,
+		`SELECT texts_id FROM channel_posts
+		WHERE channel_id = $1 AND message_id = $2;`,
+		p.ChannelID, p.MessageID).Scan(&textsID); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, ErrNoPost
+		}
+		return 0, er.New("unable to find post", op, err)
+	}
+
+	return textsID, nil
+}
@@ -0,0 +1,84 @@
+package channel
+
+import (
+	"context"
+
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+type Repository interface {
+	Save(ctx context.Context, c *Channel) error
+	Find(ctx context.Context, id int64) (*Channel, error)
+	Remove(ctx context.Context, c *Channel) error
+	SavePost(ctx context.Context, p *Post) error
+	FindPost(ctx context.Context, p *Post) (int, error)
+}
+
+type service struct {
+	log  *logger.Logger
+	repo Repository
+}
+
+func NewService(ctx context.Context, log *logger.Logger, repo Repository) *service {
+	return &service{log: log, repo: repo}
+}
+
+func (s *service) Link(ctx context.Context, c *Channel) error {
+	ctx, span := tracing.Start(ctx, "channel.service.Link")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "channel.service.Link"))
+
+	if err := s.repo.Save(ctx, c); err != nil {
+		log.Error("failed to link channel", logger.ErrAttr(err))
+		return err
+	}
+
+	log.Info("channel linked", logger.String("channel", c.String()))
+	return nil
+}
+
+func (s *service) Unlink(ctx context.Context, userID int64, channelID int64) error {
+	ctx, span := tracing.Start(ctx, "channel.service.Unlink")
+	defer span.End()
+
+	return s.repo.Remove(ctx, &Channel{ID: channelID, UserID: userID})
+}
+
+func (s *service) Find(ctx context.Context, channelID int64) (*Channel, error) {
+	ctx, span := tracing.Start(ctx, "channel.service.Find")
+	defer span.End()
+
+	return s.repo.Find(ctx, channelID)
+}
+
+func (s *service) AddPost(ctx context.Context, channelID int64, messageID int, textsID int) {
+	ctx, span := tracing.Start(ctx, "channel.service.AddPost")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "channel.service.AddPost"))
+
+	p := &Post{ChannelID: channelID, MessageID: messageID, TextsID: textsID}
+	if err := s.repo.SavePost(ctx, p); err != nil {
+		log.Error("failed to save post", logger.String("post", p.String()), logger.ErrAttr(err))
+	}
+}
+
+// PostNoteID returns the ID of the note the post was saved as or 0.
+func (s *service) PostNoteID(ctx context.Context, channelID int64, messageID int) int {
+	ctx, span := tracing.Start(ctx, "channel.service.PostNoteID")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "channel.service.PostNoteID"))
+
+	textsID, err := s.repo.FindPost(ctx, &Post{ChannelID: channelID, MessageID: messageID})
+	if err != nil {
+		if err != ErrNoPost {
+			log.Error("failed to find post", logger.ErrAttr(err))
+		}
+		return 0
+	}
+
+	return textsID
+}
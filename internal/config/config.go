@@ -1,85 +1,350 @@
-package config
-
-import (
-	"flag"
-	"archive_bot/pkg/er"
-	"os"
-
-	"github.com/redis/go-redis/v9"
-	"gopkg.in/yaml.v3"
-)
-
-type Config struct {
-	LogLevel    string `yaml:"log_level"`
-	IsWebhook   int    `yaml:"is_webhook"`
-	AdminID     int64  `yaml:"admin_id"`
-	PostgresURL string `yaml:"postgres_url"`
-	Redis       Redis  `yaml:"redis"`
-	Bot         Bot    `yaml:"bot"`
-}
-
-type Redis struct {
-	Address    string `yaml:"address"`
-	Password   string `yaml:"password"`
-	User       string `yaml:"user"`
-	Db         int    `yaml:"db"`
-	MaxRetries int    `yaml:"max_retries"`
-}
-
-func (r Redis) Options() *redis.Options {
-	return &redis.Options{
-		Addr:       r.Address,
-		Password:   r.Password,
-		DB:         r.Db,
-		Username:   r.User,
-		MaxRetries: r.MaxRetries,
-	}
-}
-
-type Bot struct {
-	Token              string `yaml:"token"`
-	WebhookURL         string `yaml:"webhook_url"`
-	WebhookSecretToken string `yaml:"webhook_secret_token"`
-	Port               string `yaml:"port"`
-}
-
-func New() (*Config, error) {
-	const op = "config.New"
-
-	configPath, err := parceFlags()
-	if err != nil {
-		return nil, err
-	}
-	config := &Config{}
-
-	file, err := os.Open(configPath)
-	if err != nil {
-		return nil, er.New("failed to open "+configPath, op, err)
-	}
-	defer file.Close()
-
-	d := yaml.NewDecoder(file)
-	if err := d.Decode(&config); err != nil {
-		return nil, er.New(configPath+" failed to open the file", op, err)
-	}
-
-	return config, nil
-}
-
-func parceFlags() (string, error) {
-	const op = "config.parceFlags"
-	var configPath string
-	flag.StringVar(&configPath, "config", "", "path to config file")
-
-	flag.Parse()
-
-	s, err := os.Stat(configPath)
-	if err != nil {
-		return "", er.New(configPath+" failed to get FileInfo", op, err)
-	}
-	if s.IsDir() {
-		return "", er.New(configPath+" is a directory, must be a file", op, err)
-	}
-
-	return configPath, nil
-}
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"archive_bot/pkg/er"
+
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is read from the YAML file given by -config, every field can be
+// overridden by the environment variable named after its YAML path, for
+// example BOT_TOKEN or SENDER_GLOBAL_RATE. Secrets can also be read from
+// the file named by the variable with the _FILE suffix. The unknown keys of
+// the file are logged as warnings.
+type Config struct {
+	LogLevel        string        `yaml:"log_level"`
+	IsWebhook       Flag          `yaml:"is_webhook"`
+	AdminIDs        []int64       `yaml:"admin_ids"`          // superadmins, the other roles are stored in the database
+	AdminID         int64         `yaml:"admin_id,omitempty"` // deprecated, use AdminIDs
+	PostgresURL     string        `yaml:"postgres_url" secret:"url"`
+	AutoMigrate     bool          `yaml:"auto_migrate"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	Redis           Redis         `yaml:"redis"`
+	Bot             Bot           `yaml:"bot"`
+	Group           Group         `yaml:"group"`
+	API             API           `yaml:"api"`
+	WebApp          WebApp        `yaml:"webapp"`
+	Tracing         Tracing       `yaml:"tracing"`
+	Sender          Sender        `yaml:"sender"`
+	Router          Router        `yaml:"router"`
+	Broadcast       Broadcast     `yaml:"broadcast"`
+	Limits          Limits        `yaml:"limits"`
+	Transcription   Transcription `yaml:"transcription"`
+
+	path     string
+	warnings []string
+}
+
+// Flag is a bool which also accepts the 0 and 1 of the old configs.
+type Flag bool
+
+func (f *Flag) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Value {
+	case "0":
+		*f = false
+		return nil
+	case "1":
+		*f = true
+		return nil
+	}
+
+	var b bool
+	if err := node.Decode(&b); err != nil {
+		return err
+	}
+	*f = Flag(b)
+	return nil
+}
+
+type Redis struct {
+	Address    string `yaml:"address"`
+	Password   string `yaml:"password" secret:"true"`
+	User       string `yaml:"user"`
+	Db         int    `yaml:"db"`
+	MaxRetries int    `yaml:"max_retries"`
+}
+
+func (r Redis) Options() *redis.Options {
+	return &redis.Options{
+		Addr:       r.Address,
+		Password:   r.Password,
+		DB:         r.Db,
+		Username:   r.User,
+		MaxRetries: r.MaxRetries,
+	}
+}
+
+type Bot struct {
+	Token              string        `yaml:"token" secret:"true"`
+	WebhookURL         string        `yaml:"webhook_url"`
+	WebhookSecretToken string        `yaml:"webhook_secret_token" secret:"true"`
+	Port               string        `yaml:"port"`
+	PollTimeout        time.Duration `yaml:"poll_timeout"`
+}
+
+// Group configures archiving from group chats.
+// Replying with "/save@bot [folder]" works in privacy mode,
+// "@bot save [folder]" and the keyword need privacy mode to be disabled.
+type Group struct {
+	Keyword string `yaml:"keyword"`
+}
+
+// API configures the REST API served under /api/v1.
+// RateLimit is the number of requests allowed per user per minute.
+type API struct {
+	RateLimit int `yaml:"rate_limit"`
+}
+
+// WebApp configures the Mini App served under /webapp/.
+// The menu button opening it is set only when URL is not empty.
+type WebApp struct {
+	URL        string `yaml:"url"`
+	ButtonText string `yaml:"button_text"`
+}
+
+// Tracing configures OpenTelemetry. Exporter is "otlp", "stdout" or empty
+// to disable tracing. The OTLP exporter also reads OTEL_EXPORTER_OTLP_* variables.
+type Tracing struct {
+	Exporter    string  `yaml:"exporter"`
+	Endpoint    string  `yaml:"endpoint"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// Sender configures the outbound queue. Rates are messages per second,
+// Telegram allows about 30 in total and 1 per chat.
+type Sender struct {
+	GlobalRate  float64 `yaml:"global_rate"`
+	ChatRate    float64 `yaml:"chat_rate"`
+	ChatBurst   int     `yaml:"chat_burst"`
+	Workers     int     `yaml:"workers"`
+	MaxAttempts int     `yaml:"max_attempts"`
+}
+
+// Router configures the update intake. RateLimit is the number of updates
+// allowed per user per minute, 0 disables the limit, it is counted in redis
+// for all the instances. Workers bounds the number of updates handled at
+// once, the updates of one user run in order. QueueSize is the number of
+// the waiting updates of one user, the updates over it are dropped, 0 is
+// no limit. FolderColumns is the number of the folder buttons in a row of
+// the folders list.
+type Router struct {
+	RateLimit     int `yaml:"rate_limit"`
+	Burst         int `yaml:"burst"`
+	Workers       int `yaml:"workers"`
+	QueueSize     int `yaml:"queue_size"`
+	FolderColumns int `yaml:"folder_columns"`
+}
+
+// Broadcast configures the admin broadcasts. Rate is messages per second,
+// it is taken from the Telegram limit shared with the answers.
+type Broadcast struct {
+	Rate float64 `yaml:"rate"`
+}
+
+// Limits are the limits of every user, 0 is no limit. The admins override
+// them per user with /adm_quota.
+type Limits struct {
+	NotesPerDay int   `yaml:"notes_per_day"`
+	MaxNotes    int   `yaml:"max_notes"`
+	MaxFolders  int   `yaml:"max_folders"`
+	MaxMediaMB  int64 `yaml:"max_media_mb"`
+}
+
+// Transcription configures the speech-to-text of the voice and audio notes
+// of the users who turned it on, it is disabled when URL is empty. URL is
+// the /inference endpoint of a whisper.cpp server or the
+// /v1/audio/transcriptions one of an OpenAI compatible server like
+// faster-whisper-server. Model is sent to the latter, an empty Language
+// is detected by the server. A failed note is retried up to MaxAttempts.
+type Transcription struct {
+	URL         string        `yaml:"url" secret:"url"`
+	Model       string        `yaml:"model"`
+	Language    string        `yaml:"language"`
+	Timeout     time.Duration `yaml:"timeout"`
+	MaxAttempts int           `yaml:"max_attempts"`
+}
+
+var (
+	configPath  = flag.String("config", "", "path to config file, the environment overrides it")
+	printConfig = flag.Bool("print-config", false, "print the config with redacted secrets and exit")
+)
+
+func defaults() *Config {
+	return &Config{
+		LogLevel:        "info",
+		ShutdownTimeout: 30 * time.Second,
+		Redis: Redis{
+			MaxRetries: 3,
+		},
+		Bot: Bot{
+			Port:        "3001",
+			PollTimeout: time.Minute,
+		},
+		API: API{
+			RateLimit: 60,
+		},
+		Tracing: Tracing{
+			SampleRatio: 1,
+		},
+		Router: Router{
+			RateLimit:     60,
+			Burst:         10,
+			QueueSize:     20,
+			FolderColumns: 2,
+		},
+		Broadcast: Broadcast{
+			Rate: 10,
+		},
+		Transcription: Transcription{
+			Timeout:     2 * time.Minute,
+			MaxAttempts: 5,
+		},
+	}
+}
+
+// New reads the config given by the flags. With -print-config it prints
+// the config with redacted secrets and exits.
+func New() (*Config, error) {
+	const op = "config.New"
+
+	path, printOnly := parceFlags()
+
+	config, err := Load(path)
+	if printOnly {
+		os.Exit(writeConfig(os.Stdout, os.Stderr, config, err))
+	}
+	if err != nil {
+		return nil, er.New("failed to load config", op, err)
+	}
+
+	return config, nil
+}
+
+// Load reads the file, applies the environment and validates the result.
+// The path may be empty, then the config is read from the environment only.
+// The returned config is not nil even when it is invalid.
+func Load(path string) (*Config, error) {
+	const op = "config.Load"
+
+	config := defaults()
+	config.path = path
+
+	if path != "" {
+		if err := decodeFile(path, config); err != nil {
+			return config, err
+		}
+	}
+
+	if err := applyEnv(config, os.LookupEnv, os.ReadFile); err != nil {
+		return config, er.New("failed to apply environment", op, err)
+	}
+
+	if config.AdminID != 0 && !slices.Contains(config.AdminIDs, config.AdminID) {
+		config.AdminIDs = append(config.AdminIDs, config.AdminID)
+	}
+
+	if err := config.Validate(); err != nil {
+		return config, er.New("config is invalid", op, err)
+	}
+
+	return config, nil
+}
+
+// Warnings are the problems of the file which don't stop the bot,
+// like the unknown keys left from the older versions.
+func (c *Config) Warnings() []string {
+	return c.warnings
+}
+
+// Reload reads the config again from the same file and environment.
+func (c *Config) Reload() (*Config, error) {
+	return Load(c.path)
+}
+
+// decodeFile reads the file into the config. The unknown keys are saved
+// as the warnings, so a key removed from the config does not stop the bot.
+func decodeFile(path string, config *Config) error {
+	const op = "config.decodeFile"
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return er.New("failed to open "+path, op, err)
+	}
+
+	d := yaml.NewDecoder(bytes.NewReader(data))
+	d.KnownFields(true)
+	err = d.Decode(config)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	unknown, ok := unknownKeys(err)
+	if !ok {
+		return er.New(path+" failed to decode the file", op, err)
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return er.New(path+" failed to decode the file", op, err)
+	}
+	config.warnings = append(config.warnings, unknown...)
+
+	return nil
+}
+
+// unknownKeys returns the problems of the strict decoding when all of them
+// are unknown keys.
+func unknownKeys(err error) ([]string, bool) {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return nil, false
+	}
+	for _, e := range typeErr.Errors {
+		if !strings.Contains(e, "not found in type") {
+			return nil, false
+		}
+	}
+
+	return typeErr.Errors, true
+}
+
+// writeConfig writes the redacted config and the problems and returns the exit code.
+func writeConfig(out io.Writer, errOut io.Writer, config *Config, err error) int {
+	if config != nil {
+		data, mErr := yaml.Marshal(config.Redacted())
+		if mErr != nil {
+			fmt.Fprintln(errOut, mErr)
+			return 1
+		}
+		out.Write(data)
+	}
+
+	if config != nil {
+		for _, w := range config.Warnings() {
+			fmt.Fprintln(errOut, "warning:", w)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+
+	return 0
+}
+
+func parceFlags() (string, bool) {
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+
+	return *configPath, *printConfig
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const fileSuffix string = "_FILE"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+type (
+	lookupFunc   func(key string) (string, bool)
+	readFileFunc func(name string) ([]byte, error)
+)
+
+// applyEnv overrides the fields by the environment. The variable name is the
+// upper-cased YAML path joined by underscores. The secrets can be read from
+// the file named by the variable with the _FILE suffix, the plain variable
+// wins when both are set.
+func applyEnv(config *Config, lookup lookupFunc, readFile readFileFunc) error {
+	var errs []error
+	walk(reflect.ValueOf(config).Elem(), "", func(field reflect.Value, name string, sf reflect.StructField) {
+		raw, ok := lookup(name)
+		if !ok && sf.Tag.Get("secret") != "" {
+			var path string
+			if path, ok = lookup(name + fileSuffix); ok {
+				data, err := readFile(path)
+				if err != nil {
+					errs = append(errs, errors.New(name+fileSuffix+": "+err.Error()))
+					return
+				}
+				raw = strings.TrimRight(string(data), "\r\n")
+			}
+		}
+		if !ok {
+			return
+		}
+
+		if err := setField(field, raw); err != nil {
+			errs = append(errs, errors.New(name+": "+err.Error()))
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// walk calls fn for every settable leaf field with its variable name.
+func walk(v reflect.Value, prefix string, fn func(reflect.Value, string, reflect.StructField)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		name := strings.ToUpper(tag)
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			walk(field, name, fn)
+			continue
+		}
+		fn(field, name, sf)
+	}
+}
+
+func setField(field reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.Int64 {
+			return errors.New("unsupported type " + field.Type().String())
+		}
+		ids := reflect.MakeSlice(field.Type(), 0, 0)
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			n, err := strconv.ParseInt(part, 10, 64)
+			if err != nil {
+				return err
+			}
+			ids = reflect.Append(ids, reflect.ValueOf(n))
+		}
+		field.Set(ids)
+	default:
+		return errors.New("unsupported type " + field.Type().String())
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const redacted string = "<redacted>"
+
+// Validate reports all the problems of the config at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, problem string) {
+		if !ok {
+			errs = append(errs, errors.New(problem))
+		}
+	}
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.LogLevel)) == nil, "log_level: must be debug, info, warn or error")
+	for _, id := range c.AdminIDs {
+		check(id > 0, "admin_ids: must be positive user IDs")
+	}
+	check(c.ShutdownTimeout > 0, "shutdown_timeout: must be positive")
+
+	if c.PostgresURL == "" {
+		check(false, "postgres_url: is required")
+	} else if _, err := pgxpool.ParseConfig(c.PostgresURL); err != nil {
+		check(false, "postgres_url: is not a valid connection string")
+	}
+
+	_, _, err := net.SplitHostPort(c.Redis.Address)
+	check(err == nil, "redis.address: must be host:port")
+	check(c.Redis.Db >= 0 && c.Redis.Db <= 15, "redis.db: must be from 0 to 15")
+	check(c.Redis.MaxRetries >= -1, "redis.max_retries: must be -1 or more")
+
+	check(c.Bot.Token != "", "bot.token: is required")
+	port, err := strconv.Atoi(c.Bot.Port)
+	check(err == nil && port > 0 && port < 1<<16, "bot.port: must be a port number")
+	check(c.Bot.PollTimeout > 0, "bot.poll_timeout: must be positive")
+	if c.IsWebhook {
+		check(isHTTPS(c.Bot.WebhookURL), "bot.webhook_url: must be an https URL when is_webhook is set")
+	}
+
+	check(c.API.RateLimit > 0, "api.rate_limit: must be positive")
+	if c.WebApp.URL != "" {
+		check(isHTTPS(c.WebApp.URL), "webapp.url: must be an https URL")
+	}
+
+	switch c.Tracing.Exporter {
+	case "", "otlp", "stdout":
+	default:
+		check(false, "tracing.exporter: must be otlp, stdout or empty")
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio: must be from 0 to 1")
+
+	check(c.Sender.GlobalRate >= 0, "sender.global_rate: must not be negative")
+	check(c.Sender.ChatRate >= 0, "sender.chat_rate: must not be negative")
+	check(c.Sender.ChatBurst >= 0, "sender.chat_burst: must not be negative")
+	check(c.Sender.Workers >= 0, "sender.workers: must not be negative")
+	check(c.Sender.MaxAttempts >= 0, "sender.max_attempts: must not be negative")
+
+	check(c.Router.RateLimit >= 0, "router.rate_limit: must not be negative")
+	check(c.Router.Burst >= 0, "router.burst: must not be negative")
+	check(c.Router.Workers >= 0, "router.workers: must not be negative")
+	check(c.Router.QueueSize >= 0, "router.queue_size: must not be negative")
+	check(c.Router.FolderColumns >= 1 && c.Router.FolderColumns <= 8, "router.folder_columns: must be from 1 to 8")
+
+	check(c.Broadcast.Rate >= 0, "broadcast.rate: must not be negative")
+
+	check(c.Limits.NotesPerDay >= 0, "limits.notes_per_day: must not be negative")
+	check(c.Limits.MaxNotes >= 0, "limits.max_notes: must not be negative")
+	check(c.Limits.MaxFolders >= 0, "limits.max_folders: must not be negative")
+	check(c.Limits.MaxMediaMB >= 0, "limits.max_media_mb: must not be negative")
+
+	if c.Transcription.URL != "" {
+		check(isHTTP(c.Transcription.URL), "transcription.url: must be an http or https URL")
+	}
+	check(c.Transcription.Timeout > 0, "transcription.timeout: must be positive")
+	check(c.Transcription.MaxAttempts > 0, "transcription.max_attempts: must be positive")
+
+	return errors.Join(errs...)
+}
+
+// Redacted returns a copy of the config without the secrets.
+// The passwords of the URLs are hidden, the rest of the URL is kept.
+func (c *Config) Redacted() *Config {
+	cp := *c
+	cp.AdminIDs = append([]int64(nil), c.AdminIDs...)
+
+	walk(reflect.ValueOf(&cp).Elem(), "", func(field reflect.Value, _ string, sf reflect.StructField) {
+		if field.Kind() != reflect.String || field.String() == "" {
+			return
+		}
+
+		switch sf.Tag.Get("secret") {
+		case "":
+		case "url":
+			u, err := url.Parse(field.String())
+			if err != nil || u.Scheme == "" {
+				field.SetString(redacted)
+				return
+			}
+			field.SetString(u.Redacted())
+		default:
+			field.SetString(redacted)
+		}
+	})
+
+	return &cp
+}
+
+func isHTTP(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func isHTTPS(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
@@ -1,25 +1,130 @@
-package buttons
-
-const (
-	Prefix       string = "btn_"
-	Delimiter    string = ":"
-	WithPhoto    string = "ph"
-	CreateFolder string = Prefix + "create_folder"
-	DeleteFolder string = Prefix + "delete_folder"
-	MoveNote     string = Prefix + "1_move"
-	UpdateNote   string = Prefix + "2_update"
-	DeleteNote   string = Prefix + "3_delete"
-	Folders      string = "📁📁📁"
-)
-
-const DefaultFolderName = "Прочее"
-
-var CatalogueOptions = map[string]string{
-	MoveNote:   "📤",
-	DeleteNote: "🗑️",
-}
-
-var MenuOptions = map[string]string{
-	CreateFolder: "✅",
-	DeleteFolder: "❌",
-}
+package buttons
+
+import "archive_bot/internal/entities"
+
+const (
+	Prefix       string = "btn_"
+	Delimiter    string = ":"
+	WithPhoto    string = "ph"
+	CreateFolder string = Prefix + "create_folder"
+	DeleteFolder string = Prefix + "delete_folder"
+	SetupFolder  string = Prefix + "setup_folder"
+	Favorite     string = Prefix + "0_favorite"
+	MoveNote     string = Prefix + "1_move"
+	UpdateNote   string = Prefix + "2_update"
+	DeleteNote   string = Prefix + "3_delete"
+	Language     string = Prefix + "lang" + Delimiter
+	Folders      string = "📁📁📁"
+)
+
+var CatalogueOptions = map[string]string{
+	MoveNote:   "📤",
+	DeleteNote: "🗑️",
+}
+
+// FavoriteOptions are the texts of the favorite button of a note: the note
+// is a favorite or not.
+var FavoriteOptions = map[bool]string{
+	true:  "⭐",
+	false: "☆",
+}
+
+var MenuOptions = map[string]string{
+	CreateFolder: "✅",
+	SetupFolder:  "⚙️",
+	DeleteFolder: "❌",
+}
+
+// The callbacks of the folder settings, the ID of the folder follows
+// the delimiter.
+const (
+	FolderPrefix   string = "fld_"
+	FolderList     string = FolderPrefix + "list"
+	FolderMenu     string = FolderPrefix + "menu"
+	FolderRename   string = FolderPrefix + "name"
+	FolderIcon     string = FolderPrefix + "icon"
+	FolderUp       string = FolderPrefix + "up"
+	FolderDown     string = FolderPrefix + "down"
+	FolderPosition string = FolderPrefix + "pos"
+	FolderHide     string = FolderPrefix + "hide"
+	FolderCopy     string = FolderPrefix + "copy"
+	FolderMerge    string = FolderPrefix + "merge"
+	FolderSplit    string = FolderPrefix + "split"
+	// the ID of the target folder or the type of the notes follows the ID
+	FolderMergeTo   string = FolderPrefix + "into"
+	FolderMergeOk   string = FolderPrefix + "merge_ok"
+	FolderSplitType string = FolderPrefix + "split_t"
+	FolderSplitDate string = FolderPrefix + "split_d"
+	FolderAliases   string = FolderPrefix + "alias"
+	// the ID of the offered folder follows the delimiter
+	FolderSuggestOk  string = FolderPrefix + "dym"
+	FolderSuggestNew string = FolderPrefix + "dym_new"
+)
+
+var FolderOptions = map[string]string{
+	FolderList:       "⬅️",
+	FolderRename:     "✏️",
+	FolderIcon:       "🎨",
+	FolderUp:         "⬆️",
+	FolderDown:       "⬇️",
+	FolderPosition:   "🔢",
+	FolderHide:       "🙈",
+	FolderCopy:       "📑",
+	FolderMerge:      "🔀",
+	FolderSplit:      "✂️",
+	FolderMergeOk:    "✅",
+	FolderSplitDate:  "📅",
+	FolderAliases:    "🔖",
+	FolderSuggestNew: "➕",
+}
+
+// FolderShow is the text of FolderHide for the hidden folder.
+const FolderShow string = "👁"
+
+// The callbacks of the selection of the notes, the argument follows
+// the delimiter.
+const (
+	SelectPrefix   string = "sel_"
+	SelectStart    string = SelectPrefix + "start"
+	SelectToggle   string = SelectPrefix + "tgl"
+	SelectAll      string = SelectPrefix + "all"
+	SelectType     string = SelectPrefix + "type"
+	SelectPage     string = SelectPrefix + "page"
+	SelectMove     string = SelectPrefix + "move"
+	SelectTo       string = SelectPrefix + "to"
+	SelectTag      string = SelectPrefix + "tag"
+	SelectExport   string = SelectPrefix + "export"
+	SelectDelete   string = SelectPrefix + "del"
+	SelectDeleteOk string = SelectPrefix + "del_ok"
+	SelectBack     string = SelectPrefix + "back"
+	SelectCancel   string = SelectPrefix + "cancel"
+)
+
+var SelectOptions = map[string]string{
+	SelectStart:    "☑️",
+	SelectAll:      "☑️",
+	SelectMove:     "📤",
+	SelectTag:      "🏷",
+	SelectExport:   "📦",
+	SelectDelete:   "🗑️",
+	SelectDeleteOk: "🗑️",
+	SelectBack:     "⬅️",
+	SelectCancel:   "✖️",
+}
+
+// The marks of the notes in the selection.
+const (
+	Selected   string = "✅"
+	Unselected string = "⬜"
+)
+
+// TypeIcons are the icons of the types of the notes.
+var TypeIcons = map[entities.Type]string{
+	entities.Message:   "📝",
+	entities.Photo:     "🖼",
+	entities.Audio:     "🎵",
+	entities.Document:  "📄",
+	entities.Video:     "🎬",
+	entities.Animation: "🎞",
+	entities.Voice:     "🎤",
+}
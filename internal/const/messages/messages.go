@@ -1,51 +1,236 @@
-package messages
-
-const (
-	StartCommand string = `Привет! Я – твой личный помощник, который помогает создавать уютные папочки для заметок и ссылок.😬 
-Со мной ты можешь:
-
-• Организовывать свои идеи и интересные материалы в папки  📁
-• Быстро находить сохранённые заметки и ссылки именно тогда, когда они понадобятся 🔍
-
-Чтобы начать – просто отправь мне сообщение с заметкой или ссылкой – и я сразу её сохраню для тебя. Если хочешь увидеть свои папки или создать новую - нажми кнопку 📂📂📂.
-
-Посмотреть инструкцию можно, набрав команду /info`
-	FoldersCaption string = "📌 Мои папки"
-	UnknownCommand string = "Не знаю о чем ты. О чем ты? 🤔"
-	Error          string = "Ой! Не делай так... 😵"
-	NotesIsEmpty   string = "Ничего нет 🕵🏼"
-	NoteCreated    string = "Запись добавлена ✏️"
-	Moved          string = "✏️"
-	NoteRemoved    string = "Запись удалена 🧹"
-	EmptyMessage   string = "▲▲▲▲▲"
-	InfoVideo1     string = "BAACAgIAAxkBAAJKW2fgJe-M4SzvW1uvJNIP5JvaNO70AAJgawACzAwAAUtn4sBEP276AAE2BA"
-	InfoVideo2     string = "BAACAgIAAxkBAAJKXGfgJe-ZHLN7SkOLTofpdDvEJsmEAAJhawACzAwAAUvPfV2u_yGyNzYE"
-	InfoVideo3     string = "BAACAgIAAxkBAAJKXWfgJe-SHdLJCa8-bVcKSvPdP_eKAAJiawACzAwAAUsZ5QqXvx__eDYE"
-	InfoVideo4     string = "BAACAgIAAxkBAAJKXmfgJe-o628i1tfcrmRCJSTVBJAFAAJkawACzAwAAUu7iI-JnZ0EqDYE"
-	InfoVideo5     string = "BAACAgIAAxkBAAJKX2fgJe-2BRgqwzm_78tOq87a__ERAAJjawACzAwAAUsnF-m5Z4dWLjYE"
-	InfoMessage1   string = "1. Чтобы добавить новую заметку, нужно написать или прислать что-то в бота.\nЧтобы стереть все кроме главного меню нажмите на 📂📂📂 или введите /folders"
-	InfoMessage2   string = "2. Добавить новую папку можно, нажав на левую кнопку главного меню. Чтобы удалить папку, нужно нажать на правую кнопку."
-	InfoMessage3   string = "3. Если написать или добавить что-то в бота, находясь в папке, новая запись сохранится в эту папку"
-	InfoMessage4   string = "4. Левая кнопка под записью перемещает ее в нужную папку (после нажатия этой кнопки нужно выбрать папку, в которую необходимо переместить запись).\nПравая кнопка удаляет запись"
-	InfoMessage5   string = "5. При добавлении записей через опцию 'Поделиться',\nесли в сообщении написать восклицательный знак и название папки (!название), то запись будеть добавлена в эту папку. Если этой папки не существует, она создастся автоматически."
-)
-
-var InfoMap = map[string]string{
-	InfoVideo1: InfoMessage1,
-	InfoVideo2: InfoMessage2,
-	InfoVideo3: InfoMessage3,
-	InfoVideo4: InfoMessage4,
-	InfoVideo5: InfoMessage5,
-}
-
-const (
-	FolderEmoji          string = "📁"
-	AskFolderName        string = "Название папки?"
-	FolderIsEmpty        string = "Нету папочек 🕵🏼"
-	FolderCreated        string = "А вот и папочка ✏️"
-	FolderNotExists      string = "Нет такой папки"
-	FolderDeleted        string = "Папка удалена"
-	WrongFolder          string = "Эту папку нельзя удалить"
-	ChooseFolderToMove   string = "Выбери папку, в которую хочешь переместить заметку"
-	ChooseFolderToDelete string = "Выбери папку, которую хочешь удалить"
-)
+// Package messages holds the keys of the bot texts, the texts themselves
+// are in the catalogs of internal/i18n. Emoji-only texts are the same in
+// every language and are used as is.
+package messages
+
+const (
+	StartCommand   string = "start"
+	FoldersCaption string = "folders_caption"
+	UnknownCommand string = "unknown_command"
+	Error          string = "error"
+	NotesIsEmpty   string = "notes_empty"
+	NoteCreated    string = "note_created"
+	Moved          string = "✏️"
+	NoteRemoved    string = "note_removed"
+	EmptyMessage   string = "▲▲▲▲▲"
+	Source         string = "source"
+	InfoVideo1     string = "BAACAgIAAxkBAAJKW2fgJe-M4SzvW1uvJNIP5JvaNO70AAJgawACzAwAAUtn4sBEP276AAE2BA"
+	InfoVideo2     string = "BAACAgIAAxkBAAJKXGfgJe-ZHLN7SkOLTofpdDvEJsmEAAJhawACzAwAAUvPfV2u_yGyNzYE"
+	InfoVideo3     string = "BAACAgIAAxkBAAJKXWfgJe-SHdLJCa8-bVcKSvPdP_eKAAJiawACzAwAAUsZ5QqXvx__eDYE"
+	InfoVideo4     string = "BAACAgIAAxkBAAJKXmfgJe-o628i1tfcrmRCJSTVBJAFAAJkawACzAwAAUu7iI-JnZ0EqDYE"
+	InfoVideo5     string = "BAACAgIAAxkBAAJKX2fgJe-2BRgqwzm_78tOq87a__ERAAJjawACzAwAAUsnF-m5Z4dWLjYE"
+	InfoMessage1   string = "info_1"
+	InfoMessage2   string = "info_2"
+	InfoMessage3   string = "info_3"
+	InfoMessage4   string = "info_4"
+	InfoMessage5   string = "info_5"
+)
+
+var InfoMap = map[string]string{
+	InfoVideo1: InfoMessage1,
+	InfoVideo2: InfoMessage2,
+	InfoVideo3: InfoMessage3,
+	InfoVideo4: InfoMessage4,
+	InfoVideo5: InfoMessage5,
+}
+
+const (
+	FolderEmoji          string = "📁"
+	FolderDefault        string = "folder_default"
+	AskFolderName        string = "ask_folder_name"
+	FolderIsEmpty        string = "folders_empty"
+	FolderCreated        string = "folder_created"
+	FolderNotExists      string = "folder_not_exists"
+	FolderDeleted        string = "folder_deleted"
+	WrongFolder          string = "wrong_folder"
+	ChooseFolderToMove   string = "choose_folder_to_move"
+	ChooseFolderToDelete string = "choose_folder_to_delete"
+)
+
+const (
+	FolderSettings        string = "folder_settings"
+	FolderIsHidden        string = "folder_is_hidden"
+	FolderLocked          string = "folder_locked"
+	AskFolderIcon         string = "ask_folder_icon"
+	AskFolderPosition     string = "ask_folder_position"
+	FolderRenamed         string = "folder_renamed"
+	FolderIconSet         string = "folder_icon_set"
+	FolderMoved           string = "folder_moved"
+	FolderHidden          string = "folder_hidden"
+	FolderShown           string = "folder_shown"
+	FolderNameInvalid     string = "folder_name_invalid"
+	FolderNameTaken       string = "folder_name_taken"
+	FolderIconInvalid     string = "folder_icon_invalid"
+	FolderPositionInvalid string = "folder_position_invalid"
+)
+
+const (
+	FolderCopyName         string = "folder_copy_name"
+	FolderSplitBefore      string = "folder_split_before"
+	FolderCopied           string = "folder_copied"
+	FolderMergeChoose      string = "folder_merge_choose"
+	FolderMergeAsk         string = "folder_merge_ask"
+	FolderMerged           string = "folder_merged"
+	FolderSplitChoose      string = "folder_split_choose"
+	AskSplitDate           string = "ask_split_date"
+	FolderSplitDone        string = "folder_split_done"
+	FolderSplitEmpty       string = "folder_split_empty"
+	FolderSplitDateInvalid string = "folder_split_date_invalid"
+	AskFolderAliases       string = "ask_folder_aliases"
+	FolderAliases          string = "folder_aliases"
+	FolderAliasesSet       string = "folder_aliases_set"
+	FolderAliasInvalid     string = "folder_alias_invalid"
+	FolderAliasTaken       string = "folder_alias_taken"
+	// FolderSuggested asks whether the mistyped name is the offered folder.
+	FolderSuggested         string = "folder_suggested"
+	FolderSuggestionExpired string = "folder_suggestion_expired"
+	FolderCreateNamed       string = "folder_create_named"
+	FolderCreateAsk         string = "folder_create_ask"
+)
+
+const (
+	ChannelLinkUsage   string = "channel_link_usage"
+	ChannelNotFound    string = "channel_not_found"
+	ChannelNotAdmin    string = "channel_not_admin"
+	ChannelBotNotAdmin string = "channel_bot_not_admin"
+	ChannelLinked      string = "channel_linked"
+	ChannelUnlinked    string = "channel_unlinked"
+)
+
+const (
+	TokenIssued   string = "token_issued"
+	TokenList     string = "token_list"
+	TokensEmpty   string = "tokens_empty"
+	TokenRevoked  string = "token_revoked"
+	TokenNotFound string = "token_not_found"
+	TokenUsage    string = "token_usage"
+	TokensLimit   string = "tokens_limit"
+)
+
+const (
+	QuotaNotes   string = "quota_notes"
+	QuotaDaily   string = "quota_daily"
+	QuotaMedia   string = "quota_media"
+	QuotaFolders string = "quota_folders"
+	Throttled    string = "throttled"
+)
+
+const (
+	FavoritesCaption string = "favorites_caption"
+	FavoritesEmpty   string = "favorites_empty"
+	FavoriteAdded    string = "favorite_added"
+	FavoriteRemoved  string = "favorite_removed"
+)
+
+const (
+	NotesSelected         string = "notes_selected"
+	SelectionEmpty        string = "selection_empty"
+	SelectionExpired      string = "selection_expired"
+	SelectionChooseFolder string = "selection_choose_folder"
+	SelectionRemoveAsk    string = "selection_remove_ask"
+	AskTags               string = "ask_tags"
+	TagsInvalid           string = "tags_invalid"
+	NotesMoved            string = "notes_moved"
+	NotesRemoved          string = "notes_removed"
+	NotesTagged           string = "notes_tagged"
+	NotesExported         string = "notes_exported"
+)
+
+const (
+	RulesUsage         string = "rules_usage"
+	RulesEmpty         string = "rules_empty"
+	RulesList          string = "rules_list"
+	RulesLimit         string = "rules_limit"
+	RuleAdded          string = "rule_added"
+	RuleRemoved        string = "rule_removed"
+	RuleMoved          string = "rule_moved"
+	RuleNotFound       string = "rule_not_found"
+	RuleSyntax         string = "rule_syntax"
+	RuleTypeInvalid    string = "rule_type_invalid"
+	RuleRegexInvalid   string = "rule_regex_invalid"
+	RuleNoAction       string = "rule_no_action"
+	RuleFolderNotFound string = "rule_folder_not_found"
+	RuleFiled          string = "rule_filed"
+	RuleTagged         string = "rule_tagged"
+	RulesDryRun        string = "rules_dry_run"
+	RulePreview        string = "rule_preview"
+)
+
+const (
+	TranscribeOn          string = "transcribe_on"
+	TranscribeOff         string = "transcribe_off"
+	TranscribeUnavailable string = "transcribe_unavailable"
+	TranscribeUsage       string = "transcribe_usage"
+)
+
+const (
+	LanguageChoose      string = "language_choose"
+	LanguageSet         string = "language_set"
+	LanguageUnsupported string = "language_unsupported"
+)
+
+const (
+	AdminPanel      string = "admin_panel"
+	AdminNotAllowed string = "admin_not_allowed"
+	AdminError      string = "admin_error"
+	AdminUsersCount string = "admin_users_count"
+	AdminCancel     string = "admin_cancel"
+
+	AdminButtonCount     string = "admin_button_count"
+	AdminButtonUsers     string = "admin_button_users"
+	AdminButtonStats     string = "admin_button_stats"
+	AdminButtonBroadcast string = "admin_button_broadcast"
+	AdminButtonAdmins    string = "admin_button_admins"
+)
+
+const (
+	UsersUsage    string = "users_usage"
+	UserNotFound  string = "user_not_found"
+	UserOutranked string = "user_outranked"
+	UserBanned    string = "user_banned"
+	UserUnbanned  string = "user_unbanned"
+	UserQuotaSet  string = "user_quota_set"
+	UserWipeAsk   string = "user_wipe_ask"
+	UserWiped     string = "user_wiped"
+	UserCard      string = "user_card"
+	UserCardBan   string = "user_card_ban"
+	UserBan       string = "user_ban"
+	UserUnban     string = "user_unban"
+	UserWipe      string = "user_wipe"
+)
+
+const (
+	RolesUsage     string = "roles_usage"
+	RoleGranted    string = "role_granted"
+	RoleRevoked    string = "role_revoked"
+	RoleOwn        string = "role_own"
+	RoleBootstrap  string = "role_bootstrap"
+	RoleNoAdmin    string = "role_no_admin"
+	AdminsList     string = "admins_list"
+	AdminFromConf  string = "admin_from_config"
+	AdminGrantedBy string = "admin_granted_by"
+)
+
+const (
+	BroadcastCompose   string = "broadcast_compose"
+	BroadcastAborted   string = "broadcast_aborted"
+	BroadcastSendAll   string = "broadcast_send_all"
+	BroadcastNotDraft  string = "broadcast_not_draft"
+	BroadcastStarted   string = "broadcast_started"
+	BroadcastCancelled string = "broadcast_cancelled"
+	BroadcastOver      string = "broadcast_over"
+	BroadcastFinished  string = "broadcast_finished"
+	BroadcastReport    string = "broadcast_report"
+)
+
+const (
+	StatsActivity string = "stats_activity"
+	StatsSignups  string = "stats_signups"
+	StatsNotes    string = "stats_notes"
+	StatsPerUser  string = "stats_per_user"
+	StatsStorage  string = "stats_storage"
+	StatsLargest  string = "stats_largest"
+	StatsFolders  string = "stats_folders"
+	StatsErrors   string = "stats_errors"
+	StatsCSV      string = "stats_csv"
+)
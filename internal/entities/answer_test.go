@@ -0,0 +1,19 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortNotes(t *testing.T) {
+	t.Parallel()
+	notes := map[int]*AnswerParams{
+		1: {},
+		2: {Favorite: true},
+		3: {},
+		5: {Favorite: true},
+	}
+
+	assert.Equal(t, []int{2, 5, 1, 3}, SortNotes(notes))
+}
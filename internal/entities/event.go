@@ -1,235 +1,368 @@
-package entities
-
-import (
-	"context"
-	"archive_bot/pkg/logger"
-	"strconv"
-	"strings"
-	"time"
-
-	"github.com/go-telegram/bot/models"
-)
-
-type Event struct {
-	Type            Type
-	IsCallbackQuery bool
-	IsEdited        bool
-	Text            string
-	FileID          string
-	MediaGroupID    string
-	NoteID          int
-	FolderID        int
-	Meta            Meta
-}
-
-type Meta struct {
-	UserID          int64
-	ChatID          int64
-	MessageID       int
-	UserName        string
-	CallbackQueryID string
-	Date            time.Time
-	Media           string
-}
-
-func NewEvent(ctx context.Context, update *models.Update) *Event {
-	log := logger.L(ctx).With(logger.String("operation", "entities.NewEvent"))
-
-	event := &Event{}
-	eventType := fetchType(update, event)
-	event.Type = eventType
-
-	if update.Message != nil {
-		log.Debug(
-			"update data",
-			logger.String("text", update.Message.Text),
-			logger.String("Caption", update.Message.Caption),
-		)
-	}
-
-	if event.IsCallbackQuery {
-		event.Text = update.CallbackQuery.Data
-		event.Meta = Meta{
-			UserID:          update.CallbackQuery.From.ID,
-			ChatID:          update.CallbackQuery.Message.Message.Chat.ID,
-			MessageID:       update.CallbackQuery.Message.Message.ID,
-			UserName:        update.CallbackQuery.From.Username,
-			CallbackQueryID: update.CallbackQuery.ID,
-			Date:            time.Unix(int64(update.CallbackQuery.Message.Message.Date), 0),
-		}
-		return event
-	}
-
-	if update.Message == nil {
-		return event
-	}
-
-	switch eventType {
-	case Message:
-		event.Text = checkForwardOrigin(update, event)
-	case Photo:
-		event.Text = checkForwardOrigin(update, event)
-		event.MediaGroupID = update.Message.MediaGroupID
-		photo := update.Message.Photo[len(update.Message.Photo)-1]
-		event.FileID = photo.FileID
-	case Document:
-		event.Text = checkForwardOrigin(update, event)
-		event.MediaGroupID = update.Message.MediaGroupID
-		event.FileID = update.Message.Document.FileID
-	case Video:
-		event.Text = checkForwardOrigin(update, event)
-		event.MediaGroupID = update.Message.MediaGroupID
-		event.FileID = update.Message.Video.FileID
-	case Audio:
-		event.MediaGroupID = update.Message.MediaGroupID
-		event.FileID = update.Message.Audio.FileID
-	case Animation:
-		event.MediaGroupID = update.Message.MediaGroupID
-		event.FileID = update.Message.Animation.FileID
-	case Voice:
-		event.Text = checkForwardOrigin(update, event)
-		event.FileID = update.Message.Voice.FileID
-	}
-	event.Meta = Meta{
-		UserID:    update.Message.From.ID,
-		ChatID:    update.Message.Chat.ID,
-		MessageID: update.Message.ID,
-		UserName:  update.Message.From.Username,
-		Date:      time.Unix(int64(update.Message.Date), 0),
-	}
-
-	log.Debug("new event data", logger.String("event", event.String()))
-	return event
-}
-
-func checkForwardOrigin(update *models.Update, event *Event) string {
-	if event.Type == Message {
-		if update.Message.ForwardOrigin != nil {
-			return setSource(update, update.Message.Text)
-		} else {
-			return update.Message.Text
-		}
-	}
-	if update.Message.ForwardOrigin != nil {
-		return setSource(update, update.Message.Caption)
-	} else {
-		return update.Message.Caption
-	}
-}
-
-const source string = "Источник: @"
-
-func setSource(update *models.Update, text string) string {
-	b := &strings.Builder{}
-	b.WriteString(source)
-	messageOrigin := update.Message.ForwardOrigin
-	switch {
-	case messageOrigin.MessageOriginChannel != nil:
-		if messageOrigin.MessageOriginChannel.Chat.Username != "" {
-			b.WriteString(messageOrigin.MessageOriginChannel.Chat.Username)
-		}
-	case messageOrigin.MessageOriginChat != nil:
-		if messageOrigin.MessageOriginChat.SenderChat.Username != "" {
-			b.WriteString(messageOrigin.MessageOriginChat.SenderChat.Username)
-		}
-	case messageOrigin.MessageOriginHiddenUser != nil:
-		if messageOrigin.MessageOriginHiddenUser.SenderUserName != "" {
-			b.WriteString(messageOrigin.MessageOriginHiddenUser.SenderUserName)
-		}
-	case messageOrigin.MessageOriginChannel != nil:
-		if messageOrigin.MessageOriginUser.SenderUser.Username != "" {
-			b.WriteString(messageOrigin.MessageOriginUser.SenderUser.Username)
-		}
-	}
-
-	if b.String() == source && text == "" {
-		return ""
-	}
-
-	b.WriteString("\n\n")
-	b.WriteString(text)
-	return b.String()
-}
-
-func fetchType(update *models.Update, event *Event) Type {
-	if update.CallbackQuery != nil {
-		event.IsCallbackQuery = true
-		if update.CallbackQuery.Message.Message != nil {
-			if len(update.CallbackQuery.Message.Message.Photo) != 0 {
-				return Photo
-			}
-			return Message
-		}
-	}
-
-	if update.Message == nil {
-		return Unknown
-	}
-
-	switch {
-	case len(update.Message.Photo) != 0:
-		return Photo
-	case update.Message.Document != nil:
-		return Document
-	case update.Message.Video != nil:
-		return Video
-	case update.Message.Audio != nil:
-		return Audio
-	case update.Message.Animation != nil:
-		return Animation
-	case update.Message.Voice != nil:
-		return Voice
-	case update.Message.Text != "":
-		return Message
-	}
-
-	return Unknown
-}
-
-func (e *Event) String() string {
-	b := &strings.Builder{}
-
-	b.WriteString("Event{Type: ")
-	b.WriteString(e.Type.String())
-	b.WriteString(", IsCallbackQuery: ")
-	b.WriteString(strconv.FormatBool(e.IsCallbackQuery))
-	b.WriteString(", IsEdited: ")
-	b.WriteString(strconv.FormatBool(e.IsEdited))
-	b.WriteString(", Text: ")
-	b.WriteString(e.Text)
-	b.WriteString(", FileID: ")
-	b.WriteString(e.FileID)
-	b.WriteString(", MediaGroupID: ")
-	b.WriteString(e.MediaGroupID)
-	b.WriteString(", NoteID: ")
-	b.WriteString(strconv.Itoa(e.NoteID))
-	b.WriteString(", FolderID: ")
-	b.WriteString(strconv.Itoa(e.FolderID))
-	b.WriteString(", Meta: ")
-	b.WriteString(e.Meta.String())
-	b.WriteRune('}')
-
-	return b.String()
-}
-
-func (m Meta) String() string {
-	b := &strings.Builder{}
-
-	b.WriteString("Meta{UserID: ")
-	b.WriteString(strconv.FormatInt(m.UserID, 10))
-	b.WriteString(", ChatID: ")
-	b.WriteString(strconv.FormatInt(m.ChatID, 10))
-	b.WriteString(", MessageID: ")
-	b.WriteString(strconv.Itoa(m.MessageID))
-	b.WriteString(", UserName: ")
-	b.WriteString(m.UserName)
-	b.WriteString(", CallbackQueryID: ")
-	b.WriteString(m.CallbackQueryID)
-	b.WriteString(", Date: ")
-	b.WriteString(m.Date.String())
-	b.WriteString(", Media: ")
-	b.WriteString(m.Media)
-	b.WriteRune('}')
-
-	return b.String()
-}
+package entities
+
+import (
+	"context"
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/i18n"
+	"archive_bot/pkg/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+)
+
+type Event struct {
+	Type            Type
+	IsCallbackQuery bool
+	IsEdited        bool
+	Text            string
+	FileID          string
+	FileSize        int64
+	MediaGroupID    string
+	NoteID          int
+	FolderID        int
+	FolderName      string
+	Origin          Origin
+	Meta            Meta
+}
+
+// Origin is the chat or the user the message comes from: the origin of
+// the forwarded message, the author of the replied one or the channel of
+// the post. It is empty for the messages written by the user.
+type Origin struct {
+	ChatID   int64
+	Username string
+	Title    string
+}
+
+type Meta struct {
+	UserID          int64
+	ChatID          int64
+	ChatType        models.ChatType
+	MessageID       int
+	UserName        string
+	CallbackQueryID string
+	Date            time.Time
+	Media           string
+	Language        i18n.Lang
+}
+
+func NewEvent(ctx context.Context, update *models.Update) *Event {
+	log := logger.L(ctx).With(logger.String("operation", "entities.NewEvent"))
+
+	event := &Event{}
+	eventType := fetchType(update, event)
+	event.Type = eventType
+
+	if update.Message != nil {
+		log.Debug(
+			"update data",
+			logger.String("text", update.Message.Text),
+			logger.String("Caption", update.Message.Caption),
+		)
+	}
+
+	if event.IsCallbackQuery {
+		event.Text = update.CallbackQuery.Data
+		event.Meta = Meta{
+			UserID:          update.CallbackQuery.From.ID,
+			ChatID:          update.CallbackQuery.Message.Message.Chat.ID,
+			ChatType:        update.CallbackQuery.Message.Message.Chat.Type,
+			MessageID:       update.CallbackQuery.Message.Message.ID,
+			UserName:        update.CallbackQuery.From.Username,
+			CallbackQueryID: update.CallbackQuery.ID,
+			Date:            time.Unix(int64(update.CallbackQuery.Message.Message.Date), 0),
+			Language:        i18n.FromCode(update.CallbackQuery.From.LanguageCode),
+		}
+		return event
+	}
+
+	if update.Message == nil {
+		return event
+	}
+
+	if from := update.Message.From; from != nil {
+		event.Meta.Language = i18n.FromCode(from.LanguageCode)
+	} else {
+		event.Meta.Language = i18n.Default
+	}
+
+	switch eventType {
+	case Message:
+		event.Text = checkForwardOrigin(update, event)
+	case Photo:
+		event.Text = checkForwardOrigin(update, event)
+		event.MediaGroupID = update.Message.MediaGroupID
+		photo := update.Message.Photo[len(update.Message.Photo)-1]
+		event.FileID = photo.FileID
+		event.FileSize = int64(photo.FileSize)
+	case Document:
+		event.Text = checkForwardOrigin(update, event)
+		event.MediaGroupID = update.Message.MediaGroupID
+		event.FileID = update.Message.Document.FileID
+		event.FileSize = update.Message.Document.FileSize
+	case Video:
+		event.Text = checkForwardOrigin(update, event)
+		event.MediaGroupID = update.Message.MediaGroupID
+		event.FileID = update.Message.Video.FileID
+		event.FileSize = update.Message.Video.FileSize
+	case Audio:
+		event.MediaGroupID = update.Message.MediaGroupID
+		event.FileID = update.Message.Audio.FileID
+		event.FileSize = update.Message.Audio.FileSize
+	case Animation:
+		event.MediaGroupID = update.Message.MediaGroupID
+		event.FileID = update.Message.Animation.FileID
+		event.FileSize = update.Message.Animation.FileSize
+	case Voice:
+		event.Text = checkForwardOrigin(update, event)
+		event.FileID = update.Message.Voice.FileID
+		event.FileSize = update.Message.Voice.FileSize
+	}
+	if update.Message.ForwardOrigin != nil {
+		event.Origin = newOrigin(update.Message.ForwardOrigin)
+	}
+	event.Meta = Meta{
+		ChatID:    update.Message.Chat.ID,
+		ChatType:  update.Message.Chat.Type,
+		MessageID: update.Message.ID,
+		Date:      time.Unix(int64(update.Message.Date), 0),
+		Language:  event.Meta.Language,
+	}
+	if from := update.Message.From; from != nil {
+		event.Meta.UserID = from.ID
+		event.Meta.UserName = from.Username
+	}
+
+	log.Debug("new event data", logger.String("event", event.String()))
+	return event
+}
+
+// NewReplyEvent creates an event for the message the update replies to.
+// The event belongs to the replier: the note is saved into the replier's
+// archive and the answers are sent to the replier's private chat.
+func NewReplyEvent(ctx context.Context, update *models.Update) *Event {
+	replier := update.Message
+	original := replier.ReplyToMessage
+	lang := i18n.FromCode(replier.From.LanguageCode)
+
+	event := NewEvent(ctx, &models.Update{ID: update.ID, Message: original})
+	if original.ForwardOrigin == nil && original.From != nil && original.From.Username != "" {
+		event.Text = withSource(lang, original.From.Username, event.Text)
+		event.Origin = userOrigin(original.From)
+	}
+
+	event.Meta = Meta{
+		UserID:   replier.From.ID,
+		ChatID:   replier.From.ID,
+		ChatType: models.ChatTypePrivate,
+		UserName: replier.From.Username,
+		Date:     time.Unix(int64(replier.Date), 0),
+		Language: lang,
+	}
+
+	return event
+}
+
+// NewChannelEvent creates an event for a channel post.
+// The text starts with the source that links to the original post.
+func NewChannelEvent(ctx context.Context, post *models.Message) *Event {
+	event := NewEvent(ctx, &models.Update{Message: post})
+	event.Text = withPostLink(event.Meta.Language, post, event.Text)
+	event.Origin = chatOrigin(post.Chat)
+
+	return event
+}
+
+// PostLink returns the link to the channel post.
+func PostLink(chat models.Chat, messageID int) string {
+	if chat.Username != "" {
+		return "https://t.me/" + chat.Username + "/" + strconv.Itoa(messageID)
+	}
+
+	id := strings.TrimPrefix(strconv.FormatInt(chat.ID, 10), "-100")
+	return "https://t.me/c/" + id + "/" + strconv.Itoa(messageID)
+}
+
+func checkForwardOrigin(update *models.Update, event *Event) string {
+	if event.Type == Message {
+		if update.Message.ForwardOrigin != nil {
+			return setSource(update, event.Meta.Language, update.Message.Text)
+		} else {
+			return update.Message.Text
+		}
+	}
+	if update.Message.ForwardOrigin != nil {
+		return setSource(update, event.Meta.Language, update.Message.Caption)
+	} else {
+		return update.Message.Caption
+	}
+}
+
+func setSource(update *models.Update, lang i18n.Lang, text string) string {
+	var username string
+	messageOrigin := update.Message.ForwardOrigin
+	switch {
+	case messageOrigin.MessageOriginChannel != nil:
+		username = messageOrigin.MessageOriginChannel.Chat.Username
+	case messageOrigin.MessageOriginChat != nil:
+		username = messageOrigin.MessageOriginChat.SenderChat.Username
+	case messageOrigin.MessageOriginHiddenUser != nil:
+		username = messageOrigin.MessageOriginHiddenUser.SenderUserName
+	case messageOrigin.MessageOriginUser != nil:
+		username = messageOrigin.MessageOriginUser.SenderUser.Username
+	}
+
+	return withSource(lang, username, text)
+}
+
+func newOrigin(origin *models.MessageOrigin) Origin {
+	switch {
+	case origin.MessageOriginChannel != nil:
+		return chatOrigin(origin.MessageOriginChannel.Chat)
+	case origin.MessageOriginChat != nil:
+		return chatOrigin(origin.MessageOriginChat.SenderChat)
+	case origin.MessageOriginHiddenUser != nil:
+		return Origin{Title: origin.MessageOriginHiddenUser.SenderUserName}
+	case origin.MessageOriginUser != nil:
+		return userOrigin(&origin.MessageOriginUser.SenderUser)
+	}
+
+	return Origin{}
+}
+
+func chatOrigin(chat models.Chat) Origin {
+	return Origin{ChatID: chat.ID, Username: chat.Username, Title: chat.Title}
+}
+
+func userOrigin(user *models.User) Origin {
+	return Origin{
+		ChatID:   user.ID,
+		Username: user.Username,
+		Title:    strings.TrimSpace(user.FirstName + " " + user.LastName),
+	}
+}
+
+func withSource(lang i18n.Lang, username string, text string) string {
+	if username == "" && text == "" {
+		return ""
+	}
+
+	b := &strings.Builder{}
+	b.WriteString(i18n.T(lang, messages.Source))
+	b.WriteString("@")
+	b.WriteString(username)
+	b.WriteString("\n\n")
+	b.WriteString(text)
+	return b.String()
+}
+
+func withPostLink(lang i18n.Lang, post *models.Message, text string) string {
+	b := &strings.Builder{}
+	b.WriteString(i18n.T(lang, messages.Source))
+	if post.Chat.Username != "" {
+		b.WriteString("@")
+		b.WriteString(post.Chat.Username)
+	} else {
+		b.WriteString(post.Chat.Title)
+	}
+	b.WriteString("\n")
+	b.WriteString(PostLink(post.Chat, post.ID))
+	if text != "" {
+		b.WriteString("\n\n")
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+func fetchType(update *models.Update, event *Event) Type {
+	if update.CallbackQuery != nil {
+		event.IsCallbackQuery = true
+		if update.CallbackQuery.Message.Message != nil {
+			if len(update.CallbackQuery.Message.Message.Photo) != 0 {
+				return Photo
+			}
+			return Message
+		}
+	}
+
+	if update.Message == nil {
+		return Unknown
+	}
+
+	switch {
+	case len(update.Message.Photo) != 0:
+		return Photo
+	case update.Message.Document != nil:
+		return Document
+	case update.Message.Video != nil:
+		return Video
+	case update.Message.Audio != nil:
+		return Audio
+	case update.Message.Animation != nil:
+		return Animation
+	case update.Message.Voice != nil:
+		return Voice
+	case update.Message.Text != "":
+		return Message
+	}
+
+	return Unknown
+}
+
+func (e *Event) String() string {
+	b := &strings.Builder{}
+
+	b.WriteString("Event{Type: ")
+	b.WriteString(e.Type.String())
+	b.WriteString(", IsCallbackQuery: ")
+	b.WriteString(strconv.FormatBool(e.IsCallbackQuery))
+	b.WriteString(", IsEdited: ")
+	b.WriteString(strconv.FormatBool(e.IsEdited))
+	b.WriteString(", Text: ")
+	b.WriteString(e.Text)
+	b.WriteString(", FileID: ")
+	b.WriteString(e.FileID)
+	b.WriteString(", FileSize: ")
+	b.WriteString(strconv.FormatInt(e.FileSize, 10))
+	b.WriteString(", MediaGroupID: ")
+	b.WriteString(e.MediaGroupID)
+	b.WriteString(", NoteID: ")
+	b.WriteString(strconv.Itoa(e.NoteID))
+	b.WriteString(", FolderID: ")
+	b.WriteString(strconv.Itoa(e.FolderID))
+	b.WriteString(", FolderName: ")
+	b.WriteString(e.FolderName)
+	b.WriteString(", Meta: ")
+	b.WriteString(e.Meta.String())
+	b.WriteRune('}')
+
+	return b.String()
+}
+
+func (m Meta) String() string {
+	b := &strings.Builder{}
+
+	b.WriteString("Meta{UserID: ")
+	b.WriteString(strconv.FormatInt(m.UserID, 10))
+	b.WriteString(", ChatID: ")
+	b.WriteString(strconv.FormatInt(m.ChatID, 10))
+	b.WriteString(", ChatType: ")
+	b.WriteString(string(m.ChatType))
+	b.WriteString(", MessageID: ")
+	b.WriteString(strconv.Itoa(m.MessageID))
+	b.WriteString(", UserName: ")
+	b.WriteString(m.UserName)
+	b.WriteString(", CallbackQueryID: ")
+	b.WriteString(m.CallbackQueryID)
+	b.WriteString(", Date: ")
+	b.WriteString(m.Date.String())
+	b.WriteString(", Media: ")
+	b.WriteString(m.Media)
+	b.WriteString(", Language: ")
+	b.WriteString(string(m.Language))
+	b.WriteRune('}')
+
+	return b.String()
+}
@@ -0,0 +1,25 @@
+package entities
+
+// Status is the outcome of a processor call the router acts on.
+type Status int
+
+const (
+	// StatusSkipped means the call doesn't apply to the event, e.g. the
+	// user is not in the state the call ends.
+	StatusSkipped Status = iota
+	StatusDone
+	StatusFailed
+	// StatusRejected means the user can't do it: the folder can't be
+	// changed or a limit is reached.
+	StatusRejected
+	// StatusSuggested means the folder suggestion waits for the answer
+	// of the user.
+	StatusSuggested
+)
+
+// Result is the outcome of a processor call and the text for the user,
+// already rendered in the language of the user.
+type Result struct {
+	Status Status
+	Text   string
+}
@@ -0,0 +1,132 @@
+package folder
+
+import (
+	"strings"
+
+	"archive_bot/internal/i18n"
+)
+
+// minSimilarity is how alike the typed name and the name of the folder
+// have to be for the folder to be offered.
+const minSimilarity float64 = 0.6
+
+// Match finds the folder for the name typed by the user. The name, the
+// names shown to the user and the aliases are compared ignoring the case,
+// exact is false when the folder only looks like the name. It returns nil
+// if no folder is close enough.
+func Match(name string, folders []*Folder) (*Folder, bool) {
+	name = normalizeName(name)
+	if name == "" {
+		return nil, false
+	}
+
+	var (
+		best  *Folder
+		score float64
+	)
+	for _, f := range folders {
+		for _, key := range matchKeys(f) {
+			if key == name {
+				return f, true
+			}
+			if s := similarity(name, key); s > score {
+				best, score = f, s
+			}
+		}
+	}
+	if score < minSimilarity {
+		return nil, false
+	}
+
+	return best, false
+}
+
+// matchKeys are the names the folder is found by, the default folder is
+// found by its name in any language.
+func matchKeys(f *Folder) []string {
+	keys := make([]string, 0, len(f.Aliases)+1)
+	keys = append(keys, normalizeName(f.Name))
+	if f.IsDefault() {
+		for _, lang := range i18n.Supported() {
+			keys = append(keys, normalizeName(DisplayName(f, lang)))
+		}
+	}
+	for _, alias := range f.Aliases {
+		keys = append(keys, normalizeName(alias))
+	}
+	return keys
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// similarity is the best of the edit distance and the trigram similarity
+// of the names, from 0 for the different names to 1 for the same ones.
+func similarity(a string, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	byEdits := 1 - float64(distance(ra, rb))/float64(longest)
+
+	return max(byEdits, trigramSimilarity(a, b))
+}
+
+// distance is the number of the insertions, deletions, substitutions and
+// transpositions of the adjacent runes turning a into b.
+func distance(a []rune, b []rune) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	return prev[len(b)]
+}
+
+// trigramSimilarity is the share of the trigrams the names have in common,
+// the words are padded like pg_trgm does.
+func trigramSimilarity(a string, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	common := 0
+	for t := range ta {
+		if tb[t] {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+func trigrams(s string) map[string]bool {
+	res := make(map[string]bool)
+	for _, word := range strings.Fields(s) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			res[string(runes[i:i+3])] = true
+		}
+	}
+	return res
+}
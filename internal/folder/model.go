@@ -1,26 +1,89 @@
-package folder
-
-import (
-	"strconv"
-	"strings"
-)
-
-type Folder struct {
-	ID     int
-	UserID int64
-	Name   string
-}
-
-func (f *Folder) String() string {
-	b := &strings.Builder{}
-
-	b.WriteString("Folder{ID: ")
-	b.WriteString(strconv.Itoa(f.ID))
-	b.WriteString(", UserID: ")
-	b.WriteString(strconv.FormatInt(f.UserID, 10))
-	b.WriteString(", Name: ")
-	b.WriteString(f.Name)
-	b.WriteRune('}')
-
-	return b.String()
-}
+package folder
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"archive_bot/internal/entities"
+)
+
+// The values of the folder menu asked by a text message.
+const (
+	FieldName      string = "name"
+	FieldIcon      string = "icon"
+	FieldPosition  string = "position"
+	FieldSplitDate string = "split_date"
+	FieldAliases   string = "aliases"
+)
+
+type Folder struct {
+	ID       int
+	UserID   int64
+	Name     string
+	Icon     string
+	Position int
+	Hidden   bool
+	// Aliases are the other names the folder is found by.
+	Aliases []string
+}
+
+// Suggestion is the folder offered for the name the user has mistyped.
+// Folder is nil when no folder looks like the name, only a new folder is
+// offered then. NoteID is the note to move into the folder, 0 to open the
+// folder.
+type Suggestion struct {
+	Name   string
+	Folder *Folder
+	NoteID int
+}
+
+// FolderID returns the ID of the offered folder, 0 if there is none.
+func (s *Suggestion) FolderID() int {
+	if s.Folder == nil {
+		return 0
+	}
+	return s.Folder.ID
+}
+
+// SplitBy selects the notes moved out of the folder by the split: the notes
+// of the type, the notes created before the time or both.
+type SplitBy struct {
+	Type   entities.Type
+	Before time.Time
+}
+
+// Label is the name of the folder with its icon.
+func (f *Folder) Label(name string) string {
+	if f.Icon == "" {
+		return name
+	}
+	return f.Icon + " " + name
+}
+
+// IsDefault reports whether the folder is the default one.
+func (f *Folder) IsDefault() bool {
+	return f.Name == defaultName
+}
+
+func (f *Folder) String() string {
+	b := &strings.Builder{}
+
+	b.WriteString("Folder{ID: ")
+	b.WriteString(strconv.Itoa(f.ID))
+	b.WriteString(", UserID: ")
+	b.WriteString(strconv.FormatInt(f.UserID, 10))
+	b.WriteString(", Name: ")
+	b.WriteString(f.Name)
+	b.WriteString(", Icon: ")
+	b.WriteString(f.Icon)
+	b.WriteString(", Position: ")
+	b.WriteString(strconv.Itoa(f.Position))
+	b.WriteString(", Hidden: ")
+	b.WriteString(strconv.FormatBool(f.Hidden))
+	b.WriteString(", Aliases: ")
+	b.WriteString(strings.Join(f.Aliases, ","))
+	b.WriteRune('}')
+
+	return b.String()
+}
@@ -1,147 +1,524 @@
-package folder
-
-import (
-	"context"
-	"sync"
-
-	"archive_bot/pkg/er"
-	"archive_bot/pkg/logger"
-
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
-)
-
-var ErrNoFolders = er.New("there's no saved folders", "", nil)
-
-var (
-	instance *pgRepository
-	once     sync.Once
-)
-
-type pgRepository struct {
-	log *logger.Logger
-	db  *pgxpool.Pool
-}
-
-// NewRepository creates new folder repository.
-func NewRepository(ctx context.Context, log *logger.Logger, db *pgxpool.Pool) (*pgRepository, error) {
-	once.Do(func() {
-		instance = &pgRepository{log: log, db: db}
-	})
-
-	return instance, nil
-}
-
-// Save saves catalogue to database.
-func (repo *pgRepository) Save(ctx context.Context, f *Folder) (int, error) {
-	const op string = "folder.repository.Save"
-
-	var id int
-	if err := repo.db.QueryRow(ctx,
-		`INSERT INTO folders (user_id, name)
-		VALUES ($1, $2)
-		ON CONFLICT (user_id, name) DO UPDATE 
-		SET name = $2
-		RETURNING id;`,
-		f.UserID, f.Name).Scan(&id); err != nil {
-		return 0, er.New("unable to create folder", op, err)
-	}
-
-	return id, nil
-}
-
-func (repo *pgRepository) Find(ctx context.Context, f *Folder) (string, error) {
-	const op string = "folder.repository.FindOrCreate"
-
-	var folderName string
-
-	if err := repo.db.QueryRow(ctx,
-		`SELECT name FROM folders WHERE id = $1`,
-		f.ID).Scan(&folderName); err != nil {
-		return "", er.New("unable to find folder(id)", op, err)
-	}
-
-	return folderName, nil
-}
-
-// TODO: maybe transaction
-func (repo *pgRepository) FindOrCreate(ctx context.Context, f *Folder) (int, error) {
-	const op string = "folder.repository.FindOrCreate"
-
-	var folderID int
-	if err := repo.db.QueryRow(ctx,
-		`INSERT INTO folders (user_id, name)
-		VALUES ($1, $2)
-		ON CONFLICT (user_id, name) DO UPDATE 
-		SET name = $2
-		RETURNING id;`,
-		f.UserID, f.Name).Scan(&folderID); err != nil {
-		return 0, er.New("unable to find or create folder(id)", op, err)
-	}
-
-	return folderID, nil
-}
-
-// Save saves catalogue to database.
-func (repo *pgRepository) All(ctx context.Context, f *Folder) ([]*Folder, error) {
-	const op string = "folder.repository.All"
-
-	rows, err := repo.db.Query(ctx,
-		`SELECT id, name FROM folders WHERE user_id = $1;`, f.UserID)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, ErrNoFolders
-		}
-		return nil, er.New("unable to get all folders", op, err)
-	}
-	defer rows.Close()
-
-	catalogues := []*Folder{}
-	for rows.Next() {
-		var ctl Folder
-		if err := rows.Scan(&ctl.ID, &ctl.Name); err != nil {
-			return nil, er.New("unable to scan data", op, err)
-		}
-		catalogues = append(catalogues, &ctl)
-	}
-
-	// if len(catalogues) == 0 {
-	// 	return nil, ErrNoFolders
-	// }
-
-	if err := rows.Err(); err != nil {
-		return nil, er.New("error in rows", op, err)
-	}
-
-	return catalogues, nil
-}
-
-func (repo *pgRepository) RemoveByID(ctx context.Context, id int) error {
-	const op string = "folder.repository.RemoveLast"
-
-	if _, err := repo.db.Exec(ctx,
-		`DELETE FROM folders WHERE id = ($1);`,
-		id); err != nil {
-		return er.New("the folder could not be removed", op, err)
-	}
-
-	return nil
-}
-
-// DefaultCatalogueID find default catalogue id.
-func (repo *pgRepository) DefaultFolderID(ctx context.Context, user_id int64) (int, error) {
-	const op string = "folder.repository.DefaultFolderID"
-
-	var id int
-	if err := repo.db.QueryRow(ctx,
-		`SELECT id FROM folders
-		WHERE user_id = $1 AND name = 'default';`,
-		user_id).Scan(&id); err != nil {
-		if err == pgx.ErrNoRows {
-			return 0, ErrNoFolders
-		}
-		return 0, er.New("unable to get id of default folder", op, err)
-	}
-
-	return id, nil
-}
+package folder
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"archive_bot/internal/entities"
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNoFolders = er.New("there's no saved folders", "", nil)
+	ErrNoFolder  = er.New("the folder does not exist", "", nil)
+	ErrNameTaken = er.New("the folder name is taken", "", nil)
+
+	ErrNothingToSplit = er.New("no notes match the split", "", nil)
+)
+
+// uniqueViolation is the postgres code of the unique constraint violation.
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether the error breaks a unique constraint,
+// e.g. the name of the folder is taken by another folder of the user.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+var (
+	instance *pgRepository
+	once     sync.Once
+)
+
+type pgRepository struct {
+	log *logger.Logger
+	db  *pgxpool.Pool
+}
+
+// NewRepository creates new folder repository.
+func NewRepository(ctx context.Context, log *logger.Logger, db *pgxpool.Pool) (*pgRepository, error) {
+	once.Do(func() {
+		instance = &pgRepository{log: log, db: db}
+	})
+
+	return instance, nil
+}
+
+// Save saves catalogue to database.
+func (repo *pgRepository) Save(ctx context.Context, f *Folder) (int, error) {
+	const op string = "folder.repository.Save"
+
+	var id int
+	if err := repo.db.QueryRow(ctx,
+		`INSERT INTO folders (user_id, name, position)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(position), 0) + 1 FROM folders WHERE user_id = $1))
+		ON CONFLICT (user_id, name) DO UPDATE 
+		SET name = $2
+		RETURNING id;`,
+		f.UserID, f.Name).Scan(&id); err != nil {
+		return 0, er.New("unable to create folder", op, err)
+	}
+
+	return id, nil
+}
+
+func (repo *pgRepository) Find(ctx context.Context, f *Folder) (string, error) {
+	const op string = "folder.repository.FindOrCreate"
+
+	var folderName string
+
+	if err := repo.db.QueryRow(ctx,
+		`SELECT name FROM folders WHERE id = $1`,
+		f.ID).Scan(&folderName); err != nil {
+		return "", er.New("unable to find folder(id)", op, err)
+	}
+
+	return folderName, nil
+}
+
+// TODO: maybe transaction
+func (repo *pgRepository) FindOrCreate(ctx context.Context, f *Folder) (int, error) {
+	const op string = "folder.repository.FindOrCreate"
+
+	var folderID int
+	if err := repo.db.QueryRow(ctx,
+		`INSERT INTO folders (user_id, name, position)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(position), 0) + 1 FROM folders WHERE user_id = $1))
+		ON CONFLICT (user_id, name) DO UPDATE 
+		SET name = $2
+		RETURNING id;`,
+		f.UserID, f.Name).Scan(&folderID); err != nil {
+		return 0, er.New("unable to find or create folder(id)", op, err)
+	}
+
+	return folderID, nil
+}
+
+// All returns all the folders of the user in their order, the hidden ones too.
+func (repo *pgRepository) All(ctx context.Context, f *Folder) ([]*Folder, error) {
+	const op string = "folder.repository.All"
+
+	rows, err := repo.db.Query(ctx,
+		`SELECT id, name, icon, position, hidden, aliases FROM folders
+		WHERE user_id = $1
+		ORDER BY position, id;`, f.UserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNoFolders
+		}
+		return nil, er.New("unable to get all folders", op, err)
+	}
+	defer rows.Close()
+
+	catalogues := []*Folder{}
+	for rows.Next() {
+		ctl := Folder{UserID: f.UserID}
+		if err := rows.Scan(&ctl.ID, &ctl.Name, &ctl.Icon, &ctl.Position, &ctl.Hidden, &ctl.Aliases); err != nil {
+			return nil, er.New("unable to scan data", op, err)
+		}
+		catalogues = append(catalogues, &ctl)
+	}
+
+	// if len(catalogues) == 0 {
+	// 	return nil, ErrNoFolders
+	// }
+
+	if err := rows.Err(); err != nil {
+		return nil, er.New("error in rows", op, err)
+	}
+
+	return catalogues, nil
+}
+
+func (repo *pgRepository) RemoveByID(ctx context.Context, id int) error {
+	const op string = "folder.repository.RemoveLast"
+
+	if _, err := repo.db.Exec(ctx,
+		`DELETE FROM folders WHERE id = ($1);`,
+		id); err != nil {
+		return er.New("the folder could not be removed", op, err)
+	}
+
+	return nil
+}
+
+// FindByID finds the folder of the user.
+func (repo *pgRepository) FindByID(ctx context.Context, f *Folder) (*Folder, error) {
+	const op string = "folder.repository.FindByID"
+
+	res := Folder{ID: f.ID, UserID: f.UserID}
+	if err := repo.db.QueryRow(ctx,
+		`SELECT name, icon, position, hidden, aliases FROM folders WHERE id = $1 AND user_id = $2;`,
+		f.ID, f.UserID).Scan(&res.Name, &res.Icon, &res.Position, &res.Hidden, &res.Aliases); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNoFolder
+		}
+		return nil, er.New("unable to find folder", op, err)
+	}
+
+	return &res, nil
+}
+
+// Rename renames the folder of the user, the default folder can't be renamed.
+// It returns ErrNameTaken when another folder of the user has the name.
+func (repo *pgRepository) Rename(ctx context.Context, f *Folder) error {
+	const op string = "folder.repository.Rename"
+
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE folders SET name = $1
+		WHERE id = $2 AND user_id = $3 AND name <> 'default';`,
+		f.Name, f.ID, f.UserID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrNameTaken
+		}
+		return er.New("the folder could not be renamed", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoFolder
+	}
+
+	return nil
+}
+
+// SetIcon sets the icon of the folder of the user, the empty one removes it.
+func (repo *pgRepository) SetIcon(ctx context.Context, f *Folder) error {
+	const op string = "folder.repository.SetIcon"
+
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE folders SET icon = $1 WHERE id = $2 AND user_id = $3;`,
+		f.Icon, f.ID, f.UserID)
+	if err != nil {
+		return er.New("unable to set folder icon", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoFolder
+	}
+
+	return nil
+}
+
+// SetAliases replaces the aliases of the folder of the user.
+func (repo *pgRepository) SetAliases(ctx context.Context, f *Folder) error {
+	const op string = "folder.repository.SetAliases"
+
+	aliases := f.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE folders SET aliases = $1 WHERE id = $2 AND user_id = $3;`,
+		aliases, f.ID, f.UserID)
+	if err != nil {
+		return er.New("unable to set folder aliases", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoFolder
+	}
+
+	return nil
+}
+
+// SetHidden hides the folder of the user from the list or shows it again,
+// the default folder can't be hidden.
+func (repo *pgRepository) SetHidden(ctx context.Context, f *Folder) error {
+	const op string = "folder.repository.SetHidden"
+
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE folders SET hidden = $1
+		WHERE id = $2 AND user_id = $3 AND name <> 'default';`,
+		f.Hidden, f.ID, f.UserID)
+	if err != nil {
+		return er.New("unable to hide folder", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoFolder
+	}
+
+	return nil
+}
+
+// Reorder numbers the folders of the user in the order of the IDs.
+func (repo *pgRepository) Reorder(ctx context.Context, userID int64, ids []int) error {
+	const op string = "folder.repository.Reorder"
+
+	if _, err := repo.db.Exec(ctx,
+		`UPDATE folders f SET position = o.position
+		FROM unnest($2::int[]) WITH ORDINALITY AS o(id, position)
+		WHERE f.id = o.id AND f.user_id = $1;`,
+		userID, ids); err != nil {
+		return er.New("unable to reorder folders", op, err)
+	}
+
+	return nil
+}
+
+// Remove removes the folder of the user, the default folder can't be removed.
+func (repo *pgRepository) Remove(ctx context.Context, f *Folder) error {
+	const op string = "folder.repository.Remove"
+
+	tag, err := repo.db.Exec(ctx,
+		`DELETE FROM folders
+		WHERE id = $1 AND user_id = $2 AND name <> 'default';`,
+		f.ID, f.UserID)
+	if err != nil {
+		return er.New("the folder could not be removed", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoFolder
+	}
+
+	return nil
+}
+
+// DefaultCatalogueID find default catalogue id.
+func (repo *pgRepository) DefaultFolderID(ctx context.Context, user_id int64) (int, error) {
+	const op string = "folder.repository.DefaultFolderID"
+
+	var id int
+	if err := repo.db.QueryRow(ctx,
+		`SELECT id FROM folders
+		WHERE user_id = $1 AND name = 'default';`,
+		user_id).Scan(&id); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, ErrNoFolders
+		}
+		return 0, er.New("unable to get id of default folder", op, err)
+	}
+
+	return id, nil
+}
+
+// Merge moves the notes and the linked channels of the folder into the
+// other one and removes the folder, the default folder can't be merged.
+// It returns the number of the moved notes, ErrNameTaken when the moved
+// rows break a unique constraint.
+func (repo *pgRepository) Merge(ctx context.Context, userID int64, id int, into int) (int, error) {
+	const op string = "folder.repository.Merge"
+
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return 0, er.New("unable to begin transaction", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var one int
+	if err := tx.QueryRow(ctx,
+		`SELECT 1 FROM folders WHERE id = $1 AND user_id = $2 FOR UPDATE;`,
+		into, userID).Scan(&one); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, ErrNoFolder
+		}
+		return 0, er.New("unable to find folder", op, err)
+	}
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE texts SET folder_id = $1 WHERE folder_id = $2 AND user_id = $3;`,
+		into, id, userID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, ErrNameTaken
+		}
+		return 0, er.New("unable to move notes", op, err)
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE channels SET folder_id = $1 WHERE folder_id = $2 AND user_id = $3;`,
+		into, id, userID); err != nil {
+		if isUniqueViolation(err) {
+			return 0, ErrNameTaken
+		}
+		return 0, er.New("unable to move channels", op, err)
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE rules SET folder_id = $1 WHERE folder_id = $2 AND user_id = $3;`,
+		into, id, userID); err != nil {
+		if isUniqueViolation(err) {
+			return 0, ErrNameTaken
+		}
+		return 0, er.New("unable to move rules", op, err)
+	}
+
+	removed, err := tx.Exec(ctx,
+		`DELETE FROM folders
+		WHERE id = $1 AND user_id = $2 AND name <> 'default';`,
+		id, userID)
+	if err != nil {
+		return 0, er.New("the folder could not be removed", op, err)
+	}
+	if removed.RowsAffected() == 0 {
+		return 0, ErrNoFolder
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, er.New("unable to commit merge", op, err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// Usage counts the notes of the folder and the bytes of their media.
+func (repo *pgRepository) Usage(ctx context.Context, f *Folder) (int, int64, error) {
+	const op string = "folder.repository.Usage"
+
+	var (
+		count int
+		size  int64
+	)
+	if err := repo.db.QueryRow(ctx,
+		`SELECT COUNT(*), COALESCE(SUM(media_size), 0)
+		FROM texts
+		WHERE user_id = $1 AND folder_id = $2;`,
+		f.UserID, f.ID).Scan(&count, &size); err != nil {
+		return 0, 0, er.New("unable to count folder usage", op, err)
+	}
+
+	return count, size, nil
+}
+
+// Copy creates the folder named f.Name with the copies of the notes of the
+// folder f.ID, their media and transcripts. The copied albums get their
+// own media group, so the copies don't show the media of the originals,
+// the notes still waiting for a transcript get their own job.
+func (repo *pgRepository) Copy(ctx context.Context, f *Folder) (int, int, error) {
+	const op string = "folder.repository.Copy"
+
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return 0, 0, er.New("unable to begin transaction", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	id, err := createFrom(ctx, tx, f)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tag, err := tx.Exec(ctx,
+		`CREATE TEMP TABLE note_copies ON COMMIT DROP AS
+		SELECT id AS old_id, nextval(pg_get_serial_sequence('texts', 'id')) AS new_id
+		FROM texts
+		WHERE user_id = $1 AND folder_id = $2;`,
+		f.UserID, f.ID)
+	if err != nil {
+		return 0, 0, er.New("unable to number note copies", op, err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO texts
+		(id, user_id, folder_id, type, description, media_group_id, created_at, favorite, tags, media_size, transcript)
+		SELECT c.new_id, t.user_id, $1, t.type, t.description,
+			CASE WHEN t.media_group_id = '' THEN '' ELSE t.media_group_id || '_' || c.new_id END,
+			t.created_at, t.favorite, t.tags, t.media_size, t.transcript
+		FROM texts t JOIN note_copies c ON c.old_id = t.id;`,
+		id); err != nil {
+		return 0, 0, er.New("unable to copy notes", op, err)
+	}
+
+	for _, table := range []string{"photos", "audios", "documents", "videos", "animations"} {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO `+table+` (texts_id, file_id, media_group_id)
+			SELECT c.new_id, m.file_id,
+				CASE WHEN m.media_group_id = '' THEN '' ELSE m.media_group_id || '_' || c.new_id END
+			FROM `+table+` m JOIN note_copies c ON c.old_id = m.texts_id;`); err != nil {
+			return 0, 0, er.New("unable to copy "+table, op, err)
+		}
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO voices (texts_id, file_id)
+		SELECT c.new_id, m.file_id
+		FROM voices m JOIN note_copies c ON c.old_id = m.texts_id;`); err != nil {
+		return 0, 0, er.New("unable to copy voices", op, err)
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO transcriptions (texts_id, file_id)
+		SELECT c.new_id, j.file_id
+		FROM transcriptions j JOIN note_copies c ON c.old_id = j.texts_id
+		WHERE NOT j.failed;`); err != nil {
+		return 0, 0, er.New("unable to copy transcriptions", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, er.New("unable to commit copy", op, err)
+	}
+
+	return id, int(tag.RowsAffected()), nil
+}
+
+// Split creates the folder named f.Name and moves into it the notes of the
+// folder f.ID matching the split. Nothing is created when no note matches.
+func (repo *pgRepository) Split(ctx context.Context, f *Folder, by SplitBy) (int, int, error) {
+	const op string = "folder.repository.Split"
+
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return 0, 0, er.New("unable to begin transaction", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	id, err := createFrom(ctx, tx, f)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var noteType string
+	if by.Type != entities.Unknown {
+		noteType = by.Type.String()
+	}
+	var before *time.Time
+	if !by.Before.IsZero() {
+		before = &by.Before
+	}
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE texts SET folder_id = $1
+		WHERE user_id = $2 AND folder_id = $3
+			AND ($4 = '' OR type::text = $4)
+			AND ($5::timestamptz IS NULL OR created_at < $5);`,
+		id, f.UserID, f.ID, noteType, before)
+	if err != nil {
+		return 0, 0, er.New("unable to move notes", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return 0, 0, ErrNothingToSplit
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, er.New("unable to commit split", op, err)
+	}
+
+	return id, int(tag.RowsAffected()), nil
+}
+
+// createFrom creates the folder named f.Name with the icon of the folder
+// f.ID at the bottom of the list, ErrNameTaken when the name is taken.
+func createFrom(ctx context.Context, tx pgx.Tx, f *Folder) (int, error) {
+	const op string = "folder.repository.createFrom"
+
+	var id int
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO folders (user_id, name, icon, position)
+		SELECT user_id, $3, icon, (SELECT COALESCE(MAX(position), 0) + 1 FROM folders WHERE user_id = $2)
+		FROM folders
+		WHERE id = $1 AND user_id = $2
+		RETURNING id;`,
+		f.ID, f.UserID, f.Name).Scan(&id); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, ErrNoFolder
+		}
+		if isUniqueViolation(err) {
+			return 0, ErrNameTaken
+		}
+		return 0, er.New("unable to create folder", op, err)
+	}
+
+	return id, nil
+}
@@ -1,111 +1,533 @@
-package folder
-
-import (
-	"context"
-	"strconv"
-
-	"archive_bot/internal/const/buttons"
-	"archive_bot/internal/const/messages"
-	"archive_bot/internal/entities"
-	"archive_bot/pkg/logger"
-)
-
-type Repository interface {
-	Save(ctx context.Context, c *Folder) (int, error)
-	Find(ctx context.Context, f *Folder) (string, error)
-	FindOrCreate(ctx context.Context, c *Folder) (int, error)
-	All(ctx context.Context, c *Folder) ([]*Folder, error)
-	RemoveByID(ctx context.Context, id int) error
-	DefaultFolderID(ctx context.Context, user_id int64) (int, error)
-}
-
-type service struct {
-	log             *logger.Logger
-	repo            Repository
-	defaultFolderID map[int64]int
-}
-
-func NewService(ctx context.Context, log *logger.Logger, repo Repository) *service {
-	return &service{log: log, repo: repo, defaultFolderID: make(map[int64]int)}
-}
-
-func (s *service) Save(ctx context.Context, event *entities.Event) string {
-	log := s.log.With(logger.String("operation", "folder.service.Save"))
-
-	if _, err := s.repo.Save(ctx, &Folder{
-		UserID: event.Meta.UserID, Name: event.Text,
-	}); err != nil {
-		log.Error("failed to save folder", logger.ErrAttr(err))
-		return messages.Error
-	}
-
-	return messages.FolderCreated
-}
-
-func (s *service) RemoveByID(ctx context.Context, id int) error {
-	return s.repo.RemoveByID(ctx, id)
-}
-
-func (s *service) FindOrCreate(ctx context.Context, event *entities.Event) (int, error) {
-	return s.repo.FindOrCreate(ctx, &Folder{
-		UserID: event.Meta.UserID, Name: event.Text,
-	})
-}
-
-func (s *service) Find(ctx context.Context, event *entities.Event) (string, error) {
-	return s.repo.Find(ctx, &Folder{ID: event.FolderID})
-}
-
-func (s *service) SaveDefault(ctx context.Context, event *entities.Event) error {
-	log := s.log.With(logger.String("operation", "folder.service.Save"))
-
-	c := &Folder{UserID: event.Meta.UserID, Name: "default"}
-	FolderID, err := s.repo.Save(ctx, c)
-	if err != nil {
-		log.Error(
-			"failed to save Folder",
-			logger.ErrAttr(err),
-		)
-		return err
-	}
-
-	s.defaultFolderID[event.Meta.UserID] = FolderID
-	return nil
-}
-
-func (s *service) DefaultFolderID(ctx context.Context, user_id int64) int {
-	defaultFolderID, ok := s.defaultFolderID[user_id]
-	if !ok {
-		defaultFolderID, err := s.repo.DefaultFolderID(ctx, user_id)
-		if err != nil {
-			return 0
-		}
-		s.defaultFolderID[user_id] = defaultFolderID
-	}
-	return defaultFolderID
-}
-
-func (s *service) All(ctx context.Context, event *entities.Event) map[string]string {
-	log := s.log.With(logger.String("operation", "Folder.service.AllAsInlineButtons"))
-
-	folders, err := s.repo.All(ctx, &Folder{UserID: event.Meta.UserID})
-	if err != nil {
-		if err == ErrNoFolders {
-			log.Info("Folders is empty", logger.ErrAttr(err))
-			return nil
-		}
-		log.Error("failed to get all Folders", logger.ErrAttr(err))
-		return nil
-	}
-
-	res := make(map[string]string, len(folders))
-	for _, f := range folders {
-		if f.Name == "default" {
-			res[buttons.Prefix+strconv.Itoa(f.ID)] = buttons.DefaultFolderName
-			continue
-		}
-		res[buttons.Prefix+strconv.Itoa(f.ID)] = f.Name
-	}
-
-	return res
-}
+package folder
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"archive_bot/internal/const/buttons"
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+const (
+	defaultName   string = "default"
+	maxNameLength int    = 100
+	maxIconLength int    = 8
+	noIcon        string = "-"
+
+	maxAliases     int    = 10
+	maxAliasLength int    = 32
+	aliasDelimiter string = ","
+	noAliases      string = "-"
+)
+
+var (
+	ErrInvalidName     = er.New("the folder name is invalid", "", nil)
+	ErrInvalidIcon     = er.New("the folder icon is invalid", "", nil)
+	ErrInvalidPosition = er.New("the folder position is invalid", "", nil)
+	ErrSameFolder      = er.New("the folder can't be merged into itself", "", nil)
+	ErrInvalidAlias    = er.New("the folder alias is invalid", "", nil)
+	ErrAliasTaken      = er.New("the folder alias is taken", "", nil)
+)
+
+type Repository interface {
+	Save(ctx context.Context, c *Folder) (int, error)
+	Find(ctx context.Context, f *Folder) (string, error)
+	FindOrCreate(ctx context.Context, c *Folder) (int, error)
+	All(ctx context.Context, c *Folder) ([]*Folder, error)
+	FindByID(ctx context.Context, f *Folder) (*Folder, error)
+	Rename(ctx context.Context, f *Folder) error
+	Remove(ctx context.Context, f *Folder) error
+	RemoveByID(ctx context.Context, id int) error
+	SetIcon(ctx context.Context, f *Folder) error
+	SetHidden(ctx context.Context, f *Folder) error
+	SetAliases(ctx context.Context, f *Folder) error
+	Reorder(ctx context.Context, userID int64, ids []int) error
+	Merge(ctx context.Context, userID int64, id int, into int) (int, error)
+	Copy(ctx context.Context, f *Folder) (int, int, error)
+	Usage(ctx context.Context, f *Folder) (int, int64, error)
+	Split(ctx context.Context, f *Folder, by SplitBy) (int, int, error)
+	DefaultFolderID(ctx context.Context, user_id int64) (int, error)
+}
+
+type service struct {
+	log             *logger.Logger
+	repo            Repository
+	defaultFolderID map[int64]int
+}
+
+func NewService(ctx context.Context, log *logger.Logger, repo Repository) *service {
+	return &service{log: log, repo: repo, defaultFolderID: make(map[int64]int)}
+}
+
+func (s *service) Save(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "folder.service.Save")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "folder.service.Save"))
+
+	if _, err := s.repo.Save(ctx, &Folder{
+		UserID: event.Meta.UserID, Name: event.Text,
+	}); err != nil {
+		log.Error("failed to save folder", logger.ErrAttr(err))
+		return messages.Error
+	}
+
+	return messages.FolderCreated
+}
+
+func (s *service) RemoveByID(ctx context.Context, id int) error {
+	ctx, span := tracing.Start(ctx, "folder.service.RemoveByID")
+	defer span.End()
+
+	return s.repo.RemoveByID(ctx, id)
+}
+
+func (s *service) FindOrCreate(ctx context.Context, event *entities.Event) (int, error) {
+	ctx, span := tracing.Start(ctx, "folder.service.FindOrCreate")
+	defer span.End()
+
+	return s.FindOrCreateByName(ctx, event.Meta.UserID, event.Text)
+}
+
+// FindOrCreateByName returns the ID of the folder with the name or the alias
+// ignoring the case, the folder is created if there is none.
+func (s *service) FindOrCreateByName(ctx context.Context, userID int64, name string) (int, error) {
+	ctx, span := tracing.Start(ctx, "folder.service.FindOrCreateByName")
+	defer span.End()
+
+	f, exact, err := s.Resolve(ctx, userID, name)
+	if err != nil {
+		return 0, err
+	}
+	if f != nil && exact {
+		return f.ID, nil
+	}
+
+	return s.repo.FindOrCreate(ctx, &Folder{UserID: userID, Name: strings.TrimSpace(name)})
+}
+
+// Resolve finds the folder of the user for the typed name, see Match.
+func (s *service) Resolve(ctx context.Context, userID int64, name string) (*Folder, bool, error) {
+	ctx, span := tracing.Start(ctx, "folder.service.Resolve")
+	defer span.End()
+
+	folders, err := s.repo.All(ctx, &Folder{UserID: userID})
+	if err != nil && err != ErrNoFolders {
+		return nil, false, err
+	}
+
+	f, exact := Match(name, folders)
+	return f, exact, nil
+}
+
+func (s *service) Find(ctx context.Context, event *entities.Event) (string, error) {
+	ctx, span := tracing.Start(ctx, "folder.service.Find")
+	defer span.End()
+
+	return s.repo.Find(ctx, &Folder{ID: event.FolderID})
+}
+
+func (s *service) SaveDefault(ctx context.Context, event *entities.Event) error {
+	ctx, span := tracing.Start(ctx, "folder.service.SaveDefault")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "folder.service.Save"))
+
+	c := &Folder{UserID: event.Meta.UserID, Name: defaultName}
+	FolderID, err := s.repo.Save(ctx, c)
+	if err != nil {
+		log.Error(
+			"failed to save Folder",
+			logger.ErrAttr(err),
+		)
+		return err
+	}
+
+	s.defaultFolderID[event.Meta.UserID] = FolderID
+	return nil
+}
+
+func (s *service) DefaultFolderID(ctx context.Context, user_id int64) int {
+	ctx, span := tracing.Start(ctx, "folder.service.DefaultFolderID")
+	defer span.End()
+
+	defaultFolderID, ok := s.defaultFolderID[user_id]
+	if !ok {
+		var err error
+		defaultFolderID, err = s.repo.DefaultFolderID(ctx, user_id)
+		if err != nil {
+			return 0
+		}
+		s.defaultFolderID[user_id] = defaultFolderID
+	}
+	return defaultFolderID
+}
+
+// All returns the buttons of the folders shown in the list, the hidden
+// folders are left out.
+func (s *service) All(ctx context.Context, event *entities.Event) []entities.Button {
+	ctx, span := tracing.Start(ctx, "folder.service.All")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "Folder.service.AllAsInlineButtons"))
+
+	folders, err := s.repo.All(ctx, &Folder{UserID: event.Meta.UserID})
+	if err != nil {
+		if err == ErrNoFolders {
+			log.Info("Folders is empty", logger.ErrAttr(err))
+			return nil
+		}
+		log.Error("failed to get all Folders", logger.ErrAttr(err))
+		return nil
+	}
+
+	res := make([]entities.Button, 0, len(folders))
+	for _, f := range folders {
+		if f.Hidden {
+			continue
+		}
+		res = append(res, entities.Button{
+			Data: buttons.Prefix + strconv.Itoa(f.ID),
+			Text: f.Label(DisplayName(f, event.Meta.Language)),
+		})
+	}
+
+	return res
+}
+
+// DisplayName is the name of the folder shown to the user, the default
+// folder is named in the language of the user.
+func DisplayName(f *Folder, lang i18n.Lang) string {
+	if f.IsDefault() {
+		return i18n.T(lang, messages.FolderDefault)
+	}
+	return f.Name
+}
+
+func (s *service) List(ctx context.Context, userID int64) ([]*Folder, error) {
+	ctx, span := tracing.Start(ctx, "folder.service.List")
+	defer span.End()
+
+	return s.repo.All(ctx, &Folder{UserID: userID})
+}
+
+func (s *service) Get(ctx context.Context, userID int64, id int) (*Folder, error) {
+	ctx, span := tracing.Start(ctx, "folder.service.Get")
+	defer span.End()
+
+	return s.repo.FindByID(ctx, &Folder{ID: id, UserID: userID})
+}
+
+func (s *service) Create(ctx context.Context, userID int64, name string) (*Folder, error) {
+	ctx, span := tracing.Start(ctx, "folder.service.Create")
+	defer span.End()
+
+	name, err := validName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Folder{UserID: userID, Name: name}
+	id, err := s.repo.Save(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	f.ID = id
+
+	return f, nil
+}
+
+func (s *service) Rename(ctx context.Context, userID int64, id int, name string) error {
+	ctx, span := tracing.Start(ctx, "folder.service.Rename")
+	defer span.End()
+
+	name, err := validName(name)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Rename(ctx, &Folder{ID: id, UserID: userID, Name: name})
+}
+
+func (s *service) Remove(ctx context.Context, userID int64, id int) error {
+	ctx, span := tracing.Start(ctx, "folder.service.Remove")
+	defer span.End()
+
+	return s.repo.Remove(ctx, &Folder{ID: id, UserID: userID})
+}
+
+// SetIcon sets the emoji of the folder, "-" removes it.
+func (s *service) SetIcon(ctx context.Context, userID int64, id int, icon string) error {
+	ctx, span := tracing.Start(ctx, "folder.service.SetIcon")
+	defer span.End()
+
+	icon, err := validIcon(icon)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SetIcon(ctx, &Folder{ID: id, UserID: userID, Icon: icon})
+}
+
+// SetAliases sets the other names of the folder separated by commas, "-"
+// removes them. An alias can't be the name or the alias of another folder.
+func (s *service) SetAliases(ctx context.Context, userID int64, id int, text string) error {
+	ctx, span := tracing.Start(ctx, "folder.service.SetAliases")
+	defer span.End()
+
+	aliases, err := validAliases(text)
+	if err != nil {
+		return err
+	}
+
+	folders, err := s.repo.All(ctx, &Folder{UserID: userID})
+	if err != nil {
+		return err
+	}
+	for _, alias := range aliases {
+		if f, exact := Match(alias, folders); exact && f.ID != id {
+			return ErrAliasTaken
+		}
+	}
+
+	return s.repo.SetAliases(ctx, &Folder{ID: id, UserID: userID, Aliases: aliases})
+}
+
+// SetHidden hides the folder from the list or shows it again, the notes
+// of the hidden folder are kept.
+func (s *service) SetHidden(ctx context.Context, userID int64, id int, hidden bool) error {
+	ctx, span := tracing.Start(ctx, "folder.service.SetHidden")
+	defer span.End()
+
+	return s.repo.SetHidden(ctx, &Folder{ID: id, UserID: userID, Hidden: hidden})
+}
+
+// Move puts the folder at the position counted from 1, the positions past
+// the end of the list put it at the bottom.
+func (s *service) Move(ctx context.Context, userID int64, id int, position int) error {
+	ctx, span := tracing.Start(ctx, "folder.service.Move")
+	defer span.End()
+
+	if position < 1 {
+		return ErrInvalidPosition
+	}
+
+	folders, err := s.repo.All(ctx, &Folder{UserID: userID})
+	if err != nil {
+		return err
+	}
+
+	ids, ok := reorder(folders, id, position)
+	if !ok {
+		return ErrNoFolder
+	}
+
+	return s.repo.Reorder(ctx, userID, ids)
+}
+
+// Shift moves the folder up by a negative delta or down by a positive one.
+func (s *service) Shift(ctx context.Context, userID int64, id int, delta int) error {
+	ctx, span := tracing.Start(ctx, "folder.service.Shift")
+	defer span.End()
+
+	folders, err := s.repo.All(ctx, &Folder{UserID: userID})
+	if err != nil {
+		return err
+	}
+
+	for i, f := range folders {
+		if f.ID == id {
+			ids, _ := reorder(folders, id, i+1+delta)
+			return s.repo.Reorder(ctx, userID, ids)
+		}
+	}
+
+	return ErrNoFolder
+}
+
+// Merge moves the notes of the folder into the other one and removes the
+// folder. It returns the number of the moved notes.
+func (s *service) Merge(ctx context.Context, userID int64, id int, into int) (int, error) {
+	ctx, span := tracing.Start(ctx, "folder.service.Merge")
+	defer span.End()
+
+	if id == into {
+		return 0, ErrSameFolder
+	}
+
+	return s.repo.Merge(ctx, userID, id, into)
+}
+
+// Copy duplicates the folder with its notes under the name, the name taken
+// by another folder gets a number. It returns the copy and the number of
+// the copied notes.
+func (s *service) Copy(ctx context.Context, userID int64, id int, name string) (*Folder, int, error) {
+	ctx, span := tracing.Start(ctx, "folder.service.Copy")
+	defer span.End()
+
+	name, err := s.freeName(ctx, userID, name)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	newID, count, err := s.repo.Copy(ctx, &Folder{ID: id, UserID: userID, Name: name})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &Folder{ID: newID, UserID: userID, Name: name}, count, nil
+}
+
+// Usage returns the number of the notes of the folder and the bytes of
+// their media.
+func (s *service) Usage(ctx context.Context, userID int64, id int) (int, int64, error) {
+	ctx, span := tracing.Start(ctx, "folder.service.Usage")
+	defer span.End()
+
+	return s.repo.Usage(ctx, &Folder{ID: id, UserID: userID})
+}
+
+// Split moves the notes of the folder matching the split into the new
+// folder with the name, the name taken by another folder gets a number.
+// It returns the new folder and the number of the moved notes.
+func (s *service) Split(ctx context.Context, userID int64, id int, name string, by SplitBy) (*Folder, int, error) {
+	ctx, span := tracing.Start(ctx, "folder.service.Split")
+	defer span.End()
+
+	name, err := s.freeName(ctx, userID, name)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	newID, count, err := s.repo.Split(ctx, &Folder{ID: id, UserID: userID, Name: name}, by)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &Folder{ID: newID, UserID: userID, Name: name}, count, nil
+}
+
+func (s *service) freeName(ctx context.Context, userID int64, name string) (string, error) {
+	name, err := validName(name)
+	if err != nil {
+		return "", err
+	}
+
+	folders, err := s.repo.All(ctx, &Folder{UserID: userID})
+	if err != nil {
+		return "", err
+	}
+
+	return uniqueName(name, folders), nil
+}
+
+// uniqueName returns the name, or the name with the first free number if
+// a folder already has it. The name is cut to fit the number.
+func uniqueName(name string, folders []*Folder) string {
+	taken := make(map[string]bool, len(folders))
+	for _, f := range folders {
+		taken[f.Name] = true
+	}
+
+	res := name
+	for n := 2; taken[res]; n++ {
+		suffix := " (" + strconv.Itoa(n) + ")"
+		runes := []rune(name)
+		if keep := maxNameLength - len(suffix); len(runes) > keep {
+			runes = runes[:keep]
+		}
+		res = string(runes) + suffix
+	}
+
+	return res
+}
+
+// reorder returns the IDs of the folders with the folder moved to the
+// position, false if there is no such folder.
+func reorder(folders []*Folder, id int, position int) ([]int, bool) {
+	ids := make([]int, 0, len(folders))
+	found := false
+	for _, f := range folders {
+		if f.ID == id {
+			found = true
+			continue
+		}
+		ids = append(ids, f.ID)
+	}
+	if !found {
+		return nil, false
+	}
+
+	i := min(max(position-1, 0), len(ids))
+
+	return slices.Insert(ids, i, id), true
+}
+
+func validIcon(icon string) (string, error) {
+	icon = strings.TrimSpace(icon)
+	if icon == noIcon {
+		return "", nil
+	}
+
+	runes := []rune(icon)
+	if len(runes) == 0 || len(runes) > maxIconLength {
+		return "", ErrInvalidIcon
+	}
+	// an emoji has no letters, the keycaps have ASCII digits in them
+	isEmoji := false
+	for _, r := range runes {
+		if unicode.IsLetter(r) || unicode.IsSpace(r) {
+			return "", ErrInvalidIcon
+		}
+		if r > unicode.MaxASCII {
+			isEmoji = true
+		}
+	}
+	if !isEmoji {
+		return "", ErrInvalidIcon
+	}
+
+	return icon, nil
+}
+
+func validAliases(text string) ([]string, error) {
+	text = strings.TrimSpace(text)
+	if text == noAliases {
+		return nil, nil
+	}
+
+	aliases := []string{}
+	seen := make(map[string]bool)
+	for _, alias := range strings.Split(text, aliasDelimiter) {
+		alias = strings.Join(strings.Fields(strings.TrimLeft(alias, "! ")), " ")
+		if alias == "" || len([]rune(alias)) > maxAliasLength {
+			return nil, ErrInvalidAlias
+		}
+		if key := normalizeName(alias); !seen[key] {
+			seen[key] = true
+			aliases = append(aliases, alias)
+		}
+	}
+	if len(aliases) > maxAliases {
+		return nil, ErrInvalidAlias
+	}
+
+	return aliases, nil
+}
+
+func validName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == defaultName || len([]rune(name)) > maxNameLength {
+		return "", ErrInvalidName
+	}
+
+	return name, nil
+}
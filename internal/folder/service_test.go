@@ -0,0 +1,166 @@
+package folder
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReorder(t *testing.T) {
+	t.Parallel()
+	folders := []*Folder{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}
+
+	testCases := []struct {
+		title    string
+		id       int
+		position int
+		want     []int
+		ok       bool
+	}{
+		{"top", 3, 1, []int{3, 1, 2, 4}, true},
+		{"bottom", 1, 4, []int{2, 3, 4, 1}, true},
+		{"middle", 4, 2, []int{1, 4, 2, 3}, true},
+		{"same", 2, 2, []int{1, 2, 3, 4}, true},
+		{"below the top", 2, 0, []int{2, 1, 3, 4}, true},
+		{"past the bottom", 2, 10, []int{1, 3, 4, 2}, true},
+		{"unknown", 5, 1, nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			ids, ok := reorder(folders, tc.id, tc.position)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, ids)
+		})
+	}
+}
+
+func TestMoveInvalidPosition(t *testing.T) {
+	t.Parallel()
+	s := &service{}
+
+	for _, position := range []int{0, -1} {
+		assert.Equal(t, ErrInvalidPosition, s.Move(context.Background(), 1, 1, position))
+	}
+}
+
+func TestValidIcon(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		icon string
+		want string
+		err  error
+	}{
+		{"📚", "📚", nil},
+		{" 🏠 ", "🏠", nil},
+		{"👨‍💻", "👨‍💻", nil},
+		{"1️⃣", "1️⃣", nil},
+		{"-", "", nil},
+		{"", "", ErrInvalidIcon},
+		{"a", "", ErrInvalidIcon},
+		{"5", "", ErrInvalidIcon},
+		{"дом", "", ErrInvalidIcon},
+		{"📚 📚", "", ErrInvalidIcon},
+		{"📚📚📚📚📚📚📚📚📚", "", ErrInvalidIcon},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.icon, func(t *testing.T) {
+			icon, err := validIcon(tc.icon)
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.want, icon)
+		})
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("я", maxNameLength)
+	folders := []*Folder{{Name: "Links"}, {Name: "Links (2)"}, {Name: "Reading"}, {Name: long}}
+
+	testCases := []struct {
+		title string
+		name  string
+		want  string
+	}{
+		{"free", "Books", "Books"},
+		{"taken", "Reading", "Reading (2)"},
+		{"number taken", "Links", "Links (3)"},
+		{"cut", long, strings.Repeat("я", maxNameLength-4) + " (2)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			assert.Equal(t, tc.want, uniqueName(tc.name, folders))
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+	folders := []*Folder{
+		{ID: 1, Name: defaultName},
+		{ID: 2, Name: "Work", Aliases: []string{"job"}},
+		{ID: 3, Name: "Reading list"},
+		{ID: 4, Name: "Go"},
+	}
+
+	testCases := []struct {
+		title string
+		name  string
+		id    int
+		exact bool
+	}{
+		{"name", "Work", 2, true},
+		{"case and spaces", "  WORK ", 2, true},
+		{"alias", "Job", 2, true},
+		{"default in english", "other", 1, true},
+		{"default in russian", "прочее", 1, true},
+		{"transposition", "wrok", 2, false},
+		{"missing letter", "Readng list", 3, false},
+		{"first word", "reading", 3, false},
+		{"short", "gp", 0, false},
+		{"different", "groceries", 0, false},
+		{"empty", " ", 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			f, exact := Match(tc.name, folders)
+			id := 0
+			if f != nil {
+				id = f.ID
+			}
+			assert.Equal(t, tc.id, id)
+			assert.Equal(t, tc.exact, exact)
+		})
+	}
+}
+
+func TestValidAliases(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		text string
+		want []string
+		err  error
+	}{
+		{"w, job", []string{"w", "job"}, nil},
+		{" !w ,  my   job ", []string{"w", "my job"}, nil},
+		{"w, W", []string{"w"}, nil},
+		{"-", nil, nil},
+		{"", nil, ErrInvalidAlias},
+		{"w,,job", nil, ErrInvalidAlias},
+		{strings.Repeat("a", maxAliasLength+1), nil, ErrInvalidAlias},
+		{"a, b, c, d, e, f, g, h, i, j, k", nil, ErrInvalidAlias},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			aliases, err := validAliases(tc.text)
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.want, aliases)
+		})
+	}
+}
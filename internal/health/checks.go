@@ -0,0 +1,98 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	errNoBotAPICall   = errors.New("no successful Bot API call")
+	errIntakeInactive = errors.New("updates intake is not active")
+)
+
+// HTTPClient is the client used for the Bot API requests.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// BotAPIMonitor remembers the time of the last successful Bot API call.
+type BotAPIMonitor struct {
+	client HTTPClient
+	last   atomic.Int64
+}
+
+func NewBotAPIMonitor(client HTTPClient) *BotAPIMonitor {
+	return &BotAPIMonitor{client: client}
+}
+
+// Do sends the request. Any response except 5xx means the API is reachable.
+func (m *BotAPIMonitor) Do(req *http.Request) (*http.Response, error) {
+	resp, err := m.client.Do(req)
+	if err == nil && resp.StatusCode < http.StatusInternalServerError {
+		m.last.Store(time.Now().UnixNano())
+	}
+
+	return resp, err
+}
+
+// LastSuccess returns the time of the last successful call.
+func (m *BotAPIMonitor) LastSuccess() time.Time {
+	last := m.last.Load()
+	if last == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, last)
+}
+
+// Check passes if the last successful call is newer than maxAge. Otherwise
+// the probe is called, it is expected to make a cheap call such as getMe.
+func (m *BotAPIMonitor) Check(maxAge time.Duration, probe Check) Check {
+	return func(ctx context.Context) error {
+		if time.Since(m.LastSuccess()) < maxAge {
+			return nil
+		}
+		if err := probe(ctx); err != nil {
+			return err
+		}
+		if time.Since(m.LastSuccess()) >= maxAge {
+			return errNoBotAPICall
+		}
+
+		return nil
+	}
+}
+
+// Intake tracks whether updates are received by polling or webhook.
+type Intake struct {
+	mu     sync.RWMutex
+	mode   string
+	active bool
+}
+
+func (i *Intake) Set(mode string, active bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.mode = mode
+	i.active = active
+}
+
+// Check passes while the intake is active.
+func (i *Intake) Check(ctx context.Context) error {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if !i.active {
+		if i.mode == "" {
+			return errIntakeInactive
+		}
+		return errors.New(i.mode + " is not active")
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	LivenessPath  string = "/healthz"
+	ReadinessPath string = "/readyz"
+
+	statusOK   string = "ok"
+	statusFail string = "fail"
+
+	checkTimeout time.Duration = 3 * time.Second
+)
+
+// Check returns an error if the dependency is not available.
+type Check func(ctx context.Context) error
+
+type checkResult struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type response struct {
+	Status string                 `json:"status"`
+	Uptime string                 `json:"uptime"`
+	Checks map[string]checkResult `json:"checks,omitempty"`
+}
+
+type health struct {
+	started time.Time
+
+	mu     sync.RWMutex
+	names  []string
+	checks map[string]Check
+}
+
+func New() *health {
+	return &health{started: time.Now(), checks: make(map[string]Check)}
+}
+
+// Add registers the check used by the readiness probe.
+func (h *health) Add(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.checks[name]; !ok {
+		h.names = append(h.names, name)
+	}
+	h.checks[name] = check
+}
+
+// Liveness reports that the process is running.
+func (h *health) Liveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, response{Status: statusOK, Uptime: h.uptime()})
+}
+
+// Readiness runs all checks concurrently and fails if any of them fails.
+func (h *health) Readiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+	defer cancel()
+
+	h.mu.RLock()
+	checks := make(map[string]Check, len(h.checks))
+	for name, check := range h.checks {
+		checks[name] = check
+	}
+	h.mu.RUnlock()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]checkResult, len(checks))
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := run(ctx, check)
+
+			mu.Lock()
+			results[name] = res
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	res := response{Status: statusOK, Uptime: h.uptime(), Checks: results}
+	status := http.StatusOK
+	for _, result := range results {
+		if result.Status != statusOK {
+			res.Status = statusFail
+			status = http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	writeJSON(w, status, res)
+}
+
+func (h *health) uptime() string {
+	return time.Since(h.started).Round(time.Second).String()
+}
+
+func run(ctx context.Context, check Check) checkResult {
+	start := time.Now()
+	err := check(ctx)
+
+	res := checkResult{Status: statusOK, LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		res.Status = statusFail
+		res.Error = err.Error()
+	}
+
+	return res
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,76 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		checks     map[string]Check
+		wantCode   int
+		wantStatus string
+	}{
+		{
+			name:       "no checks",
+			wantCode:   http.StatusOK,
+			wantStatus: statusOK,
+		},
+		{
+			name: "all passed",
+			checks: map[string]Check{
+				"postgres": func(context.Context) error { return nil },
+				"redis":    func(context.Context) error { return nil },
+			},
+			wantCode:   http.StatusOK,
+			wantStatus: statusOK,
+		},
+		{
+			name: "one failed",
+			checks: map[string]Check{
+				"postgres": func(context.Context) error { return nil },
+				"redis":    func(context.Context) error { return errors.New("connection refused") },
+			},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: statusFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New()
+			for name, check := range tt.checks {
+				h.Add(name, check)
+			}
+
+			rec := httptest.NewRecorder()
+			h.Readiness(rec, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+
+			var res response
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&res))
+			assert.Equal(t, tt.wantStatus, res.Status)
+			assert.Len(t, res.Checks, len(tt.checks))
+		})
+	}
+}
+
+func TestIntakeCheck(t *testing.T) {
+	i := &Intake{}
+	assert.Error(t, i.Check(context.Background()))
+
+	i.Set("polling", true)
+	assert.NoError(t, i.Check(context.Background()))
+
+	i.Set("polling", false)
+	assert.EqualError(t, i.Check(context.Background()), "polling is not active")
+}
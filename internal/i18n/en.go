@@ -0,0 +1,234 @@
+package i18n
+
+var en = catalog{
+	texts: map[string]string{
+		"language_name": "🇬🇧 English",
+
+		"start": `Hi! I'm your personal assistant for keeping notes and links in cozy folders.😬 
+With me you can:
+
+• Organize your ideas and interesting finds into folders  📁
+• Quickly find your saved notes and links right when you need them 🔍
+
+To start, just send me a message with a note or a link and I'll save it for you. To see your folders or create a new one, press the 📂📂📂 button.
+
+Send /info to see the guide`,
+		"folders_caption": "📌 My folders",
+		"unknown_command": "Not sure what you mean. What do you mean? 🤔",
+		"error":           "Oops! Please don't do that... 😵",
+		"notes_empty":     "Nothing here 🕵🏼",
+		"note_created":    "Note saved ✏️",
+		"note_removed":    "Note deleted 🧹",
+		"source":          "Source: ",
+
+		"info_1": "1. To add a note, write or send anything to the bot.\nTo clear everything but the main menu, press 📂📂📂 or send /folders",
+		"info_2": "2. Press the left button of the main menu to add a folder. Press the right one to delete a folder. The middle one ⚙️ renames the folders, sets their icons and order, hides the ones you don't need, copies, merges and splits them.",
+		"info_3": "3. Anything you send while a folder is open is saved into that folder, unless one of your /rules files it elsewhere",
+		"info_4": "4. The left button under a note moves it to another folder (press it, then choose the folder).\nThe right button deletes the note.\nThe ☆ button adds the note to the favorites, they are shown first in the folder and in /favorites.\nThe ☑️ button of the folder selects several notes to move, tag, export or delete them at once",
+		"info_5": "5. When you share something with the bot,\nadd an exclamation mark and a folder name (!name) to the message to save it into that folder. The name can end the note itself: «text !name». Case doesn't matter, the aliases of the folder work too, and if the name looks mistyped I ask which folder you meant. A folder that doesn't exist is created, only for the name at the end of the note I ask first.",
+
+		"folder_default":          "Other",
+		"ask_folder_name":         "Folder name?",
+		"folders_empty":           "No folders yet 🕵🏼",
+		"folder_created":          "Here is your folder ✏️",
+		"folder_not_exists":       "There is no such folder",
+		"folder_deleted":          "Folder deleted",
+		"wrong_folder":            "This folder can't be deleted",
+		"choose_folder_to_move":   "Choose the folder to move the note to",
+		"choose_folder_to_delete": "Choose the folder to delete",
+
+		"folder_settings":         "⚙️ Choose the folder to set up",
+		"folder_is_hidden":        "🙈 Hidden from the list",
+		"folder_locked":           "The main folder can't be renamed, hidden or merged",
+		"ask_folder_icon":         "Send an emoji for the folder icon or - to remove it",
+		"ask_folder_position":     "Send the new place of the folder in the list, 1 is the top",
+		"folder_renamed":          "Folder renamed ✏️",
+		"folder_icon_set":         "Icon updated",
+		"folder_moved":            "Folder moved",
+		"folder_hidden":           "Folder hidden, its notes are kept 🙈",
+		"folder_shown":            "Folder is back in the list",
+		"folder_name_invalid":     "This name doesn't fit. Send up to 100 characters",
+		"folder_name_taken":       "You already have a folder with this name",
+		"folder_icon_invalid":     "That's not an emoji. Send one emoji or - to remove the icon",
+		"folder_position_invalid": "Send a number, 1 is the top of the list",
+
+		"folder_copy_name":          "%s (copy)",
+		"folder_split_before":       "%s before %s",
+		"folder_merge_choose":       "Choose the folder to merge «%s» into",
+		"folder_merge_ask":          "Move all the notes of «%[1]s» into «%[2]s» and delete «%[1]s»?",
+		"folder_split_choose":       "Which notes of «%s» move into a new folder? Choose a type or a date",
+		"ask_split_date":            "Send a date as YYYY-MM-DD, the notes saved before it move into a new folder",
+		"folder_split_empty":        "No notes match, nothing is moved",
+		"folder_split_date_invalid": "Send the date as YYYY-MM-DD, for example 2024-01-31",
+		"ask_folder_aliases":        "Send the other names of the folder separated by commas, for example: w, job. The folder is found by them after ! too. Send - to remove them",
+		"folder_aliases":            "Aliases: %s",
+		"folder_aliases_set":        "Aliases saved ✅",
+		"folder_alias_invalid":      "Up to 10 aliases of 32 characters, separated by commas",
+		"folder_alias_taken":        "Another folder already has this name or alias",
+		"folder_suggested":          "There is no folder «%s». Did you mean «%s»?",
+		"folder_suggestion_expired": "This question is outdated, name the folder again",
+		"folder_create_named":       "Create «%s»",
+		"folder_create_ask":         "There is no folder «%s». Create it?",
+
+		"channel_link_usage":    "Make the bot an admin of the channel and send /link_channel @channel [folder]",
+		"channel_not_found":     "Couldn't find this channel 🕵🏼",
+		"channel_not_admin":     "Only an admin of the channel can link it",
+		"channel_bot_not_admin": "Make the bot an admin of the channel first",
+		"channel_linked":        "Channel linked ✅ New posts will be saved into the folder",
+		"channel_unlinked":      "Channel unlinked",
+
+		"token_issued":    "Your API token. It is shown only once, keep it safe 🔑",
+		"tokens_empty":    "No active tokens",
+		"token_revoked":   "Token revoked",
+		"token_not_found": "There is no such token",
+		"tokens_limit":    "You can't have more tokens. Revoke the tokens you don't need with /token revoke",
+		"token_usage":     "/token — new token\n/token list — active tokens\n/token revoke <id|all> — revoke a token",
+
+		"quota_notes":   "Your archive is full, new notes aren't saved. Delete the notes you don't need 🧹",
+		"quota_folders": "You can't create more folders. Delete the folders you don't need 🧹",
+		"quota_daily":   "That's enough notes for today, let's continue tomorrow ⏳",
+		"quota_media":   "There is no room for files left, this one isn't saved. Delete the files you don't need 🧹",
+		"throttled":     "Too many messages, I can't keep up 🐢 Try again in %d s",
+
+		"favorites_caption": "⭐ Favorites",
+		"favorites_empty":   "No favorites yet. Press ☆ under a note to add it ⭐",
+		"favorite_added":    "Added to favorites ⭐",
+		"favorite_removed":  "Removed from favorites",
+
+		"selection_empty":         "Nothing is selected",
+		"selection_expired":       "The selection is closed, open the folder again",
+		"selection_choose_folder": "Choose the folder to move the selected notes to",
+		"ask_tags":                "Send the tags separated by spaces, for example #work #todo",
+		"tags_invalid":            "Tags are words of letters, digits, _ and -. Up to 10 tags of 32 characters",
+
+		"language_choose":      "Choose your language 🌐",
+		"language_set":         "I speak English now 🇬🇧",
+		"language_unsupported": "I don't know this language. Available: ru, en",
+
+		"rules_usage":           "/rules — your rules\n/rules add <conditions> -> <actions> — new rule\n/rules del <n> — delete a rule\n/rules move <n> <place> — change the order\n/rules test [n] — try the rules on the latest notes\n\nConditions: domain:github.com regex:<expression> type:photo source:@channel hashtag:#go\nActions: folder:<name> tag:<tag>\nA note must match all the conditions of a rule. The rules are checked in order and the first matching one files the note. A folder named with ! wins over the rules.\n\nExample: /rules add domain:github.com -> folder:Code tag:dev",
+		"rules_empty":           "No rules yet 🕵🏼",
+		"rules_limit":           "You can't add more rules. Delete the rules you don't need 🧹",
+		"rule_added":            "Rule %d added ✅",
+		"rule_removed":          "Rule deleted",
+		"rule_moved":            "Rule moved",
+		"rule_not_found":        "There is no such rule",
+		"rule_syntax":           "I can't read the rule. Example: /rules add domain:github.com -> folder:Code tag:dev",
+		"rule_type_invalid":     "Types: message, photo, doc, video, audio, animation, voice",
+		"rule_regex_invalid":    "The regular expression is invalid or longer than 256 characters",
+		"rule_no_action":        "Add folder:<name> or tag:<tag> after ->",
+		"rule_folder_not_found": "There is no folder «%s»",
+		"rule_filed":            "Note saved into «%s» by rule %d ✏️",
+		"rule_tagged":           "Note saved and tagged %s by rule %d ✏️",
+		"rule_preview":          "matches: %d, moves: %d",
+
+		"transcribe_on":          "🎙 Transcription is on: new voice and audio notes get a text version, it is shown under the note and found by the search. /transcribe off turns it off",
+		"transcribe_off":         "Transcription is off. /transcribe on turns it on",
+		"transcribe_unavailable": "Transcription is not available on this bot",
+		"transcribe_usage":       "/transcribe — is transcription on\n/transcribe on — transcribe new voice and audio notes\n/transcribe off — stop transcribing",
+
+		"admin_panel":            "Welcome to admin panel!",
+		"admin_not_allowed":      "Your role doesn't allow this",
+		"admin_error":            "Something went wrong, see the logs",
+		"admin_users_count":      "The quantity of users is %d",
+		"admin_cancel":           "Cancel",
+		"admin_button_count":     "Count of users",
+		"admin_button_users":     "Users",
+		"admin_button_stats":     "Statistics",
+		"admin_button_broadcast": "Broadcast",
+		"admin_button_admins":    "Admins",
+
+		"users_usage":    "Users:\n/adm_user <id|@username> - show the user\n/adm_ban <id> [reason] - ban the user\n/adm_unban <id> - unban the user\n/adm_quota <id> [day=N] [notes=N] [folders=N] [media=MB] - override the limits, 0 is no limit, the omitted ones are the defaults\n/adm_quota <id> default - drop the overrides\n/adm_wipe <id> - delete the notes and folders of the user",
+		"user_not_found": "User not found",
+		"user_outranked": "You can't act on an admin of your role or a higher one",
+		"user_banned":    "User %d is banned",
+		"user_unbanned":  "User %d is unbanned",
+		"user_quota_set": "Limits of user %d are updated",
+		"user_wipe_ask":  "Delete all the notes and folders of user %d? It can't be undone.",
+		"user_wiped":     "Data of user %d is wiped",
+		"user_card":      "User %d%s\nNotes: %d, today %d\nFolders: %d\nMedia: %d MB\nCreated: %s\nLast seen: %s\nLimits: %s",
+		"user_card_ban":  "Banned: %s",
+		"user_ban":       "Ban",
+		"user_unban":     "Unban",
+		"user_wipe":      "Wipe",
+
+		"roles_usage":       "Roles: support < moderator < superadmin\n/adm_grant <id> <role> - grant the role to the user\n/adm_revoke <id> - revoke the role of the user\n/adm_admins - show the admins",
+		"role_granted":      "User %d is %s now",
+		"role_revoked":      "User %d is not an admin now",
+		"role_own":          "You can't change your own role",
+		"role_bootstrap":    "User %d is set in the config, change the admin_ids there",
+		"role_no_admin":     "User %d is not an admin",
+		"admins_list":       "Admins:",
+		"admin_from_config": "(config)",
+		"admin_granted_by":  "granted by %d on %s",
+
+		"broadcast_compose":   "Send the message to broadcast, any media is fine. You will see a preview before it is sent.",
+		"broadcast_aborted":   "Broadcast cancelled",
+		"broadcast_send_all":  "Send to all",
+		"broadcast_not_draft": "Broadcast #%d is already started or cancelled",
+		"broadcast_started":   "Broadcast #%d started, the report will come when it is over",
+		"broadcast_cancelled": "Broadcast #%d cancelled",
+		"broadcast_over":      "Broadcast #%d is already over",
+		"broadcast_finished":  "Broadcast #%d finished",
+		"broadcast_report":    "%s\nDelivered: %d\nFailed: %d\nBlocked: %d",
+
+		"stats_activity": "Active users",
+		"stats_signups":  "New users",
+		"stats_notes":    "Notes by type",
+		"stats_per_user": "Per user",
+		"stats_storage":  "Storage",
+		"stats_largest":  "Largest archives",
+		"stats_folders":  "Top folders",
+		"stats_errors":   "Errors since start",
+		"stats_csv":      "CSV",
+	},
+	plurals: map[string]Plural{
+		"notes_selected": {
+			One:   "☑️ %d note selected",
+			Other: "☑️ %d notes selected",
+		},
+		"selection_remove_ask": {
+			One:   "Delete %d note? It can't be undone",
+			Other: "Delete %d notes? It can't be undone",
+		},
+		"notes_moved": {
+			One:   "📤 %d note moved",
+			Other: "📤 %d notes moved",
+		},
+		"notes_removed": {
+			One:   "🗑️ %d note deleted",
+			Other: "🗑️ %d notes deleted",
+		},
+		"notes_tagged": {
+			One:   "🏷 %d note tagged %s",
+			Other: "🏷 %d notes tagged %s",
+		},
+		"notes_exported": {
+			One:   "📦 %d note exported",
+			Other: "📦 %d notes exported",
+		},
+		"folder_copied": {
+			One:   "📑 The copy with %d note is created",
+			Other: "📑 The copy with %d notes is created",
+		},
+		"folder_merged": {
+			One:   "🔀 %d note moved, the folders are merged",
+			Other: "🔀 %d notes moved, the folders are merged",
+		},
+		"folder_split_done": {
+			One:   "✂️ %d note moved into the new folder",
+			Other: "✂️ %d notes moved into the new folder",
+		},
+		"rules_list": {
+			One:   "%d rule, checked in this order:",
+			Other: "%d rules, checked in this order:",
+		},
+		"rules_dry_run": {
+			One:   "🔍 The rules tried on the last %d note, nothing is changed:",
+			Other: "🔍 The rules tried on the last %d notes, nothing is changed:",
+		},
+		"token_list": {
+			One:   "%d active token (created / last used):",
+			Other: "%d active tokens (created / last used):",
+		},
+	},
+}
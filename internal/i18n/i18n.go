@@ -0,0 +1,134 @@
+// Package i18n renders the bot texts in the language of the user.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Lang string
+
+const (
+	RU Lang = "ru"
+	EN Lang = "en"
+
+	// Default is used for the users without a language code and for
+	// the keys missing in the user's catalog.
+	Default Lang = RU
+)
+
+// Plural holds the forms of a counted message, the forms take the count
+// as the first argument. English uses One and Other, Russian One, Few and Many.
+type Plural struct {
+	One   string
+	Few   string
+	Many  string
+	Other string
+}
+
+type catalog struct {
+	texts   map[string]string
+	plurals map[string]Plural
+}
+
+var catalogs = map[Lang]catalog{
+	RU: ru,
+	EN: en,
+}
+
+// Supported returns the languages with a catalog in the menu order.
+func Supported() []Lang {
+	return []Lang{RU, EN}
+}
+
+// Parse returns the supported language for the Telegram language code
+// such as "en" or "ru-RU", ok is false when it is not supported.
+func Parse(code string) (Lang, bool) {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(code)), "-")
+	if _, ok := catalogs[Lang(base)]; ok {
+		return Lang(base), true
+	}
+	return Default, false
+}
+
+// FromCode returns the language for the Telegram language code. Users of
+// unsupported languages get English, users without a code get Default.
+func FromCode(code string) Lang {
+	if code == "" {
+		return Default
+	}
+	if lang, ok := Parse(code); ok {
+		return lang
+	}
+	return EN
+}
+
+// Name returns the name of the language in itself.
+func (l Lang) Name() string {
+	return T(l, "language_name")
+}
+
+// T renders the message. The text of a key missing in every catalog is the
+// key itself, so language neutral texts such as emoji pass through.
+func T(lang Lang, key string, args ...any) string {
+	text, ok := lookup(lang, key)
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// N renders the plural form of the message for the count n.
+func N(lang Lang, key string, n int, args ...any) string {
+	p, ok := catalogs[lang].plurals[key]
+	if !ok {
+		lang = Default
+		if p, ok = catalogs[Default].plurals[key]; !ok {
+			return key
+		}
+	}
+
+	return fmt.Sprintf(p.form(lang, n), append([]any{n}, args...)...)
+}
+
+func lookup(lang Lang, key string) (string, bool) {
+	if text, ok := catalogs[lang].texts[key]; ok {
+		return text, true
+	}
+	text, ok := catalogs[Default].texts[key]
+	return text, ok
+}
+
+func (p Plural) form(lang Lang, n int) string {
+	var form string
+	switch lang {
+	case RU:
+		form = p.russian(n)
+	default:
+		if n == 1 {
+			form = p.One
+		}
+	}
+
+	if form == "" {
+		return p.Other
+	}
+	return form
+}
+
+func (p Plural) russian(n int) string {
+	if n < 0 {
+		n = -n
+	}
+	switch mod10, mod100 := n%10, n%100; {
+	case mod10 == 1 && mod100 != 11:
+		return p.One
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return p.Few
+	default:
+		return p.Many
+	}
+}
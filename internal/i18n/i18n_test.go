@@ -0,0 +1,86 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	t.Parallel()
+	for lang, c := range catalogs {
+		for other, oc := range catalogs {
+			for key, text := range c.texts {
+				otherText, ok := oc.texts[key]
+				if assert.True(t, ok, "%s misses %q of %s", other, key, lang) {
+					assert.Equal(t, strings.Count(text, "%"), strings.Count(otherText, "%"), key)
+				}
+			}
+			for key := range c.plurals {
+				assert.Contains(t, oc.plurals, key, "%s misses plural %q of %s", other, key, lang)
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		code string
+		want Lang
+		ok   bool
+	}{
+		{"ru", RU, true},
+		{"en", EN, true},
+		{"en-US", EN, true},
+		{" RU ", RU, true},
+		{"de", Default, false},
+		{"", Default, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.code, func(t *testing.T) {
+			lang, ok := Parse(tc.code)
+			assert.Equal(t, tc.want, lang)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+
+	assert.Equal(t, EN, FromCode("de"))
+	assert.Equal(t, Default, FromCode(""))
+}
+
+func TestT(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "Folder deleted", T(EN, "folder_deleted"))
+	assert.Equal(t, "Папка удалена", T(RU, "folder_deleted"))
+	assert.Equal(t, "Папка удалена", T("de", "folder_deleted"))
+	assert.Equal(t, "📁", T(EN, "📁"))
+}
+
+func TestN(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		lang Lang
+		n    int
+		want string
+	}{
+		{EN, 1, "1 active token"},
+		{EN, 2, "2 active tokens"},
+		{EN, 0, "0 active tokens"},
+		{RU, 1, "1 активный токен"},
+		{RU, 21, "21 активный токен"},
+		{RU, 3, "3 активных токена"},
+		{RU, 11, "11 активных токенов"},
+		{RU, 14, "14 активных токенов"},
+		{RU, 25, "25 активных токенов"},
+		{RU, 102, "102 активных токена"},
+	}
+
+	for _, tc := range testCases {
+		got := N(tc.lang, "token_list", tc.n)
+		assert.True(t, strings.HasPrefix(got, tc.want), "%s %d: %s", tc.lang, tc.n, got)
+	}
+	assert.Equal(t, "missing", N(EN, "missing", 1))
+}
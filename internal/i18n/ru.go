@@ -0,0 +1,246 @@
+package i18n
+
+var ru = catalog{
+	texts: map[string]string{
+		"language_name": "🇷🇺 Русский",
+
+		"start": `Привет! Я – твой личный помощник, который помогает создавать уютные папочки для заметок и ссылок.😬 
+Со мной ты можешь:
+
+• Организовывать свои идеи и интересные материалы в папки  📁
+• Быстро находить сохранённые заметки и ссылки именно тогда, когда они понадобятся 🔍
+
+Чтобы начать – просто отправь мне сообщение с заметкой или ссылкой – и я сразу её сохраню для тебя. Если хочешь увидеть свои папки или создать новую - нажми кнопку 📂📂📂.
+
+Посмотреть инструкцию можно, набрав команду /info`,
+		"folders_caption": "📌 Мои папки",
+		"unknown_command": "Не знаю о чем ты. О чем ты? 🤔",
+		"error":           "Ой! Не делай так... 😵",
+		"notes_empty":     "Ничего нет 🕵🏼",
+		"note_created":    "Запись добавлена ✏️",
+		"note_removed":    "Запись удалена 🧹",
+		"source":          "Источник: ",
+
+		"info_1": "1. Чтобы добавить новую заметку, нужно написать или прислать что-то в бота.\nЧтобы стереть все кроме главного меню нажмите на 📂📂📂 или введите /folders",
+		"info_2": "2. Добавить новую папку можно, нажав на левую кнопку главного меню. Чтобы удалить папку, нужно нажать на правую кнопку. Средняя кнопка ⚙️ переименовывает папки, меняет их значки и порядок, скрывает ненужные, копирует, объединяет и разделяет их.",
+		"info_3": "3. Если написать или добавить что-то в бота, находясь в папке, новая запись сохранится в эту папку, если ее не разложит одно из твоих правил /rules",
+		"info_4": "4. Левая кнопка под записью перемещает ее в нужную папку (после нажатия этой кнопки нужно выбрать папку, в которую необходимо переместить запись).\nПравая кнопка удаляет запись.\nКнопка ☆ добавляет запись в избранное, оно показывается первым в папке и в /favorites.\nКнопка ☑️ у папки выбирает несколько записей, чтобы переместить, пометить тегами, выгрузить или удалить их разом",
+		"info_5": "5. При добавлении записей через опцию 'Поделиться',\nесли в сообщении написать восклицательный знак и название папки (!название), то запись будеть добавлена в эту папку. Название можно дописать в конце самой записи: «текст !название». Регистр не важен, другие названия папки тоже подходят, а если название похоже на опечатку, я спрошу, какая папка нужна. Если этой папки не существует, она создастся автоматически, а для названия в конце записи я сначала спрошу.",
+
+		"folder_default":          "Прочее",
+		"ask_folder_name":         "Название папки?",
+		"folders_empty":           "Нету папочек 🕵🏼",
+		"folder_created":          "А вот и папочка ✏️",
+		"folder_not_exists":       "Нет такой папки",
+		"folder_deleted":          "Папка удалена",
+		"wrong_folder":            "Эту папку нельзя удалить",
+		"choose_folder_to_move":   "Выбери папку, в которую хочешь переместить заметку",
+		"choose_folder_to_delete": "Выбери папку, которую хочешь удалить",
+
+		"folder_settings":         "⚙️ Выбери папку, которую хочешь настроить",
+		"folder_is_hidden":        "🙈 Скрыта из списка",
+		"folder_locked":           "Основную папку нельзя переименовать, скрыть или объединить",
+		"ask_folder_icon":         "Пришли эмодзи для значка папки или -, чтобы убрать его",
+		"ask_folder_position":     "Пришли новое место папки в списке, 1 — самое верхнее",
+		"folder_renamed":          "Папка переименована ✏️",
+		"folder_icon_set":         "Значок обновлен",
+		"folder_moved":            "Папка перемещена",
+		"folder_hidden":           "Папка скрыта, записи в ней сохранены 🙈",
+		"folder_shown":            "Папка снова в списке",
+		"folder_name_invalid":     "Такое название не подходит. Пришли до 100 символов",
+		"folder_name_taken":       "У тебя уже есть папка с таким названием",
+		"folder_icon_invalid":     "Это не эмодзи. Пришли один эмодзи или -, чтобы убрать значок",
+		"folder_position_invalid": "Пришли число, 1 — самое верхнее место в списке",
+
+		"folder_copy_name":          "%s (копия)",
+		"folder_split_before":       "%s до %s",
+		"folder_merge_choose":       "Выбери папку, с которой объединить «%s»",
+		"folder_merge_ask":          "Перенести все записи из «%[1]s» в «%[2]s» и удалить «%[1]s»?",
+		"folder_split_choose":       "Какие записи из «%s» перенести в новую папку? Выбери тип или дату",
+		"ask_split_date":            "Пришли дату в виде ГГГГ-ММ-ДД, записи, сохраненные до нее, перенесутся в новую папку",
+		"folder_split_empty":        "Подходящих записей нет, ничего не перенесено",
+		"folder_split_date_invalid": "Пришли дату в виде ГГГГ-ММ-ДД, например 2024-01-31",
+		"ask_folder_aliases":        "Пришли другие названия папки через запятую, например: р, работа. По ним папку тоже можно найти после !. Пришли -, чтобы убрать их",
+		"folder_aliases":            "Другие названия: %s",
+		"folder_aliases_set":        "Названия сохранены ✅",
+		"folder_alias_invalid":      "Не больше 10 названий по 32 символа через запятую",
+		"folder_alias_taken":        "Так уже называется другая папка",
+		"folder_suggested":          "Папки «%s» нет. Может, «%s»?",
+		"folder_suggestion_expired": "Этот вопрос устарел, назови папку еще раз",
+		"folder_create_named":       "Создать «%s»",
+		"folder_create_ask":         "Папки «%s» нет. Создать ее?",
+
+		"channel_link_usage":    "Добавь бота администратором канала и отправь /link_channel @канал [папка]",
+		"channel_not_found":     "Не нашел такой канал 🕵🏼",
+		"channel_not_admin":     "Подключить канал может только его администратор",
+		"channel_bot_not_admin": "Сначала добавь бота администратором канала",
+		"channel_linked":        "Канал подключен ✅ Новые посты будут сохраняться в папку",
+		"channel_unlinked":      "Канал отключен",
+
+		"token_issued":    "Твой токен для API. Он показывается только один раз, сохрани его 🔑",
+		"tokens_empty":    "Нет активных токенов",
+		"token_revoked":   "Токен отозван",
+		"token_not_found": "Нет такого токена",
+		"tokens_limit":    "Больше токенов создать нельзя. Отзови ненужные через /token revoke",
+		"token_usage":     "/token — новый токен\n/token list — активные токены\n/token revoke <id|all> — отозвать токен",
+
+		"quota_notes":   "Архив заполнен, новые записи не сохраняются. Удали ненужные записи 🧹",
+		"quota_folders": "Больше папок создать нельзя. Удали ненужные папки 🧹",
+		"quota_daily":   "На сегодня хватит записей, продолжим завтра ⏳",
+		"quota_media":   "Место для файлов закончилось, этот файл не сохранён. Удали ненужные файлы 🧹",
+		"throttled":     "Слишком много сообщений, я не успеваю 🐢 Попробуй через %d с",
+
+		"favorites_caption": "⭐ Избранное",
+		"favorites_empty":   "В избранном пусто. Нажми ☆ под записью, чтобы добавить ее ⭐",
+		"favorite_added":    "Добавлено в избранное ⭐",
+		"favorite_removed":  "Убрано из избранного",
+
+		"selection_empty":         "Ничего не выбрано",
+		"selection_expired":       "Выбор закрыт, открой папку снова",
+		"selection_choose_folder": "Выбери папку, в которую переместить выбранные записи",
+		"ask_tags":                "Пришли теги через пробел, например #работа #сделать",
+		"tags_invalid":            "Тег — это слово из букв, цифр, _ и -. Не больше 10 тегов по 32 символа",
+
+		"language_choose":      "Выбери язык 🌐",
+		"language_set":         "Теперь я говорю по-русски 🇷🇺",
+		"language_unsupported": "Такого языка я не знаю. Доступны: ru, en",
+
+		"rules_usage":           "/rules — твои правила\n/rules add <условия> -> <действия> — новое правило\n/rules del <n> — удалить правило\n/rules move <n> <место> — изменить порядок\n/rules test [n] — проверить правила на последних записях\n\nУсловия: domain:github.com regex:<выражение> type:photo source:@канал hashtag:#go\nДействия: folder:<папка> tag:<тег>\nЗапись должна подходить под все условия правила. Правила проверяются по порядку, запись раскладывает первое подходящее. Папка, указанная через !, важнее правил.\n\nПример: /rules add domain:github.com -> folder:Код tag:dev",
+		"rules_empty":           "Правил пока нет 🕵🏼",
+		"rules_limit":           "Больше правил добавить нельзя. Удали ненужные правила 🧹",
+		"rule_added":            "Правило %d добавлено ✅",
+		"rule_removed":          "Правило удалено",
+		"rule_moved":            "Правило перемещено",
+		"rule_not_found":        "Нет такого правила",
+		"rule_syntax":           "Не понял правило. Пример: /rules add domain:github.com -> folder:Код tag:dev",
+		"rule_type_invalid":     "Типы: message, photo, doc, video, audio, animation, voice",
+		"rule_regex_invalid":    "Регулярное выражение неверное или длиннее 256 символов",
+		"rule_no_action":        "Добавь folder:<папка> или tag:<тег> после ->",
+		"rule_folder_not_found": "Нет папки «%s»",
+		"rule_filed":            "Запись сохранена в «%s» по правилу %d ✏️",
+		"rule_tagged":           "Запись сохранена и отмечена %s по правилу %d ✏️",
+		"rule_preview":          "подходит: %d, переедет: %d",
+
+		"transcribe_on":          "🎙 Расшифровка включена: у новых голосовых и аудио появится текст, он показывается под записью и находится поиском. /transcribe off выключает ее",
+		"transcribe_off":         "Расшифровка выключена. /transcribe on включает ее",
+		"transcribe_unavailable": "Расшифровка в этом боте недоступна",
+		"transcribe_usage":       "/transcribe — включена ли расшифровка\n/transcribe on — расшифровывать новые голосовые и аудио\n/transcribe off — не расшифровывать",
+
+		"admin_panel":            "Добро пожаловать в админку!",
+		"admin_not_allowed":      "Твоя роль этого не позволяет",
+		"admin_error":            "Что-то пошло не так, смотри логи",
+		"admin_users_count":      "Пользователей: %d",
+		"admin_cancel":           "Отменить",
+		"admin_button_count":     "Число пользователей",
+		"admin_button_users":     "Пользователи",
+		"admin_button_stats":     "Статистика",
+		"admin_button_broadcast": "Рассылка",
+		"admin_button_admins":    "Админы",
+
+		"users_usage":    "Пользователи:\n/adm_user <id|@username> - показать пользователя\n/adm_ban <id> [причина] - заблокировать пользователя\n/adm_unban <id> - разблокировать пользователя\n/adm_quota <id> [day=N] [notes=N] [folders=N] [media=MB] - задать лимиты, 0 - без лимита, пропущенные берутся по умолчанию\n/adm_quota <id> default - сбросить лимиты\n/adm_wipe <id> - удалить заметки и папки пользователя",
+		"user_not_found": "Пользователь не найден",
+		"user_outranked": "Нельзя менять админа с твоей ролью или выше",
+		"user_banned":    "Пользователь %d заблокирован",
+		"user_unbanned":  "Пользователь %d разблокирован",
+		"user_quota_set": "Лимиты пользователя %d обновлены",
+		"user_wipe_ask":  "Удалить все заметки и папки пользователя %d? Это нельзя отменить.",
+		"user_wiped":     "Данные пользователя %d удалены",
+		"user_card":      "Пользователь %d%s\nЗаметки: %d, сегодня %d\nПапки: %d\nФайлы: %d МБ\nСоздан: %s\nПоследний раз: %s\nЛимиты: %s",
+		"user_card_ban":  "Заблокирован: %s",
+		"user_ban":       "Заблокировать",
+		"user_unban":     "Разблокировать",
+		"user_wipe":      "Удалить данные",
+
+		"roles_usage":       "Роли: support < moderator < superadmin\n/adm_grant <id> <роль> - выдать роль пользователю\n/adm_revoke <id> - снять роль с пользователя\n/adm_admins - показать админов",
+		"role_granted":      "Пользователь %d теперь %s",
+		"role_revoked":      "Пользователь %d больше не админ",
+		"role_own":          "Свою роль менять нельзя",
+		"role_bootstrap":    "Пользователь %d задан в конфиге, поменяй admin_ids там",
+		"role_no_admin":     "Пользователь %d не админ",
+		"admins_list":       "Админы:",
+		"admin_from_config": "(конфиг)",
+		"admin_granted_by":  "выдал %d %s",
+
+		"broadcast_compose":   "Пришли сообщение для рассылки, можно с любыми файлами. Перед отправкой будет превью.",
+		"broadcast_aborted":   "Рассылка отменена",
+		"broadcast_send_all":  "Отправить всем",
+		"broadcast_not_draft": "Рассылка #%d уже запущена или отменена",
+		"broadcast_started":   "Рассылка #%d запущена, отчёт придёт, когда она закончится",
+		"broadcast_cancelled": "Рассылка #%d отменена",
+		"broadcast_over":      "Рассылка #%d уже закончилась",
+		"broadcast_finished":  "Рассылка #%d закончена",
+		"broadcast_report":    "%s\nДоставлено: %d\nОшибок: %d\nЗаблокировали бота: %d",
+
+		"stats_activity": "Активные пользователи",
+		"stats_signups":  "Новые пользователи",
+		"stats_notes":    "Заметки по типам",
+		"stats_per_user": "На пользователя",
+		"stats_storage":  "Хранилище",
+		"stats_largest":  "Самые большие архивы",
+		"stats_folders":  "Популярные папки",
+		"stats_errors":   "Ошибки с запуска",
+		"stats_csv":      "CSV",
+	},
+	plurals: map[string]Plural{
+		"notes_selected": {
+			One:  "☑️ Выбрана %d запись",
+			Few:  "☑️ Выбрано %d записи",
+			Many: "☑️ Выбрано %d записей",
+		},
+		"selection_remove_ask": {
+			One:  "Удалить %d запись? Восстановить ее не получится",
+			Few:  "Удалить %d записи? Восстановить их не получится",
+			Many: "Удалить %d записей? Восстановить их не получится",
+		},
+		"notes_moved": {
+			One:  "📤 Перемещена %d запись",
+			Few:  "📤 Перемещено %d записи",
+			Many: "📤 Перемещено %d записей",
+		},
+		"notes_removed": {
+			One:  "🗑️ Удалена %d запись",
+			Few:  "🗑️ Удалено %d записи",
+			Many: "🗑️ Удалено %d записей",
+		},
+		"notes_tagged": {
+			One:  "🏷 %d запись отмечена %s",
+			Few:  "🏷 %d записи отмечены %s",
+			Many: "🏷 %d записей отмечены %s",
+		},
+		"notes_exported": {
+			One:  "📦 Выгружена %d запись",
+			Few:  "📦 Выгружено %d записи",
+			Many: "📦 Выгружено %d записей",
+		},
+		"folder_copied": {
+			One:  "📑 Создана копия с %d записью",
+			Few:  "📑 Создана копия с %d записями",
+			Many: "📑 Создана копия с %d записями",
+		},
+		"folder_merged": {
+			One:  "🔀 Перенесена %d запись, папки объединены",
+			Few:  "🔀 Перенесены %d записи, папки объединены",
+			Many: "🔀 Перенесено %d записей, папки объединены",
+		},
+		"folder_split_done": {
+			One:  "✂️ В новую папку перенесена %d запись",
+			Few:  "✂️ В новую папку перенесены %d записи",
+			Many: "✂️ В новую папку перенесено %d записей",
+		},
+		"rules_list": {
+			One:  "%d правило, проверяется по порядку:",
+			Few:  "%d правила, проверяются по порядку:",
+			Many: "%d правил, проверяются по порядку:",
+		},
+		"rules_dry_run": {
+			One:  "🔍 Правила проверены на последней %d записи, ничего не изменено:",
+			Few:  "🔍 Правила проверены на последних %d записях, ничего не изменено:",
+			Many: "🔍 Правила проверены на последних %d записях, ничего не изменено:",
+		},
+		"token_list": {
+			One:  "%d активный токен (создан / использован):",
+			Few:  "%d активных токена (создан / использован):",
+			Many: "%d активных токенов (создан / использован):",
+		},
+	},
+}
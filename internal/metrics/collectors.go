@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// RegisterDBPool exports the stats of the postgres pool.
+func RegisterDBPool(pool *pgxpool.Pool) {
+	prometheus.MustRegister(&dbPoolCollector{pool: pool})
+}
+
+// RegisterRedisPool exports the stats of the redis pool.
+func RegisterRedisPool(client *redis.Client) {
+	prometheus.MustRegister(&redisPoolCollector{client: client})
+}
+
+// RegisterActiveFlows exports the number of unfinished dialogs by flow name.
+func RegisterActiveFlows(flows func() map[string]int) {
+	prometheus.MustRegister(&flowsCollector{flows: flows})
+}
+
+var (
+	dbAcquiredConns = prometheus.NewDesc(namespace+"_db_pool_acquired_conns",
+		"Connections currently in use.", nil, nil)
+	dbIdleConns = prometheus.NewDesc(namespace+"_db_pool_idle_conns",
+		"Idle connections.", nil, nil)
+	dbTotalConns = prometheus.NewDesc(namespace+"_db_pool_total_conns",
+		"Total connections.", nil, nil)
+	dbMaxConns = prometheus.NewDesc(namespace+"_db_pool_max_conns",
+		"Maximum size of the pool.", nil, nil)
+	dbAcquireCount = prometheus.NewDesc(namespace+"_db_pool_acquire_total",
+		"Successful acquires from the pool.", nil, nil)
+	dbAcquireDuration = prometheus.NewDesc(namespace+"_db_pool_acquire_duration_seconds_total",
+		"Total time spent acquiring connections.", nil, nil)
+	dbEmptyAcquireCount = prometheus.NewDesc(namespace+"_db_pool_empty_acquire_total",
+		"Acquires that waited for a connection.", nil, nil)
+
+	redisHits = prometheus.NewDesc(namespace+"_redis_pool_hits_total",
+		"Free connections found in the pool.", nil, nil)
+	redisMisses = prometheus.NewDesc(namespace+"_redis_pool_misses_total",
+		"Free connections not found in the pool.", nil, nil)
+	redisTimeouts = prometheus.NewDesc(namespace+"_redis_pool_timeouts_total",
+		"Waits for a connection that timed out.", nil, nil)
+	redisTotalConns = prometheus.NewDesc(namespace+"_redis_pool_total_conns",
+		"Total connections.", nil, nil)
+	redisIdleConns = prometheus.NewDesc(namespace+"_redis_pool_idle_conns",
+		"Idle connections.", nil, nil)
+
+	activeFlows = prometheus.NewDesc(namespace+"_active_flows",
+		"Dialogs waiting for the user input.", []string{"flow"}, nil)
+)
+
+type dbPoolCollector struct {
+	pool *pgxpool.Pool
+}
+
+func (c *dbPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *dbPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(dbAcquiredConns, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(dbIdleConns, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(dbTotalConns, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(dbMaxConns, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(dbAcquireCount, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(dbAcquireDuration, prometheus.CounterValue, s.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(dbEmptyAcquireCount, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+}
+
+type redisPoolCollector struct {
+	client *redis.Client
+}
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.client.PoolStats()
+
+	ch <- prometheus.MustNewConstMetric(redisHits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(redisMisses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(redisTimeouts, prometheus.CounterValue, float64(s.Timeouts))
+	ch <- prometheus.MustNewConstMetric(redisTotalConns, prometheus.GaugeValue, float64(s.TotalConns))
+	ch <- prometheus.MustNewConstMetric(redisIdleConns, prometheus.GaugeValue, float64(s.IdleConns))
+}
+
+type flowsCollector struct {
+	flows func() map[string]int
+}
+
+func (c *flowsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeFlows
+}
+
+func (c *flowsCollector) Collect(ch chan<- prometheus.Metric) {
+	for flow, count := range c.flows() {
+		ch <- prometheus.MustNewConstMetric(activeFlows, prometheus.GaugeValue, float64(count), flow)
+	}
+}
@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	Path string = "/metrics"
+
+	namespace string = "archive_bot"
+
+	outcomeOK              string = "ok"
+	outcomeTooManyRequests string = "too_many_requests"
+	outcomeError           string = "error"
+)
+
+var (
+	updates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "updates_total",
+		Help:      "Updates received by type.",
+	}, []string{"type"})
+
+	updatesDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "updates_dropped_total",
+		Help:      "Updates dropped by the router middlewares by reason.",
+	}, []string{"reason"})
+
+	updateDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "update_duration_seconds",
+		Help:      "Duration of update processing including the wait in the per-user queue.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"type"})
+
+	handlerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "handler_duration_seconds",
+		Help:      "Duration of update handlers.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"handler"})
+
+	handlerPanics = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "handler_panics_total",
+		Help:      "Update handlers finished with a panic.",
+	}, []string{"handler"})
+
+	telegramRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_requests_total",
+		Help:      "Telegram Bot API calls by method and outcome.",
+	}, []string{"method", "outcome"})
+
+	sendDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "send_dropped_total",
+		Help:      "Queued Bot API calls dropped after a permanent error or too many attempts.",
+	}, []string{"method"})
+
+	broadcastMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "broadcast_messages_total",
+		Help:      "Broadcast messages by result: delivered, failed or blocked.",
+	}, []string{"result"})
+
+	transcriptions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transcriptions_total",
+		Help:      "Transcription jobs by result: queued, done, retried or failed.",
+	}, []string{"result"})
+
+	notesSaved = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notes_saved_total",
+		Help:      "Saved notes by type.",
+	}, []string{"type"})
+)
+
+// Handler serves the metrics of the default registry.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+func UpdateReceived(updateType string) {
+	updates.WithLabelValues(updateType).Inc()
+}
+
+func UpdateDropped(reason string) {
+	updatesDropped.WithLabelValues(reason).Inc()
+}
+
+// ObserveUpdate records the duration of the update received at start.
+func ObserveUpdate(updateType string, start time.Time) {
+	updateDuration.WithLabelValues(updateType).Observe(time.Since(start).Seconds())
+}
+
+// ObserveHandler records the duration of the handler started at start
+// and whether it panicked.
+func ObserveHandler(handler string, start time.Time, panicked bool) {
+	handlerDuration.WithLabelValues(handler).Observe(time.Since(start).Seconds())
+	if panicked {
+		handlerPanics.WithLabelValues(handler).Inc()
+	}
+}
+
+// TelegramRequest records the outcome of the Bot API method call.
+func TelegramRequest(method string, err error) {
+	telegramRequests.WithLabelValues(method, outcome(err)).Inc()
+}
+
+func SendDropped(method string) {
+	sendDropped.WithLabelValues(method).Inc()
+}
+
+func BroadcastMessage(result string) {
+	broadcastMessages.WithLabelValues(result).Inc()
+}
+
+func Transcription(result string) {
+	transcriptions.WithLabelValues(result).Inc()
+}
+
+func NoteSaved(noteType string) {
+	notesSaved.WithLabelValues(noteType).Inc()
+}
+
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return outcomeOK
+	case bot.IsTooManyRequestsError(err), errors.Is(err, bot.ErrorTooManyRequests):
+		return outcomeTooManyRequests
+	default:
+		return outcomeError
+	}
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Totals are the counters of the process since its start.
+type Totals struct {
+	Updates          float64
+	UpdatesDropped   float64
+	Handled          float64
+	HandlerPanics    float64
+	TelegramRequests float64
+	TelegramErrors   float64
+	SendDropped      float64
+}
+
+// Snapshot returns the current totals, they are reset by a restart.
+func Snapshot() Totals {
+	failed := func(m *dto.Metric) bool {
+		for _, l := range m.GetLabel() {
+			if l.GetName() == "outcome" {
+				return l.GetValue() != outcomeOK
+			}
+		}
+		return false
+	}
+
+	return Totals{
+		Updates:          sum(updates, nil),
+		UpdatesDropped:   sum(updatesDropped, nil),
+		Handled:          sum(handlerDuration, nil),
+		HandlerPanics:    sum(handlerPanics, nil),
+		TelegramRequests: sum(telegramRequests, nil),
+		TelegramErrors:   sum(telegramRequests, failed),
+		SendDropped:      sum(sendDropped, nil),
+	}
+}
+
+// sum adds up the counters or the histogram counts of the collector
+// with the labels accepted by match, all of them when match is nil.
+func sum(c prometheus.Collector, match func(m *dto.Metric) bool) float64 {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+
+	var total float64
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			continue
+		}
+		if match != nil && !match(&m) {
+			continue
+		}
+
+		switch {
+		case m.Counter != nil:
+			total += m.GetCounter().GetValue()
+		case m.Histogram != nil:
+			total += float64(m.GetHistogram().GetSampleCount())
+		}
+	}
+
+	return total
+}
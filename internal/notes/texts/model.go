@@ -1,39 +1,136 @@
-package texts
-
-import (
-	"strconv"
-	"strings"
-	"time"
-)
-
-type TextNote struct {
-	ID           int
-	UserID       int64
-	FolderID     int
-	Type         string
-	Description  string
-	MediaGroupID string
-	CreatedAt    time.Time
-}
-
-func (tn *TextNote) String() string {
-	b := &strings.Builder{}
-
-	b.WriteString("TextNote{ID: ")
-	b.WriteString(strconv.Itoa(tn.ID))
-	b.WriteString(", UserID: ")
-	b.WriteString(strconv.FormatInt(tn.UserID, 10))
-	b.WriteString(", FolderID: ")
-	b.WriteString(strconv.Itoa(tn.FolderID))
-	b.WriteString(", Type: ")
-	b.WriteString(tn.Type)
-	b.WriteString(", Description: ")
-	b.WriteString(tn.Description)
-	b.WriteString(", MediaGroupID: ")
-	b.WriteString(tn.MediaGroupID)
-	b.WriteString(", CreatedAt: ")
-	b.WriteString(tn.CreatedAt.String())
-	b.WriteRune('}')
-
-	return b.String()
-}
+package texts
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	maxTags      int = 10
+	maxTagLength int = 32
+	// transcriptMark starts the transcript under the description.
+	transcriptMark string = "🎙 "
+)
+
+type TextNote struct {
+	ID           int
+	UserID       int64
+	FolderID     int
+	Type         string
+	Description  string
+	MediaGroupID string
+	Favorite     bool
+	Tags         []string
+	Transcript   string // the speech of the voice or the audio, empty until it is transcribed
+	CreatedAt    time.Time
+}
+
+func (tn *TextNote) String() string {
+	b := &strings.Builder{}
+
+	b.WriteString("TextNote{ID: ")
+	b.WriteString(strconv.Itoa(tn.ID))
+	b.WriteString(", UserID: ")
+	b.WriteString(strconv.FormatInt(tn.UserID, 10))
+	b.WriteString(", FolderID: ")
+	b.WriteString(strconv.Itoa(tn.FolderID))
+	b.WriteString(", Type: ")
+	b.WriteString(tn.Type)
+	b.WriteString(", Description: ")
+	b.WriteString(tn.Description)
+	b.WriteString(", MediaGroupID: ")
+	b.WriteString(tn.MediaGroupID)
+	b.WriteString(", Favorite: ")
+	b.WriteString(strconv.FormatBool(tn.Favorite))
+	b.WriteString(", Tags: ")
+	b.WriteString(strings.Join(tn.Tags, " "))
+	b.WriteString(", Transcript: ")
+	b.WriteString(tn.Transcript)
+	b.WriteString(", CreatedAt: ")
+	b.WriteString(tn.CreatedAt.String())
+	b.WriteRune('}')
+
+	return b.String()
+}
+
+// Caption is the description shown with the note, followed by the transcript.
+func (tn *TextNote) Caption() string {
+	switch {
+	case tn.Transcript == "":
+		return tn.Description
+	case tn.Description == "":
+		return transcriptMark + tn.Transcript
+	default:
+		return tn.Description + "\n\n" + transcriptMark + tn.Transcript
+	}
+}
+
+// Filter selects notes of the user. Zero FolderID and empty Query match
+// any note, Query is searched in the descriptions and the transcripts.
+type Filter struct {
+	UserID   int64
+	FolderID int
+	Query    string
+	Limit    int
+	Offset   int
+}
+
+// ParseTags parses the tags separated by spaces or commas, the # is optional.
+// The tags are lowercased and deduplicated, false if there is no tags or
+// one of them is invalid.
+func ParseTags(text string) ([]string, bool) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ','
+	})
+	if len(fields) == 0 || len(fields) > maxTags {
+		return nil, false
+	}
+
+	tags := make([]string, 0, len(fields))
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		tag := strings.ToLower(strings.TrimPrefix(field, "#"))
+		if tag == "" || len([]rune(tag)) > maxTagLength {
+			return nil, false
+		}
+		for _, r := range tag {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+				return nil, false
+			}
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, true
+}
+
+// WriteCSV writes the notes with the header, the tags are joined by spaces.
+func WriteCSV(w io.Writer, notes []*TextNote) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "folder_id", "type", "created_at", "favorite", "tags", "text"}); err != nil {
+		return err
+	}
+	for _, n := range notes {
+		if err := cw.Write([]string{
+			strconv.Itoa(n.ID),
+			strconv.Itoa(n.FolderID),
+			n.Type,
+			n.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.FormatBool(n.Favorite),
+			strings.Join(n.Tags, " "),
+			n.Description,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
@@ -0,0 +1,74 @@
+package texts
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTags(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		text string
+		want []string
+		ok   bool
+	}{
+		{"#work urgent", []string{"work", "urgent"}, true},
+		{"Work, #WORK,todo", []string{"work", "todo"}, true},
+		{"#дом #to-do #v_2", []string{"дом", "to-do", "v_2"}, true},
+		{"", nil, false},
+		{"#", nil, false},
+		{"#a.b", nil, false},
+		{"1 2 3 4 5 6 7 8 9 10 11", nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			tags, ok := ParseTags(tc.text)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, tags)
+		})
+	}
+}
+
+func TestCaption(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description string
+		transcript  string
+		want        string
+	}{
+		{"note", "", "note"},
+		{"", "hello there", "🎙 hello there"},
+		{"note", "hello there", "note\n\n🎙 hello there"},
+		{"", "", ""},
+	}
+
+	for _, tc := range testCases {
+		n := &TextNote{Description: tc.description, Transcript: tc.transcript}
+		assert.Equal(t, tc.want, n.Caption())
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+	notes := []*TextNote{{
+		ID:          7,
+		FolderID:    2,
+		Type:        "message",
+		Description: "line, \"quoted\"",
+		Favorite:    true,
+		Tags:        []string{"work", "todo"},
+		CreatedAt:   time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+	}}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteCSV(buf, notes))
+	assert.Equal(t,
+		"id,folder_id,type,created_at,favorite,tags,text\n"+
+			"7,2,message,2026-10-19T12:00:00Z,true,work todo,\"line, \"\"quoted\"\"\"\n",
+		buf.String())
+}
@@ -1,193 +1,454 @@
-package texts
-
-import (
-	"context"
-	"sync"
-
-	"archive_bot/pkg/er"
-	"archive_bot/pkg/logger"
-
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
-)
-
-var ErrNoTextNote = er.New("there's no saved note", "", nil)
-
-var (
-	instance *pgRepository
-	once     sync.Once
-)
-
-type pgRepository struct {
-	log *logger.Logger
-	mu  sync.Mutex
-	db  *pgxpool.Pool
-}
-
-// NewRepository creates new note repository.
-func NewRepository(ctx context.Context, log *logger.Logger, db *pgxpool.Pool) (*pgRepository, error) {
-	once.Do(func() {
-		instance = &pgRepository{log: log, mu: sync.Mutex{}, db: db}
-	})
-
-	return instance, nil
-}
-
-// Save saves note to database.
-func (repo *pgRepository) Save(ctx context.Context, n *TextNote) (int, error) {
-	const op string = "texts.repository.Save"
-
-	// TODO: one statement
-	var id int
-	var desc string
-	if n.MediaGroupID != "" {
-		repo.mu.Lock()
-		if err := repo.db.QueryRow(ctx,
-			`SELECT id, description FROM texts WHERE user_id = $1 AND media_group_id = $2;`,
-			n.UserID, n.MediaGroupID).Scan(&id, &desc); err != nil {
-			repo.log.Debug(
-				"media_group_id is exists",
-				logger.String("operation", op),
-				logger.Int("id", id),
-				logger.String("media_group_id", n.MediaGroupID),
-				logger.ErrAttr(err),
-			)
-			if err == pgx.ErrNoRows {
-				if err := repo.db.QueryRow(ctx,
-					`INSERT INTO texts
-						(user_id, folder_id, description, media_group_id, type)
-						VALUES ($1, $2, $3, $4, $5)
-						RETURNING id;`,
-					n.UserID, n.FolderID, n.Description, n.MediaGroupID, n.Type,
-				).Scan(&id); err != nil {
-					return 0, er.New("unable to save note", op, err)
-				}
-			}
-		} else if len(n.Description) > len(desc) {
-			n.ID = id
-			if err := repo.UpdateByID(ctx, n); err != nil {
-				return 0, er.New("unable to set description note", op, err)
-			}
-		}
-		repo.mu.Unlock()
-	} else {
-		if err := repo.db.QueryRow(ctx,
-			`INSERT INTO texts
-			(user_id, folder_id, description, type)
-			VALUES ($1, $2, $3, $4)
-			RETURNING id;`,
-			n.UserID, n.FolderID, n.Description, n.Type).Scan(&id); err != nil {
-			return 0, er.New("unable to save note", op, err)
-		}
-	}
-
-	return id, nil
-}
-
-func (repo *pgRepository) AllFrom(ctx context.Context, n *TextNote) ([]*TextNote, error) {
-	const op string = "texts.repository.AllFrom"
-
-	rows, err := repo.db.Query(ctx,
-		`SELECT id, description, type, media_group_id
-		FROM texts
-		WHERE user_id = $1 AND folder_id = $2
-		ORDER BY created_at DESC`,
-		n.UserID, n.FolderID)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, ErrNoTextNote
-		}
-		return nil, er.New("unable to get all text notes", op, err)
-	}
-	defer rows.Close()
-
-	notes := []*TextNote{}
-	for rows.Next() {
-		var note TextNote
-		if err := rows.Scan(&note.ID, &note.Description, &note.Type, &note.MediaGroupID); err != nil {
-			return nil, er.New("unable to scan data", op, err)
-		}
-		notes = append(notes, &note)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, er.New("error in rows: %w", op, err)
-	}
-
-	return notes, nil
-}
-
-// Move - move  a note to catalogue.
-func (repo *pgRepository) Move(ctx context.Context, n *TextNote) error {
-	const op string = "texts.repository.Move"
-	log := repo.log.With(logger.String("operation", op))
-
-	if _, err := repo.db.Exec(ctx,
-		`UPDATE texts SET folder_id = $1 WHERE id = $2;`,
-		n.FolderID, n.ID); err != nil {
-		log.Error("", logger.ErrAttr(err))
-		return er.New("unable to move note", op, err)
-	}
-
-	return nil
-}
-
-func (repo *pgRepository) FindLast(ctx context.Context, userID int64) (*TextNote, error) {
-	const op string = "texts.repository.FindLast"
-
-	note := TextNote{}
-	if err := repo.db.QueryRow(ctx,
-		`SELECT description, created_at
-		FROM texts
-		WHERE user_id = $1
-		ORDER BY created_at DESC 
-		LIMIT 1;`,
-		userID).Scan(&note.Description, &note.CreatedAt); err != nil {
-		return nil, er.New("the last note could not be find", op, err)
-	}
-
-	return &note, nil
-}
-
-// Move - move  a note to catalogue.
-func (repo *pgRepository) MoveLast(ctx context.Context, n *TextNote) error {
-	const op string = "texts.repository.MoveLast"
-
-	if _, err := repo.db.Exec(ctx,
-		`UPDATE texts
-		SET folder_id = $1
-		WHERE id = (
-			SELECT id FROM texts
-			WHERE user_id = $2
-			ORDER BY created_at DESC 
-			LIMIT 1
-		);`, n.FolderID, n.UserID); err != nil {
-		return er.New("unable to move note", op, err)
-	}
-
-	return nil
-}
-
-// TODO: type to update
-func (repo *pgRepository) UpdateByID(ctx context.Context, n *TextNote) error {
-	const op string = "texts.repository.UpdateByID"
-
-	if _, err := repo.db.Exec(ctx,
-		`UPDATE texts SET description = $1 WHERE id = $2;`,
-		n.Description, n.ID); err != nil {
-		return er.New("unable to update note", op, err)
-	}
-
-	return nil
-}
-
-func (repo *pgRepository) RemoveByID(ctx context.Context, id int) error {
-	const op string = "texts.repository.RemoveByID"
-
-	if _, err := repo.db.Exec(ctx,
-		`DELETE FROM texts WHERE id = $1;`, id); err != nil {
-		return er.New("the note could not be removed", op, err)
-	}
-
-	return nil
-}
+package texts
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNoTextNote = er.New("there's no saved note", "", nil)
+	ErrNoFolder   = er.New("the folder does not exist", "", nil)
+)
+
+var (
+	instance *pgRepository
+	once     sync.Once
+)
+
+type pgRepository struct {
+	log *logger.Logger
+	mu  sync.Mutex
+	db  *pgxpool.Pool
+}
+
+// NewRepository creates new note repository.
+func NewRepository(ctx context.Context, log *logger.Logger, db *pgxpool.Pool) (*pgRepository, error) {
+	once.Do(func() {
+		instance = &pgRepository{log: log, mu: sync.Mutex{}, db: db}
+	})
+
+	return instance, nil
+}
+
+// Save saves note to database.
+func (repo *pgRepository) Save(ctx context.Context, n *TextNote) (int, error) {
+	const op string = "texts.repository.Save"
+
+	// TODO: one statement
+	var id int
+	var desc string
+	if n.MediaGroupID != "" {
+		repo.mu.Lock()
+		if err := repo.db.QueryRow(ctx,
+			`SELECT id, description FROM texts WHERE user_id = $1 AND media_group_id = $2;`,
+			n.UserID, n.MediaGroupID).Scan(&id, &desc); err != nil {
+			repo.log.Debug(
+				"media_group_id is exists",
+				logger.String("operation", op),
+				logger.Int("id", id),
+				logger.String("media_group_id", n.MediaGroupID),
+				logger.ErrAttr(err),
+			)
+			if err == pgx.ErrNoRows {
+				if err := repo.db.QueryRow(ctx,
+					`INSERT INTO texts
+						(user_id, folder_id, description, media_group_id, type)
+						VALUES ($1, $2, $3, $4, $5)
+						RETURNING id;`,
+					n.UserID, n.FolderID, n.Description, n.MediaGroupID, n.Type,
+				).Scan(&id); err != nil {
+					return 0, er.New("unable to save note", op, err)
+				}
+			}
+		} else if len(n.Description) > len(desc) {
+			n.ID = id
+			if err := repo.UpdateByID(ctx, n); err != nil {
+				return 0, er.New("unable to set description note", op, err)
+			}
+		}
+		repo.mu.Unlock()
+	} else {
+		if err := repo.db.QueryRow(ctx,
+			`INSERT INTO texts
+			(user_id, folder_id, description, type)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id;`,
+			n.UserID, n.FolderID, n.Description, n.Type).Scan(&id); err != nil {
+			return 0, er.New("unable to save note", op, err)
+		}
+	}
+
+	return id, nil
+}
+
+func (repo *pgRepository) AllFrom(ctx context.Context, n *TextNote) ([]*TextNote, error) {
+	const op string = "texts.repository.AllFrom"
+
+	rows, err := repo.db.Query(ctx,
+		`SELECT id, description, type, media_group_id, favorite, COALESCE(transcript, '')
+		FROM texts
+		WHERE user_id = $1 AND folder_id = $2
+		ORDER BY favorite DESC, created_at DESC`,
+		n.UserID, n.FolderID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNoTextNote
+		}
+		return nil, er.New("unable to get all text notes", op, err)
+	}
+	defer rows.Close()
+
+	notes := []*TextNote{}
+	for rows.Next() {
+		var note TextNote
+		if err := rows.Scan(
+			&note.ID, &note.Description, &note.Type, &note.MediaGroupID, &note.Favorite, &note.Transcript,
+		); err != nil {
+			return nil, er.New("unable to scan data", op, err)
+		}
+		notes = append(notes, &note)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, er.New("error in rows: %w", op, err)
+	}
+
+	return notes, nil
+}
+
+// Favorites returns the favorite notes of the user from all the folders.
+func (repo *pgRepository) Favorites(ctx context.Context, userID int64) ([]*TextNote, error) {
+	const op string = "texts.repository.Favorites"
+
+	rows, err := repo.db.Query(ctx,
+		`SELECT id, folder_id, description, type, media_group_id, COALESCE(transcript, '')
+		FROM texts
+		WHERE user_id = $1 AND favorite
+		ORDER BY created_at DESC`,
+		userID)
+	if err != nil {
+		return nil, er.New("unable to get favorite notes", op, err)
+	}
+	defer rows.Close()
+
+	notes := []*TextNote{}
+	for rows.Next() {
+		note := TextNote{UserID: userID, Favorite: true}
+		if err := rows.Scan(
+			&note.ID, &note.FolderID, &note.Description, &note.Type, &note.MediaGroupID, &note.Transcript,
+		); err != nil {
+			return nil, er.New("unable to scan data", op, err)
+		}
+		notes = append(notes, &note)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, er.New("error in rows", op, err)
+	}
+
+	return notes, nil
+}
+
+// ToggleFavorite adds the note to the favorites or removes it from them
+// and returns whether the note is a favorite now.
+func (repo *pgRepository) ToggleFavorite(ctx context.Context, n *TextNote) (bool, error) {
+	const op string = "texts.repository.ToggleFavorite"
+
+	var favorite bool
+	if err := repo.db.QueryRow(ctx,
+		`UPDATE texts SET favorite = NOT favorite
+		WHERE id = $1 AND user_id = $2
+		RETURNING favorite;`,
+		n.ID, n.UserID).Scan(&favorite); err != nil {
+		if err == pgx.ErrNoRows {
+			return false, ErrNoTextNote
+		}
+		return false, er.New("unable to toggle favorite", op, err)
+	}
+
+	return favorite, nil
+}
+
+// Move - move  a note to catalogue.
+func (repo *pgRepository) Move(ctx context.Context, n *TextNote) error {
+	const op string = "texts.repository.Move"
+	log := repo.log.With(logger.String("operation", op))
+
+	if _, err := repo.db.Exec(ctx,
+		`UPDATE texts SET folder_id = $1 WHERE id = $2;`,
+		n.FolderID, n.ID); err != nil {
+		log.Error("", logger.ErrAttr(err))
+		return er.New("unable to move note", op, err)
+	}
+
+	return nil
+}
+
+func (repo *pgRepository) FindLast(ctx context.Context, userID int64) (*TextNote, error) {
+	const op string = "texts.repository.FindLast"
+
+	note := TextNote{}
+	if err := repo.db.QueryRow(ctx,
+		`SELECT id, description, created_at
+		FROM texts
+		WHERE user_id = $1
+		ORDER BY created_at DESC 
+		LIMIT 1;`,
+		userID).Scan(&note.ID, &note.Description, &note.CreatedAt); err != nil {
+		return nil, er.New("the last note could not be find", op, err)
+	}
+
+	return &note, nil
+}
+
+// Move - move  a note to catalogue.
+func (repo *pgRepository) MoveLast(ctx context.Context, n *TextNote) error {
+	const op string = "texts.repository.MoveLast"
+
+	if _, err := repo.db.Exec(ctx,
+		`UPDATE texts
+		SET folder_id = $1
+		WHERE id = (
+			SELECT id FROM texts
+			WHERE user_id = $2
+			ORDER BY created_at DESC 
+			LIMIT 1
+		);`, n.FolderID, n.UserID); err != nil {
+		return er.New("unable to move note", op, err)
+	}
+
+	return nil
+}
+
+// TODO: type to update
+func (repo *pgRepository) UpdateByID(ctx context.Context, n *TextNote) error {
+	const op string = "texts.repository.UpdateByID"
+
+	if _, err := repo.db.Exec(ctx,
+		`UPDATE texts SET description = $1 WHERE id = $2;`,
+		n.Description, n.ID); err != nil {
+		return er.New("unable to update note", op, err)
+	}
+
+	return nil
+}
+
+func (repo *pgRepository) RemoveByID(ctx context.Context, id int) error {
+	const op string = "texts.repository.RemoveByID"
+
+	if _, err := repo.db.Exec(ctx,
+		`DELETE FROM texts WHERE id = $1;`, id); err != nil {
+		return er.New("the note could not be removed", op, err)
+	}
+
+	return nil
+}
+
+// List returns the notes matching the filter, newest first.
+func (repo *pgRepository) List(ctx context.Context, f *Filter) ([]*TextNote, error) {
+	const op string = "texts.repository.List"
+
+	rows, err := repo.db.Query(ctx,
+		`SELECT id, folder_id, type, COALESCE(description, ''), media_group_id,
+			COALESCE(transcript, ''), created_at
+		FROM texts
+		WHERE user_id = $1
+			AND ($2 = 0 OR folder_id = $2)
+			AND ($3 = '' OR description ILIKE $3 ESCAPE '\' OR transcript ILIKE $3 ESCAPE '\')
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4 OFFSET $5;`,
+		f.UserID, f.FolderID, containsPattern(f.Query), f.Limit, f.Offset)
+	if err != nil {
+		return nil, er.New("unable to list notes", op, err)
+	}
+	defer rows.Close()
+
+	notes := []*TextNote{}
+	for rows.Next() {
+		note := TextNote{UserID: f.UserID}
+		if err := rows.Scan(
+			&note.ID, &note.FolderID, &note.Type,
+			&note.Description, &note.MediaGroupID, &note.Transcript, &note.CreatedAt,
+		); err != nil {
+			return nil, er.New("unable to scan data", op, err)
+		}
+		notes = append(notes, &note)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, er.New("error in rows", op, err)
+	}
+
+	return notes, nil
+}
+
+// FindByID finds the note of the user.
+func (repo *pgRepository) FindByID(ctx context.Context, n *TextNote) (*TextNote, error) {
+	const op string = "texts.repository.FindByID"
+
+	note := TextNote{ID: n.ID, UserID: n.UserID}
+	if err := repo.db.QueryRow(ctx,
+		`SELECT folder_id, type, COALESCE(description, ''), media_group_id,
+			COALESCE(transcript, ''), created_at
+		FROM texts
+		WHERE id = $1 AND user_id = $2;`,
+		n.ID, n.UserID).Scan(
+		&note.FolderID, &note.Type, &note.Description, &note.MediaGroupID, &note.Transcript, &note.CreatedAt,
+	); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNoTextNote
+		}
+		return nil, er.New("unable to find note", op, err)
+	}
+
+	return &note, nil
+}
+
+// MoveTo moves the note of the user to another folder of the same user.
+func (repo *pgRepository) MoveTo(ctx context.Context, n *TextNote) error {
+	const op string = "texts.repository.MoveTo"
+
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE texts SET folder_id = $1
+		WHERE id = $2 AND user_id = $3
+			AND EXISTS (SELECT 1 FROM folders WHERE id = $1 AND user_id = $3);`,
+		n.FolderID, n.ID, n.UserID)
+	if err != nil {
+		return er.New("unable to move note", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoTextNote
+	}
+
+	return nil
+}
+
+// Remove removes the note of the user.
+func (repo *pgRepository) Remove(ctx context.Context, n *TextNote) error {
+	const op string = "texts.repository.Remove"
+
+	tag, err := repo.db.Exec(ctx,
+		`DELETE FROM texts WHERE id = $1 AND user_id = $2;`, n.ID, n.UserID)
+	if err != nil {
+		return er.New("the note could not be removed", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoTextNote
+	}
+
+	return nil
+}
+
+// FolderExists checks that the folder belongs to the user.
+func (repo *pgRepository) FolderExists(ctx context.Context, n *TextNote) error {
+	const op string = "texts.repository.FolderExists"
+
+	var isExists bool
+	if err := repo.db.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM folders WHERE id = $1 AND user_id = $2);`,
+		n.FolderID, n.UserID).Scan(&isExists); err != nil {
+		return er.New("unable to check folder", op, err)
+	}
+
+	if !isExists {
+		return ErrNoFolder
+	}
+
+	return nil
+}
+
+func (repo *pgRepository) MoveMany(ctx context.Context, userID int64, ids []int, folderID int) (int, error) {
+	const op string = "texts.repository.MoveMany"
+
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE texts SET folder_id = $1
+		WHERE user_id = $2 AND id = ANY($3);`,
+		folderID, userID, ids)
+	if err != nil {
+		return 0, er.New("unable to move notes", op, err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+func (repo *pgRepository) RemoveMany(ctx context.Context, userID int64, ids []int) (int, error) {
+	const op string = "texts.repository.RemoveMany"
+
+	tag, err := repo.db.Exec(ctx,
+		`DELETE FROM texts WHERE user_id = $1 AND id = ANY($2);`, userID, ids)
+	if err != nil {
+		return 0, er.New("the notes could not be removed", op, err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// TagMany adds the tags to the notes of the user, the tags the notes
+// already have are kept once.
+func (repo *pgRepository) TagMany(ctx context.Context, userID int64, ids []int, tags []string) (int, error) {
+	const op string = "texts.repository.TagMany"
+
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE texts
+		SET tags = ARRAY(SELECT DISTINCT unnest(tags || $3::text[]) ORDER BY 1)
+		WHERE user_id = $1 AND id = ANY($2);`,
+		userID, ids, tags)
+	if err != nil {
+		return 0, er.New("unable to tag notes", op, err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// FindMany returns the notes of the user by the IDs, the oldest first.
+func (repo *pgRepository) FindMany(ctx context.Context, userID int64, ids []int) ([]*TextNote, error) {
+	const op string = "texts.repository.FindMany"
+
+	rows, err := repo.db.Query(ctx,
+		`SELECT id, folder_id, type, COALESCE(description, ''), media_group_id, favorite, tags, created_at
+		FROM texts
+		WHERE user_id = $1 AND id = ANY($2)
+		ORDER BY created_at, id;`,
+		userID, ids)
+	if err != nil {
+		return nil, er.New("unable to find notes", op, err)
+	}
+	defer rows.Close()
+
+	notes := []*TextNote{}
+	for rows.Next() {
+		note := TextNote{UserID: userID}
+		if err := rows.Scan(
+			&note.ID, &note.FolderID, &note.Type, &note.Description,
+			&note.MediaGroupID, &note.Favorite, &note.Tags, &note.CreatedAt,
+		); err != nil {
+			return nil, er.New("unable to scan data", op, err)
+		}
+		notes = append(notes, &note)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, er.New("error in rows", op, err)
+	}
+
+	return notes, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern turns the query into the ILIKE pattern matching the text
+// containing it, the wildcards of the query match themselves.
+func containsPattern(query string) string {
+	if query == "" {
+		return ""
+	}
+
+	return "%" + likeEscaper.Replace(query) + "%"
+}
@@ -0,0 +1,28 @@
+package texts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainsPattern(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		query string
+		want  string
+	}{
+		{"", ""},
+		{"milk", "%milk%"},
+		{"100%", `%100\%%`},
+		{"snake_case", `%snake\_case%`},
+		{`C:\temp`, `%C:\\temp%`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			assert.Equal(t, tc.want, containsPattern(tc.query))
+		})
+	}
+}
@@ -1,149 +1,336 @@
-package texts
-
-import (
-	"context"
-	"archive_bot/internal/const/messages"
-	"archive_bot/internal/entities"
-	"archive_bot/pkg/logger"
-	"time"
-)
-
-type Repository interface {
-	Save(ctx context.Context, n *TextNote) (int, error)
-	AllFrom(ctx context.Context, n *TextNote) ([]*TextNote, error)
-	Move(ctx context.Context, n *TextNote) error
-	FindLast(ctx context.Context, userID int64) (*TextNote, error)
-	MoveLast(ctx context.Context, n *TextNote) error
-	UpdateByID(ctx context.Context, n *TextNote) error
-	RemoveByID(ctx context.Context, id int) error
-}
-
-type service struct {
-	log  *logger.Logger
-	repo Repository
-}
-
-func NewService(ctx context.Context, log *logger.Logger, repo Repository) *service {
-	return &service{log: log, repo: repo}
-}
-
-func (s *service) Save(ctx context.Context, event *entities.Event) (int, string) {
-	log := s.log.With(logger.String("operation", "texts.service.Save"))
-
-	n := &TextNote{
-		UserID:       event.Meta.UserID,
-		FolderID:     event.FolderID,
-		Type:         event.Type.String(),
-		Description:  event.Text,
-		MediaGroupID: event.MediaGroupID,
-	}
-
-	id, err := s.repo.Save(ctx, n)
-	if err != nil {
-		log.Error("failed to save note", logger.ErrAttr(err))
-		return 0, messages.Error
-	}
-
-	if n.MediaGroupID != "" && n.Description == "" {
-		return id, ""
-	}
-
-	log.Debug("result", logger.Int("id", id))
-	return id, messages.NoteCreated
-}
-
-func (s *service) AllFrom(
-	ctx context.Context, event *entities.Event,
-) (map[int]*entities.AnswerParams, int) {
-	log := s.log.With(logger.String("operation", "texts.service.AllFrom"))
-
-	notes, err := s.repo.AllFrom(ctx, &TextNote{
-		UserID: event.Meta.UserID, FolderID: event.FolderID,
-	})
-	if err != nil {
-		if err == ErrNoTextNote {
-			log.Info("notes is empty", logger.ErrAttr(err))
-			return nil, 0
-		}
-		log.Error("failed to get all text notes", logger.ErrAttr(err))
-		return nil, 0
-	}
-
-	res := make(map[int]*entities.AnswerParams, len(notes))
-	for i := range notes {
-		res[notes[i].ID] = &entities.AnswerParams{
-			Message: notes[i].Description,
-			Type:    entities.ParseType(notes[i].Type),
-		}
-		s.log.Debug(
-			"AnswerParams",
-			logger.String("Message", res[notes[i].ID].Message),
-			logger.String("Type", res[notes[i].ID].Type.String()),
-		)
-	}
-
-	return res, event.FolderID
-}
-
-func (s *service) Move(ctx context.Context, event *entities.Event) string {
-	log := s.log.With(logger.String("operation", "texts.service.Move"))
-
-	n := &TextNote{
-		ID:       event.NoteID,
-		FolderID: event.FolderID,
-	}
-	log.Debug("", logger.Int("note ID", n.ID), logger.Int("folder ID", n.FolderID))
-	if err := s.repo.Move(ctx, n); err != nil {
-		log.Error("failed to move texts note", logger.ErrAttr(err))
-		return messages.Error
-	}
-
-	return messages.Moved
-}
-
-func (s *service) FindLast(ctx context.Context, event *entities.Event) (string, time.Time) {
-	log := s.log.With(logger.String("operation", "texts.service.FindLast"))
-
-	note, err := s.repo.FindLast(ctx, event.Meta.UserID)
-	if err != nil {
-		log.Error("failed to find texts note", logger.ErrAttr(err))
-		return "", time.Time{}
-	}
-
-	return note.Description, note.CreatedAt
-}
-
-func (s *service) MoveLast(ctx context.Context, event *entities.Event) string {
-	log := s.log.With(logger.String("operation", "texts.service.MoveLast"))
-
-	n := &TextNote{
-		UserID:   event.Meta.UserID,
-		FolderID: event.FolderID,
-	}
-	if err := s.repo.MoveLast(ctx, n); err != nil {
-		log.Error("failed to move text note", logger.ErrAttr(err))
-		return messages.Error
-	}
-
-	return messages.Moved
-}
-
-func (s *service) UpdateByID(ctx context.Context, event *entities.Event) string {
-	log := s.log.With(logger.String("operation", "texts.service.UpdateByID"))
-
-	n := &TextNote{
-		ID:          event.NoteID,
-		Description: event.Text,
-	}
-	log.Info("", logger.Int("note ID", n.ID))
-	if err := s.repo.UpdateByID(ctx, n); err != nil {
-		log.Error("failed to update text note", logger.ErrAttr(err))
-		return messages.Error
-	}
-
-	return n.Description
-}
-
-func (s *service) RemoveByID(ctx context.Context, id int) error {
-	return s.repo.RemoveByID(ctx, id)
-}
+package texts
+
+import (
+	"context"
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLimit int = 50
+	maxLimit     int = 200
+)
+
+var ErrEmptyNote = er.New("the note is empty", "", nil)
+
+type Repository interface {
+	Save(ctx context.Context, n *TextNote) (int, error)
+	AllFrom(ctx context.Context, n *TextNote) ([]*TextNote, error)
+	Favorites(ctx context.Context, userID int64) ([]*TextNote, error)
+	ToggleFavorite(ctx context.Context, n *TextNote) (bool, error)
+	Move(ctx context.Context, n *TextNote) error
+	FindLast(ctx context.Context, userID int64) (*TextNote, error)
+	MoveLast(ctx context.Context, n *TextNote) error
+	UpdateByID(ctx context.Context, n *TextNote) error
+	RemoveByID(ctx context.Context, id int) error
+	List(ctx context.Context, f *Filter) ([]*TextNote, error)
+	FindByID(ctx context.Context, n *TextNote) (*TextNote, error)
+	MoveTo(ctx context.Context, n *TextNote) error
+	Remove(ctx context.Context, n *TextNote) error
+	FolderExists(ctx context.Context, n *TextNote) error
+	MoveMany(ctx context.Context, userID int64, ids []int, folderID int) (int, error)
+	RemoveMany(ctx context.Context, userID int64, ids []int) (int, error)
+	TagMany(ctx context.Context, userID int64, ids []int, tags []string) (int, error)
+	FindMany(ctx context.Context, userID int64, ids []int) ([]*TextNote, error)
+}
+
+type service struct {
+	log  *logger.Logger
+	repo Repository
+}
+
+func NewService(ctx context.Context, log *logger.Logger, repo Repository) *service {
+	return &service{log: log, repo: repo}
+}
+
+func (s *service) Save(ctx context.Context, event *entities.Event) (int, string) {
+	ctx, span := tracing.Start(ctx, "texts.service.Save")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "texts.service.Save"))
+
+	n := &TextNote{
+		UserID:       event.Meta.UserID,
+		FolderID:     event.FolderID,
+		Type:         event.Type.String(),
+		Description:  event.Text,
+		MediaGroupID: event.MediaGroupID,
+	}
+
+	id, err := s.repo.Save(ctx, n)
+	if err != nil {
+		log.Error("failed to save note", logger.ErrAttr(err))
+		return 0, messages.Error
+	}
+
+	if n.MediaGroupID != "" && n.Description == "" {
+		return id, ""
+	}
+
+	log.Debug("result", logger.Int("id", id))
+	return id, messages.NoteCreated
+}
+
+func (s *service) AllFrom(
+	ctx context.Context, event *entities.Event,
+) (map[int]*entities.AnswerParams, int) {
+	ctx, span := tracing.Start(ctx, "texts.service.AllFrom")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "texts.service.AllFrom"))
+
+	notes, err := s.repo.AllFrom(ctx, &TextNote{
+		UserID: event.Meta.UserID, FolderID: event.FolderID,
+	})
+	if err != nil {
+		if err == ErrNoTextNote {
+			log.Info("notes is empty", logger.ErrAttr(err))
+			return nil, 0
+		}
+		log.Error("failed to get all text notes", logger.ErrAttr(err))
+		return nil, 0
+	}
+
+	res := make(map[int]*entities.AnswerParams, len(notes))
+	for i := range notes {
+		res[notes[i].ID] = &entities.AnswerParams{
+			Message:  notes[i].Caption(),
+			Type:     entities.ParseType(notes[i].Type),
+			Favorite: notes[i].Favorite,
+		}
+		logger.L(ctx).Debug(
+			"AnswerParams",
+			logger.String("Message", res[notes[i].ID].Message),
+			logger.String("Type", res[notes[i].ID].Type.String()),
+		)
+	}
+
+	return res, event.FolderID
+}
+
+// Favorites returns the favorite notes of the user from all the folders.
+func (s *service) Favorites(ctx context.Context, event *entities.Event) map[int]*entities.AnswerParams {
+	ctx, span := tracing.Start(ctx, "texts.service.Favorites")
+	defer span.End()
+
+	notes, err := s.repo.Favorites(ctx, event.Meta.UserID)
+	if err != nil {
+		logger.L(ctx).Error("failed to get favorite notes", logger.ErrAttr(err))
+		return nil
+	}
+
+	res := make(map[int]*entities.AnswerParams, len(notes))
+	for _, n := range notes {
+		res[n.ID] = &entities.AnswerParams{
+			Message:  n.Caption(),
+			Type:     entities.ParseType(n.Type),
+			FolderID: n.FolderID,
+			Favorite: true,
+		}
+	}
+
+	return res
+}
+
+// ToggleFavorite adds the note of the event to the favorites or removes it
+// from them and returns whether the note is a favorite now.
+func (s *service) ToggleFavorite(ctx context.Context, event *entities.Event) (bool, error) {
+	ctx, span := tracing.Start(ctx, "texts.service.ToggleFavorite")
+	defer span.End()
+
+	return s.repo.ToggleFavorite(ctx, &TextNote{ID: event.NoteID, UserID: event.Meta.UserID})
+}
+
+func (s *service) Move(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "texts.service.Move")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "texts.service.Move"))
+
+	n := &TextNote{
+		ID:       event.NoteID,
+		FolderID: event.FolderID,
+	}
+	log.Debug("", logger.Int("note ID", n.ID), logger.Int("folder ID", n.FolderID))
+	if err := s.repo.Move(ctx, n); err != nil {
+		log.Error("failed to move texts note", logger.ErrAttr(err))
+		return messages.Error
+	}
+
+	return messages.Moved
+}
+
+// FindLast returns the ID and the time of the last note of the user.
+func (s *service) FindLast(ctx context.Context, event *entities.Event) (int, time.Time) {
+	ctx, span := tracing.Start(ctx, "texts.service.FindLast")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "texts.service.FindLast"))
+
+	note, err := s.repo.FindLast(ctx, event.Meta.UserID)
+	if err != nil {
+		log.Error("failed to find texts note", logger.ErrAttr(err))
+		return 0, time.Time{}
+	}
+
+	return note.ID, note.CreatedAt
+}
+
+func (s *service) MoveLast(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "texts.service.MoveLast")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "texts.service.MoveLast"))
+
+	n := &TextNote{
+		UserID:   event.Meta.UserID,
+		FolderID: event.FolderID,
+	}
+	if err := s.repo.MoveLast(ctx, n); err != nil {
+		log.Error("failed to move text note", logger.ErrAttr(err))
+		return messages.Error
+	}
+
+	return messages.Moved
+}
+
+func (s *service) UpdateByID(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "texts.service.UpdateByID")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "texts.service.UpdateByID"))
+
+	n := &TextNote{
+		ID:          event.NoteID,
+		Description: event.Text,
+	}
+	log.Info("", logger.Int("note ID", n.ID))
+	if err := s.repo.UpdateByID(ctx, n); err != nil {
+		log.Error("failed to update text note", logger.ErrAttr(err))
+		return messages.Error
+	}
+
+	return n.Description
+}
+
+func (s *service) RemoveByID(ctx context.Context, id int) error {
+	ctx, span := tracing.Start(ctx, "texts.service.RemoveByID")
+	defer span.End()
+
+	return s.repo.RemoveByID(ctx, id)
+}
+
+func (s *service) List(ctx context.Context, f *Filter) ([]*TextNote, error) {
+	ctx, span := tracing.Start(ctx, "texts.service.List")
+	defer span.End()
+
+	if f.Limit <= 0 || f.Limit > maxLimit {
+		f.Limit = defaultLimit
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	f.Query = strings.TrimSpace(f.Query)
+
+	return s.repo.List(ctx, f)
+}
+
+func (s *service) Get(ctx context.Context, userID int64, id int) (*TextNote, error) {
+	ctx, span := tracing.Start(ctx, "texts.service.Get")
+	defer span.End()
+
+	return s.repo.FindByID(ctx, &TextNote{ID: id, UserID: userID})
+}
+
+// Create creates a text note in the folder of the user.
+func (s *service) Create(ctx context.Context, n *TextNote) (int, error) {
+	ctx, span := tracing.Start(ctx, "texts.service.Create")
+	defer span.End()
+
+	n.Description = strings.TrimSpace(n.Description)
+	if n.Description == "" {
+		return 0, ErrEmptyNote
+	}
+	n.Type = entities.Message.String()
+	n.MediaGroupID = ""
+
+	if err := s.repo.FolderExists(ctx, n); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Save(ctx, n)
+}
+
+func (s *service) MoveTo(ctx context.Context, userID int64, id int, folderID int) error {
+	ctx, span := tracing.Start(ctx, "texts.service.MoveTo")
+	defer span.End()
+
+	return s.repo.MoveTo(ctx, &TextNote{ID: id, UserID: userID, FolderID: folderID})
+}
+
+func (s *service) Remove(ctx context.Context, userID int64, id int) error {
+	ctx, span := tracing.Start(ctx, "texts.service.Remove")
+	defer span.End()
+
+	return s.repo.Remove(ctx, &TextNote{ID: id, UserID: userID})
+}
+
+// MoveMany moves the notes of the user to the folder and returns
+// the number of moved notes.
+func (s *service) MoveMany(ctx context.Context, userID int64, ids []int, folderID int) (int, error) {
+	ctx, span := tracing.Start(ctx, "texts.service.MoveMany")
+	defer span.End()
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := s.repo.FolderExists(ctx, &TextNote{UserID: userID, FolderID: folderID}); err != nil {
+		return 0, err
+	}
+
+	return s.repo.MoveMany(ctx, userID, ids, folderID)
+}
+
+// RemoveMany removes the notes of the user and returns
+// the number of removed notes.
+func (s *service) RemoveMany(ctx context.Context, userID int64, ids []int) (int, error) {
+	ctx, span := tracing.Start(ctx, "texts.service.RemoveMany")
+	defer span.End()
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	return s.repo.RemoveMany(ctx, userID, ids)
+}
+
+// TagMany adds the tags to the notes of the user and returns
+// the number of tagged notes.
+func (s *service) TagMany(ctx context.Context, userID int64, ids []int, tags []string) (int, error) {
+	ctx, span := tracing.Start(ctx, "texts.service.TagMany")
+	defer span.End()
+
+	if len(ids) == 0 || len(tags) == 0 {
+		return 0, nil
+	}
+
+	return s.repo.TagMany(ctx, userID, ids, tags)
+}
+
+// FindMany returns the notes of the user by the IDs.
+func (s *service) FindMany(ctx context.Context, userID int64, ids []int) ([]*TextNote, error) {
+	ctx, span := tracing.Start(ctx, "texts.service.FindMany")
+	defer span.End()
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return s.repo.FindMany(ctx, userID, ids)
+}
@@ -0,0 +1,97 @@
+package processor
+
+import (
+	"context"
+
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/user"
+
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+func (p *processor) IsBanned(ctx context.Context, userID int64) bool {
+	return p.user.IsBanned(ctx, userID)
+}
+
+// UserProfile finds the user by the ID or the username,
+// the message is set when there is no profile to show.
+func (p *processor) UserProfile(ctx context.Context, event *entities.Event, query string) (*user.Profile, string) {
+	ctx, span := tracing.Start(ctx, "processor.UserProfile")
+	defer span.End()
+
+	profile, err := p.user.Profile(ctx, query)
+	if err != nil {
+		if err == user.ErrUserNotFound {
+			return nil, i18n.T(event.Meta.Language, messages.UserNotFound)
+		}
+		logger.L(ctx).Error("failed to get user profile", logger.ErrAttr(err))
+		return nil, i18n.T(event.Meta.Language, messages.AdminError)
+	}
+
+	return profile, ""
+}
+
+func (p *processor) BanUser(ctx context.Context, event *entities.Event, userID int64, reason string) string {
+	ctx, span := tracing.Start(ctx, "processor.BanUser")
+	defer span.End()
+
+	if err := p.user.Ban(ctx, userID, reason); err != nil {
+		return p.adminResult(ctx, event.Meta.Language, err)
+	}
+
+	return i18n.T(event.Meta.Language, messages.UserBanned, userID)
+}
+
+func (p *processor) UnbanUser(ctx context.Context, event *entities.Event, userID int64) string {
+	ctx, span := tracing.Start(ctx, "processor.UnbanUser")
+	defer span.End()
+
+	if err := p.user.Unban(ctx, userID); err != nil {
+		return p.adminResult(ctx, event.Meta.Language, err)
+	}
+
+	return i18n.T(event.Meta.Language, messages.UserUnbanned, userID)
+}
+
+func (p *processor) SetQuota(ctx context.Context, event *entities.Event, userID int64, q *user.Quota) string {
+	ctx, span := tracing.Start(ctx, "processor.SetQuota")
+	defer span.End()
+
+	if err := p.user.SetQuota(ctx, userID, q); err != nil {
+		return p.adminResult(ctx, event.Meta.Language, err)
+	}
+
+	return i18n.T(event.Meta.Language, messages.UserQuotaSet, userID)
+}
+
+// WipeUser removes the archive of the user and gives the user a new
+// default folder, the cached folder and rules of the user are reset.
+func (p *processor) WipeUser(ctx context.Context, event *entities.Event, userID int64) string {
+	ctx, span := tracing.Start(ctx, "processor.WipeUser")
+	defer span.End()
+
+	if err := p.user.Wipe(ctx, userID); err != nil {
+		return p.adminResult(ctx, event.Meta.Language, err)
+	}
+
+	p.fm.SetCurrentFolderID(userID, 0)
+	p.rules.Forget(userID)
+	wiped := &entities.Event{Meta: entities.Meta{UserID: userID}}
+	if err := p.fm.service.SaveDefault(ctx, wiped); err != nil {
+		logger.L(ctx).Error("failed to save default folder", logger.ErrAttr(err))
+	}
+
+	return i18n.T(event.Meta.Language, messages.UserWiped, userID)
+}
+
+func (p *processor) adminResult(ctx context.Context, lang i18n.Lang, err error) string {
+	if err == user.ErrUserNotFound {
+		return i18n.T(lang, messages.UserNotFound)
+	}
+
+	logger.L(ctx).Error("admin action failed", logger.ErrAttr(err))
+	return i18n.T(lang, messages.AdminError)
+}
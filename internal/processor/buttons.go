@@ -1,220 +1,315 @@
-package processor
-
-import (
-	"context"
-	"strconv"
-	"strings"
-
-	"archive_bot/internal/const/messages"
-	"archive_bot/internal/entities"
-
-	"archive_bot/pkg/logger"
-)
-
-func (p *processor) SelectFolder(
-	ctx context.Context,
-	event *entities.Event,
-) (map[int]*entities.AnswerParams, string) {
-	folderID, err := strconv.Atoi(strings.Split(event.Text, "_")[1])
-	if err != nil {
-		return nil, ""
-	}
-	event.FolderID = folderID
-	p.fm.SetCurrentFolderID(event.Meta.UserID, folderID)
-
-	folderName, err := p.fm.service.Find(ctx, event)
-	if err != nil {
-		return nil, ""
-	}
-	answerParamsMap, _ := p.nm.texts.AllFrom(ctx, event)
-
-	for textsID, ap := range answerParamsMap {
-		if ap.Message == "" {
-			ap.Message = messages.EmptyMessage
-		}
-		switch ap.Type {
-		case entities.Photo:
-			ap.FileIDs = p.nm.photos.FindByTextsID(ctx, textsID)
-		case entities.Document:
-			ap.FileIDs = p.nm.documents.FindByTextsID(ctx, textsID)
-		case entities.Video:
-			ap.FileIDs = p.nm.videos.FindByTextsID(ctx, textsID)
-		case entities.Audio:
-			ap.FileIDs = p.nm.audios.FindByTextsID(ctx, textsID)
-		case entities.Animation:
-			ap.FileIDs = p.nm.ani.FindByTextsID(ctx, textsID)
-		case entities.Voice:
-			ap.FileIDs = p.nm.voices.FindByTextsID(ctx, textsID)
-		}
-	}
-
-	return answerParamsMap, folderName
-}
-
-func (p *processor) RemoveNote(ctx context.Context, event *entities.Event) string {
-	log := logger.L(ctx).With(logger.String("operation", "processor.RemoveNote"))
-
-	if err := p.nm.texts.RemoveByID(ctx, event.NoteID); err != nil {
-		log.Error("", logger.ErrAttr(err))
-		return messages.Error
-	}
-	return messages.NoteRemoved
-}
-
-func (p *processor) AddFolderStart(ctx context.Context, event *entities.Event) string {
-	log := logger.L(ctx).With(logger.String("operation", "processor.AddFolderStart"))
-
-	state := p.fm.setStateCreate(event.Meta.UserID)
-	if state.MessageID == 0 {
-		state.MessageID = event.Meta.MessageID
-	}
-	switch state.FSM.Current() {
-	case StartCreate:
-		if err := state.FSM.Event(ctx, "begin"); err != nil {
-			log.Error("failed to transit state", logger.ErrAttr(err))
-			return messages.Error
-		}
-
-		return messages.AskFolderName
-	default:
-		return messages.Error
-	}
-
-}
-
-func (p *processor) AddFolderEnd(ctx context.Context, event *entities.Event) string {
-	log := logger.L(ctx).With(logger.String("operation", "processor.AddFolderEnd"))
-	state := p.fm.stateCreate(event.Meta.UserID)
-	if state == nil {
-		return ""
-	}
-	switch state.FSM.Current() {
-	case SelectCreate:
-		if err := state.FSM.Event(ctx, "provide_name"); err != nil {
-			log.Error("failed to transit state", logger.ErrAttr(err))
-			return messages.Error
-		}
-
-		event.Meta.MessageID = state.MessageID
-		state.MessageID = 0
-
-		return p.fm.service.Save(ctx, event)
-	default:
-		return ""
-	}
-}
-
-func (p *processor) DeleteFolderStart(ctx context.Context, event *entities.Event) string {
-	log := logger.L(ctx).With(logger.String("operation", "processor.DeleteFolderStart"))
-
-	state := p.fm.setStateDelete(event.Meta.UserID)
-
-	switch state.FSM.Current() {
-	case StartDelete:
-		if err := state.FSM.Event(ctx, "begin"); err != nil {
-			log.Error("failed to transit state", logger.ErrAttr(err))
-			return messages.Error
-		}
-
-		return messages.ChooseFolderToDelete
-	default:
-		return ""
-	}
-
-}
-
-func (p *processor) DeleteFolderEnd(ctx context.Context, event *entities.Event) string {
-	log := logger.L(ctx).With(logger.String("operation", "processor.DeleteFolderEnd"))
-
-	state := p.fm.stateDelete(event.Meta.UserID)
-	if state == nil {
-		return ""
-	}
-
-	switch state.FSM.Current() {
-	case SelectDelete:
-		if err := state.FSM.Event(ctx, "provide_name"); err != nil {
-			log.Error("failed to transit state", logger.ErrAttr(err))
-			return messages.Error
-		}
-
-		id, err := strconv.Atoi(strings.Split(event.Text, "_")[1])
-		if err != nil {
-			log.Error("failed to transit state", logger.ErrAttr(err))
-			return messages.FolderNotExists
-		}
-
-		if id == p.fm.service.DefaultFolderID(ctx, event.Meta.UserID) {
-			return messages.WrongFolder
-		}
-
-		if err := p.fm.service.RemoveByID(ctx, id); err != nil {
-			log.Error("failed to transit state", logger.ErrAttr(err))
-			return messages.FolderNotExists
-		}
-
-		return messages.FolderDeleted
-	default:
-		return ""
-	}
-}
-
-func (p *processor) MoveNoteStart(ctx context.Context, event *entities.Event) string {
-	log := logger.L(ctx).With(logger.String("operation", "processor.MoveNoteStart"))
-
-	log.Debug("",
-		logger.Int64("UserID", event.Meta.UserID),
-		logger.Int("FolderID", event.FolderID),
-		logger.Int("NoteID", event.NoteID),
-	)
-	state := p.nm.MoveState(event.Meta.UserID, event)
-	if state.ParentFolderID == 0 && state.NoteID == 0 {
-		state.ParentFolderID = event.FolderID
-		state.NoteID = event.NoteID
-	}
-
-	switch state.FSM.Current() {
-	case StartMove:
-		if err := state.FSM.Event(ctx, "begin"); err != nil {
-			log.Error("failed to transit state", logger.ErrAttr(err))
-			return messages.Error
-		}
-
-		return messages.ChooseFolderToMove
-	default:
-		return ""
-	}
-}
-
-func (p *processor) MoveNoteEnd(ctx context.Context, event *entities.Event) string {
-	log := logger.L(ctx).With(logger.String("operation", "processor.MoveNoteEnd"))
-
-	state := p.nm.MoveState(event.Meta.UserID, event)
-
-	switch state.FSM.Current() {
-	case SelectMove:
-		if err := state.FSM.Event(ctx, "provide_ID"); err != nil {
-			log.Error("failed to transit state", logger.ErrAttr(err))
-			return messages.Error
-		}
-
-		log.Debug("",
-			logger.Int64("UserID", event.Meta.UserID),
-			logger.Int("FolderID", event.FolderID),
-			logger.Int("ParentFolderID", state.ParentFolderID),
-			logger.Int("NoteID", state.NoteID),
-		)
-
-		event.NoteID = state.NoteID
-		event.FolderID, _ = strconv.Atoi(strings.Split(event.Text, "_")[1])
-		message := p.nm.texts.Move(ctx, event)
-		event.FolderID = state.ParentFolderID
-
-		state.ParentFolderID = 0
-		state.NoteID = 0
-
-		return message
-	default:
-		return ""
-	}
-}
+package processor
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/user"
+
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+func (p *processor) SelectFolder(
+	ctx context.Context,
+	event *entities.Event,
+) (map[int]*entities.AnswerParams, string) {
+	ctx, span := tracing.Start(ctx, "processor.SelectFolder")
+	defer span.End()
+
+	folderID, err := strconv.Atoi(strings.Split(event.Text, "_")[1])
+	if err != nil {
+		return nil, ""
+	}
+	event.FolderID = folderID
+	p.fm.SetCurrentFolderID(event.Meta.UserID, folderID)
+
+	folderName, err := p.fm.service.Find(ctx, event)
+	if err != nil {
+		return nil, ""
+	}
+	answerParamsMap, _ := p.nm.texts.AllFrom(ctx, event)
+	p.fillNotes(ctx, answerParamsMap)
+
+	return answerParamsMap, folderName
+}
+
+// Favorites returns the favorite notes of the user from all the folders.
+func (p *processor) Favorites(ctx context.Context, event *entities.Event) map[int]*entities.AnswerParams {
+	ctx, span := tracing.Start(ctx, "processor.Favorites")
+	defer span.End()
+
+	answerParamsMap := p.nm.texts.Favorites(ctx, event)
+	p.fillNotes(ctx, answerParamsMap)
+
+	return answerParamsMap
+}
+
+// ToggleFavorite adds the note to the favorites or removes it from them.
+// It returns whether the note is a favorite now and the result.
+func (p *processor) ToggleFavorite(ctx context.Context, event *entities.Event) (bool, entities.Result) {
+	ctx, span := tracing.Start(ctx, "processor.ToggleFavorite")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.ToggleFavorite"))
+
+	favorite, err := p.nm.texts.ToggleFavorite(ctx, event)
+	if err != nil {
+		log.Error("", logger.ErrAttr(err))
+		return false, entities.Result{Status: entities.StatusFailed, Text: i18n.T(event.Meta.Language, messages.Error)}
+	}
+	if favorite {
+		return true, entities.Result{Status: entities.StatusDone, Text: i18n.T(event.Meta.Language, messages.FavoriteAdded)}
+	}
+	return false, entities.Result{Status: entities.StatusDone, Text: i18n.T(event.Meta.Language, messages.FavoriteRemoved)}
+}
+
+// fillNotes sets the media of the notes and the placeholder of the empty ones.
+func (p *processor) fillNotes(ctx context.Context, notes map[int]*entities.AnswerParams) {
+	for textsID, ap := range notes {
+		if ap.Message == "" {
+			ap.Message = messages.EmptyMessage
+		}
+		ap.FileIDs = p.NoteFiles(ctx, ap.Type, textsID)
+	}
+}
+
+// NoteFiles returns the file IDs of the note media.
+func (p *processor) NoteFiles(ctx context.Context, noteType entities.Type, textsID int) []string {
+	ctx, span := tracing.Start(ctx, "processor.NoteFiles")
+	defer span.End()
+
+	switch noteType {
+	case entities.Photo:
+		return p.nm.photos.FindByTextsID(ctx, textsID)
+	case entities.Document:
+		return p.nm.documents.FindByTextsID(ctx, textsID)
+	case entities.Video:
+		return p.nm.videos.FindByTextsID(ctx, textsID)
+	case entities.Audio:
+		return p.nm.audios.FindByTextsID(ctx, textsID)
+	case entities.Animation:
+		return p.nm.ani.FindByTextsID(ctx, textsID)
+	case entities.Voice:
+		return p.nm.voices.FindByTextsID(ctx, textsID)
+	}
+
+	return nil
+}
+
+func (p *processor) RemoveNote(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "processor.RemoveNote")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.RemoveNote"))
+
+	if err := p.nm.texts.RemoveByID(ctx, event.NoteID); err != nil {
+		log.Error("", logger.ErrAttr(err))
+		return i18n.T(event.Meta.Language, messages.Error)
+	}
+	return i18n.T(event.Meta.Language, messages.NoteRemoved)
+}
+
+func (p *processor) AddFolderStart(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "processor.AddFolderStart")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.AddFolderStart"))
+
+	state := p.fm.setStateCreate(event.Meta.UserID)
+	if state.MessageID == 0 {
+		state.MessageID = event.Meta.MessageID
+	}
+	switch state.FSM.Current() {
+	case StartCreate:
+		if err := state.FSM.Event(ctx, "begin"); err != nil {
+			log.Error("failed to transit state", logger.ErrAttr(err))
+			return i18n.T(event.Meta.Language, messages.Error)
+		}
+
+		return i18n.T(event.Meta.Language, messages.AskFolderName)
+	default:
+		return i18n.T(event.Meta.Language, messages.Error)
+	}
+
+}
+
+func (p *processor) AddFolderEnd(ctx context.Context, event *entities.Event) entities.Result {
+	ctx, span := tracing.Start(ctx, "processor.AddFolderEnd")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.AddFolderEnd"))
+	state := p.fm.stateCreate(event.Meta.UserID)
+	if state == nil {
+		return entities.Result{}
+	}
+	switch state.FSM.Current() {
+	case SelectCreate:
+		if err := state.FSM.Event(ctx, "provide_name"); err != nil {
+			log.Error("failed to transit state", logger.ErrAttr(err))
+			return entities.Result{Status: entities.StatusFailed, Text: i18n.T(event.Meta.Language, messages.Error)}
+		}
+
+		event.Meta.MessageID = state.MessageID
+		state.MessageID = 0
+
+		if message := p.checkFolders(ctx, event); message != "" {
+			return entities.Result{Status: entities.StatusRejected, Text: message}
+		}
+
+		return entities.Result{
+			Status: entities.StatusDone,
+			Text:   i18n.T(event.Meta.Language, p.fm.service.Save(ctx, event)),
+		}
+	default:
+		return entities.Result{}
+	}
+}
+
+// checkFolders returns the message for the user who can't create one more
+// folder. The folder is created when the limits can't be checked.
+func (p *processor) checkFolders(ctx context.Context, event *entities.Event) string {
+	err := p.user.CheckFolders(ctx, event.Meta.UserID)
+	switch err {
+	case nil:
+		return ""
+	case user.ErrQuotaFolders:
+		return i18n.T(event.Meta.Language, messages.QuotaFolders)
+	default:
+		logger.L(ctx).Error("failed to check folders quota", logger.ErrAttr(err))
+		return ""
+	}
+}
+
+func (p *processor) DeleteFolderStart(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "processor.DeleteFolderStart")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.DeleteFolderStart"))
+
+	state := p.fm.setStateDelete(event.Meta.UserID)
+
+	switch state.FSM.Current() {
+	case StartDelete:
+		if err := state.FSM.Event(ctx, "begin"); err != nil {
+			log.Error("failed to transit state", logger.ErrAttr(err))
+			return i18n.T(event.Meta.Language, messages.Error)
+		}
+
+		return i18n.T(event.Meta.Language, messages.ChooseFolderToDelete)
+	default:
+		return ""
+	}
+
+}
+
+func (p *processor) DeleteFolderEnd(ctx context.Context, event *entities.Event) entities.Result {
+	ctx, span := tracing.Start(ctx, "processor.DeleteFolderEnd")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.DeleteFolderEnd"))
+
+	state := p.fm.stateDelete(event.Meta.UserID)
+	if state == nil {
+		return entities.Result{}
+	}
+
+	switch state.FSM.Current() {
+	case SelectDelete:
+		if err := state.FSM.Event(ctx, "provide_name"); err != nil {
+			log.Error("failed to transit state", logger.ErrAttr(err))
+			return entities.Result{Status: entities.StatusFailed, Text: i18n.T(event.Meta.Language, messages.Error)}
+		}
+
+		id, err := strconv.Atoi(strings.Split(event.Text, "_")[1])
+		if err != nil {
+			log.Error("failed to transit state", logger.ErrAttr(err))
+			return entities.Result{Status: entities.StatusFailed, Text: i18n.T(event.Meta.Language, messages.FolderNotExists)}
+		}
+
+		if id == p.fm.service.DefaultFolderID(ctx, event.Meta.UserID) {
+			return entities.Result{Status: entities.StatusRejected, Text: i18n.T(event.Meta.Language, messages.WrongFolder)}
+		}
+
+		if err := p.fm.service.RemoveByID(ctx, id); err != nil {
+			log.Error("failed to transit state", logger.ErrAttr(err))
+			return entities.Result{Status: entities.StatusFailed, Text: i18n.T(event.Meta.Language, messages.FolderNotExists)}
+		}
+		// The rules of the folder are removed with it.
+		p.rules.Forget(event.Meta.UserID)
+
+		return entities.Result{Status: entities.StatusDone, Text: i18n.T(event.Meta.Language, messages.FolderDeleted)}
+	default:
+		return entities.Result{}
+	}
+}
+
+func (p *processor) MoveNoteStart(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "processor.MoveNoteStart")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.MoveNoteStart"))
+
+	log.Debug("",
+		logger.Int64("UserID", event.Meta.UserID),
+		logger.Int("FolderID", event.FolderID),
+		logger.Int("NoteID", event.NoteID),
+	)
+	state := p.nm.MoveState(event.Meta.UserID, event)
+	if state.ParentFolderID == 0 && state.NoteID == 0 {
+		state.ParentFolderID = event.FolderID
+		state.NoteID = event.NoteID
+	}
+
+	switch state.FSM.Current() {
+	case StartMove:
+		if err := state.FSM.Event(ctx, "begin"); err != nil {
+			log.Error("failed to transit state", logger.ErrAttr(err))
+			return i18n.T(event.Meta.Language, messages.Error)
+		}
+
+		return i18n.T(event.Meta.Language, messages.ChooseFolderToMove)
+	default:
+		return ""
+	}
+}
+
+func (p *processor) MoveNoteEnd(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "processor.MoveNoteEnd")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.MoveNoteEnd"))
+
+	state := p.nm.MoveState(event.Meta.UserID, event)
+
+	switch state.FSM.Current() {
+	case SelectMove:
+		if err := state.FSM.Event(ctx, "provide_ID"); err != nil {
+			log.Error("failed to transit state", logger.ErrAttr(err))
+			return i18n.T(event.Meta.Language, messages.Error)
+		}
+
+		log.Debug("",
+			logger.Int64("UserID", event.Meta.UserID),
+			logger.Int("FolderID", event.FolderID),
+			logger.Int("ParentFolderID", state.ParentFolderID),
+			logger.Int("NoteID", state.NoteID),
+		)
+
+		event.NoteID = state.NoteID
+		event.FolderID, _ = strconv.Atoi(strings.Split(event.Text, "_")[1])
+		message := p.nm.texts.Move(ctx, event)
+		event.FolderID = state.ParentFolderID
+
+		state.ParentFolderID = 0
+		state.NoteID = 0
+
+		return i18n.T(event.Meta.Language, message)
+	default:
+		return ""
+	}
+}
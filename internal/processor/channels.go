@@ -0,0 +1,133 @@
+package processor
+
+import (
+	"context"
+
+	"archive_bot/internal/channel"
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+func (p *processor) LinkChannel(
+	ctx context.Context,
+	event *entities.Event,
+	channelID int64,
+	title string,
+	username string,
+) string {
+	ctx, span := tracing.Start(ctx, "processor.LinkChannel")
+	defer span.End()
+
+	folderID := p.fm.service.DefaultFolderID(ctx, event.Meta.UserID)
+	if event.FolderName != "" {
+		var message string
+		if folderID, message = p.createFolder(ctx, event); message != "" {
+			return message
+		}
+	}
+
+	if err := p.channels.Link(ctx, &channel.Channel{
+		ID:       channelID,
+		UserID:   event.Meta.UserID,
+		FolderID: folderID,
+		Title:    title,
+		Username: username,
+	}); err != nil {
+		return i18n.T(event.Meta.Language, messages.Error)
+	}
+
+	return i18n.T(event.Meta.Language, messages.ChannelLinked)
+}
+
+func (p *processor) UnlinkChannel(ctx context.Context, event *entities.Event, channelID int64) string {
+	ctx, span := tracing.Start(ctx, "processor.UnlinkChannel")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.UnlinkChannel"))
+
+	if err := p.channels.Unlink(ctx, event.Meta.UserID, channelID); err != nil {
+		if err == channel.ErrNoChannel {
+			return i18n.T(event.Meta.Language, messages.ChannelNotFound)
+		}
+		log.Error("failed to unlink channel", logger.ErrAttr(err))
+		return i18n.T(event.Meta.Language, messages.Error)
+	}
+
+	return i18n.T(event.Meta.Language, messages.ChannelUnlinked)
+}
+
+// SaveChannelPost saves the post into the folder the channel is linked to.
+// Posts of channels which are not linked are ignored.
+func (p *processor) SaveChannelPost(ctx context.Context, event *entities.Event) {
+	ctx, span := tracing.Start(ctx, "processor.SaveChannelPost")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.SaveChannelPost"))
+
+	channelID, messageID := event.Meta.ChatID, event.Meta.MessageID
+	c, err := p.channels.Find(ctx, channelID)
+	if err != nil {
+		if err != channel.ErrNoChannel {
+			log.Error("failed to find channel", logger.ErrAttr(err))
+		}
+		return
+	}
+
+	if p.user.IsBanned(ctx, c.UserID) {
+		return
+	}
+
+	event.Meta.UserID = c.UserID
+	event.Meta.ChatID = c.UserID
+	event.FolderID = c.FolderID
+
+	p.saveNote(ctx, event)
+	if event.NoteID != 0 {
+		p.channels.AddPost(ctx, channelID, messageID, event.NoteID)
+	}
+}
+
+// EditChannelPost updates the note the edited post was saved as.
+// An empty event text keeps the description, media of albums is kept as well.
+func (p *processor) EditChannelPost(ctx context.Context, event *entities.Event) {
+	ctx, span := tracing.Start(ctx, "processor.EditChannelPost")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.EditChannelPost"))
+
+	event.NoteID = p.channels.PostNoteID(ctx, event.Meta.ChatID, event.Meta.MessageID)
+	if event.NoteID == 0 {
+		return
+	}
+
+	if event.Text != "" {
+		p.nm.texts.UpdateByID(ctx, event)
+	}
+
+	if event.MediaGroupID != "" || event.FileID == "" {
+		return
+	}
+
+	var err error
+	switch event.Type {
+	case entities.Photo:
+		err = p.nm.photos.UpdateByTextsID(ctx, event)
+	case entities.Document:
+		err = p.nm.documents.UpdateByTextsID(ctx, event)
+	case entities.Video:
+		err = p.nm.videos.UpdateByTextsID(ctx, event)
+	case entities.Audio:
+		err = p.nm.audios.UpdateByTextsID(ctx, event)
+	case entities.Animation:
+		err = p.nm.ani.UpdateByTextsID(ctx, event)
+	case entities.Voice:
+		err = p.nm.voices.UpdateByTextsID(ctx, event)
+	}
+	if err != nil {
+		log.Error("failed to update media", logger.ErrAttr(err))
+	}
+}
@@ -1,103 +1,316 @@
-package processor
-
-import (
-	"cmp"
-	"context"
-	"time"
-
-	"archive_bot/internal/const/buttons"
-	"archive_bot/internal/const/messages"
-	"archive_bot/internal/entities"
-	"archive_bot/pkg/logger"
-)
-
-func (p *processor) Start(ctx context.Context, event *entities.Event) (string, string) {
-	log := p.log.With(logger.String("operation", "processor.Start"))
-
-	if p.fm.service.DefaultFolderID(ctx, event.Meta.UserID) == 0 {
-		if err := p.fm.service.SaveDefault(ctx, event); err != nil {
-			log.Error("save default folder error", logger.ErrAttr(err))
-		}
-	}
-
-	return messages.StartCommand, buttons.Folders
-}
-
-func (p *processor) Folders(ctx context.Context, event *entities.Event) map[string]string {
-	// log := p.log.With(logger.String("operation", "processor.Folders"))
-	p.fm.SetCurrentFolderID(event.Meta.UserID, event.FolderID)
-
-	return p.fm.service.All(ctx, event)
-}
-
-func (p *processor) Save(ctx context.Context, event *entities.Event) *entities.AnswerParams {
-	// log := p.log.With(logger.String("operation", "processor.Save"))
-
-	event.FolderID = cmp.Or(
-		p.fm.CurrentFolderID(event.Meta.UserID),
-		p.fm.service.DefaultFolderID(ctx, event.Meta.UserID),
-	)
-
-	noteID, message := p.nm.texts.Save(ctx, event)
-	event.NoteID = noteID
-	ap := entities.AnswerParams{Message: message}
-	switch event.Type {
-	case entities.Photo:
-		p.nm.photos.Save(ctx, event)
-	case entities.Document:
-		p.nm.documents.Save(ctx, event)
-	case entities.Video:
-		p.nm.videos.Save(ctx, event)
-	case entities.Audio:
-		p.nm.audios.Save(ctx, event)
-	case entities.Animation:
-		p.nm.ani.Save(ctx, event)
-	case entities.Voice:
-		p.nm.voices.Save(ctx, event)
-	}
-
-	return &ap
-}
-
-func (p *processor) SaveTo(ctx context.Context, event *entities.Event) string {
-	log := p.log.With(logger.String("operation", "processor.SaveTo"))
-	folderID, err := p.fm.service.FindOrCreate(ctx, event)
-	if err != nil {
-		log.Error(
-			"failed to find or create folder",
-			logger.String("event", event.String()),
-			logger.ErrAttr(err),
-		)
-		return messages.Error
-	}
-
-	_, createdAt := p.nm.texts.FindLast(ctx, event)
-	log.Debug(
-		createdAt.String(),
-		logger.Bool("createdAt", createdAt.IsZero()),
-	)
-	if createdAt.IsZero() {
-		log.Debug(
-			"last note is not exists",
-			logger.String("event", event.String()),
-		)
-	} else if event.Meta.Date.Sub(createdAt) < 1*time.Second {
-		log.Debug(
-			"last note is exists",
-			logger.String("event", event.String()),
-		)
-		event.FolderID = folderID
-		return p.nm.texts.MoveLast(ctx, event)
-	}
-
-	p.fm.SetCurrentFolderID(event.Meta.UserID, folderID)
-	event.FolderID = folderID
-	log.Debug(
-		"SaveTo",
-		logger.Int("event.FolderID", event.FolderID),
-		logger.Int("CurrentFolderID", p.fm.CurrentFolderID(event.Meta.UserID)),
-		logger.Bool("Meta.Date - createdAt", event.Meta.Date.Sub(createdAt) < 1*time.Second),
-	)
-
-	return messages.Moved
-}
+package processor
+
+import (
+	"cmp"
+	"context"
+	"strings"
+	"time"
+
+	"archive_bot/internal/const/buttons"
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/folder"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/metrics"
+	"archive_bot/internal/rule"
+	"archive_bot/internal/user"
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+func (p *processor) Start(ctx context.Context, event *entities.Event) (string, string) {
+	ctx, span := tracing.Start(ctx, "processor.Start")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.Start"))
+
+	if p.fm.service.DefaultFolderID(ctx, event.Meta.UserID) == 0 {
+		if err := p.fm.service.SaveDefault(ctx, event); err != nil {
+			log.Error("save default folder error", logger.ErrAttr(err))
+		}
+	}
+
+	return i18n.T(event.Meta.Language, messages.StartCommand), buttons.Folders
+}
+
+func (p *processor) Folders(ctx context.Context, event *entities.Event) []entities.Button {
+	ctx, span := tracing.Start(ctx, "processor.Folders")
+	defer span.End()
+
+	// log := logger.L(ctx).With(logger.String("operation", "processor.Folders"))
+	p.fm.SetCurrentFolderID(event.Meta.UserID, event.FolderID)
+
+	return p.fm.service.All(ctx, event)
+}
+
+func (p *processor) Save(ctx context.Context, event *entities.Event) *entities.AnswerParams {
+	ctx, span := tracing.Start(ctx, "processor.Save")
+	defer span.End()
+
+	// log := logger.L(ctx).With(logger.String("operation", "processor.Save"))
+
+	// The folder named in the message wins over the rules, the note with
+	// a mistyped or a new name waits in the current folder for the answer.
+	requestedID, suggestion := p.requestedFolder(ctx, event)
+	var r *rule.Rule
+	var number int
+	if requestedID == 0 && suggestion == nil {
+		r, number = p.rules.Match(ctx, event.Meta.UserID, rule.Note{
+			Type:   event.Type,
+			Text:   event.Text,
+			Origin: event.Origin,
+		})
+	}
+	ruleFolderID := 0
+	if r != nil {
+		ruleFolderID = r.FolderID
+	}
+
+	event.FolderID = cmp.Or(
+		requestedID,
+		ruleFolderID,
+		p.fm.CurrentFolderID(event.Meta.UserID),
+		p.fm.service.DefaultFolderID(ctx, event.Meta.UserID),
+	)
+
+	ap := p.saveNote(ctx, event)
+	if r != nil && event.NoteID != 0 {
+		p.applyRule(ctx, event, r, number, ap)
+	}
+	if suggestion != nil && event.NoteID != 0 {
+		suggestion.NoteID = event.NoteID
+		p.fm.setSuggestion(event.Meta.UserID, suggestion)
+	}
+
+	return ap
+}
+
+// saveNote saves the note and its media into event.FolderID.
+// The note is not saved when the user is out of the limits.
+func (p *processor) saveNote(ctx context.Context, event *entities.Event) *entities.AnswerParams {
+	if message := p.checkNote(ctx, event); message != "" {
+		return &entities.AnswerParams{Message: message}
+	}
+
+	noteID, message := p.nm.texts.Save(ctx, event)
+	event.NoteID = noteID
+	if noteID != 0 {
+		metrics.NoteSaved(event.Type.String())
+		if event.FileSize > 0 {
+			p.user.SetMediaSize(ctx, noteID, event.FileSize)
+		}
+	}
+	ap := entities.AnswerParams{Message: i18n.T(event.Meta.Language, message)}
+	switch event.Type {
+	case entities.Photo:
+		p.nm.photos.Save(ctx, event)
+	case entities.Document:
+		p.nm.documents.Save(ctx, event)
+	case entities.Video:
+		p.nm.videos.Save(ctx, event)
+	case entities.Audio:
+		p.nm.audios.Save(ctx, event)
+	case entities.Animation:
+		p.nm.ani.Save(ctx, event)
+	case entities.Voice:
+		p.nm.voices.Save(ctx, event)
+	}
+	p.transcribe(ctx, event)
+
+	return &ap
+}
+
+// checkNote returns the message for the user out of the limits. The note
+// is saved when the limits can't be checked.
+func (p *processor) checkNote(ctx context.Context, event *entities.Event) string {
+	return p.quotaMessage(ctx, event.Meta.Language, p.user.CheckNote(ctx, event.Meta.UserID, event.FileSize))
+}
+
+// quotaMessage returns the message for the quota error, an empty one when
+// the limits are not exceeded or can't be checked.
+func (p *processor) quotaMessage(ctx context.Context, lang i18n.Lang, err error) string {
+	switch err {
+	case nil:
+		return ""
+	case user.ErrQuotaNotes:
+		return i18n.T(lang, messages.QuotaNotes)
+	case user.ErrQuotaDaily:
+		return i18n.T(lang, messages.QuotaDaily)
+	case user.ErrQuotaMedia:
+		return i18n.T(lang, messages.QuotaMedia)
+	default:
+		logger.L(ctx).Error("failed to check limits", logger.ErrAttr(err))
+		return ""
+	}
+}
+
+// requestedFolder returns the ID of the folder named in the event when
+// the name or an alias of the folder matches it exactly. Otherwise the name
+// is dropped from the event and the suggestion offers the folder looking
+// like it or a new folder, the folder is not created until the user
+// chooses it.
+func (p *processor) requestedFolder(ctx context.Context, event *entities.Event) (int, *folder.Suggestion) {
+	if event.FolderName == "" {
+		return 0, nil
+	}
+
+	f, exact, err := p.fm.service.Resolve(ctx, event.Meta.UserID, event.FolderName)
+	if err != nil {
+		logger.L(ctx).Error("failed to resolve folder", logger.ErrAttr(err))
+		return 0, nil
+	}
+	if exact {
+		return f.ID, nil
+	}
+
+	s := &folder.Suggestion{Name: event.FolderName, Folder: f}
+	event.FolderName = ""
+	return 0, s
+}
+
+// FolderSuggestion returns the folder offered to the user for the mistyped
+// name, nil if there is none.
+func (p *processor) FolderSuggestion(ctx context.Context, event *entities.Event) *folder.Suggestion {
+	return p.fm.suggestion(event.Meta.UserID)
+}
+
+// AcceptSuggestion moves the note into the offered folder with the ID or
+// into the new folder with the typed name. The folder is opened if there
+// is no note to move.
+func (p *processor) AcceptSuggestion(ctx context.Context, event *entities.Event, id int, create bool) string {
+	ctx, span := tracing.Start(ctx, "processor.AcceptSuggestion")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.AcceptSuggestion"))
+
+	userID := event.Meta.UserID
+	s := p.fm.suggestion(userID)
+	if s == nil || s.FolderID() != id || (s.Folder == nil && !create) {
+		return i18n.T(event.Meta.Language, messages.FolderSuggestionExpired)
+	}
+
+	folderID := id
+	if create {
+		if message := p.checkFolders(ctx, event); message != "" {
+			return message
+		}
+		var err error
+		if folderID, err = p.fm.service.FindOrCreateByName(ctx, userID, s.Name); err != nil {
+			log.Error("failed to create folder", logger.ErrAttr(err))
+			return i18n.T(event.Meta.Language, messages.Error)
+		}
+	}
+	p.fm.setSuggestion(userID, nil)
+	event.FolderID = folderID
+
+	if s.NoteID == 0 {
+		p.fm.SetCurrentFolderID(userID, folderID)
+		return i18n.T(event.Meta.Language, messages.Moved)
+	}
+	if _, err := p.nm.texts.MoveMany(ctx, userID, []int{s.NoteID}, folderID); err != nil {
+		log.Error("failed to move note", logger.ErrAttr(err))
+		return i18n.T(event.Meta.Language, messages.Error)
+	}
+
+	return i18n.T(event.Meta.Language, messages.Moved)
+}
+
+// createFolder returns the ID of the folder named in the event, creating
+// the folder within the folders quota if no folder has the name. The
+// message is for the user when the folder is not created.
+func (p *processor) createFolder(ctx context.Context, event *entities.Event) (int, string) {
+	log := logger.L(ctx).With(logger.String("operation", "processor.createFolder"))
+
+	f, exact, err := p.fm.service.Resolve(ctx, event.Meta.UserID, event.FolderName)
+	if err != nil {
+		log.Error("failed to resolve folder", logger.ErrAttr(err))
+		return 0, i18n.T(event.Meta.Language, messages.Error)
+	}
+	if exact {
+		return f.ID, ""
+	}
+	if message := p.checkFolders(ctx, event); message != "" {
+		return 0, message
+	}
+
+	folderID, err := p.fm.service.FindOrCreateByName(ctx, event.Meta.UserID, event.FolderName)
+	if err != nil {
+		log.Error(
+			"failed to find or create folder",
+			logger.String("event", event.String()),
+			logger.ErrAttr(err),
+		)
+		return 0, i18n.T(event.Meta.Language, messages.Error)
+	}
+
+	return folderID, ""
+}
+
+// SaveTo moves the note saved just before into the folder named in the
+// event or opens the folder. The mistyped name is suggested, the result
+// has no text then.
+func (p *processor) SaveTo(ctx context.Context, event *entities.Event) entities.Result {
+	ctx, span := tracing.Start(ctx, "processor.SaveTo")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.SaveTo"))
+	f, exact, err := p.fm.service.Resolve(ctx, event.Meta.UserID, event.Text)
+	if err != nil {
+		log.Error("failed to resolve folder", logger.ErrAttr(err))
+		return entities.Result{Status: entities.StatusFailed, Text: i18n.T(event.Meta.Language, messages.Error)}
+	}
+	if f != nil && !exact {
+		s := &folder.Suggestion{Name: strings.TrimSpace(event.Text), Folder: f}
+		noteID, createdAt := p.nm.texts.FindLast(ctx, event)
+		if !createdAt.IsZero() && event.Meta.Date.Sub(createdAt) < 1*time.Second {
+			s.NoteID = noteID
+		}
+		p.fm.setSuggestion(event.Meta.UserID, s)
+		return entities.Result{Status: entities.StatusSuggested}
+	}
+	if f == nil {
+		if message := p.checkFolders(ctx, event); message != "" {
+			return entities.Result{Status: entities.StatusRejected, Text: message}
+		}
+	}
+
+	folderID, err := p.fm.service.FindOrCreate(ctx, event)
+	if err != nil {
+		log.Error(
+			"failed to find or create folder",
+			logger.String("event", event.String()),
+			logger.ErrAttr(err),
+		)
+		return entities.Result{Status: entities.StatusFailed, Text: i18n.T(event.Meta.Language, messages.Error)}
+	}
+
+	_, createdAt := p.nm.texts.FindLast(ctx, event)
+	log.Debug(
+		createdAt.String(),
+		logger.Bool("createdAt", createdAt.IsZero()),
+	)
+	if createdAt.IsZero() {
+		log.Debug(
+			"last note is not exists",
+			logger.String("event", event.String()),
+		)
+	} else if event.Meta.Date.Sub(createdAt) < 1*time.Second {
+		log.Debug(
+			"last note is exists",
+			logger.String("event", event.String()),
+		)
+		event.FolderID = folderID
+		return entities.Result{Status: entities.StatusDone, Text: i18n.T(event.Meta.Language, p.nm.texts.MoveLast(ctx, event))}
+	}
+
+	p.fm.SetCurrentFolderID(event.Meta.UserID, folderID)
+	event.FolderID = folderID
+	log.Debug(
+		"SaveTo",
+		logger.Int("event.FolderID", event.FolderID),
+		logger.Int("CurrentFolderID", p.fm.CurrentFolderID(event.Meta.UserID)),
+		logger.Bool("Meta.Date - createdAt", event.Meta.Date.Sub(createdAt) < 1*time.Second),
+	)
+
+	return entities.Result{Status: entities.StatusDone, Text: i18n.T(event.Meta.Language, messages.Moved)}
+}
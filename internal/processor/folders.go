@@ -0,0 +1,296 @@
+package processor
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"archive_bot/internal/const/buttons"
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/folder"
+	"archive_bot/internal/i18n"
+
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+var editPrompts = map[string]string{
+	folder.FieldName:      messages.AskFolderName,
+	folder.FieldIcon:      messages.AskFolderIcon,
+	folder.FieldPosition:  messages.AskFolderPosition,
+	folder.FieldSplitDate: messages.AskSplitDate,
+	folder.FieldAliases:   messages.AskFolderAliases,
+}
+
+// FolderList returns all the folders of the user in their order, the hidden
+// ones too.
+func (p *processor) FolderList(ctx context.Context, event *entities.Event) []*folder.Folder {
+	ctx, span := tracing.Start(ctx, "processor.FolderList")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.FolderList"))
+
+	folders, err := p.fm.service.List(ctx, event.Meta.UserID)
+	if err != nil {
+		log.Error("", logger.ErrAttr(err))
+		return nil
+	}
+
+	return folders
+}
+
+// FolderSettings returns the folder of the user, nil if there is no such folder.
+func (p *processor) FolderSettings(ctx context.Context, event *entities.Event, id int) *folder.Folder {
+	ctx, span := tracing.Start(ctx, "processor.FolderSettings")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.FolderSettings"))
+
+	f, err := p.fm.service.Get(ctx, event.Meta.UserID, id)
+	if err != nil {
+		if err != folder.ErrNoFolder {
+			log.Error("", logger.ErrAttr(err))
+		}
+		return nil
+	}
+
+	return f
+}
+
+// EditFolderStart asks for the new value of the setting of the folder.
+func (p *processor) EditFolderStart(ctx context.Context, event *entities.Event, id int, field string) string {
+	ctx, span := tracing.Start(ctx, "processor.EditFolderStart")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.EditFolderStart"))
+
+	prompt, ok := editPrompts[field]
+	if !ok {
+		return i18n.T(event.Meta.Language, messages.UnknownCommand)
+	}
+
+	f := p.FolderSettings(ctx, event, id)
+	if f == nil {
+		return i18n.T(event.Meta.Language, messages.FolderNotExists)
+	}
+	if f.IsDefault() && field == folder.FieldName {
+		return i18n.T(event.Meta.Language, messages.FolderLocked)
+	}
+
+	state := p.fm.setStateEdit(event.Meta.UserID, id, field)
+	if err := state.FSM.Event(ctx, "begin"); err != nil {
+		log.Error("failed to transit state", logger.ErrAttr(err))
+		return i18n.T(event.Meta.Language, messages.Error)
+	}
+
+	return i18n.T(event.Meta.Language, prompt)
+}
+
+// EditFolderEnd sets the setting asked by EditFolderStart to the text of the
+// event. It returns the empty message if no setting is asked, the edited
+// folder is set to the event.
+func (p *processor) EditFolderEnd(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "processor.EditFolderEnd")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.EditFolderEnd"))
+
+	state := p.fm.stateEdit(event.Meta.UserID)
+	if state == nil || state.FSM.Current() != SelectEdit {
+		return ""
+	}
+	if err := state.FSM.Event(ctx, "provide_value"); err != nil {
+		log.Error("failed to transit state", logger.ErrAttr(err))
+		return i18n.T(event.Meta.Language, messages.Error)
+	}
+	event.FolderID = state.FolderID
+
+	var (
+		err     error
+		success string
+	)
+	userID := event.Meta.UserID
+	switch state.Field {
+	case folder.FieldName:
+		err, success = p.fm.service.Rename(ctx, userID, state.FolderID, event.Text), messages.FolderRenamed
+	case folder.FieldIcon:
+		err, success = p.fm.service.SetIcon(ctx, userID, state.FolderID, event.Text), messages.FolderIconSet
+	case folder.FieldPosition:
+		position, convErr := strconv.Atoi(strings.TrimSpace(event.Text))
+		if convErr != nil {
+			return i18n.T(event.Meta.Language, messages.FolderPositionInvalid)
+		}
+		err, success = p.fm.service.Move(ctx, userID, state.FolderID, position), messages.FolderMoved
+	case folder.FieldAliases:
+		err, success = p.fm.service.SetAliases(ctx, userID, state.FolderID, event.Text), messages.FolderAliasesSet
+	case folder.FieldSplitDate:
+		before, parseErr := time.Parse(time.DateOnly, strings.TrimSpace(event.Text))
+		if parseErr != nil {
+			return i18n.T(event.Meta.Language, messages.FolderSplitDateInvalid)
+		}
+		_, message := p.SplitFolder(ctx, event, state.FolderID, folder.SplitBy{Before: before})
+		return message
+	default:
+		return i18n.T(event.Meta.Language, messages.Error)
+	}
+	if err == nil && state.Field == folder.FieldName {
+		// the rules show the name of their folder
+		p.rules.Forget(userID)
+	}
+
+	return p.folderResult(ctx, event.Meta.Language, err, success)
+}
+
+// ShiftFolder moves the folder up by a negative delta or down by a positive one.
+func (p *processor) ShiftFolder(ctx context.Context, event *entities.Event, id int, delta int) string {
+	ctx, span := tracing.Start(ctx, "processor.ShiftFolder")
+	defer span.End()
+
+	err := p.fm.service.Shift(ctx, event.Meta.UserID, id, delta)
+	return p.folderResult(ctx, event.Meta.Language, err, messages.FolderMoved)
+}
+
+// ToggleFolderHidden hides the folder from the list or shows it again.
+func (p *processor) ToggleFolderHidden(ctx context.Context, event *entities.Event, id int) string {
+	ctx, span := tracing.Start(ctx, "processor.ToggleFolderHidden")
+	defer span.End()
+
+	f := p.FolderSettings(ctx, event, id)
+	if f == nil {
+		return i18n.T(event.Meta.Language, messages.FolderNotExists)
+	}
+	if f.IsDefault() {
+		return i18n.T(event.Meta.Language, messages.FolderLocked)
+	}
+
+	success := messages.FolderHidden
+	if f.Hidden {
+		success = messages.FolderShown
+	}
+
+	err := p.fm.service.SetHidden(ctx, event.Meta.UserID, id, !f.Hidden)
+	return p.folderResult(ctx, event.Meta.Language, err, success)
+}
+
+// MergeFolder moves the notes of the folder into the other one and removes
+// the folder, the open folder follows its notes.
+func (p *processor) MergeFolder(ctx context.Context, event *entities.Event, id int, into int) string {
+	ctx, span := tracing.Start(ctx, "processor.MergeFolder")
+	defer span.End()
+
+	f := p.FolderSettings(ctx, event, id)
+	if f == nil {
+		return i18n.T(event.Meta.Language, messages.FolderNotExists)
+	}
+	if f.IsDefault() {
+		return i18n.T(event.Meta.Language, messages.FolderLocked)
+	}
+
+	userID := event.Meta.UserID
+	count, err := p.fm.service.Merge(ctx, userID, id, into)
+	if err != nil {
+		return p.folderResult(ctx, event.Meta.Language, err, "")
+	}
+	// the rules of the folder follow its notes
+	p.rules.Forget(userID)
+	if p.fm.CurrentFolderID(userID) == id {
+		p.fm.SetCurrentFolderID(userID, into)
+	}
+
+	return i18n.N(event.Meta.Language, messages.FolderMerged, count)
+}
+
+// CopyFolder duplicates the folder with its notes. All the notes and their
+// media count against the limits, the folder is not copied when they don't
+// fit. It returns the ID of the copy, 0 if the folder is not copied.
+func (p *processor) CopyFolder(ctx context.Context, event *entities.Event, id int) (int, string) {
+	ctx, span := tracing.Start(ctx, "processor.CopyFolder")
+	defer span.End()
+
+	lang := event.Meta.Language
+	f := p.FolderSettings(ctx, event, id)
+	if f == nil {
+		return 0, i18n.T(lang, messages.FolderNotExists)
+	}
+	if message := p.checkFolders(ctx, event); message != "" {
+		return 0, message
+	}
+	count, size, err := p.fm.service.Usage(ctx, event.Meta.UserID, id)
+	if err != nil {
+		return 0, p.folderResult(ctx, lang, err, "")
+	}
+	if message := p.quotaMessage(ctx, lang, p.user.CheckNotes(ctx, event.Meta.UserID, count, size)); message != "" {
+		return 0, message
+	}
+
+	name := i18n.T(lang, messages.FolderCopyName, folder.DisplayName(f, lang))
+	cp, count, err := p.fm.service.Copy(ctx, event.Meta.UserID, id, name)
+	if err != nil {
+		return 0, p.folderResult(ctx, lang, err, "")
+	}
+
+	return cp.ID, i18n.N(lang, messages.FolderCopied, count)
+}
+
+// SplitFolder moves the notes of the folder matching the split into a new
+// folder named after the split. It returns the ID of the new folder, 0 if
+// nothing is moved.
+func (p *processor) SplitFolder(ctx context.Context, event *entities.Event, id int, by folder.SplitBy) (int, string) {
+	ctx, span := tracing.Start(ctx, "processor.SplitFolder")
+	defer span.End()
+
+	lang := event.Meta.Language
+	f := p.FolderSettings(ctx, event, id)
+	if f == nil {
+		return 0, i18n.T(lang, messages.FolderNotExists)
+	}
+	if message := p.checkFolders(ctx, event); message != "" {
+		return 0, message
+	}
+
+	name := folder.DisplayName(f, lang)
+	if by.Type != entities.Unknown {
+		name += " " + buttons.TypeIcons[by.Type]
+	}
+	if !by.Before.IsZero() {
+		name = i18n.T(lang, messages.FolderSplitBefore, name, by.Before.Format(time.DateOnly))
+	}
+
+	split, count, err := p.fm.service.Split(ctx, event.Meta.UserID, id, name, by)
+	if err != nil {
+		return 0, p.folderResult(ctx, lang, err, "")
+	}
+
+	return split.ID, i18n.N(lang, messages.FolderSplitDone, count)
+}
+
+// folderResult maps the error of the folder service to the message.
+func (p *processor) folderResult(ctx context.Context, lang i18n.Lang, err error, success string) string {
+	switch err {
+	case nil:
+		return i18n.T(lang, success)
+	case folder.ErrNoFolder:
+		return i18n.T(lang, messages.FolderNotExists)
+	case folder.ErrInvalidName:
+		return i18n.T(lang, messages.FolderNameInvalid)
+	case folder.ErrNameTaken:
+		return i18n.T(lang, messages.FolderNameTaken)
+	case folder.ErrInvalidIcon:
+		return i18n.T(lang, messages.FolderIconInvalid)
+	case folder.ErrInvalidPosition:
+		return i18n.T(lang, messages.FolderPositionInvalid)
+	case folder.ErrSameFolder:
+		return i18n.T(lang, messages.WrongFolder)
+	case folder.ErrNothingToSplit:
+		return i18n.T(lang, messages.FolderSplitEmpty)
+	case folder.ErrInvalidAlias:
+		return i18n.T(lang, messages.FolderAliasInvalid)
+	case folder.ErrAliasTaken:
+		return i18n.T(lang, messages.FolderAliasTaken)
+	default:
+		logger.L(ctx).Error("folder action failed", logger.ErrAttr(err))
+		return i18n.T(lang, messages.Error)
+	}
+}
@@ -0,0 +1,111 @@
+package processor
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"archive_bot/internal/entities"
+	"archive_bot/internal/folder"
+	"archive_bot/internal/rule"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRuleRepository returns copies of the rules, so the cached ones only
+// change when they are read again.
+type testRuleRepository struct {
+	rules []rule.Rule
+}
+
+func (repo *testRuleRepository) Save(ctx context.Context, r *rule.Rule) (int, error) {
+	r.ID = len(repo.rules) + 1
+	repo.rules = append(repo.rules, *r)
+	return r.ID, nil
+}
+
+func (repo *testRuleRepository) All(ctx context.Context, userID int64) ([]*rule.Rule, error) {
+	res := []*rule.Rule{}
+	for _, r := range repo.rules {
+		if r.UserID == userID {
+			res = append(res, &r)
+		}
+	}
+	return res, nil
+}
+
+func (repo *testRuleRepository) Remove(ctx context.Context, userID int64, id int) error {
+	return nil
+}
+
+func (repo *testRuleRepository) Reorder(ctx context.Context, userID int64, ids []int) error {
+	return nil
+}
+
+// testFolders merges the folders like the repository does, moving their rules.
+type testFolders struct {
+	FolderService
+
+	folders []*folder.Folder
+	rules   *testRuleRepository
+}
+
+func (fs *testFolders) Get(ctx context.Context, userID int64, id int) (*folder.Folder, error) {
+	for _, f := range fs.folders {
+		if f.ID == id && f.UserID == userID {
+			return f, nil
+		}
+	}
+	return nil, folder.ErrNoFolder
+}
+
+func (fs *testFolders) Merge(ctx context.Context, userID int64, id int, into int) (int, error) {
+	for i := range fs.rules.rules {
+		if r := &fs.rules.rules[i]; r.UserID == userID && r.FolderID == id {
+			r.FolderID = into
+		}
+	}
+	return 0, nil
+}
+
+func TestMergeFolderForgetsRules(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	log := logger.NewLogger(logger.WithWriter(io.Discard), logger.WithSetDefault(false))
+
+	repo := &testRuleRepository{}
+	rules := rule.NewService(ctx, log, repo)
+	p := &processor{
+		log:   log,
+		rules: rules,
+		fm: newFolderManager(&testFolders{
+			folders: []*folder.Folder{
+				{ID: 2, UserID: 1, Name: "Links"},
+				{ID: 3, UserID: 1, Name: "Reading"},
+			},
+			rules: repo,
+		}),
+	}
+
+	_, err := rules.Add(ctx, &rule.Rule{
+		UserID:     1,
+		Conditions: []rule.Condition{{Kind: rule.KindType, Value: "message"}},
+		FolderID:   2,
+	})
+	require.NoError(t, err)
+
+	note := rule.Note{Type: entities.Message, Text: "read later"}
+	matched, _ := rules.Match(ctx, 1, note)
+	require.NotNil(t, matched)
+	assert.Equal(t, 2, matched.FolderID)
+
+	event := &entities.Event{Meta: entities.Meta{UserID: 1}}
+	p.MergeFolder(ctx, event, 2, 3)
+
+	matched, _ = rules.Match(ctx, 1, note)
+	require.NotNil(t, matched)
+	assert.Equal(t, 3, matched.FolderID)
+}
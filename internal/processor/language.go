@@ -0,0 +1,34 @@
+package processor
+
+import (
+	"context"
+
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+// SetLanguage stores the language chosen by the user, the answer is
+// already in the new language.
+func (p *processor) SetLanguage(ctx context.Context, event *entities.Event, code string) string {
+	ctx, span := tracing.Start(ctx, "processor.SetLanguage")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.SetLanguage"))
+
+	lang, ok := i18n.Parse(code)
+	if !ok {
+		return i18n.T(event.Meta.Language, messages.LanguageUnsupported)
+	}
+
+	if err := p.user.SetLanguage(ctx, event.Meta.UserID, string(lang)); err != nil {
+		log.Error("failed to set language", logger.ErrAttr(err))
+		return i18n.T(event.Meta.Language, messages.Error)
+	}
+	event.Meta.Language = lang
+
+	return i18n.T(event.Meta.Language, messages.LanguageSet)
+}
@@ -1,188 +1,298 @@
-package processor
-
-import (
-	"context"
-	"strconv"
-	"time"
-
-	"archive_bot/internal/const/messages"
-	"archive_bot/internal/entities"
-	"archive_bot/internal/user"
-
-	"archive_bot/pkg/logger"
-
-	"github.com/redis/go-redis/v9"
-)
-
-type FolderService interface {
-	Save(ctx context.Context, event *entities.Event) string
-	RemoveByID(ctx context.Context, id int) error
-	Find(ctx context.Context, event *entities.Event) (string, error)
-	FindOrCreate(ctx context.Context, event *entities.Event) (int, error)
-	SaveDefault(ctx context.Context, event *entities.Event) error
-	All(ctx context.Context, event *entities.Event) map[string]string
-	DefaultFolderID(ctx context.Context, user_id int64) int
-}
-
-type UserService interface {
-	Save(ctx context.Context, event *entities.Event) error
-	IsExists(ctx context.Context, event *entities.Event) error
-	CountUsers(ctx context.Context) (int, error)
-}
-
-type TextNoteService interface {
-	Save(ctx context.Context, event *entities.Event) (int, string)
-	AllFrom(ctx context.Context, event *entities.Event) (map[int]*entities.AnswerParams, int)
-	FindLast(ctx context.Context, event *entities.Event) (string, time.Time)
-	Move(ctx context.Context, event *entities.Event) string
-	MoveLast(ctx context.Context, event *entities.Event) string
-	RemoveByID(ctx context.Context, id int) error
-}
-
-type PhotoNoteService interface {
-	Save(ctx context.Context, event *entities.Event) int
-	FindByTextsID(ctx context.Context, textsID int) []string
-	UpdateByTextsID(ctx context.Context, event *entities.Event) error
-}
-
-type DocsNoteService interface {
-	Save(ctx context.Context, event *entities.Event) int
-	FindByTextsID(ctx context.Context, textsID int) []string
-	UpdateByTextsID(ctx context.Context, event *entities.Event) error
-}
-
-type VideoNoteService interface {
-	Save(ctx context.Context, event *entities.Event) int
-	FindByTextsID(ctx context.Context, textsID int) []string
-	UpdateByTextsID(ctx context.Context, event *entities.Event) error
-}
-
-type AudioNoteService interface {
-	Save(ctx context.Context, event *entities.Event) int
-	FindByTextsID(ctx context.Context, textsID int) []string
-	UpdateByTextsID(ctx context.Context, event *entities.Event) error
-}
-
-type AniNoteService interface {
-	Save(ctx context.Context, event *entities.Event) int
-	FindByTextsID(ctx context.Context, textsID int) []string
-	UpdateByTextsID(ctx context.Context, event *entities.Event) error
-}
-
-type VoiceNoteService interface {
-	Save(ctx context.Context, event *entities.Event) int
-	FindByTextsID(ctx context.Context, textsID int) []string
-	UpdateByTextsID(ctx context.Context, event *entities.Event) error
-}
-
-type Storage interface {
-	SetInt(ctx context.Context, key string, val int)
-	Int(ctx context.Context, key string) int
-	Append(ctx context.Context, key string, val int)
-	PopSlice(ctx context.Context, key string) []int
-}
-
-type processor struct {
-	log *logger.Logger
-
-	user UserService
-
-	nm noteManager
-	fm folderManager
-
-	storage Storage
-}
-
-func New(
-	log *logger.Logger,
-	redis *redis.Client,
-	user UserService,
-	folder FolderService,
-	textNote TextNoteService,
-	photoNote PhotoNoteService,
-	docsNote DocsNoteService,
-	videoNote VideoNoteService,
-	audioNote AudioNoteService,
-	aniNote AudioNoteService,
-	voiceNote AudioNoteService,
-) *processor {
-	return &processor{
-		log:  log,
-		user: user,
-		nm: newNoteManager(
-			textNote, photoNote, docsNote, videoNote, audioNote, aniNote, voiceNote,
-		),
-		fm:      newFolderManager(folder),
-		storage: newStorage(log, redis),
-	}
-}
-
-func (p *processor) InitUser(ctx context.Context, event *entities.Event) {
-	if err := p.user.IsExists(ctx, event); err != nil {
-		if err == user.ErrUserNotExists {
-			if err := p.user.Save(ctx, event); err != nil {
-				p.log.Error("save user error", logger.ErrAttr(err))
-			}
-			if err := p.fm.service.SaveDefault(ctx, event); err != nil {
-				p.log.Error("save default folder error", logger.ErrAttr(err))
-			}
-			return
-		}
-		p.log.Error("server error", logger.ErrAttr(err))
-	}
-}
-
-func (p *processor) CountUsers(ctx context.Context) string {
-	log := logger.L(ctx).With(logger.String("operation", "processor.CountUsers"))
-	count, err := p.user.CountUsers(ctx)
-	if err != nil {
-		log.Error("the count of users was not found", logger.ErrAttr(err))
-		return messages.Error
-	}
-	return "The quantity of users is " + strconv.Itoa(count)
-}
-
-const (
-	msgIDPrefix       = "msg:"
-	folderMsgIDPrefix = "user-msg:"
-)
-
-func (p *processor) SetInt(key string, num int) {
-	p.storage.SetInt(context.Background(), key, num)
-}
-
-func (p *processor) Int(key string) int {
-	return p.storage.Int(context.Background(), key)
-}
-
-func (p *processor) SetFolderMsgID(userID int64, messageID int) {
-	p.storage.SetInt(
-		context.Background(),
-		folderMsgIDPrefix+strconv.FormatInt(userID, 10),
-		messageID,
-	)
-}
-
-func (p *processor) FolderMsgID(userID int64) int {
-	return p.storage.Int(
-		context.Background(),
-		folderMsgIDPrefix+strconv.FormatInt(userID, 10),
-	)
-}
-
-func (p *processor) AddMessageID(userID int64, messageID int) {
-	if messageID != 0 {
-		p.storage.Append(
-			context.Background(),
-			msgIDPrefix+strconv.FormatInt(userID, 10),
-			messageID,
-		)
-	}
-}
-
-func (p *processor) MessageIDs(userID int64) []int {
-	return p.storage.PopSlice(
-		context.Background(),
-		msgIDPrefix+strconv.FormatInt(userID, 10),
-	)
-}
+package processor
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"archive_bot/internal/channel"
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/folder"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/notes/texts"
+	"archive_bot/internal/rule"
+	"archive_bot/internal/token"
+	"archive_bot/internal/user"
+
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type FolderService interface {
+	Save(ctx context.Context, event *entities.Event) string
+	RemoveByID(ctx context.Context, id int) error
+	Find(ctx context.Context, event *entities.Event) (string, error)
+	FindOrCreate(ctx context.Context, event *entities.Event) (int, error)
+	FindOrCreateByName(ctx context.Context, userID int64, name string) (int, error)
+	Resolve(ctx context.Context, userID int64, name string) (*folder.Folder, bool, error)
+	SaveDefault(ctx context.Context, event *entities.Event) error
+	All(ctx context.Context, event *entities.Event) []entities.Button
+	DefaultFolderID(ctx context.Context, user_id int64) int
+	List(ctx context.Context, userID int64) ([]*folder.Folder, error)
+	Get(ctx context.Context, userID int64, id int) (*folder.Folder, error)
+	Rename(ctx context.Context, userID int64, id int, name string) error
+	SetIcon(ctx context.Context, userID int64, id int, icon string) error
+	SetHidden(ctx context.Context, userID int64, id int, hidden bool) error
+	SetAliases(ctx context.Context, userID int64, id int, text string) error
+	Move(ctx context.Context, userID int64, id int, position int) error
+	Shift(ctx context.Context, userID int64, id int, delta int) error
+	Merge(ctx context.Context, userID int64, id int, into int) (int, error)
+	Copy(ctx context.Context, userID int64, id int, name string) (*folder.Folder, int, error)
+	Usage(ctx context.Context, userID int64, id int) (int, int64, error)
+	Split(ctx context.Context, userID int64, id int, name string, by folder.SplitBy) (*folder.Folder, int, error)
+}
+
+type UserService interface {
+	Save(ctx context.Context, event *entities.Event) error
+	Language(ctx context.Context, event *entities.Event) (string, error)
+	SetLanguage(ctx context.Context, userID int64, language string) error
+	Transcribe(ctx context.Context, userID int64) bool
+	SetTranscribe(ctx context.Context, userID int64, on bool) error
+	Seen(ctx context.Context, event *entities.Event)
+	Profile(ctx context.Context, query string) (*user.Profile, error)
+	IsBanned(ctx context.Context, userID int64) bool
+	Ban(ctx context.Context, userID int64, reason string) error
+	Unban(ctx context.Context, userID int64) error
+	SetQuota(ctx context.Context, userID int64, q *user.Quota) error
+	CheckNote(ctx context.Context, userID int64, size int64) error
+	CheckNotes(ctx context.Context, userID int64, count int, size int64) error
+	SetMediaSize(ctx context.Context, noteID int, size int64)
+	CheckFolders(ctx context.Context, userID int64) error
+	Wipe(ctx context.Context, userID int64) error
+	CountUsers(ctx context.Context) (int, error)
+}
+
+type TextNoteService interface {
+	Save(ctx context.Context, event *entities.Event) (int, string)
+	AllFrom(ctx context.Context, event *entities.Event) (map[int]*entities.AnswerParams, int)
+	FindLast(ctx context.Context, event *entities.Event) (int, time.Time)
+	Move(ctx context.Context, event *entities.Event) string
+	MoveLast(ctx context.Context, event *entities.Event) string
+	UpdateByID(ctx context.Context, event *entities.Event) string
+	RemoveByID(ctx context.Context, id int) error
+	MoveMany(ctx context.Context, userID int64, ids []int, folderID int) (int, error)
+	RemoveMany(ctx context.Context, userID int64, ids []int) (int, error)
+	TagMany(ctx context.Context, userID int64, ids []int, tags []string) (int, error)
+	FindMany(ctx context.Context, userID int64, ids []int) ([]*texts.TextNote, error)
+	List(ctx context.Context, f *texts.Filter) ([]*texts.TextNote, error)
+	Favorites(ctx context.Context, event *entities.Event) map[int]*entities.AnswerParams
+	ToggleFavorite(ctx context.Context, event *entities.Event) (bool, error)
+}
+
+type PhotoNoteService interface {
+	Save(ctx context.Context, event *entities.Event) int
+	FindByTextsID(ctx context.Context, textsID int) []string
+	UpdateByTextsID(ctx context.Context, event *entities.Event) error
+}
+
+type DocsNoteService interface {
+	Save(ctx context.Context, event *entities.Event) int
+	FindByTextsID(ctx context.Context, textsID int) []string
+	UpdateByTextsID(ctx context.Context, event *entities.Event) error
+}
+
+type VideoNoteService interface {
+	Save(ctx context.Context, event *entities.Event) int
+	FindByTextsID(ctx context.Context, textsID int) []string
+	UpdateByTextsID(ctx context.Context, event *entities.Event) error
+}
+
+type AudioNoteService interface {
+	Save(ctx context.Context, event *entities.Event) int
+	FindByTextsID(ctx context.Context, textsID int) []string
+	UpdateByTextsID(ctx context.Context, event *entities.Event) error
+}
+
+type AniNoteService interface {
+	Save(ctx context.Context, event *entities.Event) int
+	FindByTextsID(ctx context.Context, textsID int) []string
+	UpdateByTextsID(ctx context.Context, event *entities.Event) error
+}
+
+type VoiceNoteService interface {
+	Save(ctx context.Context, event *entities.Event) int
+	FindByTextsID(ctx context.Context, textsID int) []string
+	UpdateByTextsID(ctx context.Context, event *entities.Event) error
+}
+
+type ChannelService interface {
+	Link(ctx context.Context, c *channel.Channel) error
+	Unlink(ctx context.Context, userID int64, channelID int64) error
+	Find(ctx context.Context, channelID int64) (*channel.Channel, error)
+	AddPost(ctx context.Context, channelID int64, messageID int, textsID int)
+	PostNoteID(ctx context.Context, channelID int64, messageID int) int
+}
+
+type TokenService interface {
+	Issue(ctx context.Context, userID int64) (string, error)
+	All(ctx context.Context, userID int64) ([]*token.Token, error)
+	Revoke(ctx context.Context, userID int64, id int) (int, error)
+}
+
+type RuleService interface {
+	All(ctx context.Context, userID int64) ([]*rule.Rule, error)
+	Add(ctx context.Context, r *rule.Rule) (int, error)
+	Remove(ctx context.Context, userID int64, number int) error
+	Move(ctx context.Context, userID int64, number int, position int) error
+	Match(ctx context.Context, userID int64, n rule.Note) (*rule.Rule, int)
+	Forget(userID int64)
+}
+
+type TranscriptionService interface {
+	Enabled() bool
+	Enqueue(ctx context.Context, textsID int, fileID string, size int64) error
+}
+
+type Storage interface {
+	SetInt(ctx context.Context, key string, val int)
+	Int(ctx context.Context, key string) int
+	Append(ctx context.Context, key string, val int)
+	PopSlice(ctx context.Context, key string) []int
+}
+
+type processor struct {
+	log *logger.Logger
+
+	user     UserService
+	channels ChannelService
+	tokens   TokenService
+	rules    RuleService
+
+	transcription TranscriptionService
+
+	nm noteManager
+	fm folderManager
+
+	storage Storage
+}
+
+func New(
+	log *logger.Logger,
+	redis *redis.Client,
+	user UserService,
+	folder FolderService,
+	textNote TextNoteService,
+	photoNote PhotoNoteService,
+	docsNote DocsNoteService,
+	videoNote VideoNoteService,
+	audioNote AudioNoteService,
+	aniNote AudioNoteService,
+	voiceNote AudioNoteService,
+	channels ChannelService,
+	tokens TokenService,
+	rules RuleService,
+	transcription TranscriptionService,
+) *processor {
+	return &processor{
+		log:           log,
+		user:          user,
+		channels:      channels,
+		tokens:        tokens,
+		rules:         rules,
+		transcription: transcription,
+		nm: newNoteManager(
+			textNote, photoNote, docsNote, videoNote, audioNote, aniNote, voiceNote,
+		),
+		fm:      newFolderManager(folder),
+		storage: newStorage(log, redis),
+	}
+}
+
+func (p *processor) InitUser(ctx context.Context, event *entities.Event) {
+	ctx, span := tracing.Start(ctx, "processor.InitUser")
+	defer span.End()
+
+	language, err := p.user.Language(ctx, event)
+	if err != nil {
+		if err == user.ErrUserNotExists {
+			if err := p.user.Save(ctx, event); err != nil {
+				logger.L(ctx).Error("save user error", logger.ErrAttr(err))
+			}
+			if err := p.fm.service.SaveDefault(ctx, event); err != nil {
+				logger.L(ctx).Error("save default folder error", logger.ErrAttr(err))
+			}
+			return
+		}
+		logger.L(ctx).Error("server error", logger.ErrAttr(err))
+		return
+	}
+	p.user.Seen(ctx, event)
+
+	if lang, ok := i18n.Parse(language); ok {
+		event.Meta.Language = lang
+	}
+}
+
+// ActiveFlows returns the number of unfinished dialogs by flow.
+func (p *processor) ActiveFlows() map[string]int {
+	create, remove, edit := p.fm.activeFlows()
+
+	return map[string]int{
+		"create_folder": create,
+		"delete_folder": remove,
+		"edit_folder":   edit,
+		"move_note":     p.nm.activeFlows(),
+		"select_notes":  p.nm.activeSelections(),
+	}
+}
+
+func (p *processor) CountUsers(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "processor.CountUsers")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.CountUsers"))
+	count, err := p.user.CountUsers(ctx)
+	if err != nil {
+		log.Error("the count of users was not found", logger.ErrAttr(err))
+		return i18n.T(event.Meta.Language, messages.Error)
+	}
+	return i18n.T(event.Meta.Language, messages.AdminUsersCount, count)
+}
+
+const (
+	msgIDPrefix       = "msg:"
+	folderMsgIDPrefix = "user-msg:"
+)
+
+func (p *processor) SetInt(ctx context.Context, key string, num int) {
+	p.storage.SetInt(ctx, key, num)
+}
+
+func (p *processor) Int(ctx context.Context, key string) int {
+	return p.storage.Int(ctx, key)
+}
+
+func (p *processor) SetFolderMsgID(ctx context.Context, userID int64, messageID int) {
+	p.storage.SetInt(
+		ctx,
+		folderMsgIDPrefix+strconv.FormatInt(userID, 10),
+		messageID,
+	)
+}
+
+func (p *processor) FolderMsgID(ctx context.Context, userID int64) int {
+	return p.storage.Int(
+		ctx,
+		folderMsgIDPrefix+strconv.FormatInt(userID, 10),
+	)
+}
+
+func (p *processor) AddMessageID(ctx context.Context, userID int64, messageID int) {
+	if messageID != 0 {
+		p.storage.Append(
+			ctx,
+			msgIDPrefix+strconv.FormatInt(userID, 10),
+			messageID,
+		)
+	}
+}
+
+func (p *processor) MessageIDs(ctx context.Context, userID int64) []int {
+	return p.storage.PopSlice(
+		ctx,
+		msgIDPrefix+strconv.FormatInt(userID, 10),
+	)
+}
@@ -0,0 +1,282 @@
+package processor
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"archive_bot/internal/const/buttons"
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/folder"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/notes/texts"
+	"archive_bot/internal/rule"
+	"archive_bot/internal/selection"
+
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+const (
+	ruleAdd  string = "add"
+	ruleDel  string = "del"
+	ruleMove string = "move"
+	ruleTest string = "test"
+
+	// dryRunNotes is the number of the latest notes the dry run looks at.
+	dryRunNotes int = 200
+	// dryRunSamples is the number of the moved notes shown for a rule.
+	dryRunSamples int = 5
+)
+
+// Rules lists and edits the filing rules of the user: "/rules",
+// "/rules add <rule>", "/rules del <n>", "/rules move <n> <place>",
+// "/rules test [n]".
+func (p *processor) Rules(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "processor.Rules")
+	defer span.End()
+
+	args := strings.Fields(event.Text)
+	if len(args) == 0 || strings.HasPrefix(args[0], "/") {
+		return p.ruleList(ctx, event)
+	}
+
+	switch {
+	case args[0] == ruleAdd && len(args) > 1:
+		_, spec, _ := strings.Cut(strings.TrimSpace(event.Text), " ")
+		return p.addRule(ctx, event, spec)
+	case args[0] == ruleDel && len(args) == 2:
+		number, err := strconv.Atoi(args[1])
+		if err != nil {
+			return i18n.T(event.Meta.Language, messages.RulesUsage)
+		}
+		err = p.rules.Remove(ctx, event.Meta.UserID, number)
+		return p.ruleResult(ctx, event.Meta.Language, err, messages.RuleRemoved)
+	case args[0] == ruleMove && len(args) == 3:
+		number, err1 := strconv.Atoi(args[1])
+		position, err2 := strconv.Atoi(args[2])
+		if err1 != nil || err2 != nil {
+			return i18n.T(event.Meta.Language, messages.RulesUsage)
+		}
+		err := p.rules.Move(ctx, event.Meta.UserID, number, position)
+		return p.ruleResult(ctx, event.Meta.Language, err, messages.RuleMoved)
+	case args[0] == ruleTest && len(args) <= 2:
+		number := 0
+		if len(args) == 2 {
+			var err error
+			if number, err = strconv.Atoi(args[1]); err != nil {
+				return i18n.T(event.Meta.Language, messages.RulesUsage)
+			}
+		}
+		return p.testRules(ctx, event, number)
+	default:
+		return i18n.T(event.Meta.Language, messages.RulesUsage)
+	}
+}
+
+func (p *processor) ruleList(ctx context.Context, event *entities.Event) string {
+	log := logger.L(ctx).With(logger.String("operation", "processor.ruleList"))
+	lang := event.Meta.Language
+
+	rules, err := p.rules.All(ctx, event.Meta.UserID)
+	if err != nil {
+		log.Error("failed to get rules", logger.ErrAttr(err))
+		return i18n.T(lang, messages.Error)
+	}
+	if len(rules) == 0 {
+		return i18n.T(lang, messages.RulesEmpty) + "\n\n" + i18n.T(lang, messages.RulesUsage)
+	}
+
+	b := &strings.Builder{}
+	b.WriteString(i18n.N(lang, messages.RulesList, len(rules)))
+	for i, r := range rules {
+		b.WriteString("\n")
+		b.WriteString(strconv.Itoa(i + 1))
+		b.WriteString(". ")
+		b.WriteString(ruleString(r, lang))
+	}
+
+	return b.String()
+}
+
+func (p *processor) addRule(ctx context.Context, event *entities.Event, spec string) string {
+	lang := event.Meta.Language
+
+	r, err := rule.Parse(spec)
+	if err != nil {
+		return p.ruleResult(ctx, lang, err, "")
+	}
+	r.UserID = event.Meta.UserID
+
+	if r.FolderName != "" {
+		f := p.folderByName(ctx, event, r.FolderName)
+		if f == nil {
+			return i18n.T(lang, messages.RuleFolderNotFound, r.FolderName)
+		}
+		r.FolderID = f.ID
+	}
+
+	number, err := p.rules.Add(ctx, r)
+	if err != nil {
+		return p.ruleResult(ctx, lang, err, "")
+	}
+
+	return i18n.T(lang, messages.RuleAdded, number)
+}
+
+// folderByName finds the folder of the user by its name, the default
+// folder is found by the name shown to the user too.
+func (p *processor) folderByName(ctx context.Context, event *entities.Event, name string) *folder.Folder {
+	log := logger.L(ctx).With(logger.String("operation", "processor.folderByName"))
+
+	folders, err := p.fm.service.List(ctx, event.Meta.UserID)
+	if err != nil {
+		log.Error("failed to get folders", logger.ErrAttr(err))
+		return nil
+	}
+	for _, f := range folders {
+		if strings.EqualFold(f.Name, name) ||
+			strings.EqualFold(folder.DisplayName(f, event.Meta.Language), name) {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// testRules shows what the rules would do with the latest notes without
+// changing them. A non-zero number tests only that rule.
+func (p *processor) testRules(ctx context.Context, event *entities.Event, number int) string {
+	log := logger.L(ctx).With(logger.String("operation", "processor.testRules"))
+	lang := event.Meta.Language
+
+	rules, err := p.rules.All(ctx, event.Meta.UserID)
+	if err != nil {
+		log.Error("failed to get rules", logger.ErrAttr(err))
+		return i18n.T(lang, messages.Error)
+	}
+	if len(rules) == 0 {
+		return i18n.T(lang, messages.RulesEmpty) + "\n\n" + i18n.T(lang, messages.RulesUsage)
+	}
+	if number < 0 || number > len(rules) {
+		return i18n.T(lang, messages.RuleNotFound)
+	}
+	if number > 0 {
+		rules = rules[number-1 : number]
+	}
+
+	notes, err := p.nm.texts.List(ctx, &texts.Filter{UserID: event.Meta.UserID, Limit: dryRunNotes})
+	if err != nil {
+		log.Error("failed to get notes", logger.ErrAttr(err))
+		return i18n.T(lang, messages.Error)
+	}
+	folders, err := p.fm.service.List(ctx, event.Meta.UserID)
+	if err != nil {
+		log.Error("failed to get folders", logger.ErrAttr(err))
+		return i18n.T(lang, messages.Error)
+	}
+	names := make(map[int]string, len(folders))
+	for _, f := range folders {
+		names[f.ID] = folder.DisplayName(f, lang)
+	}
+
+	candidates := make([]rule.Note, 0, len(notes))
+	for _, n := range notes {
+		candidates = append(candidates, rule.Note{
+			ID:       n.ID,
+			FolderID: n.FolderID,
+			Type:     entities.ParseType(n.Type),
+			Text:     n.Description,
+		})
+	}
+
+	b := &strings.Builder{}
+	b.WriteString(i18n.N(lang, messages.RulesDryRun, len(notes)))
+	for _, res := range rule.DryRun(rules, candidates) {
+		if number > 0 {
+			res.Number = number
+		}
+		b.WriteString("\n\n")
+		b.WriteString(strconv.Itoa(res.Number))
+		b.WriteString(". ")
+		b.WriteString(ruleString(res.Rule, lang))
+		b.WriteString("\n")
+		b.WriteString(i18n.T(lang, messages.RulePreview, res.Matched, len(res.Moved)))
+		for _, n := range res.Moved[:min(len(res.Moved), dryRunSamples)] {
+			preview := selection.NewItem(n.ID, n.Type, n.Text).Preview
+			if preview == "" {
+				preview = buttons.TypeIcons[n.Type]
+			}
+			b.WriteString("\n• ")
+			b.WriteString(preview)
+			b.WriteString(": ")
+			b.WriteString(names[n.FolderID])
+			b.WriteString(" → ")
+			b.WriteString(names[res.Rule.FolderID])
+		}
+	}
+
+	return b.String()
+}
+
+// ruleString renders the rule with the name of the folder shown to the user.
+func ruleString(r *rule.Rule, lang i18n.Lang) string {
+	if r.FolderName == "" {
+		return r.String()
+	}
+	shown := *r
+	shown.FolderName = folder.DisplayName(&folder.Folder{Name: r.FolderName}, lang)
+	return shown.String()
+}
+
+// applyRule adds the tags of the rule to the saved note and tells the user
+// which rule filed it.
+func (p *processor) applyRule(
+	ctx context.Context, event *entities.Event, r *rule.Rule, number int, ap *entities.AnswerParams,
+) {
+	log := logger.L(ctx).With(logger.String("operation", "processor.applyRule"))
+	lang := event.Meta.Language
+
+	if len(r.Tags) > 0 {
+		if _, err := p.nm.texts.TagMany(ctx, event.Meta.UserID, []int{event.NoteID}, r.Tags); err != nil {
+			log.Error("failed to tag note", logger.ErrAttr(err))
+		}
+	}
+	// the items of an album without a caption are saved silently
+	if ap.Message == "" {
+		return
+	}
+
+	if r.FolderID != 0 && r.FolderID == event.FolderID {
+		name := folder.DisplayName(&folder.Folder{Name: r.FolderName}, lang)
+		ap.Message = i18n.T(lang, messages.RuleFiled, name, number)
+	} else if len(r.Tags) > 0 {
+		ap.Message = i18n.T(lang, messages.RuleTagged, "#"+strings.Join(r.Tags, " #"), number)
+	}
+}
+
+// ruleResult returns the message for the result of the rule command.
+func (p *processor) ruleResult(ctx context.Context, lang i18n.Lang, err error, success string) string {
+	switch err {
+	case nil:
+		return i18n.T(lang, success)
+	case rule.ErrNoRule:
+		return i18n.T(lang, messages.RuleNotFound)
+	case rule.ErrTooManyRules:
+		return i18n.T(lang, messages.RulesLimit)
+	case rule.ErrSyntax:
+		return i18n.T(lang, messages.RuleSyntax)
+	case rule.ErrType:
+		return i18n.T(lang, messages.RuleTypeInvalid)
+	case rule.ErrRegex:
+		return i18n.T(lang, messages.RuleRegexInvalid)
+	case rule.ErrTag:
+		return i18n.T(lang, messages.TagsInvalid)
+	case rule.ErrNoAction:
+		return i18n.T(lang, messages.RuleNoAction)
+	default:
+		logger.L(ctx).Error("failed to change rules", logger.ErrAttr(err))
+		return i18n.T(lang, messages.Error)
+	}
+}
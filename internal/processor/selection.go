@@ -0,0 +1,142 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/notes/texts"
+	"archive_bot/internal/selection"
+
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+// StartSelection opens the selection of the notes of the folder of the
+// event, the notes are in the order of the folder view.
+func (p *processor) StartSelection(ctx context.Context, event *entities.Event) *selection.Selection {
+	ctx, span := tracing.Start(ctx, "processor.StartSelection")
+	defer span.End()
+
+	notes, _ := p.nm.texts.AllFrom(ctx, event)
+	ids := entities.SortNotes(notes)
+
+	items := make([]selection.Item, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, selection.NewItem(id, notes[id].Type, notes[id].Message))
+	}
+
+	s := selection.New(event.FolderID, items)
+	p.nm.setSelection(event.Meta.UserID, s)
+
+	return s
+}
+
+// Selection returns the open selection of the user, nil if there is none.
+func (p *processor) Selection(userID int64) *selection.Selection {
+	return p.nm.selection(userID)
+}
+
+func (p *processor) EndSelection(userID int64) {
+	p.nm.setSelection(userID, nil)
+}
+
+// MoveSelection moves the selected notes to the folder in one statement
+// and closes the selection.
+func (p *processor) MoveSelection(ctx context.Context, event *entities.Event, folderID int) string {
+	ctx, span := tracing.Start(ctx, "processor.MoveSelection")
+	defer span.End()
+
+	s := p.nm.selection(event.Meta.UserID)
+	if s == nil {
+		return i18n.T(event.Meta.Language, messages.SelectionExpired)
+	}
+
+	count, err := p.nm.texts.MoveMany(ctx, event.Meta.UserID, s.IDs(), folderID)
+	if err != nil {
+		return p.selectionError(ctx, event.Meta.Language, err)
+	}
+	p.EndSelection(event.Meta.UserID)
+
+	return i18n.N(event.Meta.Language, messages.NotesMoved, count)
+}
+
+// RemoveSelection removes the selected notes in one statement and closes
+// the selection.
+func (p *processor) RemoveSelection(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "processor.RemoveSelection")
+	defer span.End()
+
+	s := p.nm.selection(event.Meta.UserID)
+	if s == nil {
+		return i18n.T(event.Meta.Language, messages.SelectionExpired)
+	}
+
+	count, err := p.nm.texts.RemoveMany(ctx, event.Meta.UserID, s.IDs())
+	if err != nil {
+		return p.selectionError(ctx, event.Meta.Language, err)
+	}
+	p.EndSelection(event.Meta.UserID)
+
+	return i18n.N(event.Meta.Language, messages.NotesRemoved, count)
+}
+
+// TagSelection adds the tags of the text to the selected notes. It returns
+// the empty message if the selection does not wait for the tags.
+func (p *processor) TagSelection(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "processor.TagSelection")
+	defer span.End()
+
+	s := p.nm.selection(event.Meta.UserID)
+	if s == nil || s.Awaiting != selection.AwaitTags {
+		return ""
+	}
+	s.Awaiting = selection.AwaitNothing
+
+	tags, ok := texts.ParseTags(event.Text)
+	if !ok {
+		return i18n.T(event.Meta.Language, messages.TagsInvalid)
+	}
+
+	count, err := p.nm.texts.TagMany(ctx, event.Meta.UserID, s.IDs(), tags)
+	if err != nil {
+		return p.selectionError(ctx, event.Meta.Language, err)
+	}
+
+	return i18n.N(event.Meta.Language, messages.NotesTagged, count, "#"+strings.Join(tags, " #"))
+}
+
+// ExportSelection writes the selected notes as CSV, the selection stays open.
+func (p *processor) ExportSelection(ctx context.Context, event *entities.Event) (*bytes.Buffer, string) {
+	ctx, span := tracing.Start(ctx, "processor.ExportSelection")
+	defer span.End()
+
+	s := p.nm.selection(event.Meta.UserID)
+	if s == nil {
+		return nil, i18n.T(event.Meta.Language, messages.SelectionExpired)
+	}
+
+	notes, err := p.nm.texts.FindMany(ctx, event.Meta.UserID, s.IDs())
+	if err != nil {
+		return nil, p.selectionError(ctx, event.Meta.Language, err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := texts.WriteCSV(buf, notes); err != nil {
+		return nil, p.selectionError(ctx, event.Meta.Language, err)
+	}
+
+	return buf, i18n.N(event.Meta.Language, messages.NotesExported, len(notes))
+}
+
+func (p *processor) selectionError(ctx context.Context, lang i18n.Lang, err error) string {
+	if err == texts.ErrNoFolder {
+		return i18n.T(lang, messages.FolderNotExists)
+	}
+
+	logger.L(ctx).Error("bulk action failed", logger.ErrAttr(err))
+	return i18n.T(lang, messages.Error)
+}
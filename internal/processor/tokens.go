@@ -0,0 +1,102 @@
+package processor
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/token"
+
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+const (
+	tokenList   string = "list"
+	tokenRevoke string = "revoke"
+	tokenAll    string = "all"
+)
+
+// Token issues, lists and revokes API tokens of the user:
+// "/token", "/token list", "/token revoke <id|all>".
+func (p *processor) Token(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "processor.Token")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.Token"))
+
+	args := strings.Fields(event.Text)
+	if len(args) == 0 || strings.HasPrefix(args[0], "/") {
+		plain, err := p.tokens.Issue(ctx, event.Meta.UserID)
+		if err == token.ErrTooManyTokens {
+			return i18n.T(event.Meta.Language, messages.TokensLimit)
+		}
+		if err != nil {
+			log.Error("failed to issue token", logger.ErrAttr(err))
+			return i18n.T(event.Meta.Language, messages.Error)
+		}
+		return i18n.T(event.Meta.Language, messages.TokenIssued) + "\n\n" + plain
+	}
+
+	switch {
+	case args[0] == tokenList:
+		return p.tokenList(ctx, event)
+	case args[0] == tokenRevoke && len(args) == 2:
+		return p.revokeToken(ctx, event, args[1])
+	default:
+		return i18n.T(event.Meta.Language, messages.TokenUsage)
+	}
+}
+
+func (p *processor) tokenList(ctx context.Context, event *entities.Event) string {
+	log := logger.L(ctx).With(logger.String("operation", "processor.tokenList"))
+
+	tokens, err := p.tokens.All(ctx, event.Meta.UserID)
+	if err != nil {
+		log.Error("failed to get tokens", logger.ErrAttr(err))
+		return i18n.T(event.Meta.Language, messages.Error)
+	}
+	if len(tokens) == 0 {
+		return i18n.T(event.Meta.Language, messages.TokensEmpty)
+	}
+
+	b := &strings.Builder{}
+	b.WriteString(i18n.N(event.Meta.Language, messages.TokenList, len(tokens)))
+	for _, t := range tokens {
+		b.WriteString("\n")
+		b.WriteString(strconv.Itoa(t.ID))
+		b.WriteString(". ")
+		b.WriteString(t.Prefix)
+		b.WriteString("… ")
+		b.WriteString(t.CreatedAt.Format("02.01.2006"))
+		if t.LastUsedAt.Unix() > 0 {
+			b.WriteString(" / ")
+			b.WriteString(t.LastUsedAt.Format("02.01.2006 15:04"))
+		}
+	}
+
+	return b.String()
+}
+
+func (p *processor) revokeToken(ctx context.Context, event *entities.Event, arg string) string {
+	id := 0
+	if arg != tokenAll {
+		var err error
+		if id, err = strconv.Atoi(arg); err != nil || id <= 0 {
+			return i18n.T(event.Meta.Language, messages.TokenUsage)
+		}
+	}
+
+	count, err := p.tokens.Revoke(ctx, event.Meta.UserID, id)
+	if err != nil {
+		return i18n.T(event.Meta.Language, messages.Error)
+	}
+	if count == 0 {
+		return i18n.T(event.Meta.Language, messages.TokenNotFound)
+	}
+
+	return i18n.T(event.Meta.Language, messages.TokenRevoked)
+}
@@ -0,0 +1,76 @@
+package processor
+
+import (
+	"context"
+	"strings"
+
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+const (
+	transcribeOn  string = "on"
+	transcribeOff string = "off"
+)
+
+// Transcribe shows and switches the transcription of the voice and audio
+// notes of the user: "/transcribe", "/transcribe on", "/transcribe off".
+func (p *processor) Transcribe(ctx context.Context, event *entities.Event) string {
+	ctx, span := tracing.Start(ctx, "processor.Transcribe")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "processor.Transcribe"))
+
+	if !p.transcription.Enabled() {
+		return i18n.T(event.Meta.Language, messages.TranscribeUnavailable)
+	}
+
+	args := strings.Fields(event.Text)
+	if len(args) == 0 || strings.HasPrefix(args[0], "/") {
+		if p.user.Transcribe(ctx, event.Meta.UserID) {
+			return i18n.T(event.Meta.Language, messages.TranscribeOn)
+		}
+		return i18n.T(event.Meta.Language, messages.TranscribeOff)
+	}
+
+	var on bool
+	switch strings.ToLower(args[0]) {
+	case transcribeOn:
+		on = true
+	case transcribeOff:
+	default:
+		return i18n.T(event.Meta.Language, messages.TranscribeUsage)
+	}
+
+	if err := p.user.SetTranscribe(ctx, event.Meta.UserID, on); err != nil {
+		log.Error("failed to set transcribe", logger.ErrAttr(err))
+		return i18n.T(event.Meta.Language, messages.Error)
+	}
+	if on {
+		return i18n.T(event.Meta.Language, messages.TranscribeOn)
+	}
+	return i18n.T(event.Meta.Language, messages.TranscribeOff)
+}
+
+// transcribe queues the voice or the audio of the saved note when the user
+// turned the transcription on. An album of audios is transcribed by its
+// first file.
+func (p *processor) transcribe(ctx context.Context, event *entities.Event) {
+	if event.NoteID == 0 || event.FileID == "" || !p.transcription.Enabled() {
+		return
+	}
+	if event.Type != entities.Voice && event.Type != entities.Audio {
+		return
+	}
+	if !p.user.Transcribe(ctx, event.Meta.UserID) {
+		return
+	}
+
+	if err := p.transcription.Enqueue(ctx, event.NoteID, event.FileID, event.FileSize); err != nil {
+		logger.L(ctx).Error("failed to enqueue transcription", logger.ErrAttr(err))
+	}
+}
@@ -0,0 +1,80 @@
+// Package role keeps the roles of the bot administrators.
+package role
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Role is the set of the admin actions a user may take. Each role may take
+// the actions of the roles below it.
+type Role string
+
+const (
+	None       Role = ""
+	Support    Role = "support"
+	Moderator  Role = "moderator"
+	Superadmin Role = "superadmin"
+)
+
+// Roles returns the roles from the lowest.
+func Roles() []Role {
+	return []Role{Support, Moderator, Superadmin}
+}
+
+// Parse returns the role by its name, ok is false for an unknown name.
+func Parse(name string) (Role, bool) {
+	r := Role(strings.ToLower(strings.TrimSpace(name)))
+	return r, r.level() > 0
+}
+
+// Can reports whether the role may take the actions of the required role.
+func (r Role) Can(required Role) bool {
+	return r.level() > 0 && r.level() >= required.level()
+}
+
+// Outranks reports whether the role is above the other one, an admin
+// acts only on the users below them.
+func (r Role) Outranks(other Role) bool {
+	return r.level() > other.level()
+}
+
+func (r Role) level() int {
+	switch r {
+	case Support:
+		return 1
+	case Moderator:
+		return 2
+	case Superadmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Admin is the user with a role. The admins from the config are
+// superadmins which are not stored and can't be revoked.
+type Admin struct {
+	UserID    int64
+	Role      Role
+	GrantedBy int64
+	CreatedAt time.Time
+	Bootstrap bool
+}
+
+func (a *Admin) String() string {
+	b := &strings.Builder{}
+
+	b.WriteString("Admin{UserID: ")
+	b.WriteString(strconv.FormatInt(a.UserID, 10))
+	b.WriteString(", Role: ")
+	b.WriteString(string(a.Role))
+	b.WriteString(", GrantedBy: ")
+	b.WriteString(strconv.FormatInt(a.GrantedBy, 10))
+	b.WriteString(", Bootstrap: ")
+	b.WriteString(strconv.FormatBool(a.Bootstrap))
+	b.WriteRune('}')
+
+	return b.String()
+}
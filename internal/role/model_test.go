@@ -0,0 +1,77 @@
+package role
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name string
+		want Role
+		ok   bool
+	}{
+		{"support", Support, true},
+		{" Moderator ", Moderator, true},
+		{"SUPERADMIN", Superadmin, true},
+		{"admin", Role("admin"), false},
+		{"", None, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, ok := Parse(tc.name)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, r)
+		})
+	}
+}
+
+func TestCan(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		role     Role
+		required Role
+		want     bool
+	}{
+		{Superadmin, Superadmin, true},
+		{Superadmin, Support, true},
+		{Moderator, Support, true},
+		{Moderator, Moderator, true},
+		{Moderator, Superadmin, false},
+		{Support, Moderator, false},
+		{None, Support, false},
+		{None, None, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.role)+"/"+string(tc.required), func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.role.Can(tc.required))
+		})
+	}
+}
+
+func TestOutranks(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		role  Role
+		other Role
+		want  bool
+	}{
+		{Moderator, None, true},
+		{Moderator, Support, true},
+		{Moderator, Moderator, false},
+		{Moderator, Superadmin, false},
+		{Superadmin, Moderator, true},
+		{Superadmin, Superadmin, false},
+		{None, None, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.role)+"/"+string(tc.other), func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.role.Outranks(tc.other))
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package role
+
+import (
+	"context"
+	"sync"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrNoAdmin = er.New("the user has no role", "", nil)
+
+var (
+	instance *pgRepository
+	once     sync.Once
+)
+
+type pgRepository struct {
+	log *logger.Logger
+	db  *pgxpool.Pool
+}
+
+// NewRepository creates new role repository.
+func NewRepository(ctx context.Context, log *logger.Logger, db *pgxpool.Pool) (*pgRepository, error) {
+	once.Do(func() {
+		instance = &pgRepository{log: log, db: db}
+	})
+
+	return instance, nil
+}
+
+// Save grants the role, the previous role of the user is replaced.
+func (repo *pgRepository) Save(ctx context.Context, a *Admin) error {
+	const op string = "role.repository.Save"
+
+	if _, err := repo.db.Exec(ctx,
+		`INSERT INTO admin_roles (user_id, role, granted_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET role = $2, granted_by = $3, created_at = CURRENT_TIMESTAMP;`,
+		a.UserID, string(a.Role), a.GrantedBy); err != nil {
+		return er.New("unable to save role", op, err)
+	}
+
+	return nil
+}
+
+func (repo *pgRepository) Remove(ctx context.Context, userID int64) error {
+	const op string = "role.repository.Remove"
+
+	tag, err := repo.db.Exec(ctx,
+		`DELETE FROM admin_roles WHERE user_id = $1;`,
+		userID)
+	if err != nil {
+		return er.New("unable to remove role", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoAdmin
+	}
+
+	return nil
+}
+
+func (repo *pgRepository) All(ctx context.Context) ([]*Admin, error) {
+	const op string = "role.repository.All"
+
+	rows, err := repo.db.Query(ctx,
+		`SELECT user_id, role, granted_by, created_at
+		FROM admin_roles ORDER BY user_id;`)
+	if err != nil {
+		return nil, er.New("unable to get roles", op, err)
+	}
+	defer rows.Close()
+
+	admins := make([]*Admin, 0)
+	for rows.Next() {
+		var (
+			a    Admin
+			role string
+		)
+		if err := rows.Scan(&a.UserID, &role, &a.GrantedBy, &a.CreatedAt); err != nil {
+			return nil, er.New("unable to scan role", op, err)
+		}
+		a.Role = Role(role)
+		admins = append(admins, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, er.New("unable to get roles", op, err)
+	}
+
+	return admins, nil
+}
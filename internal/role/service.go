@@ -0,0 +1,179 @@
+package role
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+var ErrBootstrap = er.New("the admin is set in the config", "", nil)
+
+type Repository interface {
+	Save(ctx context.Context, a *Admin) error
+	Remove(ctx context.Context, userID int64) error
+	All(ctx context.Context) ([]*Admin, error)
+}
+
+// service keeps all the roles in memory, the role is checked on every
+// update. The table is small and is loaded on the first check.
+type service struct {
+	log  *logger.Logger
+	repo Repository
+
+	mu          sync.RWMutex
+	loaded      bool
+	superadmins map[int64]struct{}
+	admins      map[int64]*Admin
+}
+
+func NewService(ctx context.Context, log *logger.Logger, repo Repository, superadmins []int64) *service {
+	s := &service{log: log, repo: repo, admins: make(map[int64]*Admin)}
+	s.SetSuperadmins(superadmins)
+
+	return s
+}
+
+// SetSuperadmins replaces the admins from the config, it is used on config reload.
+func (s *service) SetSuperadmins(ids []int64) {
+	set := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+
+	s.mu.Lock()
+	s.superadmins = set
+	s.mu.Unlock()
+}
+
+// Role returns the role of the user, None for the users without a role.
+// Only the config admins are known while the roles can't be loaded.
+func (s *service) Role(ctx context.Context, userID int64) Role {
+	s.mu.RLock()
+	_, super := s.superadmins[userID]
+	a, ok := s.admins[userID]
+	loaded := s.loaded
+	s.mu.RUnlock()
+
+	switch {
+	case super:
+		return Superadmin
+	case ok:
+		return a.Role
+	case loaded:
+		return None
+	}
+
+	if err := s.load(ctx); err != nil {
+		logger.L(ctx).Error("failed to load roles", logger.ErrAttr(err))
+		return None
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if a, ok := s.admins[userID]; ok {
+		return a.Role
+	}
+	return None
+}
+
+func (s *service) Grant(ctx context.Context, userID int64, r Role, by int64) error {
+	ctx, span := tracing.Start(ctx, "role.service.Grant")
+	defer span.End()
+
+	if s.bootstrap(userID) {
+		return ErrBootstrap
+	}
+
+	a := &Admin{UserID: userID, Role: r, GrantedBy: by, CreatedAt: time.Now()}
+	if err := s.repo.Save(ctx, a); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.admins[userID] = a
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *service) Revoke(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "role.service.Revoke")
+	defer span.End()
+
+	if s.bootstrap(userID) {
+		return ErrBootstrap
+	}
+
+	if err := s.repo.Remove(ctx, userID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.admins, userID)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// List returns the config admins first, then the granted roles.
+func (s *service) List(ctx context.Context) ([]*Admin, error) {
+	ctx, span := tracing.Start(ctx, "role.service.List")
+	defer span.End()
+
+	if err := s.load(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]*Admin, 0, len(s.superadmins)+len(s.admins))
+	for id := range s.superadmins {
+		list = append(list, &Admin{UserID: id, Role: Superadmin, Bootstrap: true})
+	}
+	for id, a := range s.admins {
+		if _, ok := s.superadmins[id]; !ok {
+			list = append(list, a)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Bootstrap != list[j].Bootstrap {
+			return list[i].Bootstrap
+		}
+		return list[i].UserID < list[j].UserID
+	})
+
+	return list, nil
+}
+
+func (s *service) bootstrap(userID int64) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.superadmins[userID]
+	return ok
+}
+
+func (s *service) load(ctx context.Context) error {
+	admins, err := s.repo.All(ctx)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[int64]*Admin, len(admins))
+	for _, a := range admins {
+		byID[a.UserID] = a
+	}
+
+	s.mu.Lock()
+	s.admins = byID
+	s.loaded = true
+	s.mu.Unlock()
+
+	return nil
+}
@@ -1,80 +1,197 @@
-package router
-
-import (
-	"context"
-	"archive_bot/internal/entities"
-	"archive_bot/pkg/logger"
-
-	"github.com/go-telegram/bot"
-	"github.com/go-telegram/bot/models"
-)
-
-const (
-	admin      string = "/adm"
-	usersCount string = "adm_count_users"
-)
-
-func (r *router) RouteAdminMessage(ctx context.Context, b *bot.Bot, update *models.Update) {
-	log := r.log.With(logger.String("operation", "router.RouteAdmin"))
-
-	if update.Message.From.ID != r.adminID {
-		log.Warn("not admin wanted to use", logger.Int64("user_ID", update.Message.From.ID))
-		return
-	}
-
-	event := entities.NewEvent(ctx, update)
-	r.process.AddMessageID(event.Meta.UserID, event.Meta.MessageID)
-
-	command, text := commandAndText(event.Text)
-	if text != "" {
-		event.Text = text
-	}
-
-	log.Debug("switch admin message", logger.String("command", command))
-	switch command {
-	case admin:
-		go r.adminPanel(ctx, b, event)
-	default:
-		go r.doUnknown(ctx, b, event)
-	}
-}
-
-func (r *router) RouteAdminCallback(ctx context.Context, b *bot.Bot, update *models.Update) {
-	log := r.log.With(logger.String("operation", "router.RouteAdmin"))
-
-	event := entities.NewEvent(ctx, update)
-	r.process.AddMessageID(event.Meta.UserID, event.Meta.MessageID)
-
-	if event.Meta.UserID != r.adminID {
-		log.Warn("not admin wanted to use", logger.Int64("user_ID", update.Message.From.ID))
-		return
-	}
-
-	log.Debug("switch admin callback", logger.String("command", event.Text))
-	switch event.Text {
-	case usersCount:
-		go r.doCountUsers(ctx, b, event)
-
-	}
-}
-
-func (r *router) adminPanel(ctx context.Context, b *bot.Bot, event *entities.Event) {
-	btns := make([][]models.InlineKeyboardButton, 0, 1)
-	btns = append(btns, []models.InlineKeyboardButton{
-		{CallbackData: usersCount, Text: "Count of users"},
-	})
-
-	panel := entities.NewAnswer(event, true, &entities.AnswerParams{
-		Message:  "Welcome to admin panel!",
-		Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: btns},
-	})
-
-	go r.sendAnswers(ctx, b, []*entities.Answer{panel})
-}
-
-func (r *router) doCountUsers(ctx context.Context, b *bot.Bot, event *entities.Event) {
-	message := r.process.CountUsers(ctx)
-	go r.sendAnswers(ctx, b, []*entities.Answer{
-		sendMessage(event, message),
-	})
-}
+package router
+
+import (
+	"context"
+	"strings"
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/role"
+	"archive_bot/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	admin      string = "/adm"
+	usersCount string = "adm_count_users"
+
+	// AdminCallbackPrefix starts the data of every admin callback.
+	AdminCallbackPrefix string = "adm_"
+)
+
+type Roles interface {
+	Role(ctx context.Context, userID int64) role.Role
+	Grant(ctx context.Context, userID int64, r role.Role, by int64) error
+	Revoke(ctx context.Context, userID int64) error
+	List(ctx context.Context) ([]*role.Admin, error)
+}
+
+type Auditor interface {
+	Log(ctx context.Context, adminID int64, action string, details string)
+}
+
+// adminAction is an admin handler with the lowest role allowed to run it.
+type adminAction struct {
+	name string
+	role role.Role
+	h    func(r *router, ctx context.Context, b *bot.Bot, event *entities.Event)
+}
+
+// adminCallback matches the callback data exactly or by the prefix,
+// the prefix is cut from the event text.
+type adminCallback struct {
+	data   string
+	prefix bool
+	action adminAction
+}
+
+// adminCommands are the admin commands, they are matched exactly.
+var adminCommands = map[string]adminAction{
+	admin:      {"admin_panel", role.Support, (*router).adminPanel},
+	userLookup: {"user_lookup", role.Support, (*router).doUserLookup},
+	userBan:    {"user_ban", role.Moderator, (*router).doUserBan},
+	userUnban:  {"user_unban", role.Moderator, (*router).doUserUnban},
+	userQuota:  {"user_quota", role.Moderator, (*router).doUserQuota},
+	userWipe:   {"user_wipe_prompt", role.Superadmin, (*router).doUserWipe},
+	roleGrant:  {"role_grant", role.Superadmin, (*router).doRoleGrant},
+	roleRevoke: {"role_revoke", role.Superadmin, (*router).doRoleRevoke},
+	roleList:   {"role_list", role.Superadmin, (*router).doRoleList},
+}
+
+var adminCallbacks = []adminCallback{
+	{usersCount, false, adminAction{"count_users", role.Support, (*router).doCountUsers}},
+	{usersHelp, false, adminAction{"users_help", role.Support, (*router).doUsersHelp}},
+	{statsText, false, adminAction{"stats", role.Support, (*router).doStats}},
+	{statsCSV, false, adminAction{"stats_csv", role.Support, (*router).doStatsCSV}},
+	{rolesHelp, false, adminAction{"role_list", role.Superadmin, (*router).doRoleList}},
+	{broadcastCompose, false, adminAction{"broadcast_compose", role.Superadmin, (*router).doBroadcastCompose}},
+	{broadcastAbort, false, adminAction{"broadcast_abort", role.Superadmin, (*router).doBroadcastAbort}},
+	{broadcastSend, true, adminAction{"broadcast_send", role.Superadmin, (*router).doBroadcastSend}},
+	{broadcastCancel, true, adminAction{"broadcast_cancel", role.Superadmin, (*router).doBroadcastCancel}},
+	{userBanCB, true, adminAction{"user_ban", role.Moderator, (*router).doUserBan}},
+	{userUnbanCB, true, adminAction{"user_unban", role.Moderator, (*router).doUserUnban}},
+	{userWipeCB, true, adminAction{"user_wipe_prompt", role.Superadmin, (*router).doUserWipe}},
+	{userWipeOkCB, true, adminAction{"user_wipe", role.Superadmin, (*router).doUserWipeConfirm}},
+}
+
+// adminButtons are the buttons of the admin panel.
+var adminButtons = []struct {
+	data string
+	key  string
+	role role.Role
+}{
+	{usersCount, messages.AdminButtonCount, role.Support},
+	{usersHelp, messages.AdminButtonUsers, role.Support},
+	{statsText, messages.AdminButtonStats, role.Support},
+	{broadcastCompose, messages.AdminButtonBroadcast, role.Superadmin},
+	{rolesHelp, messages.AdminButtonAdmins, role.Superadmin},
+}
+
+// IsAdminCommand matches the private messages starting with an admin
+// command, the other messages go to the user routes.
+func IsAdminCommand(update *models.Update) bool {
+	if update.Message == nil || isGroupChat(update.Message.Chat.Type) {
+		return false
+	}
+
+	command, _ := commandAndText(update.Message.Text)
+	_, ok := adminCommands[command]
+	return ok
+}
+
+// RouteAdminMessage handles the messages matched by IsAdminCommand.
+// The users without a role get the answer to an unknown command.
+func (r *router) RouteAdminMessage(ctx context.Context, b *bot.Bot, update *models.Update) {
+	ctx, span := startUpdate(ctx, update, "router.RouteAdminMessage")
+	defer span.End()
+
+	if r.roles.Role(ctx, update.Message.From.ID) == role.None {
+		r.RouteMessage(ctx, b, update)
+		return
+	}
+
+	event := entities.NewEvent(ctx, update)
+	r.process.AddMessageID(ctx, event.Meta.UserID, event.Meta.MessageID)
+
+	command, text := commandAndText(event.Text)
+	if text != "" {
+		event.Text = text
+	}
+
+	r.runAdmin(ctx, b, event, adminCommands[command])
+}
+
+func (r *router) RouteAdminCallback(ctx context.Context, b *bot.Bot, update *models.Update) {
+	ctx, span := startUpdate(ctx, update, "router.RouteAdminCallback")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "router.RouteAdminCallback"))
+	event := entities.NewEvent(ctx, update)
+	r.process.AddMessageID(ctx, event.Meta.UserID, event.Meta.MessageID)
+
+	log.Debug("switch admin callback", logger.String("command", event.Text))
+	for _, cb := range adminCallbacks {
+		switch {
+		case !cb.prefix && event.Text == cb.data:
+		case cb.prefix && strings.HasPrefix(event.Text, cb.data):
+			event.Text = strings.TrimPrefix(event.Text, cb.data)
+		default:
+			continue
+		}
+
+		r.runAdmin(ctx, b, event, cb.action)
+		return
+	}
+}
+
+// runAdmin checks the role of the admin, records the action in the audit
+// log and handles it.
+func (r *router) runAdmin(ctx context.Context, b *bot.Bot, event *entities.Event, a adminAction) {
+	userRole := r.roles.Role(ctx, event.Meta.UserID)
+	if !userRole.Can(a.role) {
+		logger.L(ctx).Warn(
+			"admin action not allowed",
+			logger.Int64("user_ID", event.Meta.UserID),
+			logger.String("role", string(userRole)),
+			logger.String("action", a.name),
+		)
+		if userRole != role.None {
+			r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.AdminNotAllowed)})
+		}
+		return
+	}
+
+	r.audit.Log(ctx, event.Meta.UserID, a.name, event.Text)
+	r.handle(ctx, b, event, a.name, func(ctx context.Context, b *bot.Bot, event *entities.Event) {
+		a.h(r, ctx, b, event)
+	})
+}
+
+func (r *router) adminPanel(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	userRole := r.roles.Role(ctx, event.Meta.UserID)
+
+	btns := make([][]models.InlineKeyboardButton, 0, len(adminButtons))
+	for _, btn := range adminButtons {
+		if userRole.Can(btn.role) {
+			btns = append(btns, []models.InlineKeyboardButton{
+				{CallbackData: btn.data, Text: i18n.T(event.Meta.Language, btn.key)},
+			})
+		}
+	}
+
+	panel := entities.NewAnswer(event, true, &entities.AnswerParams{
+		Message:  i18n.T(event.Meta.Language, messages.AdminPanel),
+		Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: btns},
+	})
+
+	r.sendAnswers(ctx, b, []*entities.Answer{panel})
+}
+
+func (r *router) doCountUsers(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	message := r.process.CountUsers(ctx, event)
+	r.sendAnswers(ctx, b, []*entities.Answer{
+		sendMessage(event, message),
+	})
+}
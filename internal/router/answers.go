@@ -1,210 +1,287 @@
-package router
-
-import (
-	"context"
-	"sort"
-	"strconv"
-
-	"archive_bot/internal/const/buttons"
-	"archive_bot/internal/const/messages"
-	"archive_bot/internal/entities"
-	"archive_bot/pkg/logger"
-
-	"github.com/go-telegram/bot"
-	"github.com/go-telegram/bot/models"
-)
-
-func (r *router) doDefaultCallback(ctx context.Context, b *bot.Bot, event *entities.Event) {
-	log := logger.L(ctx).With(logger.String("operation", "router.doDefaultCallback"))
-	if message := r.process.DeleteFolderEnd(ctx, event); message != "" {
-		btns := r.process.Folders(ctx, event)
-		event.IsEdited = true
-		go func() {
-			r.deleteMessages(ctx, b, event)
-			if message != messages.WrongFolder {
-				r.sendAnswers(ctx, b, []*entities.Answer{sendFoldersList(event, btns, false)})
-				r.process.SetInt(isFolderSetKey(event), 1)
-			} else {
-				event.IsEdited = false
-				r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
-			}
-		}()
-		return
-	}
-	var answers []*entities.Answer
-	if message := r.process.MoveNoteEnd(ctx, event); message != "" {
-		log.Debug("move note", logger.String("message", message))
-		answers = append(answers, sendMessage(event, message))
-	}
-	log.Debug("select folder", logger.Int("folder_id", event.FolderID))
-	notes, folderName := r.process.SelectFolder(ctx, event)
-	answers = append(answers, sendMessage(event, messages.FolderEmoji))
-	answers = append(answers, checkDefaultFolder(event, folderName))
-	answers = collectNotes(answers, event, notes)
-
-	go func() {
-		r.deleteMessages(ctx, b, event)
-		r.sendAnswers(ctx, b, answers)
-	}()
-}
-
-func (r *router) doEmpty(ctx context.Context, b *bot.Bot, event *entities.Event) {
-	if message := r.process.AddFolderEnd(ctx, event); message != "" {
-		btns := r.process.Folders(ctx, event)
-		event.IsEdited = true
-		go func() {
-			r.deleteMessages(ctx, b, event)
-			r.sendAnswers(ctx, b, []*entities.Answer{sendFoldersList(event, btns, false)})
-			r.process.SetInt(isFolderSetKey(event), 1)
-		}()
-		return
-	}
-	ap := r.process.Save(ctx, event)
-	go func() {
-		if ap.Message != "" {
-			r.sendAnswers(ctx, b, []*entities.Answer{
-				sendNote(event, event.NoteID, event.FolderID, true, ap),
-			})
-		}
-	}()
-}
-
-func (r *router) doStart(ctx context.Context, b *bot.Bot, event *entities.Event) {
-	message, btn := r.process.Start(ctx, event)
-	event.Meta.MessageID = r.process.FolderMsgID(event.Meta.UserID)
-	go func() {
-		r.deleteMessages(ctx, b, event)
-		r.deleteMessage(ctx, b, event)
-		r.process.SetInt(isFolderSetKey(event), 0)
-		r.sendAnswers(ctx, b, []*entities.Answer{sendFoldersButton(event, message, btn, true)})
-	}()
-}
-
-func (r *router) doShowFolders(ctx context.Context, b *bot.Bot, event *entities.Event) {
-	btns := r.process.Folders(ctx, event)
-	isFolderSet := r.process.Int("isFolderSet:" + strconv.FormatInt(event.Meta.UserID, 10))
-	go func() {
-		r.deleteMessages(ctx, b, event)
-		if isFolderSet == 0 {
-			r.sendAnswers(ctx, b, []*entities.Answer{sendFoldersList(event, btns, false)})
-			r.process.SetInt(isFolderSetKey(event), 1)
-		}
-	}()
-}
-
-func (r *router) doSaveTo(ctx context.Context, b *bot.Bot, event *entities.Event) {
-	log := logger.L(ctx).With(logger.String("operation", "router.doSaveTo"))
-	if message := r.process.SaveTo(ctx, event); message != "" {
-		btns := r.process.Folders(ctx, event)
-		event.Meta.MessageID = r.process.FolderMsgID(event.Meta.UserID)
-		log.Debug(
-			"save note to",
-			logger.String("message", message),
-			logger.Int("foldersID", event.Meta.MessageID),
-		)
-		go func() {
-			r.deleteMessages(ctx, b, event)
-			r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
-			event.IsEdited = true
-			r.sendAnswers(ctx, b, []*entities.Answer{sendFoldersList(event, btns, false)})
-			r.process.SetInt(isFolderSetKey(event), 1)
-		}()
-	}
-}
-
-func (r *router) doUnknown(ctx context.Context, b *bot.Bot, event *entities.Event) {
-	r.sendAnswers(ctx, b, []*entities.Answer{
-		sendMessage(event, messages.UnknownCommand),
-	})
-}
-
-func (r *router) doMoveNote(ctx context.Context, b *bot.Bot, event *entities.Event) {
-	event.NoteID, event.FolderID, _ = ParseButtonCallback(event.Text)
-	message := r.process.MoveNoteStart(ctx, event)
-	event.IsEdited = true
-	go func() {
-		r.deleteMessages(ctx, b, event)
-		r.sendAnswers(ctx, b, []*entities.Answer{
-			sendMessage(event, message),
-		})
-	}()
-}
-
-func (r *router) doDeleteNote(ctx context.Context, b *bot.Bot, event *entities.Event) {
-	event.NoteID, event.FolderID, _ = ParseButtonCallback(event.Text)
-	message := r.process.RemoveNote(ctx, event)
-	go func() {
-		r.deleteMessage(ctx, b, event)
-		r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
-	}()
-}
-
-func (r *router) doCreateFolder(ctx context.Context, b *bot.Bot, event *entities.Event) {
-	message := r.process.AddFolderStart(ctx, event)
-	go r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
-}
-
-func (r *router) doDeleteFolder(ctx context.Context, b *bot.Bot, event *entities.Event) {
-	message := r.process.DeleteFolderStart(ctx, event)
-	go r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
-}
-
-func sendMessage(event *entities.Event, message string) *entities.Answer {
-	return entities.NewAnswer(event, true, &entities.AnswerParams{Message: message})
-}
-
-func isFolderSetKey(event *entities.Event) string {
-	return "isFolderSet:" + strconv.FormatInt(event.Meta.UserID, 10)
-}
-
-func (r *router) doInfo(ctx context.Context, b *bot.Bot, event *entities.Event) {
-	videos := make([]*entities.Answer, 0, len(messages.InfoMap))
-	for videoID, caption := range messages.InfoMap {
-		ans := entities.Answer{UserID: event.Meta.UserID, DeleteAfter: true}
-		ans.SendVideo = &bot.SendVideoParams{
-			ChatID:  event.Meta.ChatID,
-			Video:   &models.InputFileString{Data: videoID},
-			Caption: caption,
-		}
-		videos = append(videos, &ans)
-	}
-
-	sort.Slice(videos, func(i, j int) bool {
-		return videos[i].SendVideo.Caption < videos[j].SendVideo.Caption
-	})
-
-	go func() {
-		r.deleteMessage(ctx, b, event)
-		r.sendAnswers(ctx, b, videos)
-	}()
-}
-
-func checkDefaultFolder(event *entities.Event, folderName string) *entities.Answer {
-	if folderName != "default" {
-		return sendFoldersButton(event, "📁"+folderName, buttons.Folders, true)
-	}
-	return sendFoldersButton(event, "📁"+buttons.DefaultFolderName, buttons.Folders, true)
-}
-
-func collectNotes(
-	answers []*entities.Answer,
-	event *entities.Event,
-	notes map[int]*entities.AnswerParams,
-) []*entities.Answer {
-	if len(notes) == 0 {
-		return append(answers, sendMessage(event, messages.NotesIsEmpty))
-	}
-	notesIDs := make([]int, 0, len(notes))
-	for id := range notes {
-		notesIDs = append(notesIDs, id)
-	}
-	sort.Ints(notesIDs)
-
-	for _, noteID := range notesIDs {
-		answers = append(answers, sendNote(
-			event, noteID, event.FolderID, true, notes[noteID],
-		))
-	}
-
-	return answers
-}
+package router
+
+import (
+	"cmp"
+	"context"
+	"sort"
+	"strconv"
+
+	"archive_bot/internal/const/buttons"
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+	"archive_bot/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+func (r *router) doDefaultCallback(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	log := logger.L(ctx).With(logger.String("operation", "router.doDefaultCallback"))
+	if res := r.process.DeleteFolderEnd(ctx, event); res.Status != entities.StatusSkipped {
+		btns := r.process.Folders(ctx, event)
+		event.IsEdited = true
+		r.deleteMessages(ctx, event)
+		if res.Status != entities.StatusRejected {
+			r.sendAnswers(ctx, b, []*entities.Answer{sendFoldersList(event, btns, r.folderColumns, false)})
+			r.process.SetInt(ctx, isFolderSetKey(event), 1)
+		} else {
+			event.IsEdited = false
+			r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, res.Text)})
+		}
+		return
+	}
+	var answers []*entities.Answer
+	if message := r.process.MoveNoteEnd(ctx, event); message != "" {
+		log.Debug("move note", logger.String("message", message))
+		answers = append(answers, sendMessage(event, message))
+	}
+	log.Debug("select folder", logger.Int("folder_id", event.FolderID))
+	r.showFolder(ctx, b, event, answers)
+}
+
+// showFolder sends the notes of the folder of the event text after the
+// answers instead of the previous messages.
+func (r *router) showFolder(ctx context.Context, b *bot.Bot, event *entities.Event, answers []*entities.Answer) {
+	notes, folderName := r.process.SelectFolder(ctx, event)
+	answers = append(answers, folderHeader(event, len(notes) > 0))
+	answers = append(answers, checkDefaultFolder(event, folderName))
+	answers = collectNotes(answers, event, notes)
+
+	r.deleteMessages(ctx, event)
+	r.sendAnswers(ctx, b, answers)
+}
+
+func (r *router) doEmpty(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	if res := r.process.AddFolderEnd(ctx, event); res.Status != entities.StatusSkipped {
+		btns := r.process.Folders(ctx, event)
+		event.IsEdited = true
+		r.deleteMessages(ctx, event)
+		answers := make([]*entities.Answer, 0, 2)
+		if res.Status == entities.StatusRejected {
+			answers = append(answers, sendMessage(event, res.Text))
+		}
+		answers = append(answers, sendFoldersList(event, btns, r.folderColumns, false))
+		r.sendAnswers(ctx, b, answers)
+		r.process.SetInt(ctx, isFolderSetKey(event), 1)
+		return
+	}
+	if r.editFolder(ctx, b, event) || r.tagSelection(ctx, b, event) {
+		return
+	}
+	if text, name := splitInlineFolder(event.Text); name != "" {
+		event.Text, event.FolderName = text, name
+	}
+	ap := r.process.Save(ctx, event)
+	switch {
+	case ap.Message == "":
+	case event.NoteID == 0:
+		// the note is not saved, there is nothing to move or delete
+		r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, ap.Message)})
+	default:
+		r.sendAnswers(ctx, b, []*entities.Answer{
+			sendNote(event, event.NoteID, event.FolderID, true, ap),
+		})
+	}
+	if event.NoteID != 0 {
+		r.suggestFolder(ctx, b, event, event.NoteID)
+	}
+}
+
+func (r *router) doStart(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	message, btn := r.process.Start(ctx, event)
+	event.Meta.MessageID = r.process.FolderMsgID(ctx, event.Meta.UserID)
+	r.deleteMessages(ctx, event)
+	r.deleteMessage(ctx, event)
+	r.process.SetInt(ctx, isFolderSetKey(event), 0)
+	r.sendAnswers(ctx, b, []*entities.Answer{
+		sendFoldersButton(event, message, btn, true),
+	})
+}
+
+func (r *router) doShowFolders(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	btns := r.process.Folders(ctx, event)
+	isFolderSet := r.process.Int(ctx, "isFolderSet:"+strconv.FormatInt(event.Meta.UserID, 10))
+	r.deleteMessages(ctx, event)
+	if isFolderSet == 0 {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendFoldersList(event, btns, r.folderColumns, false)})
+		r.process.SetInt(ctx, isFolderSetKey(event), 1)
+	}
+}
+
+func (r *router) doSaveTo(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	log := logger.L(ctx).With(logger.String("operation", "router.doSaveTo"))
+	res := r.process.SaveTo(ctx, event)
+	if res.Status == entities.StatusSuggested {
+		if s := r.process.FolderSuggestion(ctx, event); s != nil {
+			r.sendAnswers(ctx, b, []*entities.Answer{suggestionPrompt(event, s)})
+		}
+		return
+	}
+	if message := res.Text; message != "" {
+		btns := r.process.Folders(ctx, event)
+		event.Meta.MessageID = r.process.FolderMsgID(ctx, event.Meta.UserID)
+		log.Debug(
+			"save note to",
+			logger.String("message", message),
+			logger.Int("foldersID", event.Meta.MessageID),
+		)
+		r.deleteMessages(ctx, event)
+		r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+		event.IsEdited = true
+		r.sendAnswers(ctx, b, []*entities.Answer{sendFoldersList(event, btns, r.folderColumns, false)})
+		r.process.SetInt(ctx, isFolderSetKey(event), 1)
+	}
+}
+
+func (r *router) doUnknown(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	r.sendAnswers(ctx, b, []*entities.Answer{
+		sendKey(event, messages.UnknownCommand),
+	})
+}
+
+func (r *router) doMoveNote(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	event.NoteID, event.FolderID, _ = ParseButtonCallback(event.Text)
+	message := r.process.MoveNoteStart(ctx, event)
+	event.IsEdited = true
+	r.deleteMessages(ctx, event)
+	r.sendAnswers(ctx, b, []*entities.Answer{
+		sendMessage(event, message),
+	})
+}
+
+func (r *router) doDeleteNote(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	event.NoteID, event.FolderID, _ = ParseButtonCallback(event.Text)
+	message := r.process.RemoveNote(ctx, event)
+	r.deleteMessage(ctx, event)
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+}
+
+// doFavorite toggles the favorite flag of the note and redraws its buttons
+// in place.
+func (r *router) doFavorite(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	var withMedia string
+	event.NoteID, event.FolderID, withMedia = ParseButtonCallback(event.Text)
+	favorite, res := r.process.ToggleFavorite(ctx, event)
+
+	ans := &entities.Answer{
+		UserID: event.Meta.UserID,
+		AnswerCallbackQuery: &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: event.Meta.CallbackQueryID,
+			Text:            res.Text,
+		},
+	}
+	if res.Status == entities.StatusDone {
+		noteAndFolder := strconv.Itoa(event.NoteID) + buttons.Delimiter + strconv.Itoa(event.FolderID)
+		if withMedia != "" {
+			noteAndFolder += buttons.Delimiter + withMedia
+		}
+		ans.EditMessageReplyMarkup = &bot.EditMessageReplyMarkupParams{
+			ChatID:      event.Meta.ChatID,
+			MessageID:   event.Meta.MessageID,
+			ReplyMarkup: noteKeyboard(noteAndFolder, favorite),
+		}
+	}
+	r.sendAnswers(ctx, b, []*entities.Answer{ans})
+}
+
+// doFavorites shows the favorite notes from all the folders.
+func (r *router) doFavorites(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	notes := r.process.Favorites(ctx, event)
+	r.deleteMessages(ctx, event)
+	if len(notes) == 0 {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.FavoritesEmpty)})
+		return
+	}
+
+	answers := []*entities.Answer{sendKey(event, messages.FavoritesCaption)}
+	r.sendAnswers(ctx, b, collectNotes(answers, event, notes))
+}
+
+func (r *router) doCreateFolder(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	message := r.process.AddFolderStart(ctx, event)
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+}
+
+func (r *router) doDeleteFolder(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	message := r.process.DeleteFolderStart(ctx, event)
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+}
+
+// sendMessage sends the text as is.
+func sendMessage(event *entities.Event, text string) *entities.Answer {
+	return entities.NewAnswer(event, true, &entities.AnswerParams{
+		Message: text,
+	})
+}
+
+// sendKey renders the message key in the language of the user.
+func sendKey(event *entities.Event, key string, args ...any) *entities.Answer {
+	return sendMessage(event, i18n.T(event.Meta.Language, key, args...))
+}
+
+func isFolderSetKey(event *entities.Event) string {
+	return "isFolderSet:" + strconv.FormatInt(event.Meta.UserID, 10)
+}
+
+func (r *router) doToken(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	message := r.process.Token(ctx, event)
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+}
+
+func (r *router) doRules(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	message := r.process.Rules(ctx, event)
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+}
+
+func (r *router) doTranscribe(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	message := r.process.Transcribe(ctx, event)
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+}
+
+func (r *router) doInfo(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	videos := make([]*entities.Answer, 0, len(messages.InfoMap))
+	for videoID, caption := range messages.InfoMap {
+		ans := entities.Answer{UserID: event.Meta.UserID, DeleteAfter: true}
+		ans.SendVideo = &bot.SendVideoParams{
+			ChatID:  event.Meta.ChatID,
+			Video:   &models.InputFileString{Data: videoID},
+			Caption: i18n.T(event.Meta.Language, caption),
+		}
+		videos = append(videos, &ans)
+	}
+
+	sort.Slice(videos, func(i, j int) bool {
+		return videos[i].SendVideo.Caption < videos[j].SendVideo.Caption
+	})
+
+	r.deleteMessage(ctx, event)
+	r.sendAnswers(ctx, b, videos)
+}
+
+func checkDefaultFolder(event *entities.Event, folderName string) *entities.Answer {
+	if folderName != "default" {
+		return sendFoldersButton(event, "📁"+folderName, buttons.Folders, true)
+	}
+	return sendFoldersButton(
+		event, "📁"+i18n.T(event.Meta.Language, messages.FolderDefault), buttons.Folders, true,
+	)
+}
+
+func collectNotes(
+	answers []*entities.Answer,
+	event *entities.Event,
+	notes map[int]*entities.AnswerParams,
+) []*entities.Answer {
+	if len(notes) == 0 {
+		return append(answers, sendKey(event, messages.NotesIsEmpty))
+	}
+	for _, noteID := range entities.SortNotes(notes) {
+		ap := notes[noteID]
+		answers = append(answers, sendNote(
+			event, noteID, cmp.Or(ap.FolderID, event.FolderID), true, ap,
+		))
+	}
+
+	return answers
+}
@@ -0,0 +1,131 @@
+package router
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/role"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	broadcastCompose string = "adm_broadcast"
+	broadcastAbort   string = "adm_bc_abort"
+	broadcastSend    string = "adm_bc_send:"
+	broadcastCancel  string = "adm_bc_cancel:"
+
+	composeKeyPrefix string = "adm-broadcast:"
+)
+
+type Broadcaster interface {
+	Draft(ctx context.Context, adminID int64, chatID int64, messageID int, lang i18n.Lang) (int64, error)
+	Confirm(ctx context.Context, id int64) error
+	Cancel(ctx context.Context, id int64) error
+}
+
+var broadcastDraft = adminAction{"broadcast_draft", role.Superadmin, (*router).doBroadcastDraft}
+
+// composing reports whether the next message of the admin is a broadcast.
+func (r *router) composing(ctx context.Context, event *entities.Event) bool {
+	return r.roles.Role(ctx, event.Meta.UserID).Can(role.Superadmin) &&
+		r.process.Int(ctx, composeKey(event)) == 1
+}
+
+func composeKey(event *entities.Event) string {
+	return composeKeyPrefix + strconv.FormatInt(event.Meta.UserID, 10)
+}
+
+func (r *router) doBroadcastCompose(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	r.process.SetInt(ctx, composeKey(event), 1)
+
+	lang := event.Meta.Language
+	r.sendAnswers(ctx, b, []*entities.Answer{entities.NewAnswer(event, true, &entities.AnswerParams{
+		Message: i18n.T(lang, messages.BroadcastCompose),
+		Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{CallbackData: broadcastAbort, Text: i18n.T(lang, messages.AdminCancel)}},
+		}},
+	})})
+}
+
+func (r *router) doBroadcastAbort(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	r.process.SetInt(ctx, composeKey(event), 0)
+	r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.BroadcastAborted)})
+}
+
+// doBroadcastDraft saves the message as a draft and shows it back to the
+// admin with the buttons to send or cancel it. The message is not deleted
+// with the others, the broadcast copies it.
+func (r *router) doBroadcastDraft(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	log := logger.L(ctx).With(logger.String("operation", "router.doBroadcastDraft"))
+
+	r.process.SetInt(ctx, composeKey(event), 0)
+
+	lang := event.Meta.Language
+	id, err := r.broadcasts.Draft(ctx, event.Meta.UserID, event.Meta.ChatID, event.Meta.MessageID, lang)
+	if err != nil {
+		log.Error("failed to save broadcast", logger.ErrAttr(err))
+		r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.Error)})
+		return
+	}
+
+	idStr := strconv.FormatInt(id, 10)
+	r.sendAnswers(ctx, b, []*entities.Answer{{
+		UserID: event.Meta.UserID,
+		CopyMessage: &bot.CopyMessageParams{
+			ChatID:     event.Meta.ChatID,
+			FromChatID: event.Meta.ChatID,
+			MessageID:  event.Meta.MessageID,
+			ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{{
+				{CallbackData: broadcastSend + idStr, Text: i18n.T(lang, messages.BroadcastSendAll)},
+				{CallbackData: broadcastCancel + idStr, Text: i18n.T(lang, messages.AdminCancel)},
+			}}},
+		},
+	}})
+}
+
+func (r *router) doBroadcastSend(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	id, ok := broadcastID(event.Text, broadcastSend)
+	if !ok {
+		return
+	}
+
+	if err := r.broadcasts.Confirm(ctx, id); err != nil {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.BroadcastNotDraft, id)})
+		return
+	}
+
+	lang := event.Meta.Language
+	idStr := strconv.FormatInt(id, 10)
+	r.sendAnswers(ctx, b, []*entities.Answer{entities.NewAnswer(event, false, &entities.AnswerParams{
+		Message: i18n.T(lang, messages.BroadcastStarted, id),
+		Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{CallbackData: broadcastCancel + idStr, Text: i18n.T(lang, messages.AdminCancel)}},
+		}},
+	})})
+}
+
+func (r *router) doBroadcastCancel(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	id, ok := broadcastID(event.Text, broadcastCancel)
+	if !ok {
+		return
+	}
+
+	message := messages.BroadcastCancelled
+	if err := r.broadcasts.Cancel(ctx, id); err != nil {
+		message = messages.BroadcastOver
+	}
+	r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, message, id)})
+}
+
+func broadcastID(data string, prefix string) (int64, bool) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(data, prefix), 10, 64)
+	return id, err == nil && id > 0
+}
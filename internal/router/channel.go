@@ -0,0 +1,149 @@
+package router
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	linkChannel   string = "/link_channel"
+	unlinkChannel string = "/unlink_channel"
+)
+
+// routeChannelPost mirrors posts of linked channels into the owner's folder.
+func (r *router) routeChannelPost(ctx context.Context, b *bot.Bot, update *models.Update) {
+	log := r.log.With(logger.String("operation", "router.routeChannelPost"))
+
+	if update.ChannelPost != nil {
+		event := entities.NewChannelEvent(ctx, update.ChannelPost)
+		if event.Type == entities.Unknown {
+			return
+		}
+
+		log.Debug("save channel post", logger.Int64("channel_id", event.Meta.ChatID))
+		r.process.SaveChannelPost(ctx, event)
+		return
+	}
+
+	post := update.EditedChannelPost
+	event := entities.NewChannelEvent(ctx, post)
+	if event.Type == entities.Unknown {
+		return
+	}
+	if post.MediaGroupID != "" && post.Caption == "" {
+		event.Text = ""
+	}
+
+	log.Debug("edit channel post", logger.Int64("channel_id", event.Meta.ChatID))
+	r.process.EditChannelPost(ctx, event)
+}
+
+func (r *router) doLinkChannel(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	ref, folderName := splitChannelRef(event.Text)
+	if ref == "" {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.ChannelLinkUsage)})
+		return
+	}
+
+	chat, message := r.verifyChannel(ctx, b, event, ref)
+	if chat == nil {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+		return
+	}
+
+	event.FolderName = folderName
+	message = r.process.LinkChannel(ctx, event, chat.ID, chat.Title, chat.Username)
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+}
+
+func (r *router) doUnlinkChannel(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	ref, _ := splitChannelRef(event.Text)
+	if ref == "" {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.ChannelLinkUsage)})
+		return
+	}
+
+	chat, err := b.GetChat(ctx, &bot.GetChatParams{ChatID: chatIDParam(ref)})
+	if err != nil {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.ChannelNotFound)})
+		return
+	}
+
+	message := r.process.UnlinkChannel(ctx, event, chat.ID)
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+}
+
+// verifyChannel checks that the chat is a channel administrated by both the
+// user and the bot. It returns the chat or the message explaining the failure.
+func (r *router) verifyChannel(
+	ctx context.Context,
+	b *bot.Bot,
+	event *entities.Event,
+	ref string,
+) (*models.ChatFullInfo, string) {
+	log := logger.L(ctx).With(logger.String("operation", "router.verifyChannel"))
+
+	chat, err := b.GetChat(ctx, &bot.GetChatParams{ChatID: chatIDParam(ref)})
+	if err != nil || chat.Type != models.ChatTypeChannel {
+		log.Debug("channel not found", logger.String("channel", ref))
+		return nil, i18n.T(event.Meta.Language, messages.ChannelNotFound)
+	}
+
+	member, err := b.GetChatMember(ctx, &bot.GetChatMemberParams{
+		ChatID: chat.ID,
+		UserID: event.Meta.UserID,
+	})
+	if err != nil || !isChatAdmin(member) {
+		return nil, i18n.T(event.Meta.Language, messages.ChannelNotAdmin)
+	}
+
+	botMember, err := b.GetChatMember(ctx, &bot.GetChatMemberParams{
+		ChatID: chat.ID,
+		UserID: b.ID(),
+	})
+	if err != nil || botMember.Type != models.ChatMemberTypeAdministrator {
+		return nil, i18n.T(event.Meta.Language, messages.ChannelBotNotAdmin)
+	}
+
+	return chat, ""
+}
+
+func isChatAdmin(member *models.ChatMember) bool {
+	return member.Type == models.ChatMemberTypeOwner ||
+		member.Type == models.ChatMemberTypeAdministrator
+}
+
+// splitChannelRef splits "@channel folder name" into the channel reference
+// and the folder name. Commands without arguments give an empty reference.
+func splitChannelRef(text string) (string, string) {
+	if strings.HasPrefix(text, "/") {
+		return "", ""
+	}
+
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", ""
+	}
+
+	return fields[0], strings.Join(fields[1:], " ")
+}
+
+func chatIDParam(ref string) any {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return id
+	}
+	if !strings.HasPrefix(ref, "@") {
+		return "@" + ref
+	}
+	return ref
+}
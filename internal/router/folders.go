@@ -0,0 +1,377 @@
+package router
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"archive_bot/internal/const/buttons"
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/folder"
+	"archive_bot/internal/i18n"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// folderFields are the settings asked by a text message.
+var folderFields = map[string]string{
+	buttons.FolderRename:    folder.FieldName,
+	buttons.FolderIcon:      folder.FieldIcon,
+	buttons.FolderPosition:  folder.FieldPosition,
+	buttons.FolderSplitDate: folder.FieldSplitDate,
+	buttons.FolderAliases:   folder.FieldAliases,
+}
+
+// doSetupFolder turns the folders list into the list of the folders to set
+// up, the hidden ones too.
+func (r *router) doSetupFolder(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	event.IsEdited = true
+	r.sendAnswers(ctx, b, []*entities.Answer{
+		folderSettingsList(event, r.process.FolderList(ctx, event), r.folderColumns),
+	})
+}
+
+func (r *router) doFolderSettings(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	action, id, arg, ok := folderCallback(event.Text)
+	if !ok {
+		r.doUnknown(ctx, b, event)
+		return
+	}
+
+	if action == buttons.FolderList {
+		event.IsEdited = true
+		btns := r.process.Folders(ctx, event)
+		r.sendAnswers(ctx, b, []*entities.Answer{sendFoldersList(event, btns, r.folderColumns, false)})
+		return
+	}
+
+	if field, ok := folderFields[action]; ok {
+		message := r.process.EditFolderStart(ctx, event, id, field)
+		r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+		return
+	}
+
+	switch action {
+	case buttons.FolderMerge, buttons.FolderMergeTo, buttons.FolderSplit:
+		r.reorganizeFolder(ctx, b, event, action, id, arg)
+		return
+	case buttons.FolderSuggestOk, buttons.FolderSuggestNew:
+		message := r.process.AcceptSuggestion(ctx, event, id, action == buttons.FolderSuggestNew)
+		event.IsEdited = true
+		r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+		return
+	case buttons.FolderMergeOk:
+		message := r.process.MergeFolder(ctx, event, id, arg)
+		event.IsEdited = true
+		ans := folderSettingsList(event, r.process.FolderList(ctx, event), r.folderColumns)
+		ans.AnswerCallbackQuery.Text = message
+		r.sendAnswers(ctx, b, []*entities.Answer{ans})
+		return
+	}
+
+	var message string
+	switch action {
+	case buttons.FolderMenu:
+	case buttons.FolderCopy:
+		var newID int
+		if newID, message = r.process.CopyFolder(ctx, event, id); newID != 0 {
+			id = newID
+		}
+	case buttons.FolderSplitType:
+		var newID int
+		by := folder.SplitBy{Type: entities.Type(arg)}
+		if newID, message = r.process.SplitFolder(ctx, event, id, by); newID != 0 {
+			id = newID
+		}
+	case buttons.FolderUp:
+		message = r.process.ShiftFolder(ctx, event, id, -1)
+	case buttons.FolderDown:
+		message = r.process.ShiftFolder(ctx, event, id, 1)
+	case buttons.FolderHide:
+		message = r.process.ToggleFolderHidden(ctx, event, id)
+	default:
+		r.doUnknown(ctx, b, event)
+		return
+	}
+
+	f := r.process.FolderSettings(ctx, event, id)
+	if f == nil {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.FolderNotExists)})
+		return
+	}
+
+	event.IsEdited = true
+	ans := folderMenu(event, f)
+	if message != "" {
+		ans.AnswerCallbackQuery.Text = message
+	}
+	r.sendAnswers(ctx, b, []*entities.Answer{ans})
+}
+
+// reorganizeFolder asks where to merge the folder or how to split it.
+func (r *router) reorganizeFolder(
+	ctx context.Context,
+	b *bot.Bot,
+	event *entities.Event,
+	action string,
+	id int,
+	arg int,
+) {
+	f := r.process.FolderSettings(ctx, event, id)
+	if f == nil {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.FolderNotExists)})
+		return
+	}
+
+	var ans *entities.Answer
+	event.IsEdited = true
+	switch action {
+	case buttons.FolderMerge:
+		ans = mergeChooser(event, f, r.process.FolderList(ctx, event), r.folderColumns)
+	case buttons.FolderMergeTo:
+		into := r.process.FolderSettings(ctx, event, arg)
+		if into == nil {
+			event.IsEdited = false
+			r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.FolderNotExists)})
+			return
+		}
+		ans = mergeConfirm(event, f, into)
+	default:
+		ans = splitChooser(event, f)
+	}
+
+	r.sendAnswers(ctx, b, []*entities.Answer{ans})
+}
+
+// editFolder sets the setting asked by the folder menu, false if nothing
+// is asked.
+func (r *router) editFolder(ctx context.Context, b *bot.Bot, event *entities.Event) bool {
+	message := r.process.EditFolderEnd(ctx, event)
+	if message == "" {
+		return false
+	}
+
+	answers := []*entities.Answer{sendMessage(event, message)}
+	if f := r.process.FolderSettings(ctx, event, event.FolderID); f != nil {
+		answers = append(answers, folderMenu(event, f))
+	}
+	r.sendAnswers(ctx, b, answers)
+
+	return true
+}
+
+func folderSettingsList(event *entities.Event, folders []*folder.Folder, columns int) *entities.Answer {
+	folderButtons := make([]models.InlineKeyboardButton, 0, len(folders))
+	for _, f := range folders {
+		text := f.Label(folder.DisplayName(f, event.Meta.Language))
+		if f.Hidden {
+			text = buttons.FolderOptions[buttons.FolderHide] + " " + text
+		}
+		folderButtons = append(folderButtons, models.InlineKeyboardButton{
+			CallbackData: folderData(buttons.FolderMenu, f.ID),
+			Text:         text,
+		})
+	}
+
+	btns := buttonGrid(folderButtons, columns)
+	btns = append(btns, []models.InlineKeyboardButton{{
+		CallbackData: buttons.FolderList,
+		Text:         buttons.FolderOptions[buttons.FolderList],
+	}})
+
+	return entities.NewAnswer(event, false, &entities.AnswerParams{
+		Message:  i18n.T(event.Meta.Language, messages.FolderSettings),
+		Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: btns},
+	})
+}
+
+// folderMenu shows the settings of the folder, the default folder can't be
+// renamed or hidden.
+func folderMenu(event *entities.Event, f *folder.Folder) *entities.Answer {
+	button := func(action string) models.InlineKeyboardButton {
+		return models.InlineKeyboardButton{
+			CallbackData: folderData(action, f.ID),
+			Text:         buttons.FolderOptions[action],
+		}
+	}
+
+	message := buttons.MenuOptions[buttons.SetupFolder] + " " + f.Label(folder.DisplayName(f, event.Meta.Language))
+	hide := button(buttons.FolderHide)
+	if f.Hidden {
+		message += "\n" + i18n.T(event.Meta.Language, messages.FolderIsHidden)
+		hide.Text = buttons.FolderShow
+	}
+
+	if len(f.Aliases) > 0 {
+		message += "\n" + i18n.T(event.Meta.Language, messages.FolderAliases, strings.Join(f.Aliases, ", "))
+	}
+
+	settings := []models.InlineKeyboardButton{button(buttons.FolderIcon), button(buttons.FolderAliases)}
+	reorganize := []models.InlineKeyboardButton{button(buttons.FolderCopy), button(buttons.FolderSplit)}
+	if !f.IsDefault() {
+		settings = []models.InlineKeyboardButton{
+			button(buttons.FolderRename), button(buttons.FolderIcon), button(buttons.FolderAliases), hide,
+		}
+		reorganize = slices.Insert(reorganize, 1, button(buttons.FolderMerge))
+	}
+
+	return entities.NewAnswer(event, false, &entities.AnswerParams{
+		Message: message,
+		Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+			settings,
+			{button(buttons.FolderUp), button(buttons.FolderDown), button(buttons.FolderPosition)},
+			reorganize,
+			{{CallbackData: buttons.SetupFolder, Text: buttons.FolderOptions[buttons.FolderList]}},
+		}},
+	})
+}
+
+// mergeChooser lists the other folders to merge the folder into.
+func mergeChooser(event *entities.Event, f *folder.Folder, folders []*folder.Folder, columns int) *entities.Answer {
+	lang := event.Meta.Language
+	folderButtons := make([]models.InlineKeyboardButton, 0, len(folders))
+	for _, into := range folders {
+		if into.ID == f.ID {
+			continue
+		}
+		folderButtons = append(folderButtons, models.InlineKeyboardButton{
+			CallbackData: folderData(buttons.FolderMergeTo, f.ID, into.ID),
+			Text:         into.Label(folder.DisplayName(into, lang)),
+		})
+	}
+
+	btns := append(buttonGrid(folderButtons, columns), []models.InlineKeyboardButton{backToMenu(f.ID)})
+
+	return entities.NewAnswer(event, false, &entities.AnswerParams{
+		Message:  i18n.T(lang, messages.FolderMergeChoose, folder.DisplayName(f, lang)),
+		Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: btns},
+	})
+}
+
+func mergeConfirm(event *entities.Event, f *folder.Folder, into *folder.Folder) *entities.Answer {
+	lang := event.Meta.Language
+
+	return entities.NewAnswer(event, false, &entities.AnswerParams{
+		Message: i18n.T(lang, messages.FolderMergeAsk, folder.DisplayName(f, lang), folder.DisplayName(into, lang)),
+		Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{{
+			{
+				CallbackData: folderData(buttons.FolderMergeOk, f.ID, into.ID),
+				Text:         buttons.FolderOptions[buttons.FolderMergeOk],
+			},
+			backToMenu(f.ID),
+		}}},
+	})
+}
+
+// splitChooser offers to split the notes of the folder by the type or by
+// the date.
+func splitChooser(event *entities.Event, f *folder.Folder) *entities.Answer {
+	lang := event.Meta.Language
+	types := make([]models.InlineKeyboardButton, 0, len(buttons.TypeIcons))
+	for t := entities.Message; t <= entities.Voice; t++ {
+		types = append(types, models.InlineKeyboardButton{
+			CallbackData: folderData(buttons.FolderSplitType, f.ID, int(t)),
+			Text:         buttons.TypeIcons[t],
+		})
+	}
+
+	return entities.NewAnswer(event, false, &entities.AnswerParams{
+		Message: i18n.T(lang, messages.FolderSplitChoose, folder.DisplayName(f, lang)),
+		Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+			types,
+			{
+				{
+					CallbackData: folderData(buttons.FolderSplitDate, f.ID),
+					Text:         buttons.FolderOptions[buttons.FolderSplitDate],
+				},
+				backToMenu(f.ID),
+			},
+		}},
+	})
+}
+
+// suggestFolder asks whether the user meant the folder looking like the
+// mistyped name or a new folder. It reports whether there is a folder to
+// offer for the note of the event.
+func (r *router) suggestFolder(ctx context.Context, b *bot.Bot, event *entities.Event, noteID int) bool {
+	s := r.process.FolderSuggestion(ctx, event)
+	if s == nil || s.NoteID != noteID {
+		return false
+	}
+
+	r.sendAnswers(ctx, b, []*entities.Answer{suggestionPrompt(event, s)})
+	return true
+}
+
+func suggestionPrompt(event *entities.Event, s *folder.Suggestion) *entities.Answer {
+	lang := event.Meta.Language
+	create := []models.InlineKeyboardButton{{
+		CallbackData: folderData(buttons.FolderSuggestNew, s.FolderID()),
+		Text:         buttons.FolderOptions[buttons.FolderSuggestNew] + " " + i18n.T(lang, messages.FolderCreateNamed, s.Name),
+	}}
+	if s.Folder == nil {
+		return entities.NewAnswer(event, true, &entities.AnswerParams{
+			Message:  i18n.T(lang, messages.FolderCreateAsk, s.Name),
+			Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{create}},
+		})
+	}
+
+	name := folder.DisplayName(s.Folder, lang)
+	return entities.NewAnswer(event, true, &entities.AnswerParams{
+		Message: i18n.T(lang, messages.FolderSuggested, s.Name, name),
+		Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{
+				CallbackData: folderData(buttons.FolderSuggestOk, s.Folder.ID),
+				Text:         s.Folder.Label(name),
+			}},
+			create,
+		}},
+	})
+}
+
+func backToMenu(id int) models.InlineKeyboardButton {
+	return models.InlineKeyboardButton{
+		CallbackData: folderData(buttons.FolderMenu, id),
+		Text:         buttons.FolderOptions[buttons.FolderList],
+	}
+}
+
+// folderData joins the action, the folder ID and the optional argument.
+func folderData(action string, id int, arg ...int) string {
+	data := action + buttons.Delimiter + strconv.Itoa(id)
+	for _, a := range arg {
+		data += buttons.Delimiter + strconv.Itoa(a)
+	}
+	return data
+}
+
+// folderCallback parses the action, the folder ID and the argument of the
+// settings callback. The list callback has no ID, the argument is 0 if
+// there is none.
+func folderCallback(data string) (string, int, int, bool) {
+	if data == buttons.FolderList {
+		return data, 0, 0, true
+	}
+
+	parts := strings.Split(data, buttons.Delimiter)
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", 0, 0, false
+	}
+	// the new folder is offered with no folder to compare, its ID is 0
+	id, err := strconv.Atoi(parts[1])
+	if err != nil || id < 0 || (id == 0 && parts[0] != buttons.FolderSuggestNew) {
+		return "", 0, 0, false
+	}
+
+	arg := 0
+	if len(parts) == 3 {
+		if arg, err = strconv.Atoi(parts[2]); err != nil {
+			return "", 0, 0, false
+		}
+	}
+
+	return parts[0], id, arg, true
+}
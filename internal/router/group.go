@@ -0,0 +1,223 @@
+package router
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"archive_bot/internal/entities"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	groupSave     string = "/save"
+	groupSaveWord string = "save"
+
+	albumTTL  time.Duration = 24 * time.Hour
+	maxAlbums int           = 1000
+)
+
+// botName caches the bot username which is needed to recognize mentions.
+type botName struct {
+	mu   sync.Mutex
+	name string
+}
+
+func (n *botName) get(ctx context.Context, b *bot.Bot) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.name == "" {
+		me, err := b.GetMe(ctx)
+		if err != nil {
+			logger.L(ctx).Error("failed to get bot username", logger.ErrAttr(err))
+			return ""
+		}
+		n.name = me.Username
+	}
+
+	return n.name
+}
+
+// albums keeps the items of the recent group albums. A reply points to one
+// item of an album and the Bot API can't fetch the others, so the items
+// the bot has seen are saved together.
+type albums struct {
+	mu    sync.Mutex
+	items map[albumKey]*album
+}
+
+type albumKey struct {
+	chatID  int64
+	groupID string
+}
+
+type album struct {
+	messages []*models.Message
+	seen     time.Time
+}
+
+// add keeps the album item, the expired albums are dropped and the oldest
+// one makes room for the new album when there are maxAlbums of them.
+func (a *albums) add(message *models.Message, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.items == nil {
+		a.items = make(map[albumKey]*album)
+	}
+
+	key := albumKey{chatID: message.Chat.ID, groupID: message.MediaGroupID}
+	if al, ok := a.items[key]; ok {
+		if !slices.ContainsFunc(al.messages, func(m *models.Message) bool { return m.ID == message.ID }) {
+			al.messages = append(al.messages, message)
+		}
+		al.seen = now
+		return
+	}
+
+	var oldest *albumKey
+	for k, al := range a.items {
+		if now.Sub(al.seen) > albumTTL {
+			delete(a.items, k)
+			continue
+		}
+		if oldest == nil || al.seen.Before(a.items[*oldest].seen) {
+			oldest = &k
+		}
+	}
+	if len(a.items) >= maxAlbums && oldest != nil {
+		delete(a.items, *oldest)
+	}
+
+	a.items[key] = &album{messages: []*models.Message{message}, seen: now}
+}
+
+// get returns the seen items of the album of the message in the order
+// they were sent, the message alone when the album is unknown.
+func (a *albums) get(message *models.Message, now time.Time) []*models.Message {
+	if message.MediaGroupID == "" {
+		return []*models.Message{message}
+	}
+	a.add(message, now)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	messages := slices.Clone(a.items[albumKey{chatID: message.Chat.ID, groupID: message.MediaGroupID}].messages)
+	slices.SortFunc(messages, func(x, y *models.Message) int { return x.ID - y.ID })
+
+	return messages
+}
+
+// routeGroupMessage archives the replied-to message into the replier's archive,
+// an album is archived whole. Any other group message is only kept when it
+// is an album item.
+func (r *router) routeGroupMessage(ctx context.Context, b *bot.Bot, update *models.Update) {
+	log := r.log.With(logger.String("operation", "router.routeGroupMessage"))
+
+	message := update.Message
+	if message.MediaGroupID != "" {
+		r.albums.add(message, time.Now())
+	}
+	if message.ReplyToMessage == nil || message.From == nil || message.From.IsBot {
+		return
+	}
+
+	folderName, ok := parseGroupSave(message.Text, r.botName.get(ctx, b), r.groupKeyword)
+	if !ok {
+		return
+	}
+
+	events := replyEvents(ctx, update, r.albums.get(message.ReplyToMessage, time.Now()))
+	if len(events) == 0 {
+		log.Debug("unsupported replied message", logger.Int64("chat_id", message.Chat.ID))
+		return
+	}
+	events[0].FolderName = folderName
+
+	r.process.InitUser(ctx, events[0])
+
+	log.Debug("save from group", logger.String("folder", folderName), logger.Int("items", len(events)))
+	for _, event := range events {
+		event.Meta.Language = events[0].Meta.Language
+		r.handle(ctx, b, event, "save_from_group", r.doSaveFromGroup)
+	}
+}
+
+// replyEvents creates the events for the replied-to album items. The item
+// with the caption goes first and carries the text, so the note gets one
+// description and one answer like an album sent to the bot.
+func replyEvents(ctx context.Context, update *models.Update, items []*models.Message) []*entities.Event {
+	lead := 0
+	for i, m := range items {
+		if m.Caption != "" {
+			lead = i
+			break
+		}
+	}
+	items = append([]*models.Message{items[lead]}, slices.Delete(slices.Clone(items), lead, lead+1)...)
+
+	events := make([]*entities.Event, 0, len(items))
+	for _, m := range items {
+		reply := *update.Message
+		reply.ReplyToMessage = m
+		event := entities.NewReplyEvent(ctx, &models.Update{ID: update.ID, Message: &reply})
+		if event.Type == entities.Unknown {
+			continue
+		}
+		if len(events) > 0 {
+			event.Text = ""
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func (r *router) doSaveFromGroup(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	ap := r.process.Save(ctx, event)
+	if ap.Message != "" {
+		r.sendAnswers(ctx, b, []*entities.Answer{
+			sendNote(event, event.NoteID, event.FolderID, true, ap),
+		})
+	}
+	if event.NoteID != 0 {
+		r.suggestFolder(ctx, b, event, event.NoteID)
+	}
+}
+
+// parseGroupSave recognizes the save request in a group reply and returns the
+// requested folder name. Supported forms are "/save[@bot] [folder]",
+// "@bot save [folder]" and "<keyword> [folder]".
+func parseGroupSave(text string, botUsername string, keyword string) (string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", false
+	}
+
+	first := fields[0]
+	rest := fields[1:]
+	mention := "@" + botUsername
+
+	switch {
+	case strings.EqualFold(first, groupSave):
+	case botUsername != "" && strings.EqualFold(first, groupSave+mention):
+	case botUsername != "" && strings.EqualFold(first, mention):
+		if len(rest) == 0 || !strings.EqualFold(rest[0], groupSaveWord) {
+			return "", false
+		}
+		rest = rest[1:]
+	case keyword != "" && strings.EqualFold(first, keyword):
+	default:
+		return "", false
+	}
+
+	return strings.Join(rest, " "), true
+}
@@ -0,0 +1,63 @@
+package router
+
+import (
+	"context"
+	"time"
+
+	"archive_bot/internal/entities"
+	"archive_bot/internal/metrics"
+
+	"archive_bot/pkg/tracing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type handlerFunc func(ctx context.Context, b *bot.Bot, event *entities.Event)
+
+// handle runs the handler and records its duration. A panic is counted
+// and passed on to the recover middleware.
+func (r *router) handle(ctx context.Context, b *bot.Bot, event *entities.Event, name string, h handlerFunc) {
+	ctx, span := tracing.Start(ctx, "handler."+name)
+	defer span.End()
+
+	start := time.Now()
+	panicked := true
+	defer func() {
+		if panicked {
+			span.SetStatus(codes.Error, "panic")
+		}
+		metrics.ObserveHandler(name, start, panicked)
+	}()
+
+	h(ctx, b, event)
+	panicked = false
+}
+
+// startUpdate starts the span of the update.
+func startUpdate(ctx context.Context, update *models.Update, route string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, route,
+		attribute.Int64("telegram.update_id", update.ID),
+		attribute.String("telegram.update_type", updateType(update)),
+	)
+}
+
+func updateType(update *models.Update) string {
+	switch {
+	case update.Message != nil:
+		return "message"
+	case update.EditedMessage != nil:
+		return "edited_message"
+	case update.ChannelPost != nil:
+		return "channel_post"
+	case update.EditedChannelPost != nil:
+		return "edited_channel_post"
+	case update.CallbackQuery != nil:
+		return "callback_query"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,49 @@
+package router
+
+import (
+	"context"
+	"strings"
+
+	"archive_bot/internal/const/buttons"
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// doLanguage handles "/language [code]", without the code it offers
+// the supported languages.
+func (r *router) doLanguage(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	if event.Text == "" || strings.HasPrefix(event.Text, "/") {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendLanguages(event)})
+		return
+	}
+
+	message := r.process.SetLanguage(ctx, event, event.Text)
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+}
+
+func (r *router) doLanguageCallback(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	code := strings.TrimPrefix(event.Text, buttons.Language)
+	message := r.process.SetLanguage(ctx, event, code)
+	event.IsEdited = true
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+}
+
+func sendLanguages(event *entities.Event) *entities.Answer {
+	langs := i18n.Supported()
+	row := make([]models.InlineKeyboardButton, 0, len(langs))
+	for _, lang := range langs {
+		row = append(row, models.InlineKeyboardButton{
+			CallbackData: buttons.Language + string(lang),
+			Text:         lang.Name(),
+		})
+	}
+
+	return entities.NewAnswer(event, true, &entities.AnswerParams{
+		Message:  i18n.T(event.Meta.Language, messages.LanguageChoose),
+		Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{row}},
+	})
+}
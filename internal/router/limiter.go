@@ -0,0 +1,91 @@
+package router
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	limiterIdle       time.Duration = 10 * time.Minute
+	limiterPruneEvery time.Duration = time.Minute
+)
+
+// limiter allows perMinute updates per user with bursts of burst updates.
+// The buckets of users idle for limiterIdle are full and get removed.
+type limiter struct {
+	mu        sync.Mutex
+	limit     rate.Limit
+	burst     int
+	users     map[int64]*userBucket
+	lastPrune time.Time
+}
+
+type userBucket struct {
+	bucket *rate.Limiter
+	seen   time.Time
+}
+
+func NewLimiter(perMinute int, burst int) *limiter {
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return &limiter{
+		limit: rate.Limit(float64(perMinute) / time.Minute.Seconds()),
+		burst: burst,
+		users: make(map[int64]*userBucket),
+	}
+}
+
+// SetLimit changes the limit of all users, it is used on config reload.
+func (l *limiter) SetLimit(perMinute int, burst int) {
+	if burst <= 0 {
+		burst = 1
+	}
+	limit := rate.Limit(float64(perMinute) / time.Minute.Seconds())
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limit, l.burst = limit, burst
+	now := time.Now()
+	for _, u := range l.users {
+		u.bucket.SetLimitAt(now, limit)
+		u.bucket.SetBurstAt(now, burst)
+	}
+}
+
+// Allow reports whether the user may send one more update now.
+// A non-positive rate disables the limit.
+func (l *limiter) Allow(userID int64) bool {
+	return l.allowAt(userID, time.Now())
+}
+
+func (l *limiter) allowAt(userID int64, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limit <= 0 {
+		return true
+	}
+
+	if now.Sub(l.lastPrune) >= limiterPruneEvery {
+		for id, u := range l.users {
+			if now.Sub(u.seen) >= limiterIdle {
+				delete(l.users, id)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	u, ok := l.users[userID]
+	if !ok {
+		u = &userBucket{bucket: rate.NewLimiter(l.limit, l.burst)}
+		l.users[userID] = u
+	}
+	u.seen = now
+
+	return u.bucket.AllowN(now, 1)
+}
@@ -0,0 +1,208 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime/debug"
+	"time"
+
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/metrics"
+
+	"archive_bot/pkg/executor"
+	"archive_bot/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	dropUnauthorized string = "unauthorized"
+	dropRateLimited  string = "rate_limited"
+	dropShutdown     string = "shutdown"
+	dropQueueFull    string = "queue_full"
+	dropBanned       string = "banned"
+)
+
+// Executor runs the tasks of one key one at a time in the submission order.
+type Executor interface {
+	Submit(key int64, task func()) error
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, userID int64) (ok bool, wait time.Duration, notify bool)
+}
+
+// Middlewares returns the chain every update goes through. The checks run
+// in the bot intake, the handler runs on the executor after the previous
+// updates of the same user.
+func (r *router) Middlewares(exec Executor) []bot.Middleware {
+	return []bot.Middleware{
+		r.withMetrics,
+		r.withLogging,
+		r.withAuth,
+		r.withRateLimit,
+		r.serialize(exec),
+		r.withRecover,
+	}
+}
+
+func (r *router) withMetrics(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		metrics.UpdateReceived(updateType(update))
+		next(ctx, b, update)
+	}
+}
+
+func (r *router) withLogging(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		r.log.Debug(
+			"update received",
+			logger.Int64("update_id", update.ID),
+			logger.String("type", updateType(update)),
+			logger.Int64("key", updateKey(update)),
+		)
+		next(ctx, b, update)
+	}
+}
+
+// withAuth drops the updates without a sender, the updates of bots and
+// the ones of the banned users. Channel posts have no sender and are
+// checked by the processor.
+func (r *router) withAuth(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		if !isChannelPost(update) {
+			if from := updateSender(update); from == nil || from.IsBot {
+				r.log.Debug("update without a user dropped", logger.Int64("update_id", update.ID))
+				metrics.UpdateDropped(dropUnauthorized)
+				return
+			}
+			if r.banned(ctx, update) {
+				r.log.Debug("update of a banned user dropped", logger.Int64("update_id", update.ID))
+				metrics.UpdateDropped(dropBanned)
+				return
+			}
+		}
+		next(ctx, b, update)
+	}
+}
+
+func (r *router) withRateLimit(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		if r.limiter != nil && !isChannelPost(update) {
+			ok, wait, notify := r.limiter.Allow(ctx, updateKey(update))
+			if !ok {
+				r.log.Debug("update rate limited", logger.Int64("user_id", updateKey(update)))
+				metrics.UpdateDropped(dropRateLimited)
+				if notify {
+					r.throttled(ctx, b, update, wait)
+				}
+				return
+			}
+		}
+		next(ctx, b, update)
+	}
+}
+
+// serialize passes the update to the executor. The handler context is not
+// canceled with the intake, so the queued updates are finished on shutdown.
+func (r *router) serialize(exec Executor) bot.Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			start := time.Now()
+			ctx = context.WithoutCancel(ctx)
+
+			err := exec.Submit(updateKey(update), func() {
+				defer metrics.ObserveUpdate(updateType(update), start)
+				next(ctx, b, update)
+			})
+			if err != nil {
+				r.log.Warn(
+					"update dropped",
+					logger.Int64("update_id", update.ID),
+					logger.ErrAttr(err),
+				)
+				reason := dropShutdown
+				if errors.Is(err, executor.ErrQueueFull) {
+					reason = dropQueueFull
+				}
+				metrics.UpdateDropped(reason)
+			}
+		}
+	}
+}
+
+// throttled asks the user to slow down in the answer to the callback query
+// or in the private chat.
+func (r *router) throttled(ctx context.Context, b *bot.Bot, update *models.Update, wait time.Duration) {
+	from := updateSender(update)
+	text := i18n.T(i18n.FromCode(from.LanguageCode), messages.Throttled, int(math.Ceil(wait.Seconds())))
+
+	ans := &entities.Answer{UserID: from.ID}
+	switch {
+	case update.CallbackQuery != nil:
+		ans.AnswerCallbackQuery = &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: update.CallbackQuery.ID,
+			Text:            text,
+			ShowAlert:       true,
+		}
+	case update.Message != nil && update.Message.Chat.Type == models.ChatTypePrivate:
+		ans.SendMessage = &bot.SendMessageParams{ChatID: update.Message.Chat.ID, Text: text}
+	default:
+		return
+	}
+
+	r.sendAnswers(ctx, b, []*entities.Answer{ans})
+}
+
+func (r *router) withRecover(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				logger.L(ctx).Error(
+					"update handler panicked",
+					logger.Int64("update_id", update.ID),
+					logger.Any("panic", rec),
+					logger.String("stack", string(debug.Stack())),
+				)
+			}
+		}()
+		next(ctx, b, update)
+	}
+}
+
+func isChannelPost(update *models.Update) bool {
+	return update.ChannelPost != nil || update.EditedChannelPost != nil
+}
+
+func updateSender(update *models.Update) *models.User {
+	switch {
+	case update.Message != nil:
+		return update.Message.From
+	case update.EditedMessage != nil:
+		return update.EditedMessage.From
+	case update.CallbackQuery != nil:
+		return &update.CallbackQuery.From
+	default:
+		return nil
+	}
+}
+
+// updateKey returns the user the update belongs to, the chat for channel posts.
+func updateKey(update *models.Update) int64 {
+	if from := updateSender(update); from != nil {
+		return from.ID
+	}
+
+	switch {
+	case update.ChannelPost != nil:
+		return update.ChannelPost.Chat.ID
+	case update.EditedChannelPost != nil:
+		return update.EditedChannelPost.Chat.ID
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,97 @@
+package router
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	limiterKeyPrefix  string = "update-rate:"
+	throttleKeyPrefix string = "update-throttled:"
+)
+
+// gcra keeps the token bucket of the user as the time the bucket is full
+// again. KEYS are the bucket and the throttle notice, ARGV are now and the
+// interval between the updates in milliseconds and the burst. It returns
+// the wait in milliseconds, 0 when the update is allowed, and 1 when the
+// user is to be told about the throttling, once per wait.
+var gcra = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local tat = math.max(tonumber(redis.call('GET', KEYS[1])) or now, now)
+local wait = tat - now - interval * (burst - 1)
+if wait > 0 then
+	local notify = redis.call('SET', KEYS[2], 1, 'PX', wait, 'NX') and 1 or 0
+	return {wait, notify}
+end
+tat = tat + interval
+redis.call('SET', KEYS[1], tat, 'PX', tat - now)
+return {0, 0}
+`)
+
+// redisLimiter allows perMinute updates per user with bursts of burst
+// updates. The buckets are kept in redis and are shared by the instances,
+// the local buckets are used while redis is unavailable.
+type redisLimiter struct {
+	log   *logger.Logger
+	db    *redis.Client
+	local *limiter
+
+	// interval is the time between the updates in milliseconds
+	interval atomic.Int64
+	burst    atomic.Int64
+}
+
+func NewRedisLimiter(log *logger.Logger, db *redis.Client, perMinute int, burst int) *redisLimiter {
+	l := &redisLimiter{log: log, db: db, local: NewLimiter(perMinute, burst)}
+	l.SetLimit(perMinute, burst)
+
+	return l
+}
+
+// SetLimit changes the limit of all users, it is used on config reload.
+func (l *redisLimiter) SetLimit(perMinute int, burst int) {
+	if burst <= 0 {
+		burst = 1
+	}
+
+	var interval int64
+	if perMinute > 0 {
+		interval = max(time.Minute.Milliseconds()/int64(perMinute), 1)
+	}
+	l.interval.Store(interval)
+	l.burst.Store(int64(burst))
+	l.local.SetLimit(perMinute, burst)
+}
+
+// Allow reports whether the user may send one more update now. When not,
+// wait is the time until the next update is allowed and notify is true for
+// the first update over the limit. A non-positive rate disables the limit.
+func (l *redisLimiter) Allow(ctx context.Context, userID int64) (bool, time.Duration, bool) {
+	interval := l.interval.Load()
+	if interval == 0 {
+		return true, 0, false
+	}
+
+	id := strconv.FormatInt(userID, 10)
+	res, err := gcra.Run(ctx, l.db,
+		[]string{limiterKeyPrefix + id, throttleKeyPrefix + id},
+		time.Now().UnixMilli(), interval, l.burst.Load(),
+	).Int64Slice()
+	if err != nil || len(res) != 2 {
+		l.log.Error("failed to check update rate", logger.ErrAttr(err))
+		return l.local.Allow(userID), 0, false
+	}
+
+	if res[0] > 0 {
+		return false, time.Duration(res[0]) * time.Millisecond, res[1] == 1
+	}
+	return true, 0, false
+}
@@ -0,0 +1,101 @@
+package router
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/role"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/go-telegram/bot"
+)
+
+const (
+	roleGrant  string = "/adm_grant"
+	roleRevoke string = "/adm_revoke"
+	roleList   string = "/adm_admins"
+
+	rolesHelp string = "adm_admins"
+)
+
+func (r *router) doRoleGrant(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	id, name, ok := userArgs(event.Text, roleGrant)
+	newRole, known := role.Parse(name)
+	if !ok || !known {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.RolesUsage)})
+		return
+	}
+
+	message := i18n.T(event.Meta.Language, messages.RoleGranted, id, newRole)
+	if id == event.Meta.UserID {
+		message = i18n.T(event.Meta.Language, messages.RoleOwn)
+	} else if err := r.roles.Grant(ctx, id, newRole, event.Meta.UserID); err != nil {
+		message = roleError(ctx, event.Meta.Language, id, err)
+	}
+
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+}
+
+func (r *router) doRoleRevoke(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	id, _, ok := userArgs(event.Text, roleRevoke)
+	if !ok {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.RolesUsage)})
+		return
+	}
+
+	message := i18n.T(event.Meta.Language, messages.RoleRevoked, id)
+	if id == event.Meta.UserID {
+		message = i18n.T(event.Meta.Language, messages.RoleOwn)
+	} else if err := r.roles.Revoke(ctx, id); err != nil {
+		message = roleError(ctx, event.Meta.Language, id, err)
+	}
+
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+}
+
+func (r *router) doRoleList(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	admins, err := r.roles.List(ctx)
+	if err != nil {
+		logger.L(ctx).Error("failed to list admins", logger.ErrAttr(err))
+		r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.AdminError)})
+		return
+	}
+
+	lang := event.Meta.Language
+	sb := &strings.Builder{}
+	sb.WriteString(i18n.T(lang, messages.AdminsList))
+	for _, a := range admins {
+		sb.WriteString("\n")
+		sb.WriteString(strconv.FormatInt(a.UserID, 10))
+		sb.WriteString(" ")
+		sb.WriteString(string(a.Role))
+		if a.Bootstrap {
+			sb.WriteString(" ")
+			sb.WriteString(i18n.T(lang, messages.AdminFromConf))
+		} else {
+			sb.WriteString(", ")
+			sb.WriteString(i18n.T(lang, messages.AdminGrantedBy, a.GrantedBy, formatTime(a.CreatedAt)))
+		}
+	}
+	sb.WriteString("\n\n")
+	sb.WriteString(i18n.T(lang, messages.RolesUsage))
+
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, sb.String())})
+}
+
+func roleError(ctx context.Context, lang i18n.Lang, userID int64, err error) string {
+	switch err {
+	case role.ErrBootstrap:
+		return i18n.T(lang, messages.RoleBootstrap, userID)
+	case role.ErrNoAdmin:
+		return i18n.T(lang, messages.RoleNoAdmin, userID)
+	default:
+		logger.L(ctx).Error("failed to change role", logger.ErrAttr(err))
+		return i18n.T(lang, messages.AdminError)
+	}
+}
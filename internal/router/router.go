@@ -1,304 +1,481 @@
-package router
-
-import (
-	"context"
-	"sort"
-	"strconv"
-	"strings"
-
-	"archive_bot/internal/const/buttons"
-	"archive_bot/internal/const/messages"
-	"archive_bot/internal/entities"
-
-	"archive_bot/pkg/logger"
-
-	"github.com/go-telegram/bot"
-	"github.com/go-telegram/bot/models"
-)
-
-const (
-	start             string = "/start"
-	info              string = "/info"
-	folders           string = "/folders"
-	moveLastNote      string = "/move_note"
-	moveLastNoteAlias string = "!"
-)
-
-type Processor interface {
-	InitUser(ctx context.Context, event *entities.Event)
-	CountUsers(ctx context.Context) string
-	SetFolderMsgID(userID int64, messageID int)
-	FolderMsgID(userID int64) int
-	SetInt(key string, num int)
-	Int(key string) int
-	AddMessageID(userID int64, messageID int)
-	MessageIDs(userID int64) []int
-
-	Start(ctx context.Context, event *entities.Event) (string, string)
-	Folders(ctx context.Context, event *entities.Event) map[string]string
-	Save(ctx context.Context, event *entities.Event) *entities.AnswerParams
-	SaveTo(ctx context.Context, event *entities.Event) string
-
-	SelectFolder(ctx context.Context, event *entities.Event) (map[int]*entities.AnswerParams, string)
-	AddFolderStart(ctx context.Context, event *entities.Event) string
-	AddFolderEnd(ctx context.Context, event *entities.Event) string
-	DeleteFolderStart(ctx context.Context, event *entities.Event) string
-	DeleteFolderEnd(ctx context.Context, event *entities.Event) string
-
-	MoveNoteStart(ctx context.Context, event *entities.Event) string
-	MoveNoteEnd(ctx context.Context, event *entities.Event) string
-	RemoveNote(ctx context.Context, event *entities.Event) string
-}
-
-type router struct {
-	log     *logger.Logger
-	adminID int64
-	process Processor
-}
-
-func New(log *logger.Logger, adminID int64, processor Processor) *router {
-	return &router{log: log, process: processor, adminID: adminID}
-}
-
-func (r *router) RouteCallbackQuery(ctx context.Context, b *bot.Bot, update *models.Update) {
-	log := r.log.With(logger.String("operation", "router.RouteCallbackQuery"))
-
-	event := entities.NewEvent(ctx, update)
-	// r.process.AddMessageID(event.Meta.UserID, event.Meta.MessageID)
-	r.process.InitUser(ctx, event)
-
-	log.Debug("switch CallbackQuery", logger.String("command", event.Text))
-	switch {
-	case event.Text == buttons.CreateFolder:
-		go r.doCreateFolder(ctx, b, event)
-	case event.Text == buttons.DeleteFolder:
-		go r.doDeleteFolder(ctx, b, event)
-	case strings.HasPrefix(event.Text, buttons.DeleteNote):
-		go r.doDeleteNote(ctx, b, event)
-	case strings.HasPrefix(event.Text, buttons.MoveNote):
-		go r.doMoveNote(ctx, b, event)
-	default:
-		go r.doDefaultCallback(ctx, b, event)
-	}
-}
-
-func (r *router) RouteMessage(ctx context.Context, b *bot.Bot, update *models.Update) {
-	log := r.log.With(logger.String("operation", "router.RouteMessage"))
-
-	event := entities.NewEvent(ctx, update)
-	r.process.AddMessageID(event.Meta.UserID, event.Meta.MessageID)
-	r.process.InitUser(ctx, event)
-
-	command, text := commandAndText(event.Text)
-	if text != "" {
-		event.Text = text
-	}
-
-	if event.Type == entities.Unknown {
-		go r.doUnknown(ctx, b, event)
-		return
-	}
-
-	log.Debug("switch Message", logger.String("command", command))
-	switch command {
-	case "":
-		go r.doEmpty(ctx, b, event)
-	case start:
-		go r.doStart(ctx, b, event)
-	case info:
-		go r.doInfo(ctx, b, event)
-	case folders:
-		go r.doShowFolders(ctx, b, event)
-	case moveLastNote:
-		go r.doSaveTo(ctx, b, event)
-	default:
-		go r.doUnknown(ctx, b, event)
-	}
-}
-
-func sendFoldersList(
-	event *entities.Event,
-	buttonsMap map[string]string,
-	deleteAfter bool,
-) *entities.Answer {
-	btns := make([][]models.InlineKeyboardButton, 0, len(buttonsMap)+1)
-
-	for key, val := range buttonsMap {
-		btns = append(btns, []models.InlineKeyboardButton{
-			{CallbackData: key, Text: val},
-		})
-	}
-
-	sort.Slice(btns, func(i, j int) bool {
-		return btns[i][0].CallbackData < btns[j][0].CallbackData
-	})
-
-	btns = append(btns, []models.InlineKeyboardButton{
-		{
-			CallbackData: buttons.CreateFolder,
-			Text:         buttons.MenuOptions[buttons.CreateFolder],
-		},
-		{
-			CallbackData: buttons.DeleteFolder,
-			Text:         buttons.MenuOptions[buttons.DeleteFolder],
-		},
-	})
-
-	return entities.NewAnswer(
-		event,
-		deleteAfter,
-		&entities.AnswerParams{
-			Message:  messages.FoldersCaption,
-			Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: btns},
-		})
-}
-
-func sendNote(
-	event *entities.Event,
-	noteID int,
-	folderID int,
-	deleteAfter bool,
-	ap *entities.AnswerParams,
-) *entities.Answer {
-	btns := make([][]models.InlineKeyboardButton, 0, 1)
-
-	buttonsRow := make([]models.InlineKeyboardButton, 0, len(buttons.CatalogueOptions))
-
-	noteAndFolder := strconv.Itoa(noteID) + buttons.Delimiter + strconv.Itoa(folderID)
-
-	switch ap.Type {
-	case entities.Photo:
-		if len(ap.FileIDs) == 1 {
-			noteAndFolder += buttons.Delimiter + buttons.WithPhoto
-		}
-	}
-
-	for key, val := range buttons.CatalogueOptions {
-		buttonsRow = append(buttonsRow, models.InlineKeyboardButton{
-			CallbackData: key + buttons.Delimiter + noteAndFolder,
-			Text:         val,
-		})
-	}
-
-	sort.Slice(buttonsRow, func(i, j int) bool {
-		return buttonsRow[i].CallbackData < buttonsRow[j].CallbackData
-	})
-
-	btns = append(btns, buttonsRow)
-	ap.Keyboard = &models.InlineKeyboardMarkup{
-		InlineKeyboard: btns,
-	}
-
-	return entities.NewAnswer(event, deleteAfter, ap)
-}
-
-func sendFoldersButton(
-	event *entities.Event,
-	description string,
-	button string,
-	deleteAfter bool,
-) *entities.Answer {
-	kb := &models.ReplyKeyboardMarkup{
-		Keyboard:       [][]models.KeyboardButton{{{Text: button}}},
-		ResizeKeyboard: true,
-	}
-
-	return entities.NewAnswer(
-		event,
-		deleteAfter,
-		&entities.AnswerParams{
-			Message:  description,
-			Keyboard: kb,
-		})
-}
-
-func commandAndText(plainMessage string) (string, string) {
-	if plainMessage == "" {
-		return "", ""
-	}
-
-	if strings.HasPrefix(plainMessage, moveLastNoteAlias) {
-		return moveLastNote, strings.TrimLeft(plainMessage, moveLastNoteAlias)
-	}
-
-	if plainMessage == buttons.Folders {
-		return folders, ""
-	}
-
-	if !strings.HasPrefix(plainMessage, "/") {
-		return "", plainMessage
-	}
-
-	count := 0
-	for _, ch := range plainMessage {
-		if ch != '/' {
-			break
-		}
-		count++
-	}
-	if count > 1 {
-		return "", plainMessage
-	}
-
-	strSlice := strings.SplitN(plainMessage, " ", 2)
-
-	command := strSlice[0]
-	if len(strSlice) == 1 {
-		return command, ""
-	}
-
-	text := strings.TrimSpace(strSlice[1])
-
-	return command, text
-}
-
-func ParseButtonCallback(command string) (int, int, string) {
-	if command == "" {
-		return 0, 0, ""
-	}
-	sl := strings.Split(command, buttons.Delimiter)
-	if len(sl) < 3 {
-		return 0, 0, ""
-	}
-
-	noteID, err := strconv.Atoi(sl[1])
-	if err != nil {
-		return 0, 0, ""
-	}
-	folderID, err := strconv.Atoi(sl[2])
-	if err != nil {
-		return 0, 0, ""
-	}
-
-	if len(sl) == 4 {
-		withMedia := sl[3]
-		return noteID, folderID, withMedia
-	}
-
-	return noteID, folderID, ""
-}
-
-func (r *router) deleteMessages(ctx context.Context, b *bot.Bot, event *entities.Event) {
-	msgIDs := r.process.MessageIDs(event.Meta.UserID)
-	if len(msgIDs) > 0 {
-		_, err := b.DeleteMessages(ctx, &bot.DeleteMessagesParams{
-			ChatID:     event.Meta.ChatID,
-			MessageIDs: msgIDs,
-		})
-		if err != nil {
-			r.log.Error("", logger.ErrAttr(err))
-		}
-	}
-}
-
-func (r *router) deleteMessage(ctx context.Context, b *bot.Bot, event *entities.Event) {
-	if event.Meta.MessageID != 0 {
-		_, err := b.DeleteMessage(ctx, &bot.DeleteMessageParams{
-			ChatID:    event.Meta.ChatID,
-			MessageID: event.Meta.MessageID,
-		})
-		if err != nil {
-			r.log.Error("", logger.ErrAttr(err))
-		}
-	}
-}
+package router
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"archive_bot/internal/const/buttons"
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/folder"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/selection"
+	"archive_bot/internal/user"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	start             string = "/start"
+	info              string = "/info"
+	folders           string = "/folders"
+	moveLastNote      string = "/move_note"
+	moveLastNoteAlias string = "!"
+	apiToken          string = "/token"
+	language          string = "/language"
+	favorites         string = "/favorites"
+	rules             string = "/rules"
+	transcribe        string = "/transcribe"
+)
+
+// Processor handles the events, the messages it returns are already
+// rendered in the language of the user. The router branches on the status
+// of the entities.Result, never on the text.
+type Processor interface {
+	InitUser(ctx context.Context, event *entities.Event)
+	CountUsers(ctx context.Context, event *entities.Event) string
+	SetFolderMsgID(ctx context.Context, userID int64, messageID int)
+	FolderMsgID(ctx context.Context, userID int64) int
+	SetInt(ctx context.Context, key string, num int)
+	Int(ctx context.Context, key string) int
+	AddMessageID(ctx context.Context, userID int64, messageID int)
+
+	Start(ctx context.Context, event *entities.Event) (string, string)
+	Folders(ctx context.Context, event *entities.Event) []entities.Button
+	Save(ctx context.Context, event *entities.Event) *entities.AnswerParams
+	SaveTo(ctx context.Context, event *entities.Event) entities.Result
+	FolderSuggestion(ctx context.Context, event *entities.Event) *folder.Suggestion
+	AcceptSuggestion(ctx context.Context, event *entities.Event, id int, create bool) string
+
+	SelectFolder(ctx context.Context, event *entities.Event) (map[int]*entities.AnswerParams, string)
+	Favorites(ctx context.Context, event *entities.Event) map[int]*entities.AnswerParams
+	ToggleFavorite(ctx context.Context, event *entities.Event) (bool, entities.Result)
+	AddFolderStart(ctx context.Context, event *entities.Event) string
+	AddFolderEnd(ctx context.Context, event *entities.Event) entities.Result
+	DeleteFolderStart(ctx context.Context, event *entities.Event) string
+	DeleteFolderEnd(ctx context.Context, event *entities.Event) entities.Result
+
+	FolderList(ctx context.Context, event *entities.Event) []*folder.Folder
+	FolderSettings(ctx context.Context, event *entities.Event, id int) *folder.Folder
+	EditFolderStart(ctx context.Context, event *entities.Event, id int, field string) string
+	EditFolderEnd(ctx context.Context, event *entities.Event) string
+	ShiftFolder(ctx context.Context, event *entities.Event, id int, delta int) string
+	ToggleFolderHidden(ctx context.Context, event *entities.Event, id int) string
+	MergeFolder(ctx context.Context, event *entities.Event, id int, into int) string
+	CopyFolder(ctx context.Context, event *entities.Event, id int) (int, string)
+	SplitFolder(ctx context.Context, event *entities.Event, id int, by folder.SplitBy) (int, string)
+
+	MoveNoteStart(ctx context.Context, event *entities.Event) string
+	MoveNoteEnd(ctx context.Context, event *entities.Event) string
+	RemoveNote(ctx context.Context, event *entities.Event) string
+
+	StartSelection(ctx context.Context, event *entities.Event) *selection.Selection
+	Selection(userID int64) *selection.Selection
+	EndSelection(userID int64)
+	MoveSelection(ctx context.Context, event *entities.Event, folderID int) string
+	RemoveSelection(ctx context.Context, event *entities.Event) string
+	TagSelection(ctx context.Context, event *entities.Event) string
+	ExportSelection(ctx context.Context, event *entities.Event) (*bytes.Buffer, string)
+
+	LinkChannel(ctx context.Context, event *entities.Event, channelID int64, title string, username string) string
+	UnlinkChannel(ctx context.Context, event *entities.Event, channelID int64) string
+	SaveChannelPost(ctx context.Context, event *entities.Event)
+	EditChannelPost(ctx context.Context, event *entities.Event)
+
+	Token(ctx context.Context, event *entities.Event) string
+	Rules(ctx context.Context, event *entities.Event) string
+	Transcribe(ctx context.Context, event *entities.Event) string
+	SetLanguage(ctx context.Context, event *entities.Event, code string) string
+
+	IsBanned(ctx context.Context, userID int64) bool
+	UserProfile(ctx context.Context, event *entities.Event, query string) (*user.Profile, string)
+	BanUser(ctx context.Context, event *entities.Event, userID int64, reason string) string
+	UnbanUser(ctx context.Context, event *entities.Event, userID int64) string
+	SetQuota(ctx context.Context, event *entities.Event, userID int64, q *user.Quota) string
+	WipeUser(ctx context.Context, event *entities.Event, userID int64) string
+}
+
+type Sender interface {
+	Send(ctx context.Context, answers []*entities.Answer)
+}
+
+type router struct {
+	log           *logger.Logger
+	sender        Sender
+	limiter       Limiter
+	roles         Roles
+	groupKeyword  string
+	folderColumns int
+	botName       botName
+	albums        albums
+	process       Processor
+	broadcasts    Broadcaster
+	stats         Stats
+	audit         Auditor
+}
+
+func New(
+	log *logger.Logger,
+	roles Roles,
+	groupKeyword string,
+	folderColumns int,
+	processor Processor,
+	sender Sender,
+	limiter Limiter,
+	broadcasts Broadcaster,
+	stats Stats,
+	audit Auditor,
+) *router {
+	r := &router{
+		log:           log,
+		roles:         roles,
+		broadcasts:    broadcasts,
+		stats:         stats,
+		audit:         audit,
+		sender:        sender,
+		limiter:       limiter,
+		process:       processor,
+		groupKeyword:  groupKeyword,
+		folderColumns: folderColumns,
+	}
+
+	return r
+}
+
+func (r *router) RouteCallbackQuery(ctx context.Context, b *bot.Bot, update *models.Update) {
+	ctx, span := startUpdate(ctx, update, "router.RouteCallbackQuery")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "router.RouteCallbackQuery"))
+
+	event := entities.NewEvent(ctx, update)
+	// r.process.AddMessageID(ctx, event.Meta.UserID, event.Meta.MessageID)
+	r.process.InitUser(ctx, event)
+
+	log.Debug("switch CallbackQuery", logger.String("command", event.Text))
+	switch {
+	case event.Text == buttons.CreateFolder:
+		r.handle(ctx, b, event, "create_folder", r.doCreateFolder)
+	case event.Text == buttons.DeleteFolder:
+		r.handle(ctx, b, event, "delete_folder", r.doDeleteFolder)
+	case event.Text == buttons.SetupFolder:
+		r.handle(ctx, b, event, "setup_folder", r.doSetupFolder)
+	case strings.HasPrefix(event.Text, buttons.FolderPrefix):
+		r.handle(ctx, b, event, "folder_settings", r.doFolderSettings)
+	case strings.HasPrefix(event.Text, buttons.SelectPrefix):
+		r.handle(ctx, b, event, "select_notes", r.doSelection)
+	case strings.HasPrefix(event.Text, buttons.Favorite):
+		r.handle(ctx, b, event, "favorite", r.doFavorite)
+	case strings.HasPrefix(event.Text, buttons.DeleteNote):
+		r.handle(ctx, b, event, "delete_note", r.doDeleteNote)
+	case strings.HasPrefix(event.Text, buttons.MoveNote):
+		r.handle(ctx, b, event, "move_note", r.doMoveNote)
+	case strings.HasPrefix(event.Text, buttons.Language):
+		r.handle(ctx, b, event, "language", r.doLanguageCallback)
+	default:
+		r.handle(ctx, b, event, "default_callback", r.doDefaultCallback)
+	}
+}
+
+func (r *router) RouteMessage(ctx context.Context, b *bot.Bot, update *models.Update) {
+	ctx, span := startUpdate(ctx, update, "router.RouteMessage")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "router.RouteMessage"))
+	if update.ChannelPost != nil || update.EditedChannelPost != nil {
+		r.routeChannelPost(ctx, b, update)
+		return
+	}
+
+	if update.Message != nil && isGroupChat(update.Message.Chat.Type) {
+		r.routeGroupMessage(ctx, b, update)
+		return
+	}
+
+	event := entities.NewEvent(ctx, update)
+	if r.composing(ctx, event) {
+		r.runAdmin(ctx, b, event, broadcastDraft)
+		return
+	}
+	r.process.AddMessageID(ctx, event.Meta.UserID, event.Meta.MessageID)
+	r.process.InitUser(ctx, event)
+
+	command, text := commandAndText(event.Text)
+	if text != "" {
+		event.Text = text
+	}
+
+	if event.Type == entities.Unknown {
+		r.handle(ctx, b, event, "unknown", r.doUnknown)
+		return
+	}
+
+	log.Debug("switch Message", logger.String("command", command))
+	switch command {
+	case "":
+		r.handle(ctx, b, event, "empty", r.doEmpty)
+	case start:
+		r.handle(ctx, b, event, "start", r.doStart)
+	case info:
+		r.handle(ctx, b, event, "info", r.doInfo)
+	case folders:
+		r.handle(ctx, b, event, "show_folders", r.doShowFolders)
+	case moveLastNote:
+		r.handle(ctx, b, event, "save_to", r.doSaveTo)
+	case linkChannel:
+		r.handle(ctx, b, event, "link_channel", r.doLinkChannel)
+	case unlinkChannel:
+		r.handle(ctx, b, event, "unlink_channel", r.doUnlinkChannel)
+	case apiToken:
+		r.handle(ctx, b, event, "token", r.doToken)
+	case language:
+		r.handle(ctx, b, event, "language", r.doLanguage)
+	case favorites:
+		r.handle(ctx, b, event, "favorites", r.doFavorites)
+	case rules:
+		r.handle(ctx, b, event, "rules", r.doRules)
+	case transcribe:
+		r.handle(ctx, b, event, "transcribe", r.doTranscribe)
+	default:
+		r.handle(ctx, b, event, "unknown", r.doUnknown)
+	}
+}
+
+func isGroupChat(chatType models.ChatType) bool {
+	return chatType == models.ChatTypeGroup || chatType == models.ChatTypeSupergroup
+}
+
+// sendFoldersList lays the folders out in the grid of the columns in
+// their order, the menu row goes last.
+func sendFoldersList(
+	event *entities.Event,
+	folders []entities.Button,
+	columns int,
+	deleteAfter bool,
+) *entities.Answer {
+	folderButtons := make([]models.InlineKeyboardButton, 0, len(folders))
+	for _, f := range folders {
+		folderButtons = append(folderButtons, models.InlineKeyboardButton{
+			CallbackData: f.Data, Text: f.Text,
+		})
+	}
+
+	btns := buttonGrid(folderButtons, columns)
+	btns = append(btns, []models.InlineKeyboardButton{
+		{
+			CallbackData: buttons.CreateFolder,
+			Text:         buttons.MenuOptions[buttons.CreateFolder],
+		},
+		{
+			CallbackData: buttons.SetupFolder,
+			Text:         buttons.MenuOptions[buttons.SetupFolder],
+		},
+		{
+			CallbackData: buttons.DeleteFolder,
+			Text:         buttons.MenuOptions[buttons.DeleteFolder],
+		},
+	})
+
+	return entities.NewAnswer(
+		event,
+		deleteAfter,
+		&entities.AnswerParams{
+			Message:  i18n.T(event.Meta.Language, messages.FoldersCaption),
+			Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: btns},
+		})
+}
+
+// buttonGrid splits the buttons into the rows of the columns, one column
+// is used for the columns less than one.
+func buttonGrid(btns []models.InlineKeyboardButton, columns int) [][]models.InlineKeyboardButton {
+	columns = max(columns, 1)
+	rows := make([][]models.InlineKeyboardButton, 0, (len(btns)+columns-1)/columns+1)
+	for len(btns) > 0 {
+		n := min(columns, len(btns))
+		rows = append(rows, btns[:n])
+		btns = btns[n:]
+	}
+
+	return rows
+}
+
+func sendNote(
+	event *entities.Event,
+	noteID int,
+	folderID int,
+	deleteAfter bool,
+	ap *entities.AnswerParams,
+) *entities.Answer {
+	noteAndFolder := strconv.Itoa(noteID) + buttons.Delimiter + strconv.Itoa(folderID)
+
+	switch ap.Type {
+	case entities.Photo:
+		if len(ap.FileIDs) == 1 {
+			noteAndFolder += buttons.Delimiter + buttons.WithPhoto
+		}
+	}
+
+	ap.Keyboard = noteKeyboard(noteAndFolder, ap.Favorite)
+
+	return entities.NewAnswer(event, deleteAfter, ap)
+}
+
+// noteKeyboard builds the buttons of the note, noteAndFolder is the tail
+// of their callback data.
+func noteKeyboard(noteAndFolder string, favorite bool) *models.InlineKeyboardMarkup {
+	buttonsRow := make([]models.InlineKeyboardButton, 0, len(buttons.CatalogueOptions)+1)
+	buttonsRow = append(buttonsRow, models.InlineKeyboardButton{
+		CallbackData: buttons.Favorite + buttons.Delimiter + noteAndFolder,
+		Text:         buttons.FavoriteOptions[favorite],
+	})
+
+	for key, val := range buttons.CatalogueOptions {
+		buttonsRow = append(buttonsRow, models.InlineKeyboardButton{
+			CallbackData: key + buttons.Delimiter + noteAndFolder,
+			Text:         val,
+		})
+	}
+
+	sort.Slice(buttonsRow, func(i, j int) bool {
+		return buttonsRow[i].CallbackData < buttonsRow[j].CallbackData
+	})
+
+	return &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{buttonsRow},
+	}
+}
+
+func sendFoldersButton(
+	event *entities.Event,
+	description string,
+	button string,
+	deleteAfter bool,
+) *entities.Answer {
+	kb := &models.ReplyKeyboardMarkup{
+		Keyboard:       [][]models.KeyboardButton{{{Text: button}}},
+		ResizeKeyboard: true,
+	}
+
+	return entities.NewAnswer(
+		event,
+		deleteAfter,
+		&entities.AnswerParams{
+			Message:  description,
+			Keyboard: kb,
+		})
+}
+
+func commandAndText(plainMessage string) (string, string) {
+	if plainMessage == "" {
+		return "", ""
+	}
+
+	if strings.HasPrefix(plainMessage, moveLastNoteAlias) {
+		return moveLastNote, strings.TrimLeft(plainMessage, moveLastNoteAlias)
+	}
+
+	if plainMessage == buttons.Folders {
+		return folders, ""
+	}
+
+	if !strings.HasPrefix(plainMessage, "/") {
+		return "", plainMessage
+	}
+
+	count := 0
+	for _, ch := range plainMessage {
+		if ch != '/' {
+			break
+		}
+		count++
+	}
+	if count > 1 {
+		return "", plainMessage
+	}
+
+	strSlice := strings.SplitN(plainMessage, " ", 2)
+
+	command := strSlice[0]
+	if len(strSlice) == 1 {
+		return command, ""
+	}
+
+	text := strings.TrimSpace(strSlice[1])
+
+	return command, text
+}
+
+// inlineFolder is the folder named at the end of the note: "text !name".
+var inlineFolder = regexp.MustCompile(`\s!([\p{L}\p{N}_-]+)\s*$`)
+
+// splitInlineFolder cuts the folder name off the end of the note text, the
+// name is empty if the note names no folder.
+func splitInlineFolder(text string) (string, string) {
+	loc := inlineFolder.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return text, ""
+	}
+	rest := strings.TrimRightFunc(text[:loc[0]], unicode.IsSpace)
+	if rest == "" {
+		return text, ""
+	}
+
+	return rest, text[loc[2]:loc[3]]
+}
+
+func ParseButtonCallback(command string) (int, int, string) {
+	if command == "" {
+		return 0, 0, ""
+	}
+	sl := strings.Split(command, buttons.Delimiter)
+	if len(sl) < 3 {
+		return 0, 0, ""
+	}
+
+	noteID, err := strconv.Atoi(sl[1])
+	if err != nil {
+		return 0, 0, ""
+	}
+	folderID, err := strconv.Atoi(sl[2])
+	if err != nil {
+		return 0, 0, ""
+	}
+
+	if len(sl) == 4 {
+		withMedia := sl[3]
+		return noteID, folderID, withMedia
+	}
+
+	return noteID, folderID, ""
+}
+
+// deleteMessages queues the removal of the previous screen. The messages
+// queued before it are delivered first, so they are removed too.
+func (r *router) deleteMessages(ctx context.Context, event *entities.Event) {
+	r.sender.Send(ctx, []*entities.Answer{{
+		UserID:         event.Meta.UserID,
+		DeleteMessages: &bot.DeleteMessagesParams{ChatID: event.Meta.ChatID},
+	}})
+}
+
+// deleteMessage queues the removal of the message of the event.
+func (r *router) deleteMessage(ctx context.Context, event *entities.Event) {
+	if event.Meta.MessageID == 0 {
+		return
+	}
+	r.sender.Send(ctx, []*entities.Answer{{
+		UserID: event.Meta.UserID,
+		DeleteMessages: &bot.DeleteMessagesParams{
+			ChatID:     event.Meta.ChatID,
+			MessageIDs: []int{event.Meta.MessageID},
+		},
+	}})
+}
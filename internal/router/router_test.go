@@ -1,86 +1,525 @@
-package router
-
-import (
-	"archive_bot/internal/const/buttons"
-	"testing"
-
-	"github.com/stretchr/testify/assert"
-)
-
-func TestCommandAndTest(t *testing.T) {
-	t.Parallel()
-	testCases := []struct {
-		title string
-		input string
-		want  []string
-	}{
-		{
-			"empty", "", []string{"", ""},
-		},
-		{
-			"move last note alias", moveLastNoteAlias + "folder name", []string{moveLastNote, "folder name"},
-		},
-		{
-			"move last note alias", buttons.Folders, []string{folders, ""},
-		},
-		{
-			"some text without /", "some text without /", []string{"", "some text without /"},
-		},
-		{
-			"command and text", "/command some text", []string{"/command", "some text"},
-		},
-		{
-			"many slashes in a row", "//////////some text", []string{"", "//////////some text"},
-		},
-		{
-			"many slashes in different places", "/command //:some/link", []string{"/command", "//:some/link"},
-		},
-	}
-
-	for _, tc := range testCases {
-		tc := tc
-		t.Run(tc.title, func(t *testing.T) {
-			command, text := commandAndText(tc.input)
-			got := []string{command, text}
-			assert.Equal(t, tc.want, got)
-		})
-	}
-}
-
-func TestParseButtonCallback(t *testing.T) {
-	// key + "_" + strconv.Itoa(noteID) + "_" + strconv.Itoa(folderID)
-	t.Parallel()
-	testCases := []struct {
-		title string
-		input string
-		want  []int
-	}{
-		{
-			"empty", "", []int{0, 0},
-		},
-		{
-			"too long", buttons.CreateFolder + buttons.Delimiter + "1" + buttons.Delimiter + "2" + buttons.Delimiter + "3", []int{1, 2},
-		},
-		{
-			"too short", buttons.CreateFolder + buttons.Delimiter + "1", []int{0, 0},
-		},
-		{
-			"error in 1", buttons.CreateFolder + buttons.Delimiter + "error" + buttons.Delimiter + "2", []int{0, 0},
-		},
-		{
-			"error in 2", buttons.CreateFolder + buttons.Delimiter + "1" + buttons.Delimiter + "error", []int{0, 0},
-		},
-		{
-			"good case", buttons.CreateFolder + buttons.Delimiter + "1" + buttons.Delimiter + "2", []int{1, 2},
-		},
-	}
-
-	for _, tc := range testCases {
-		tc := tc
-		t.Run(tc.title, func(t *testing.T) {
-			noteID, FolderID, _ := ParseButtonCallback(tc.input)
-			got := []int{noteID, FolderID}
-			assert.Equal(t, tc.want, got)
-		})
-	}
-}
+package router
+
+import (
+	"archive_bot/internal/const/buttons"
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/selection"
+	"archive_bot/internal/user"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandAndTest(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		title string
+		input string
+		want  []string
+	}{
+		{
+			"empty", "", []string{"", ""},
+		},
+		{
+			"move last note alias", moveLastNoteAlias + "folder name", []string{moveLastNote, "folder name"},
+		},
+		{
+			"move last note alias", buttons.Folders, []string{folders, ""},
+		},
+		{
+			"some text without /", "some text without /", []string{"", "some text without /"},
+		},
+		{
+			"command and text", "/command some text", []string{"/command", "some text"},
+		},
+		{
+			"many slashes in a row", "//////////some text", []string{"", "//////////some text"},
+		},
+		{
+			"many slashes in different places", "/command //:some/link", []string{"/command", "//:some/link"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			command, text := commandAndText(tc.input)
+			got := []string{command, text}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSplitInlineFolder(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		title string
+		input string
+		text  string
+		name  string
+	}{
+		{"folder at the end", "buy milk !home", "buy milk", "home"},
+		{"trailing spaces", "read later\n\nhttps://go.dev !Go_links  ", "read later\n\nhttps://go.dev", "Go_links"},
+		{"no folder", "just a note", "just a note", ""},
+		{"exclamation", "Hello world!", "Hello world!", ""},
+		{"in the middle", "a !b c", "a !b c", ""},
+		{"only the folder", "!work", "!work", ""},
+		{"no space", "wow!work", "wow!work", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			text, name := splitInlineFolder(tc.input)
+			assert.Equal(t, tc.text, text)
+			assert.Equal(t, tc.name, name)
+		})
+	}
+}
+
+func TestParseButtonCallback(t *testing.T) {
+	// key + "_" + strconv.Itoa(noteID) + "_" + strconv.Itoa(folderID)
+	t.Parallel()
+	testCases := []struct {
+		title string
+		input string
+		want  []int
+	}{
+		{
+			"empty", "", []int{0, 0},
+		},
+		{
+			"too long", buttons.CreateFolder + buttons.Delimiter + "1" + buttons.Delimiter + "2" + buttons.Delimiter + "3", []int{1, 2},
+		},
+		{
+			"too short", buttons.CreateFolder + buttons.Delimiter + "1", []int{0, 0},
+		},
+		{
+			"error in 1", buttons.CreateFolder + buttons.Delimiter + "error" + buttons.Delimiter + "2", []int{0, 0},
+		},
+		{
+			"error in 2", buttons.CreateFolder + buttons.Delimiter + "1" + buttons.Delimiter + "error", []int{0, 0},
+		},
+		{
+			"good case", buttons.CreateFolder + buttons.Delimiter + "1" + buttons.Delimiter + "2", []int{1, 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			noteID, FolderID, _ := ParseButtonCallback(tc.input)
+			got := []int{noteID, FolderID}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestParseGroupSave(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		title    string
+		input    string
+		keyword  string
+		wantName string
+		wantOK   bool
+	}{
+		{"empty", "", "", "", false},
+		{"plain chatter", "nice link", "", "", false},
+		{"command", "/save", "", "", true},
+		{"command with folder", "/save work stuff", "", "work stuff", true},
+		{"command with mention", "/save@archive_bot work", "", "work", true},
+		{"command for another bot", "/save@other_bot work", "", "", false},
+		{"mention", "@archive_bot save", "", "", true},
+		{"mention with folder", "@Archive_Bot SAVE reading", "", "reading", true},
+		{"mention without save", "@archive_bot hello", "", "", false},
+		{"keyword", "#archive links", "#archive", "links", true},
+		{"keyword is not set", "#archive links", "", "", false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			name, ok := parseGroupSave(tc.input, "archive_bot", tc.keyword)
+			assert.Equal(t, tc.wantOK, ok)
+			assert.Equal(t, tc.wantName, name)
+		})
+	}
+}
+
+func TestAlbums(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	chat := models.Chat{ID: -100}
+	item := func(id int, group string) *models.Message {
+		return &models.Message{ID: id, Chat: chat, MediaGroupID: group}
+	}
+	ids := func(messages []*models.Message) []int {
+		res := make([]int, 0, len(messages))
+		for _, m := range messages {
+			res = append(res, m.ID)
+		}
+		return res
+	}
+
+	var a albums
+	a.add(item(3, "g"), now)
+	a.add(item(1, "g"), now)
+	a.add(item(2, "g"), now)
+	a.add(item(2, "g"), now)
+	a.add(item(7, "old"), now.Add(-2*albumTTL))
+
+	assert.Equal(t, []int{1, 2, 3}, ids(a.get(item(2, "g"), now)))
+	assert.Len(t, a.items, 2)
+
+	assert.Equal(t, []int{4}, ids(a.get(item(4, "unknown"), now)))
+	assert.Equal(t, []int{5}, ids(a.get(item(5, ""), now)))
+	_, ok := a.items[albumKey{chatID: chat.ID, groupID: "old"}]
+	assert.False(t, ok, "expired album should be dropped")
+}
+
+func TestReplyEvents(t *testing.T) {
+	t.Parallel()
+	photo := func(id int, caption string) *models.Message {
+		return &models.Message{
+			ID:           id,
+			Chat:         models.Chat{ID: -100, Type: models.ChatTypeSupergroup},
+			MediaGroupID: "g",
+			Caption:      caption,
+			Photo:        []models.PhotoSize{{FileID: "file" + strconv.Itoa(id)}},
+		}
+	}
+	update := &models.Update{Message: &models.Message{
+		ID:   10,
+		Chat: models.Chat{ID: -100, Type: models.ChatTypeSupergroup},
+		From: &models.User{ID: 42},
+		Text: "/save",
+	}}
+
+	events := replyEvents(context.Background(), update, []*models.Message{
+		photo(1, ""), photo(2, "the caption"), photo(3, ""),
+	})
+
+	files := make([]string, 0, len(events))
+	for _, e := range events {
+		files = append(files, e.FileID)
+		assert.Equal(t, int64(42), e.Meta.UserID)
+	}
+	assert.Equal(t, []string{"file2", "file1", "file3"}, files)
+	assert.Equal(t, "the caption", events[0].Text)
+	assert.Empty(t, events[1].Text)
+	assert.Empty(t, events[2].Text)
+}
+
+func TestSplitChannelRef(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		title string
+		input string
+		want  []string
+	}{
+		{"command without arguments", linkChannel, []string{"", ""}},
+		{"channel only", "@my_channel", []string{"@my_channel", ""}},
+		{"channel and folder", "@my_channel reading list", []string{"@my_channel", "reading list"}},
+		{"channel id", "-1001234567890 links", []string{"-1001234567890", "links"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			ref, folder := splitChannelRef(tc.input)
+			assert.Equal(t, tc.want, []string{ref, folder})
+		})
+	}
+}
+
+func TestUpdateKey(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		title  string
+		update *models.Update
+		want   int64
+	}{
+		{
+			"message", &models.Update{Message: &models.Message{
+				From: &models.User{ID: 1}, Chat: models.Chat{ID: -10},
+			}}, 1,
+		},
+		{
+			"callback query", &models.Update{CallbackQuery: &models.CallbackQuery{
+				From: models.User{ID: 2},
+			}}, 2,
+		},
+		{
+			"channel post", &models.Update{ChannelPost: &models.Message{
+				Chat: models.Chat{ID: -100},
+			}}, -100,
+		},
+		{
+			"anonymous", &models.Update{Message: &models.Message{
+				Chat: models.Chat{ID: -10},
+			}}, 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			assert.Equal(t, tc.want, updateKey(tc.update))
+		})
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+
+	l := NewLimiter(60, 2)
+	assert.True(t, l.allowAt(1, now))
+	assert.True(t, l.allowAt(1, now))
+	assert.False(t, l.allowAt(1, now))
+	assert.True(t, l.allowAt(2, now))
+	assert.True(t, l.allowAt(1, now.Add(time.Second)))
+
+	l.allowAt(3, now.Add(limiterIdle+time.Second))
+	assert.Len(t, l.users, 1)
+
+	off := NewLimiter(0, 0)
+	for i := 0; i < 10; i++ {
+		assert.True(t, off.allowAt(1, now))
+	}
+}
+
+func TestBroadcastID(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		data   string
+		prefix string
+		want   int64
+		ok     bool
+	}{
+		{broadcastSend + "12", broadcastSend, 12, true},
+		{broadcastCancel + "7", broadcastCancel, 7, true},
+		{broadcastSend, broadcastSend, 0, false},
+		{broadcastSend + "0", broadcastSend, 0, false},
+		{broadcastSend + "x", broadcastSend, 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.data, func(t *testing.T) {
+			id, ok := broadcastID(tc.data, tc.prefix)
+			assert.Equal(t, tc.ok, ok)
+			if tc.ok {
+				assert.Equal(t, tc.want, id)
+			}
+		})
+	}
+}
+
+func TestParseQuota(t *testing.T) {
+	t.Parallel()
+	n := func(v int) *int { return &v }
+	mb := func(v int64) *int64 { v *= megabyte; return &v }
+
+	testCases := []struct {
+		args string
+		want *user.Quota
+		ok   bool
+	}{
+		{"notes=100 folders=5", &user.Quota{MaxNotes: n(100), MaxFolders: n(5)}, true},
+		{"folders=3", &user.Quota{MaxFolders: n(3)}, true},
+		{"notes=0", &user.Quota{MaxNotes: n(0)}, true},
+		{"day=20 media=512", &user.Quota{NotesPerDay: n(20), MaxMediaBytes: mb(512)}, true},
+		{"default", &user.Quota{}, true},
+		{"", nil, false},
+		{"notes=-1", nil, false},
+		{"notes=x", nil, false},
+		{"files=1", nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.args, func(t *testing.T) {
+			q, ok := parseQuota(tc.args)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, q)
+		})
+	}
+}
+
+func TestIsAdminCommand(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name     string
+		text     string
+		chatType models.ChatType
+		want     bool
+	}{
+		{"panel", "/adm", models.ChatTypePrivate, true},
+		{"with args", "/adm_ban 42 spam", models.ChatTypePrivate, true},
+		{"contains adm", "my admin panel link", models.ChatTypePrivate, false},
+		{"prefix of command", "/admin", models.ChatTypePrivate, false},
+		{"group", "/adm", models.ChatTypeSupergroup, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			update := &models.Update{Message: &models.Message{
+				Text: tc.text,
+				Chat: models.Chat{Type: tc.chatType},
+			}}
+			assert.Equal(t, tc.want, IsAdminCommand(update))
+		})
+	}
+	assert.False(t, IsAdminCommand(&models.Update{}))
+}
+
+func TestNoteKeyboard(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		favorite bool
+		want     string
+	}{
+		{true, "⭐"},
+		{false, "☆"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.want, func(t *testing.T) {
+			kb := noteKeyboard("4:2:ph", tc.favorite)
+			row := kb.InlineKeyboard[0]
+			assert.Len(t, row, 3)
+			assert.Equal(t, tc.want, row[0].Text)
+			assert.Equal(t, buttons.Favorite+":4:2:ph", row[0].CallbackData)
+
+			noteID, folderID, withMedia := ParseButtonCallback(row[0].CallbackData)
+			assert.Equal(t, 4, noteID)
+			assert.Equal(t, 2, folderID)
+			assert.Equal(t, buttons.WithPhoto, withMedia)
+		})
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	t.Parallel()
+	event := &entities.Event{Meta: entities.Meta{UserID: 1, ChatID: 1, Language: i18n.EN}}
+
+	// the text of the user is never taken for a message key
+	assert.Equal(t, messages.Error, sendMessage(event, messages.Error).SendMessage.Text)
+	assert.Equal(t, i18n.T(i18n.EN, messages.Error), sendKey(event, messages.Error).SendMessage.Text)
+	assert.Equal(t, "Broadcast #7 is already over", sendKey(event, messages.BroadcastOver, 7).SendMessage.Text)
+}
+
+func TestButtonGrid(t *testing.T) {
+	t.Parallel()
+	btns := make([]models.InlineKeyboardButton, 5)
+	for i := range btns {
+		btns[i].CallbackData = buttons.Prefix + strconv.Itoa(i)
+	}
+
+	testCases := []struct {
+		columns int
+		want    []int
+	}{
+		{1, []int{1, 1, 1, 1, 1}},
+		{2, []int{2, 2, 1}},
+		{3, []int{3, 2}},
+		{8, []int{5}},
+		{0, []int{1, 1, 1, 1, 1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(strconv.Itoa(tc.columns), func(t *testing.T) {
+			rows := buttonGrid(btns, tc.columns)
+			sizes := make([]int, 0, len(rows))
+			for _, row := range rows {
+				sizes = append(sizes, len(row))
+			}
+			assert.Equal(t, tc.want, sizes)
+			assert.Equal(t, btns[len(btns)-1], rows[len(rows)-1][len(rows[len(rows)-1])-1])
+		})
+	}
+}
+
+func TestFolderCallback(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		data   string
+		action string
+		id     int
+		arg    int
+		ok     bool
+	}{
+		{buttons.FolderUp + ":12", buttons.FolderUp, 12, 0, true},
+		{buttons.FolderMenu + ":3", buttons.FolderMenu, 3, 0, true},
+		{buttons.FolderList, buttons.FolderList, 0, 0, true},
+		{buttons.FolderMergeTo + ":3:7", buttons.FolderMergeTo, 3, 7, true},
+		{folderData(buttons.FolderSplitType, 4, 2), buttons.FolderSplitType, 4, 2, true},
+		{buttons.FolderHide, "", 0, 0, false},
+		{buttons.FolderHide + ":x", "", 0, 0, false},
+		{buttons.FolderHide + ":0", "", 0, 0, false},
+		{buttons.FolderSuggestNew + ":0", buttons.FolderSuggestNew, 0, 0, true},
+		{buttons.FolderSuggestOk + ":0", "", 0, 0, false},
+		{buttons.FolderMergeTo + ":3:x", "", 0, 0, false},
+		{buttons.FolderMergeTo + ":3:7:1", "", 0, 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.data, func(t *testing.T) {
+			action, id, arg, ok := folderCallback(tc.data)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.action, action)
+			assert.Equal(t, tc.id, id)
+			assert.Equal(t, tc.arg, arg)
+		})
+	}
+}
+
+func TestSelectionCallback(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		data   string
+		action string
+		arg    int
+	}{
+		{buttons.SelectToggle + ":7", buttons.SelectToggle, 7},
+		{buttons.SelectPage + ":-1", buttons.SelectPage, -1},
+		{buttons.SelectAll, buttons.SelectAll, 0},
+		{buttons.SelectDeleteOk, buttons.SelectDeleteOk, 0},
+		{buttons.SelectTo + ":x", buttons.SelectTo, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.data, func(t *testing.T) {
+			action, arg := selectionCallback(tc.data)
+			assert.Equal(t, tc.action, action)
+			assert.Equal(t, tc.arg, arg)
+		})
+	}
+}
+
+func TestSelectionRows(t *testing.T) {
+	t.Parallel()
+	items := make([]selection.Item, 0, selection.PageSize+1)
+	for id := 1; id <= selection.PageSize+1; id++ {
+		items = append(items, selection.NewItem(id, entities.Message, "note"))
+	}
+	items[0].Type = entities.Photo
+	s := selection.New(1, items)
+	s.Toggle(2)
+
+	rows := selectionRows(s)
+	if !assert.Len(t, rows, selection.PageSize+4) {
+		return
+	}
+	assert.Equal(t, "⬜ 🖼 note", rows[0][0].Text)
+	assert.Equal(t, "✅ 📝 note", rows[1][0].Text)
+	assert.Equal(t, "1/2", rows[selection.PageSize][1].Text)
+	assert.Len(t, rows[selection.PageSize+1], 3)
+
+	s.SetPage(1)
+	assert.Len(t, selectionRows(s), 1+4)
+}
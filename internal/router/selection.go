@@ -0,0 +1,272 @@
+package router
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"archive_bot/internal/const/buttons"
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/selection"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// folderHeader opens the folder view, the folder with notes offers
+// the selection.
+func folderHeader(event *entities.Event, hasNotes bool) *entities.Answer {
+	ap := &entities.AnswerParams{Message: messages.FolderEmoji}
+	if hasNotes {
+		ap.Keyboard = &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{{{
+			CallbackData: selectionData(buttons.SelectStart, event.FolderID),
+			Text:         buttons.SelectOptions[buttons.SelectStart],
+		}}}}
+	}
+
+	return entities.NewAnswer(event, true, ap)
+}
+
+// doSelection handles the panel of the selection. The panel is edited in
+// place, the bulk actions close it and show the folder again.
+func (r *router) doSelection(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	action, arg := selectionCallback(event.Text)
+	if action == buttons.SelectStart {
+		if arg <= 0 {
+			r.doUnknown(ctx, b, event)
+			return
+		}
+		event.FolderID = arg
+		s := r.process.StartSelection(ctx, event)
+		r.sendAnswers(ctx, b, []*entities.Answer{selectionPanel(event, s, nil, r.folderColumns)})
+		return
+	}
+
+	s := r.process.Selection(event.Meta.UserID)
+	if s == nil {
+		r.deleteMessage(ctx, event)
+		r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.SelectionExpired)})
+		return
+	}
+	event.FolderID = s.FolderID
+
+	var toast string
+	switch action {
+	case buttons.SelectToggle:
+		s.Toggle(arg)
+	case buttons.SelectAll:
+		s.ToggleAll()
+	case buttons.SelectType:
+		s.ToggleType(entities.Type(arg))
+	case buttons.SelectPage:
+		s.SetPage(arg)
+	case buttons.SelectBack:
+		s.Awaiting = selection.AwaitNothing
+	case buttons.SelectMove, buttons.SelectDelete, buttons.SelectTag, buttons.SelectExport:
+		if len(s.IDs()) == 0 {
+			toast = messages.SelectionEmpty
+			break
+		}
+		switch action {
+		case buttons.SelectMove:
+			s.Awaiting = selection.AwaitFolder
+		case buttons.SelectDelete:
+			s.Awaiting = selection.AwaitRemove
+		case buttons.SelectTag:
+			s.Awaiting = selection.AwaitTags
+			r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.AskTags)})
+			return
+		case buttons.SelectExport:
+			r.exportSelection(ctx, b, event)
+			return
+		}
+	case buttons.SelectTo:
+		r.finishSelection(ctx, b, event, r.process.MoveSelection(ctx, event, arg))
+		return
+	case buttons.SelectDeleteOk:
+		r.finishSelection(ctx, b, event, r.process.RemoveSelection(ctx, event))
+		return
+	case buttons.SelectCancel:
+		r.process.EndSelection(event.Meta.UserID)
+		answers := []*entities.Answer{{UserID: event.Meta.UserID}}
+		answerCallback(event, answers)
+		r.sendAnswers(ctx, b, answers)
+		r.deleteMessage(ctx, event)
+		return
+	default:
+		r.doUnknown(ctx, b, event)
+		return
+	}
+
+	var folders []entities.Button
+	if s.Awaiting == selection.AwaitFolder {
+		folders = r.process.Folders(ctx, event)
+	}
+
+	event.IsEdited = true
+	ans := selectionPanel(event, s, folders, r.folderColumns)
+	if toast != "" {
+		ans.AnswerCallbackQuery.Text = i18n.T(event.Meta.Language, toast)
+	}
+	r.sendAnswers(ctx, b, []*entities.Answer{ans})
+}
+
+// tagSelection tags the selected notes with the text, false if the
+// selection does not wait for the tags.
+func (r *router) tagSelection(ctx context.Context, b *bot.Bot, event *entities.Event) bool {
+	message := r.process.TagSelection(ctx, event)
+	if message == "" {
+		return false
+	}
+
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+	return true
+}
+
+// finishSelection shows the folder of the selection with the summary
+// of the bulk action.
+func (r *router) finishSelection(ctx context.Context, b *bot.Bot, event *entities.Event, message string) {
+	event.Text = buttons.Prefix + strconv.Itoa(event.FolderID)
+	r.showFolder(ctx, b, event, []*entities.Answer{sendMessage(event, message)})
+}
+
+// exportSelection sends the selected notes as a file.
+func (r *router) exportSelection(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	buf, message := r.process.ExportSelection(ctx, event)
+	if buf == nil {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+		return
+	}
+
+	answers := []*entities.Answer{{
+		UserID: event.Meta.UserID,
+		SendDocument: &bot.SendDocumentParams{
+			ChatID:  event.Meta.ChatID,
+			Caption: message,
+			Document: &models.InputFileUpload{
+				Filename: "notes-" + time.Now().Format(time.DateOnly) + ".csv",
+				Data:     buf,
+			},
+		},
+	}}
+	answerCallback(event, answers)
+	r.sendAnswers(ctx, b, answers)
+}
+
+// selectionPanel renders the notes with the marks or, while the selection
+// waits for the folder or the confirmation, the question.
+func selectionPanel(
+	event *entities.Event,
+	s *selection.Selection,
+	folders []entities.Button,
+	columns int,
+) *entities.Answer {
+	lang := event.Meta.Language
+	count := len(s.IDs())
+	back := models.InlineKeyboardButton{
+		CallbackData: buttons.SelectBack,
+		Text:         buttons.SelectOptions[buttons.SelectBack],
+	}
+
+	message := i18n.N(lang, messages.NotesSelected, count)
+	var rows [][]models.InlineKeyboardButton
+	switch s.Awaiting {
+	case selection.AwaitFolder:
+		message += "\n" + i18n.T(lang, messages.SelectionChooseFolder)
+		btns := make([]models.InlineKeyboardButton, 0, len(folders))
+		for _, f := range folders {
+			id, err := strconv.Atoi(strings.TrimPrefix(f.Data, buttons.Prefix))
+			if err != nil || id == s.FolderID {
+				continue
+			}
+			btns = append(btns, models.InlineKeyboardButton{
+				CallbackData: selectionData(buttons.SelectTo, id),
+				Text:         f.Text,
+			})
+		}
+		rows = append(buttonGrid(btns, columns), []models.InlineKeyboardButton{back})
+	case selection.AwaitRemove:
+		message = i18n.N(lang, messages.SelectionRemoveAsk, count)
+		rows = [][]models.InlineKeyboardButton{{
+			{CallbackData: buttons.SelectDeleteOk, Text: buttons.SelectOptions[buttons.SelectDeleteOk]},
+			back,
+		}}
+	default:
+		rows = selectionRows(s)
+	}
+
+	return entities.NewAnswer(event, true, &entities.AnswerParams{
+		Message:  message,
+		Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: rows},
+	})
+}
+
+// selectionRows lays out a note per row, the pages, the filters and
+// the actions.
+func selectionRows(s *selection.Selection) [][]models.InlineKeyboardButton {
+	rows := make([][]models.InlineKeyboardButton, 0, selection.PageSize+4)
+	for _, it := range s.PageItems() {
+		mark := buttons.Unselected
+		if s.IsSelected(it.ID) {
+			mark = buttons.Selected
+		}
+		rows = append(rows, []models.InlineKeyboardButton{{
+			CallbackData: selectionData(buttons.SelectToggle, it.ID),
+			Text:         strings.TrimSpace(mark + " " + buttons.TypeIcons[it.Type] + " " + it.Preview),
+		}})
+	}
+
+	if pages := s.Pages(); pages > 1 {
+		rows = append(rows, []models.InlineKeyboardButton{
+			{CallbackData: selectionData(buttons.SelectPage, s.Page-1), Text: "◀️"},
+			{
+				CallbackData: selectionData(buttons.SelectPage, s.Page),
+				Text:         strconv.Itoa(s.Page+1) + "/" + strconv.Itoa(pages),
+			},
+			{CallbackData: selectionData(buttons.SelectPage, s.Page+1), Text: "▶️"},
+		})
+	}
+
+	filters := []models.InlineKeyboardButton{{
+		CallbackData: buttons.SelectAll,
+		Text:         buttons.SelectOptions[buttons.SelectAll],
+	}}
+	if types := s.Types(); len(types) > 1 {
+		for _, t := range types {
+			filters = append(filters, models.InlineKeyboardButton{
+				CallbackData: selectionData(buttons.SelectType, int(t)),
+				Text:         buttons.TypeIcons[t],
+			})
+		}
+	}
+	rows = append(rows, filters)
+
+	actions := make([]models.InlineKeyboardButton, 0, 4)
+	for _, action := range []string{buttons.SelectMove, buttons.SelectTag, buttons.SelectExport, buttons.SelectDelete} {
+		actions = append(actions, models.InlineKeyboardButton{
+			CallbackData: action,
+			Text:         buttons.SelectOptions[action],
+		})
+	}
+
+	return append(rows, actions, []models.InlineKeyboardButton{{
+		CallbackData: buttons.SelectCancel,
+		Text:         buttons.SelectOptions[buttons.SelectCancel],
+	}})
+}
+
+func selectionData(action string, arg int) string {
+	return action + buttons.Delimiter + strconv.Itoa(arg)
+}
+
+// selectionCallback parses the action and the argument of the selection
+// callback, the missing argument is 0.
+func selectionCallback(data string) (string, int) {
+	action, argStr, _ := strings.Cut(data, buttons.Delimiter)
+	arg, _ := strconv.Atoi(argStr)
+
+	return action, arg
+}
@@ -0,0 +1,108 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"html"
+	"time"
+
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/stats"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	statsText string = "adm_stats"
+	statsCSV  string = "adm_stats_csv"
+)
+
+type Stats interface {
+	Report(ctx context.Context) ([]*stats.Table, error)
+}
+
+// doStats sends a message per table, the last one offers the CSV.
+func (r *router) doStats(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	tables, err := r.report(ctx, event)
+	if err != nil {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.Error)})
+		return
+	}
+
+	answers := make([]*entities.Answer, 0, len(tables))
+	for _, t := range tables {
+		answers = append(answers, &entities.Answer{
+			UserID:      event.Meta.UserID,
+			DeleteAfter: true,
+			SendMessage: &bot.SendMessageParams{
+				ChatID:    event.Meta.ChatID,
+				Text:      "<pre>" + html.EscapeString(t.Text()) + "</pre>",
+				ParseMode: models.ParseModeHTML,
+			},
+		})
+	}
+	if len(answers) > 0 {
+		answers[len(answers)-1].SendMessage.ReplyMarkup = &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{{{CallbackData: statsCSV, Text: i18n.T(event.Meta.Language, messages.StatsCSV)}}},
+		}
+	}
+	answerCallback(event, answers)
+
+	r.sendAnswers(ctx, b, answers)
+}
+
+// doStatsCSV sends the tables as a file.
+func (r *router) doStatsCSV(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	log := logger.L(ctx).With(logger.String("operation", "router.doStatsCSV"))
+
+	tables, err := r.report(ctx, event)
+	if err != nil {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.Error)})
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err := stats.WriteCSV(buf, tables); err != nil {
+		log.Error("failed to write csv", logger.ErrAttr(err))
+		r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.Error)})
+		return
+	}
+
+	answers := []*entities.Answer{{
+		UserID: event.Meta.UserID,
+		SendDocument: &bot.SendDocumentParams{
+			ChatID: event.Meta.ChatID,
+			Document: &models.InputFileUpload{
+				Filename: "stats-" + time.Now().Format(time.DateOnly) + ".csv",
+				Data:     buf,
+			},
+		},
+	}}
+	answerCallback(event, answers)
+	r.sendAnswers(ctx, b, answers)
+}
+
+// report returns the statistics with the titles in the language of the admin.
+func (r *router) report(ctx context.Context, event *entities.Event) ([]*stats.Table, error) {
+	tables, err := r.stats.Report(ctx)
+	for _, t := range tables {
+		t.Title = i18n.T(event.Meta.Language, t.Title)
+	}
+
+	return tables, err
+}
+
+// answerCallback answers the callback query with the first answer.
+func answerCallback(event *entities.Event, answers []*entities.Answer) {
+	if !event.IsCallbackQuery || len(answers) == 0 {
+		return
+	}
+	answers[0].AnswerCallbackQuery = &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: event.Meta.CallbackQueryID,
+	}
+}
@@ -0,0 +1,256 @@
+package router
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/entities"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/role"
+	"archive_bot/internal/user"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	userLookup string = "/adm_user"
+	userBan    string = "/adm_ban"
+	userUnban  string = "/adm_unban"
+	userQuota  string = "/adm_quota"
+	userWipe   string = "/adm_wipe"
+
+	usersHelp     string = "adm_users"
+	userBanCB     string = "adm_ban:"
+	userUnbanCB   string = "adm_unban:"
+	userWipeCB    string = "adm_wipe:"
+	userWipeOkCB  string = "adm_wipe_ok:"
+	userTimestamp string = "2006-01-02 15:04"
+	megabyte      int64  = 1 << 20
+)
+
+// banned reports whether the update comes from a banned user, the admins
+// are never banned.
+func (r *router) banned(ctx context.Context, update *models.Update) bool {
+	from := updateSender(update)
+	if from == nil || r.roles.Role(ctx, from.ID) != role.None {
+		return false
+	}
+
+	return r.process.IsBanned(ctx, from.ID)
+}
+
+func (r *router) doUsersHelp(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.UsersUsage)})
+}
+
+func (r *router) doUserLookup(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	query := strings.TrimPrefix(strings.TrimSpace(event.Text), "@")
+	if query == "" || query == userLookup {
+		r.doUsersHelp(ctx, b, event)
+		return
+	}
+
+	profile, message := r.process.UserProfile(ctx, event, query)
+	if profile == nil {
+		r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, message)})
+		return
+	}
+
+	r.sendAnswers(ctx, b, []*entities.Answer{userCard(event, profile)})
+}
+
+// outranks answers and returns false when the target user has the role
+// of the caller or a higher one.
+func (r *router) outranks(ctx context.Context, b *bot.Bot, event *entities.Event, id int64) bool {
+	if r.roles.Role(ctx, event.Meta.UserID).Outranks(r.roles.Role(ctx, id)) {
+		return true
+	}
+
+	r.sendAnswers(ctx, b, []*entities.Answer{sendKey(event, messages.UserOutranked)})
+	return false
+}
+
+func (r *router) doUserBan(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	id, reason, ok := userArgs(event.Text, userBan)
+	if !ok {
+		r.doUsersHelp(ctx, b, event)
+		return
+	}
+	if !r.outranks(ctx, b, event, id) {
+		return
+	}
+
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, r.process.BanUser(ctx, event, id, reason))})
+}
+
+func (r *router) doUserUnban(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	id, _, ok := userArgs(event.Text, userUnban)
+	if !ok {
+		r.doUsersHelp(ctx, b, event)
+		return
+	}
+	if !r.outranks(ctx, b, event, id) {
+		return
+	}
+
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, r.process.UnbanUser(ctx, event, id))})
+}
+
+func (r *router) doUserQuota(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	id, args, ok := userArgs(event.Text, userQuota)
+	if !ok {
+		r.doUsersHelp(ctx, b, event)
+		return
+	}
+	q, ok := parseQuota(args)
+	if !ok {
+		r.doUsersHelp(ctx, b, event)
+		return
+	}
+	if !r.outranks(ctx, b, event, id) {
+		return
+	}
+
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, r.process.SetQuota(ctx, event, id, q))})
+}
+
+// doUserWipe asks to confirm, the data can't be restored.
+func (r *router) doUserWipe(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	id, _, ok := userArgs(event.Text, userWipe)
+	if !ok {
+		r.doUsersHelp(ctx, b, event)
+		return
+	}
+	if !r.outranks(ctx, b, event, id) {
+		return
+	}
+	lang := event.Meta.Language
+	idStr := strconv.FormatInt(id, 10)
+
+	r.sendAnswers(ctx, b, []*entities.Answer{entities.NewAnswer(event, true, &entities.AnswerParams{
+		Message: i18n.T(lang, messages.UserWipeAsk, id),
+		Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{CallbackData: userWipeOkCB + idStr, Text: i18n.T(lang, messages.UserWipe)}},
+		}},
+	})})
+}
+
+func (r *router) doUserWipeConfirm(ctx context.Context, b *bot.Bot, event *entities.Event) {
+	id, _, ok := userArgs(event.Text, userWipeOkCB)
+	if !ok || !r.outranks(ctx, b, event, id) {
+		return
+	}
+
+	r.sendAnswers(ctx, b, []*entities.Answer{sendMessage(event, r.process.WipeUser(ctx, event, id))})
+}
+
+func userCard(event *entities.Event, p *user.Profile) *entities.Answer {
+	lang := event.Meta.Language
+	var username string
+	if p.Username != "" {
+		username = " @" + p.Username
+	}
+
+	b := &strings.Builder{}
+	b.WriteString(i18n.T(lang, messages.UserCard,
+		p.ID, username,
+		p.Notes, p.NotesToday,
+		p.Folders,
+		p.MediaBytes/megabyte,
+		formatTime(p.CreatedAt),
+		formatTime(p.LastSeenAt),
+		formatLimits(p.Limits, &p.Quota),
+	))
+
+	idStr := strconv.FormatInt(p.ID, 10)
+	ban := models.InlineKeyboardButton{CallbackData: userBanCB + idStr, Text: i18n.T(lang, messages.UserBan)}
+	if p.IsBanned() {
+		b.WriteString("\n")
+		b.WriteString(i18n.T(lang, messages.UserCardBan, formatTime(p.BannedAt)))
+		if p.BanReason != "" {
+			b.WriteString(", ")
+			b.WriteString(p.BanReason)
+		}
+		ban = models.InlineKeyboardButton{CallbackData: userUnbanCB + idStr, Text: i18n.T(lang, messages.UserUnban)}
+	}
+
+	return entities.NewAnswer(event, true, &entities.AnswerParams{
+		Message: b.String(),
+		Keyboard: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{
+			{ban, {CallbackData: userWipeCB + idStr, Text: i18n.T(lang, messages.UserWipe)}},
+		}},
+	})
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(userTimestamp)
+}
+
+// userArgs parses the user ID after the command or the callback prefix,
+// the rest is returned as is.
+func userArgs(text string, prefix string) (int64, string, bool) {
+	text = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), prefix))
+	idStr, rest, _ := strings.Cut(text, " ")
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	return id, strings.TrimSpace(rest), err == nil && id > 0
+}
+
+// formatLimits renders the limits, the overridden ones are marked with *.
+func formatLimits(l user.Limits, q *user.Quota) string {
+	mark := func(overridden bool) string {
+		if overridden {
+			return "*"
+		}
+		return ""
+	}
+
+	return "day=" + strconv.Itoa(l.NotesPerDay) + mark(q.NotesPerDay != nil) +
+		" notes=" + strconv.Itoa(l.MaxNotes) + mark(q.MaxNotes != nil) +
+		" folders=" + strconv.Itoa(l.MaxFolders) + mark(q.MaxFolders != nil) +
+		" media=" + strconv.FormatInt(l.MaxMediaBytes/megabyte, 10) + "MB" + mark(q.MaxMediaBytes != nil)
+}
+
+// parseQuota parses "day=N notes=N folders=N media=MB", the omitted limits
+// are not overridden. "default" drops all the overrides.
+func parseQuota(args string) (*user.Quota, bool) {
+	q := &user.Quota{}
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return nil, false
+	}
+	if len(fields) == 1 && fields[0] == "default" {
+		return q, true
+	}
+
+	for _, field := range fields {
+		key, value, _ := strings.Cut(field, "=")
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+
+		switch key {
+		case "day":
+			q.NotesPerDay = &n
+		case "notes":
+			q.MaxNotes = &n
+		case "folders":
+			q.MaxFolders = &n
+		case "media":
+			bytes := int64(n) * megabyte
+			q.MaxMediaBytes = &bytes
+		default:
+			return nil, false
+		}
+	}
+
+	return q, true
+}
@@ -0,0 +1,198 @@
+// Package rule files the incoming notes by the rules of the user.
+package rule
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"archive_bot/internal/entities"
+)
+
+// Kind is what the condition looks at.
+type Kind string
+
+const (
+	KindDomain  Kind = "domain"
+	KindRegex   Kind = "regex"
+	KindType    Kind = "type"
+	KindSource  Kind = "source"
+	KindHashtag Kind = "hashtag"
+)
+
+var (
+	urlHost = regexp.MustCompile(`(?i)https?://([^\s/?#]+)`)
+	hashtag = regexp.MustCompile(`#([\p{L}\p{N}_-]+)`)
+)
+
+// Condition is a single check of the rule. The regular expression of the
+// regex condition is compiled once when the rule is parsed or read.
+type Condition struct {
+	Kind  Kind   `json:"kind"`
+	Value string `json:"value"`
+
+	re *regexp.Regexp
+}
+
+// Rule puts the note matching all the conditions into the folder and adds
+// the tags to it. FolderName is the name of the folder for the rules read
+// from the database.
+type Rule struct {
+	ID         int
+	UserID     int64
+	Position   int
+	Conditions []Condition
+	FolderID   int
+	FolderName string
+	Tags       []string
+}
+
+// Note is what the rules look at. Origin is only known for the notes
+// being saved, the source conditions don't match the stored ones.
+type Note struct {
+	ID       int
+	FolderID int
+	Type     entities.Type
+	Text     string
+	Origin   entities.Origin
+}
+
+// Matches reports whether the note matches all the conditions of the rule.
+func (r *Rule) Matches(n Note) bool {
+	for _, c := range r.Conditions {
+		if !c.matches(n) {
+			return false
+		}
+	}
+	return len(r.Conditions) > 0
+}
+
+func (c Condition) matches(n Note) bool {
+	switch c.Kind {
+	case KindDomain:
+		for _, m := range urlHost.FindAllStringSubmatch(n.Text, -1) {
+			host := normalizeDomain(m[1])
+			if host == c.Value || strings.HasSuffix(host, "."+c.Value) {
+				return true
+			}
+		}
+		return false
+	case KindRegex:
+		return c.re != nil && c.re.MatchString(n.Text)
+	case KindType:
+		return n.Type == entities.ParseType(c.Value)
+	case KindSource:
+		return matchesOrigin(n.Origin, c.Value)
+	case KindHashtag:
+		for _, m := range hashtag.FindAllStringSubmatch(n.Text, -1) {
+			if strings.ToLower(m[1]) == c.Value {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// First returns the first rule matching the note and its number counted
+// from 1, nil if no rule matches.
+func First(rules []*Rule, n Note) (*Rule, int) {
+	for i, r := range rules {
+		if r.Matches(n) {
+			return r, i + 1
+		}
+	}
+	return nil, 0
+}
+
+// Result is what the rule would do with the notes.
+type Result struct {
+	Rule    *Rule
+	Number  int
+	Matched int
+	Moved   []Note
+}
+
+// DryRun applies the rules to the notes without saving anything. A note
+// counts for the first rule it matches, it is moved if the rule puts it
+// into another folder.
+func DryRun(rules []*Rule, notes []Note) []Result {
+	res := make([]Result, len(rules))
+	for i, r := range rules {
+		res[i] = Result{Rule: r, Number: i + 1}
+	}
+
+	for _, n := range notes {
+		r, number := First(rules, n)
+		if r == nil {
+			continue
+		}
+		res[number-1].Matched++
+		if r.FolderID != 0 && r.FolderID != n.FolderID {
+			res[number-1].Moved = append(res[number-1].Moved, n)
+		}
+	}
+
+	return res
+}
+
+// String renders the rule in the syntax of Parse.
+func (r *Rule) String() string {
+	b := &strings.Builder{}
+	for _, c := range r.Conditions {
+		writeToken(b, string(c.Kind), c.Value)
+	}
+	b.WriteString(" " + arrow)
+	if r.FolderName != "" {
+		writeToken(b, actionFolder, r.FolderName)
+	}
+	for _, tag := range r.Tags {
+		writeToken(b, actionTag, tag)
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+func writeToken(b *strings.Builder, key string, value string) {
+	if strings.ContainsAny(value, " \t\n\"") {
+		value = `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+	}
+	b.WriteString(" ")
+	b.WriteString(key)
+	b.WriteString(":")
+	b.WriteString(value)
+}
+
+// compile prepares the regular expression of the regex condition.
+func (c *Condition) compile() error {
+	if c.Kind != KindRegex {
+		return nil
+	}
+	re, err := regexp.Compile(c.Value)
+	if err != nil {
+		return ErrRegex
+	}
+	c.re = re
+	return nil
+}
+
+// matchesOrigin reports whether the source is the ID, the username or the
+// title of the origin.
+func matchesOrigin(o entities.Origin, source string) bool {
+	return (o.ChatID != 0 && strconv.FormatInt(o.ChatID, 10) == source) ||
+		normalizeSource(o.Username) == source ||
+		normalizeSource(o.Title) == source
+}
+
+func normalizeSource(source string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(source), "@"))
+}
+
+func normalizeDomain(host string) string {
+	host = strings.ToLower(host)
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return strings.TrimPrefix(host, "www.")
+}
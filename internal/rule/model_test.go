@@ -0,0 +1,141 @@
+package rule
+
+import (
+	"regexp"
+	"testing"
+
+	"archive_bot/internal/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		title string
+		text  string
+		want  *Rule
+		err   error
+	}{
+		{
+			title: "domain to folder",
+			text:  "domain:https://www.GitHub.com/trending -> folder:Code",
+			want: &Rule{
+				Conditions: []Condition{{Kind: KindDomain, Value: "github.com"}},
+				FolderName: "Code",
+			},
+		},
+		{
+			title: "quoted folder and tags",
+			text:  `type:document source:@GoNews → folder:"Reading list" tag:#go,news tag:go`,
+			want: &Rule{
+				Conditions: []Condition{{Kind: KindType, Value: "doc"}, {Kind: KindSource, Value: "gonews"}},
+				FolderName: "Reading list",
+				Tags:       []string{"go", "news"},
+			},
+		},
+		{
+			title: "regex with spaces",
+			text:  `regex:"(?i)^buy \"milk\"" hashtag:#Todo -> tag:shop`,
+			want: &Rule{
+				Conditions: []Condition{
+					{Kind: KindRegex, Value: `(?i)^buy "milk"`, re: regexp.MustCompile(`(?i)^buy "milk"`)},
+					{Kind: KindHashtag, Value: "todo"},
+				},
+				Tags: []string{"shop"},
+			},
+		},
+		{title: "no arrow", text: "domain:github.com folder:Code", err: ErrSyntax},
+		{title: "no conditions", text: "-> folder:Code", err: ErrSyntax},
+		{title: "no action", text: "domain:github.com ->", err: ErrNoAction},
+		{title: "unknown condition", text: "size:10 -> folder:Code", err: ErrSyntax},
+		{title: "unknown type", text: "type:sticker -> folder:Code", err: ErrType},
+		{title: "bad regex", text: "regex:( -> folder:Code", err: ErrRegex},
+		{title: "bad tag", text: "domain:github.com -> tag:a!b", err: ErrTag},
+		{title: "open quote", text: `domain:github.com -> folder:"Code`, err: ErrSyntax},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			r, err := Parse(tc.text)
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.want, r)
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	t.Parallel()
+	text := `regex:"a \"b\"" type:photo -> folder:"Reading list" tag:go`
+
+	r, err := Parse(text)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, text, r.String())
+}
+
+func TestMatches(t *testing.T) {
+	t.Parallel()
+	forwarded := "Source: @GoNews\n\nRead https://blog.golang.org/go1.23 #Go"
+	channel := entities.Origin{ChatID: -1001234, Username: "GoNews", Title: "Go News"}
+	private := entities.Origin{ChatID: -1005678, Title: "Go Digest"}
+
+	testCases := []struct {
+		title string
+		rule  string
+		note  Note
+		want  bool
+	}{
+		{"subdomain", "domain:golang.org -> tag:go", Note{Text: forwarded}, true},
+		{"other domain", "domain:go.dev -> tag:go", Note{Text: forwarded}, false},
+		{"domain in the text only", "domain:golang.org -> tag:go", Note{Text: "golang.org"}, false},
+		{"regex", `regex:go1\.\d+ -> tag:go`, Note{Text: forwarded}, true},
+		{"type", "type:photo -> tag:go", Note{Type: entities.Photo}, true},
+		{"other type", "type:photo -> tag:go", Note{Type: entities.Message}, false},
+		{"source", "source:@gonews -> tag:go", Note{Origin: channel}, true},
+		{"source title", `source:"go digest" -> tag:go`, Note{Origin: private}, true},
+		{"source id", "source:-1005678 -> tag:go", Note{Origin: private}, true},
+		{"other source", "source:gonews -> tag:go", Note{Origin: private}, false},
+		{"source in the text", "source:gonews -> tag:go", Note{Text: forwarded}, false},
+		{"hashtag", "hashtag:go -> tag:go", Note{Text: forwarded}, true},
+		{"all conditions", "hashtag:go type:photo -> tag:go", Note{Text: forwarded}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			r, err := Parse(tc.rule)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tc.want, r.Matches(tc.note))
+		})
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	t.Parallel()
+	rules := []*Rule{
+		{Conditions: []Condition{{Kind: KindHashtag, Value: "go"}}, FolderID: 2},
+		{Conditions: []Condition{{Kind: KindType, Value: "photo"}}, FolderID: 3},
+		{Conditions: []Condition{{Kind: KindType, Value: "message"}}, Tags: []string{"misc"}},
+	}
+	notes := []Note{
+		{ID: 1, FolderID: 1, Type: entities.Message, Text: "#go"},
+		{ID: 2, FolderID: 2, Type: entities.Photo, Text: "#go"},
+		{ID: 3, FolderID: 1, Type: entities.Photo},
+		{ID: 4, FolderID: 1, Type: entities.Message},
+		{ID: 5, FolderID: 1, Type: entities.Voice},
+	}
+
+	res := DryRun(rules, notes)
+	if !assert.Len(t, res, 3) {
+		return
+	}
+	assert.Equal(t, 2, res[0].Matched)
+	assert.Equal(t, []Note{notes[0]}, res[0].Moved)
+	assert.Equal(t, 1, res[1].Matched)
+	assert.Equal(t, []Note{notes[2]}, res[1].Moved)
+	assert.Equal(t, 1, res[2].Matched)
+	assert.Empty(t, res[2].Moved)
+}
@@ -0,0 +1,187 @@
+package rule
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"archive_bot/internal/entities"
+	"archive_bot/internal/notes/texts"
+	"archive_bot/pkg/er"
+)
+
+const (
+	arrow          string = "->"
+	arrowAlias     string = "→"
+	actionFolder   string = "folder"
+	actionTag      string = "tag"
+	maxConditions  int    = 5
+	maxTags        int    = 10
+	maxRegexLength int    = 256
+)
+
+var (
+	ErrSyntax   = er.New("the rule can't be parsed", "", nil)
+	ErrType     = er.New("the note type is unknown", "", nil)
+	ErrRegex    = er.New("the regular expression is invalid", "", nil)
+	ErrTag      = er.New("the tag is invalid", "", nil)
+	ErrNoAction = er.New("the rule has no action", "", nil)
+)
+
+// Parse reads the rule "<conditions> -> <actions>" where the conditions
+// are domain:, regex:, type:, source: and hashtag: and the actions are
+// folder: and tag:. The values with spaces are quoted, \" is the quote in
+// them. The folder of the rule is only named, it is resolved by the caller.
+func Parse(text string) (*Rule, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Rule{}
+	actions := false
+	for _, token := range tokens {
+		if token == arrow || token == arrowAlias {
+			if actions {
+				return nil, ErrSyntax
+			}
+			actions = true
+			continue
+		}
+
+		key, value, ok := strings.Cut(token, ":")
+		if !ok || value == "" {
+			return nil, ErrSyntax
+		}
+		if strings.HasPrefix(value, `"`) {
+			if len(value) < 2 || !strings.HasSuffix(value, `"`) {
+				return nil, ErrSyntax
+			}
+			value = strings.ReplaceAll(value[1:len(value)-1], `\"`, `"`)
+		}
+
+		if actions {
+			err = r.addAction(key, value)
+		} else {
+			err = r.addCondition(Kind(strings.ToLower(key)), value)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case !actions || len(r.Conditions) == 0 || len(r.Conditions) > maxConditions:
+		return nil, ErrSyntax
+	case r.FolderName == "" && len(r.Tags) == 0:
+		return nil, ErrNoAction
+	}
+
+	return r, nil
+}
+
+func (r *Rule) addCondition(kind Kind, value string) error {
+	switch kind {
+	case KindDomain:
+		value = strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(value), "http://"), "https://")
+		value, _, _ = strings.Cut(value, "/")
+		value = normalizeDomain(value)
+	case KindRegex:
+		if len(value) > maxRegexLength {
+			return ErrRegex
+		}
+	case KindType:
+		value = strings.ToLower(value)
+		if value == "document" {
+			value = entities.Document.String()
+		}
+		if entities.ParseType(value) == entities.Unknown {
+			return ErrType
+		}
+	case KindSource:
+		value = normalizeSource(value)
+	case KindHashtag:
+		tags, ok := texts.ParseTags(value)
+		if !ok || len(tags) != 1 {
+			return ErrTag
+		}
+		value = tags[0]
+	default:
+		return ErrSyntax
+	}
+	if value == "" {
+		return ErrSyntax
+	}
+
+	c := Condition{Kind: kind, Value: value}
+	if err := c.compile(); err != nil {
+		return err
+	}
+	r.Conditions = append(r.Conditions, c)
+	return nil
+}
+
+func (r *Rule) addAction(key string, value string) error {
+	switch strings.ToLower(key) {
+	case actionFolder:
+		if r.FolderName != "" {
+			return ErrSyntax
+		}
+		r.FolderName = strings.TrimSpace(value)
+	case actionTag:
+		tags, ok := texts.ParseTags(value)
+		if !ok {
+			return ErrTag
+		}
+		for _, tag := range tags {
+			if !slices.Contains(r.Tags, tag) {
+				r.Tags = append(r.Tags, tag)
+			}
+		}
+		if len(r.Tags) > maxTags {
+			return ErrTag
+		}
+	default:
+		return ErrSyntax
+	}
+
+	return nil
+}
+
+// tokenize splits the text by the spaces out of the quotes, the quotes are
+// kept in the tokens.
+func tokenize(text string) ([]string, error) {
+	var (
+		tokens  []string
+		b       strings.Builder
+		quoted  bool
+		escaped bool
+	)
+	flush := func() {
+		if b.Len() > 0 {
+			tokens = append(tokens, b.String())
+			b.Reset()
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && unicode.IsSpace(r):
+			flush()
+			continue
+		}
+		b.WriteRune(r)
+	}
+	if quoted {
+		return nil, ErrSyntax
+	}
+	flush()
+
+	return tokens, nil
+}
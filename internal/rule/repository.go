@@ -0,0 +1,137 @@
+package rule
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrNoRule = er.New("the rule does not exist", "", nil)
+
+var (
+	instance *pgRepository
+	once     sync.Once
+)
+
+type pgRepository struct {
+	log *logger.Logger
+	db  *pgxpool.Pool
+}
+
+// NewRepository creates new rule repository.
+func NewRepository(ctx context.Context, log *logger.Logger, db *pgxpool.Pool) (*pgRepository, error) {
+	once.Do(func() {
+		instance = &pgRepository{log: log, db: db}
+	})
+
+	return instance, nil
+}
+
+// Save adds the rule at the end of the rules of the user.
+func (repo *pgRepository) Save(ctx context.Context, r *Rule) (int, error) {
+	const op string = "rule.repository.Save"
+
+	conditions, err := json.Marshal(r.Conditions)
+	if err != nil {
+		return 0, er.New("unable to encode conditions", op, err)
+	}
+
+	var folderID *int
+	if r.FolderID != 0 {
+		folderID = &r.FolderID
+	}
+	tags := r.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	var id int
+	if err := repo.db.QueryRow(ctx,
+		`INSERT INTO rules (user_id, position, conditions, folder_id, tags)
+		VALUES ($1, (SELECT COALESCE(MAX(position), 0) + 1 FROM rules WHERE user_id = $1), $2, $3, $4)
+		RETURNING id;`,
+		r.UserID, conditions, folderID, tags).Scan(&id); err != nil {
+		return 0, er.New("unable to save rule", op, err)
+	}
+
+	return id, nil
+}
+
+// All returns the rules of the user in their order with the names of
+// their folders.
+func (repo *pgRepository) All(ctx context.Context, userID int64) ([]*Rule, error) {
+	const op string = "rule.repository.All"
+
+	rows, err := repo.db.Query(ctx,
+		`SELECT r.id, r.position, r.conditions, COALESCE(r.folder_id, 0), COALESCE(f.name, ''), r.tags
+		FROM rules r
+		LEFT JOIN folders f ON f.id = r.folder_id
+		WHERE r.user_id = $1
+		ORDER BY r.position, r.id;`,
+		userID)
+	if err != nil {
+		return nil, er.New("unable to get rules", op, err)
+	}
+	defer rows.Close()
+
+	rules := []*Rule{}
+	for rows.Next() {
+		r := Rule{UserID: userID}
+		var conditions []byte
+		if err := rows.Scan(&r.ID, &r.Position, &conditions, &r.FolderID, &r.FolderName, &r.Tags); err != nil {
+			return nil, er.New("unable to scan data", op, err)
+		}
+		if err := json.Unmarshal(conditions, &r.Conditions); err != nil {
+			return nil, er.New("unable to decode conditions", op, err)
+		}
+		for i := range r.Conditions {
+			if err := r.Conditions[i].compile(); err != nil {
+				return nil, er.New("unable to compile conditions", op, err)
+			}
+		}
+		rules = append(rules, &r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, er.New("error in rows", op, err)
+	}
+
+	return rules, nil
+}
+
+// Remove removes the rule of the user.
+func (repo *pgRepository) Remove(ctx context.Context, userID int64, id int) error {
+	const op string = "rule.repository.Remove"
+
+	tag, err := repo.db.Exec(ctx,
+		`DELETE FROM rules WHERE id = $1 AND user_id = $2;`,
+		id, userID)
+	if err != nil {
+		return er.New("the rule could not be removed", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoRule
+	}
+
+	return nil
+}
+
+// Reorder numbers the rules of the user in the order of the IDs.
+func (repo *pgRepository) Reorder(ctx context.Context, userID int64, ids []int) error {
+	const op string = "rule.repository.Reorder"
+
+	if _, err := repo.db.Exec(ctx,
+		`UPDATE rules r SET position = o.position
+		FROM unnest($2::int[]) WITH ORDINALITY AS o(id, position)
+		WHERE r.id = o.id AND r.user_id = $1;`,
+		userID, ids); err != nil {
+		return er.New("unable to reorder rules", op, err)
+	}
+
+	return nil
+}
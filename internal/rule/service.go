@@ -0,0 +1,167 @@
+package rule
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+const maxRules int = 50
+
+// rulesTTL is how long the rules are cached, so a change the cache is not
+// told about is picked up within it.
+const rulesTTL time.Duration = 5 * time.Minute
+
+var ErrTooManyRules = er.New("the rules limit is reached", "", nil)
+
+type Repository interface {
+	Save(ctx context.Context, r *Rule) (int, error)
+	All(ctx context.Context, userID int64) ([]*Rule, error)
+	Remove(ctx context.Context, userID int64, id int) error
+	Reorder(ctx context.Context, userID int64, ids []int) error
+}
+
+type service struct {
+	log  *logger.Logger
+	repo Repository
+
+	mu     sync.Mutex
+	cached map[int64]cachedRules
+}
+
+type cachedRules struct {
+	rules []*Rule
+	until time.Time
+}
+
+func NewService(ctx context.Context, log *logger.Logger, repo Repository) *service {
+	return &service{log: log, repo: repo, cached: make(map[int64]cachedRules)}
+}
+
+// All returns the rules of the user in the order they are evaluated.
+func (s *service) All(ctx context.Context, userID int64) ([]*Rule, error) {
+	ctx, span := tracing.Start(ctx, "rule.service.All")
+	defer span.End()
+
+	return s.repo.All(ctx, userID)
+}
+
+// Add puts the rule after the rules of the user and returns its number.
+func (s *service) Add(ctx context.Context, r *Rule) (int, error) {
+	ctx, span := tracing.Start(ctx, "rule.service.Add")
+	defer span.End()
+
+	rules, err := s.repo.All(ctx, r.UserID)
+	if err != nil {
+		return 0, err
+	}
+	if len(rules) >= maxRules {
+		return 0, ErrTooManyRules
+	}
+
+	if r.ID, err = s.repo.Save(ctx, r); err != nil {
+		return 0, err
+	}
+	s.Forget(r.UserID)
+
+	return len(rules) + 1, nil
+}
+
+// Remove removes the rule by its number counted from 1.
+func (s *service) Remove(ctx context.Context, userID int64, number int) error {
+	ctx, span := tracing.Start(ctx, "rule.service.Remove")
+	defer span.End()
+
+	rules, err := s.repo.All(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if number < 1 || number > len(rules) {
+		return ErrNoRule
+	}
+
+	defer s.Forget(userID)
+	return s.repo.Remove(ctx, userID, rules[number-1].ID)
+}
+
+// Move puts the rule with the number at the position, the positions out
+// of the list put it first or last.
+func (s *service) Move(ctx context.Context, userID int64, number int, position int) error {
+	ctx, span := tracing.Start(ctx, "rule.service.Move")
+	defer span.End()
+
+	rules, err := s.repo.All(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if number < 1 || number > len(rules) {
+		return ErrNoRule
+	}
+
+	ids := make([]int, 0, len(rules))
+	for _, r := range rules {
+		ids = append(ids, r.ID)
+	}
+	id := ids[number-1]
+	ids = slices.Delete(ids, number-1, number)
+	ids = slices.Insert(ids, min(max(position-1, 0), len(ids)), id)
+
+	defer s.Forget(userID)
+	return s.repo.Reorder(ctx, userID, ids)
+}
+
+// Match returns the first rule of the user matching the note and its
+// number, nil if none matches or the rules can't be read. The rules are
+// cached for rulesTTL or until they are changed.
+func (s *service) Match(ctx context.Context, userID int64, n Note) (*Rule, int) {
+	ctx, span := tracing.Start(ctx, "rule.service.Match")
+	defer span.End()
+
+	rules, ok := s.cachedRules(userID, time.Now())
+	if !ok {
+		var err error
+		if rules, err = s.repo.All(ctx, userID); err != nil {
+			logger.L(ctx).Error("failed to get rules", logger.ErrAttr(err))
+			return nil, 0
+		}
+		s.cacheRules(userID, rules, time.Now())
+	}
+
+	return First(rules, n)
+}
+
+// Forget drops the cached rules of the user, it is called when the rules
+// or their folders change.
+func (s *service) Forget(userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.cached, userID)
+}
+
+// cachedRules returns the unexpired cached rules of the user, the expired
+// ones are dropped.
+func (s *service) cachedRules(userID int64, now time.Time) ([]*Rule, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.cached[userID]
+	if ok && !now.Before(c.until) {
+		delete(s.cached, userID)
+		return nil, false
+	}
+
+	return c.rules, ok
+}
+
+func (s *service) cacheRules(userID int64, rules []*Rule, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cached[userID] = cachedRules{rules: rules, until: now.Add(rulesTTL)}
+}
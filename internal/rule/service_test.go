@@ -0,0 +1,32 @@
+package rule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedRules(t *testing.T) {
+	t.Parallel()
+	s := &service{cached: make(map[int64]cachedRules)}
+	rules := []*Rule{{ID: 1}}
+	now := time.Now()
+
+	_, ok := s.cachedRules(1, now)
+	assert.False(t, ok)
+
+	s.cacheRules(1, rules, now)
+	cached, ok := s.cachedRules(1, now.Add(rulesTTL-time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, rules, cached)
+
+	s.Forget(1)
+	_, ok = s.cachedRules(1, now)
+	assert.False(t, ok)
+
+	s.cacheRules(1, rules, now)
+	_, ok = s.cachedRules(1, now.Add(rulesTTL))
+	assert.False(t, ok)
+	assert.Empty(t, s.cached)
+}
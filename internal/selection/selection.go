@@ -0,0 +1,143 @@
+// Package selection keeps the notes of a folder picked for a bulk action.
+package selection
+
+import (
+	"slices"
+	"strings"
+
+	"archive_bot/internal/entities"
+)
+
+const (
+	PageSize      int = 8
+	previewLength int = 24
+)
+
+// Awaiting is the input the selection waits for.
+type Awaiting int
+
+const (
+	AwaitNothing Awaiting = iota
+	AwaitTags
+	AwaitFolder
+	AwaitRemove
+)
+
+// Item is a note of the folder.
+type Item struct {
+	ID      int
+	Type    entities.Type
+	Preview string
+}
+
+// Selection is the notes of the folder and the picked ones.
+type Selection struct {
+	FolderID int
+	Items    []Item
+	Page     int
+	Awaiting Awaiting
+
+	selected map[int]bool
+}
+
+func New(folderID int, items []Item) *Selection {
+	return &Selection{FolderID: folderID, Items: items, selected: make(map[int]bool)}
+}
+
+// NewItem makes the item of the note with the first line of the text as
+// the preview.
+func NewItem(id int, t entities.Type, text string) Item {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	if runes := []rune(line); len(runes) > previewLength {
+		line = string(runes[:previewLength-1]) + "…"
+	}
+
+	return Item{ID: id, Type: t, Preview: line}
+}
+
+// Toggle picks the note or drops it, the notes of other folders are ignored.
+func (s *Selection) Toggle(id int) {
+	if !slices.ContainsFunc(s.Items, func(it Item) bool { return it.ID == id }) {
+		return
+	}
+	s.set(id, !s.selected[id])
+}
+
+// ToggleAll picks all the notes, or drops them if all are picked.
+func (s *Selection) ToggleAll() {
+	s.toggleWhere(func(Item) bool { return true })
+}
+
+// ToggleType picks all the notes of the type, or drops them if all of them
+// are picked.
+func (s *Selection) ToggleType(t entities.Type) {
+	s.toggleWhere(func(it Item) bool { return it.Type == t })
+}
+
+func (s *Selection) IsSelected(id int) bool {
+	return s.selected[id]
+}
+
+// IDs returns the picked notes in the order of the items.
+func (s *Selection) IDs() []int {
+	ids := make([]int, 0, len(s.selected))
+	for _, it := range s.Items {
+		if s.selected[it.ID] {
+			ids = append(ids, it.ID)
+		}
+	}
+
+	return ids
+}
+
+// Types returns the types of the notes in the order they first appear.
+func (s *Selection) Types() []entities.Type {
+	types := []entities.Type{}
+	for _, it := range s.Items {
+		if !slices.Contains(types, it.Type) {
+			types = append(types, it.Type)
+		}
+	}
+
+	return types
+}
+
+func (s *Selection) Pages() int {
+	return max((len(s.Items)+PageSize-1)/PageSize, 1)
+}
+
+// SetPage moves to the page, the pages out of range are clamped.
+func (s *Selection) SetPage(page int) {
+	s.Page = min(max(page, 0), s.Pages()-1)
+}
+
+// PageItems returns the items of the current page.
+func (s *Selection) PageItems() []Item {
+	from := min(s.Page*PageSize, len(s.Items))
+	to := min(from+PageSize, len(s.Items))
+
+	return s.Items[from:to]
+}
+
+func (s *Selection) toggleWhere(match func(Item) bool) {
+	all := true
+	for _, it := range s.Items {
+		if match(it) && !s.selected[it.ID] {
+			all = false
+			break
+		}
+	}
+	for _, it := range s.Items {
+		if match(it) {
+			s.set(it.ID, !all)
+		}
+	}
+}
+
+func (s *Selection) set(id int, selected bool) {
+	if selected {
+		s.selected[id] = true
+		return
+	}
+	delete(s.selected, id)
+}
@@ -0,0 +1,87 @@
+package selection
+
+import (
+	"testing"
+
+	"archive_bot/internal/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func items() []Item {
+	return []Item{
+		{ID: 1, Type: entities.Message},
+		{ID: 2, Type: entities.Photo},
+		{ID: 3, Type: entities.Message},
+		{ID: 4, Type: entities.Document},
+	}
+}
+
+func TestToggle(t *testing.T) {
+	t.Parallel()
+	s := New(1, items())
+
+	s.Toggle(3)
+	s.Toggle(1)
+	s.Toggle(9)
+	assert.Equal(t, []int{1, 3}, s.IDs())
+
+	s.Toggle(3)
+	assert.Equal(t, []int{1}, s.IDs())
+	assert.False(t, s.IsSelected(3))
+}
+
+func TestToggleAll(t *testing.T) {
+	t.Parallel()
+	s := New(1, items())
+
+	s.Toggle(2)
+	s.ToggleAll()
+	assert.Equal(t, []int{1, 2, 3, 4}, s.IDs())
+
+	s.ToggleAll()
+	assert.Empty(t, s.IDs())
+}
+
+func TestToggleType(t *testing.T) {
+	t.Parallel()
+	s := New(1, items())
+
+	s.ToggleType(entities.Message)
+	assert.Equal(t, []int{1, 3}, s.IDs())
+
+	s.ToggleType(entities.Photo)
+	assert.Equal(t, []int{1, 2, 3}, s.IDs())
+
+	s.ToggleType(entities.Message)
+	assert.Equal(t, []int{2}, s.IDs())
+
+	assert.Equal(t, []entities.Type{entities.Message, entities.Photo, entities.Document}, s.Types())
+}
+
+func TestPages(t *testing.T) {
+	t.Parallel()
+	all := make([]Item, 0, PageSize+3)
+	for i := range PageSize + 3 {
+		all = append(all, Item{ID: i + 1})
+	}
+	s := New(1, all)
+
+	assert.Equal(t, 2, s.Pages())
+	assert.Len(t, s.PageItems(), PageSize)
+
+	s.SetPage(5)
+	assert.Equal(t, 1, s.Page)
+	assert.Len(t, s.PageItems(), 3)
+
+	s.SetPage(-1)
+	assert.Equal(t, 0, s.Page)
+	assert.Equal(t, 1, New(1, nil).Pages())
+}
+
+func TestNewItem(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "first line", NewItem(1, entities.Message, "  first line\nsecond").Preview)
+	assert.Equal(t, "abcdefghijklmnopqrstuvw…", NewItem(1, entities.Message, "abcdefghijklmnopqrstuvwxyz").Preview)
+	assert.Equal(t, "", NewItem(1, entities.Photo, "").Preview)
+}
@@ -0,0 +1,361 @@
+package sender
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"archive_bot/internal/entities"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// envelope is a single Bot API call stored in the queue. The interface
+// fields of the params can't be decoded by encoding/json, so they are
+// stored separately and put back on decode. The uploaded files are kept
+// in memory under the Upload key, they don't survive the restart.
+type envelope struct {
+	Method      string             `json:"method"`
+	UserID      int64              `json:"user_id"`
+	ChatID      int64              `json:"chat_id"`
+	FromChatID  int64              `json:"from_chat_id,omitempty"`
+	DeleteAfter bool               `json:"delete_after"`
+	Attempts    int                `json:"attempts"`
+	Params      json.RawMessage    `json:"params"`
+	Markups     map[string]markup  `json:"markups,omitempty"`
+	Files       map[string]string  `json:"files,omitempty"`
+	Media       map[string][]media `json:"media,omitempty"`
+	Upload      string             `json:"upload,omitempty"`
+
+	uploads map[string]upload
+}
+
+type upload struct {
+	Filename string
+	Data     []byte
+}
+
+type markup struct {
+	Kind  string          `json:"kind"`
+	Value json.RawMessage `json:"value"`
+}
+
+type media struct {
+	Type      string           `json:"type"`
+	Media     string           `json:"media"`
+	Caption   string           `json:"caption,omitempty"`
+	ParseMode models.ParseMode `json:"parse_mode,omitempty"`
+}
+
+const (
+	markupInline     string = "inline"
+	markupReply      string = "reply"
+	markupRemove     string = "remove"
+	markupForceReply string = "force_reply"
+
+	mediaPhoto     string = "photo"
+	mediaDocument  string = "document"
+	mediaVideo     string = "video"
+	mediaAudio     string = "audio"
+	mediaAnimation string = "animation"
+
+	// callbackMethod is answered immediately and never queued.
+	callbackMethod  string = "AnswerCallbackQuery"
+	chatIDField     string = "ChatID"
+	fromChatIDField string = "FromChatID"
+)
+
+var (
+	replyMarkupType = reflect.TypeOf((*models.ReplyMarkup)(nil)).Elem()
+	inputFileType   = reflect.TypeOf((*models.InputFile)(nil)).Elem()
+	inputMediaType  = reflect.TypeOf((*models.InputMedia)(nil)).Elem()
+	mediaSliceType  = reflect.TypeOf([]models.InputMedia(nil))
+
+	// methods maps the fields of entities.Answer to the types of their params.
+	methods = answerMethods()
+
+	// order is the order the calls of one answer are sent in:
+	// the previous messages are removed first and the media goes before
+	// the text that didn't fit into the caption.
+	order = []string{
+		"DeleteMessages",
+		"SendMediaGroup",
+		"SendPhoto",
+		"SendDocument",
+		"SendVideo",
+		"SendAudio",
+		"SendAnimation",
+		"SendVoice",
+		"CopyMessage",
+		"SendMessage",
+		"EditMessageText",
+		"EditMessageCaption",
+		"EditMessageMedia",
+		"EditMessageReplyMarkup",
+	}
+)
+
+func answerMethods() map[string]reflect.Type {
+	t := reflect.TypeOf(entities.Answer{})
+
+	res := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type.Kind() == reflect.Pointer && f.Name != callbackMethod {
+			res[f.Name] = f.Type.Elem()
+		}
+	}
+
+	return res
+}
+
+// split returns the queued calls of the answer.
+func split(ans *entities.Answer) ([]*envelope, error) {
+	v := reflect.ValueOf(ans).Elem()
+
+	res := make([]*envelope, 0, 1)
+	for _, name := range order {
+		field := v.FieldByName(name)
+		if field.IsNil() {
+			continue
+		}
+
+		env, err := encode(name, field.Interface())
+		if err != nil {
+			return nil, err
+		}
+		env.UserID = ans.UserID
+		env.DeleteAfter = ans.DeleteAfter
+
+		res = append(res, env)
+	}
+
+	return res, nil
+}
+
+func encode(method string, params any) (*envelope, error) {
+	env := &envelope{Method: method}
+
+	src := reflect.ValueOf(params).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	dst.Set(src)
+
+	for i := 0; i < dst.NumField(); i++ {
+		field, name := dst.Field(i), dst.Type().Field(i).Name
+
+		switch {
+		case name == chatIDField:
+			chatID, ok := field.Interface().(int64)
+			if !ok {
+				return nil, fmt.Errorf("%s: chat id must be int64, got %T", method, field.Interface())
+			}
+			env.ChatID = chatID
+		case name == fromChatIDField:
+			chatID, ok := field.Interface().(int64)
+			if !ok {
+				return nil, fmt.Errorf("%s: from chat id must be int64, got %T", method, field.Interface())
+			}
+			env.FromChatID = chatID
+		case field.Type() == replyMarkupType && !field.IsNil():
+			m, err := encodeMarkup(field.Interface())
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", method, err)
+			}
+			setMap(&env.Markups, name, m)
+		case field.Type() == inputFileType && !field.IsNil():
+			switch file := field.Interface().(type) {
+			case *models.InputFileString:
+				setMap(&env.Files, name, file.Data)
+			case *models.InputFileUpload:
+				var data []byte
+				if file.Data != nil {
+					var err error
+					if data, err = io.ReadAll(file.Data); err != nil {
+						return nil, fmt.Errorf("%s: %w", method, err)
+					}
+				}
+				setMap(&env.uploads, name, upload{Filename: file.Filename, Data: data})
+			default:
+				return nil, fmt.Errorf("%s: unsupported input file %T", method, file)
+			}
+		case field.Type() == inputMediaType && !field.IsNil():
+			m, err := encodeMedia(field.Interface().(models.InputMedia))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", method, err)
+			}
+			setMap(&env.Media, name, []media{m})
+		case field.Type() == mediaSliceType && !field.IsNil():
+			items := field.Interface().([]models.InputMedia)
+			list := make([]media, 0, len(items))
+			for _, item := range items {
+				m, err := encodeMedia(item)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", method, err)
+				}
+				list = append(list, m)
+			}
+			setMap(&env.Media, name, list)
+		default:
+			continue
+		}
+
+		field.Set(reflect.Zero(field.Type()))
+	}
+
+	raw, err := json.Marshal(dst.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	env.Params = raw
+
+	return env, nil
+}
+
+// decode returns the params of the call, a pointer to one of the bot.*Params.
+func decode(env *envelope) (any, error) {
+	t, ok := methods[env.Method]
+	if !ok {
+		return nil, fmt.Errorf("unknown method %q", env.Method)
+	}
+
+	ptr := reflect.New(t)
+	if err := json.Unmarshal(env.Params, ptr.Interface()); err != nil {
+		return nil, fmt.Errorf("%s: %w", env.Method, err)
+	}
+
+	v := ptr.Elem()
+	if f := v.FieldByName(chatIDField); f.IsValid() {
+		f.Set(reflect.ValueOf(env.ChatID))
+	}
+	if f := v.FieldByName(fromChatIDField); f.IsValid() {
+		f.Set(reflect.ValueOf(env.FromChatID))
+	}
+
+	for name, m := range env.Markups {
+		val, err := decodeMarkup(m)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", env.Method, err)
+		}
+		v.FieldByName(name).Set(reflect.ValueOf(val))
+	}
+
+	for name, data := range env.Files {
+		v.FieldByName(name).Set(reflect.ValueOf(&models.InputFileString{Data: data}))
+	}
+
+	for name, u := range env.uploads {
+		v.FieldByName(name).Set(reflect.ValueOf(&models.InputFileUpload{
+			Filename: u.Filename,
+			Data:     bytes.NewReader(u.Data),
+		}))
+	}
+
+	for name, list := range env.Media {
+		f := v.FieldByName(name)
+		items := make([]models.InputMedia, 0, len(list))
+		for _, m := range list {
+			item, err := decodeMedia(m)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", env.Method, err)
+			}
+			items = append(items, item)
+		}
+
+		switch {
+		case f.Type() == mediaSliceType:
+			f.Set(reflect.ValueOf(items))
+		case len(items) > 0:
+			f.Set(reflect.ValueOf(&items[0]).Elem())
+		}
+	}
+
+	return ptr.Interface(), nil
+}
+
+func encodeMarkup(val any) (markup, error) {
+	var kind string
+	switch val.(type) {
+	case *models.InlineKeyboardMarkup:
+		kind = markupInline
+	case *models.ReplyKeyboardMarkup:
+		kind = markupReply
+	case *models.ReplyKeyboardRemove:
+		kind = markupRemove
+	case *models.ForceReply:
+		kind = markupForceReply
+	default:
+		return markup{}, fmt.Errorf("unsupported reply markup %T", val)
+	}
+
+	raw, err := json.Marshal(val)
+	if err != nil {
+		return markup{}, err
+	}
+
+	return markup{Kind: kind, Value: raw}, nil
+}
+
+func decodeMarkup(m markup) (any, error) {
+	var val any
+	switch m.Kind {
+	case markupInline:
+		val = &models.InlineKeyboardMarkup{}
+	case markupReply:
+		val = &models.ReplyKeyboardMarkup{}
+	case markupRemove:
+		val = &models.ReplyKeyboardRemove{}
+	case markupForceReply:
+		val = &models.ForceReply{}
+	default:
+		return nil, fmt.Errorf("unsupported reply markup %q", m.Kind)
+	}
+
+	if err := json.Unmarshal(m.Value, val); err != nil {
+		return nil, err
+	}
+
+	return val, nil
+}
+
+func encodeMedia(item models.InputMedia) (media, error) {
+	switch m := item.(type) {
+	case *models.InputMediaPhoto:
+		return media{Type: mediaPhoto, Media: m.Media, Caption: m.Caption, ParseMode: m.ParseMode}, nil
+	case *models.InputMediaDocument:
+		return media{Type: mediaDocument, Media: m.Media, Caption: m.Caption, ParseMode: m.ParseMode}, nil
+	case *models.InputMediaVideo:
+		return media{Type: mediaVideo, Media: m.Media, Caption: m.Caption, ParseMode: m.ParseMode}, nil
+	case *models.InputMediaAudio:
+		return media{Type: mediaAudio, Media: m.Media, Caption: m.Caption, ParseMode: m.ParseMode}, nil
+	case *models.InputMediaAnimation:
+		return media{Type: mediaAnimation, Media: m.Media, Caption: m.Caption, ParseMode: m.ParseMode}, nil
+	default:
+		return media{}, fmt.Errorf("unsupported input media %T", item)
+	}
+}
+
+func decodeMedia(m media) (models.InputMedia, error) {
+	switch m.Type {
+	case mediaPhoto:
+		return &models.InputMediaPhoto{Media: m.Media, Caption: m.Caption, ParseMode: m.ParseMode}, nil
+	case mediaDocument:
+		return &models.InputMediaDocument{Media: m.Media, Caption: m.Caption, ParseMode: m.ParseMode}, nil
+	case mediaVideo:
+		return &models.InputMediaVideo{Media: m.Media, Caption: m.Caption, ParseMode: m.ParseMode}, nil
+	case mediaAudio:
+		return &models.InputMediaAudio{Media: m.Media, Caption: m.Caption, ParseMode: m.ParseMode}, nil
+	case mediaAnimation:
+		return &models.InputMediaAnimation{Media: m.Media, Caption: m.Caption, ParseMode: m.ParseMode}, nil
+	default:
+		return nil, fmt.Errorf("unsupported input media %q", m.Type)
+	}
+}
+
+func setMap[V any](m *map[string]V, key string, val V) {
+	if *m == nil {
+		*m = make(map[string]V)
+	}
+	(*m)[key] = val
+}
@@ -0,0 +1,210 @@
+package sender
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"archive_bot/internal/entities"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	keyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{{{Text: "move", CallbackData: "btn_move:1:2"}}},
+	}
+
+	tests := []struct {
+		name   string
+		answer *entities.Answer
+		want   []any
+	}{
+		{
+			name: "message with inline keyboard",
+			answer: &entities.Answer{
+				UserID:      1,
+				DeleteAfter: true,
+				SendMessage: &bot.SendMessageParams{ChatID: int64(10), Text: "note", ReplyMarkup: keyboard},
+			},
+			want: []any{
+				&bot.SendMessageParams{ChatID: int64(10), Text: "note", ReplyMarkup: keyboard},
+			},
+		},
+		{
+			name: "photo with reply keyboard",
+			answer: &entities.Answer{
+				SendPhoto: &bot.SendPhotoParams{
+					ChatID:  int64(-100123),
+					Photo:   &models.InputFileString{Data: "file-id"},
+					Caption: "caption",
+					ReplyMarkup: &models.ReplyKeyboardMarkup{
+						Keyboard:       [][]models.KeyboardButton{{{Text: "folders"}}},
+						ResizeKeyboard: true,
+					},
+				},
+			},
+			want: []any{
+				&bot.SendPhotoParams{
+					ChatID:  int64(-100123),
+					Photo:   &models.InputFileString{Data: "file-id"},
+					Caption: "caption",
+					ReplyMarkup: &models.ReplyKeyboardMarkup{
+						Keyboard:       [][]models.KeyboardButton{{{Text: "folders"}}},
+						ResizeKeyboard: true,
+					},
+				},
+			},
+		},
+		{
+			name: "media group before message, callback is skipped",
+			answer: &entities.Answer{
+				SendMessage: &bot.SendMessageParams{ChatID: int64(10), Text: "long text"},
+				SendMediaGroup: &bot.SendMediaGroupParams{
+					ChatID: int64(10),
+					Media: []models.InputMedia{
+						&models.InputMediaPhoto{Media: "p1"},
+						&models.InputMediaVideo{Media: "v1", Caption: "c"},
+					},
+				},
+				AnswerCallbackQuery: &bot.AnswerCallbackQueryParams{CallbackQueryID: "q"},
+			},
+			want: []any{
+				&bot.SendMediaGroupParams{
+					ChatID: int64(10),
+					Media: []models.InputMedia{
+						&models.InputMediaPhoto{Media: "p1"},
+						&models.InputMediaVideo{Media: "v1", Caption: "c"},
+					},
+				},
+				&bot.SendMessageParams{ChatID: int64(10), Text: "long text"},
+			},
+		},
+		{
+			name: "edit media",
+			answer: &entities.Answer{
+				EditMessageMedia: &bot.EditMessageMediaParams{
+					ChatID:    int64(10),
+					MessageID: 5,
+					Media:     &models.InputMediaDocument{Media: "d1"},
+				},
+			},
+			want: []any{
+				&bot.EditMessageMediaParams{
+					ChatID:    int64(10),
+					MessageID: 5,
+					Media:     &models.InputMediaDocument{Media: "d1"},
+				},
+			},
+		},
+		{
+			name: "broadcast preview",
+			answer: &entities.Answer{
+				CopyMessage: &bot.CopyMessageParams{
+					ChatID:      int64(10),
+					FromChatID:  int64(10),
+					MessageID:   7,
+					ReplyMarkup: keyboard,
+				},
+			},
+			want: []any{
+				&bot.CopyMessageParams{
+					ChatID:      int64(10),
+					FromChatID:  int64(10),
+					MessageID:   7,
+					ReplyMarkup: keyboard,
+				},
+			},
+		},
+		{
+			name: "buttons are changed after the edits",
+			answer: &entities.Answer{
+				EditMessageText: &bot.EditMessageTextParams{ChatID: int64(10), MessageID: 5, Text: "note"},
+				EditMessageReplyMarkup: &bot.EditMessageReplyMarkupParams{
+					ChatID:      int64(10),
+					MessageID:   5,
+					ReplyMarkup: keyboard,
+				},
+			},
+			want: []any{
+				&bot.EditMessageTextParams{ChatID: int64(10), MessageID: 5, Text: "note"},
+				&bot.EditMessageReplyMarkupParams{
+					ChatID:      int64(10),
+					MessageID:   5,
+					ReplyMarkup: keyboard,
+				},
+			},
+		},
+		{
+			name: "tracked messages are removed before the message",
+			answer: &entities.Answer{
+				UserID:         1,
+				SendMessage:    &bot.SendMessageParams{ChatID: int64(10), Text: "folder"},
+				DeleteMessages: &bot.DeleteMessagesParams{ChatID: int64(10)},
+			},
+			want: []any{
+				&bot.DeleteMessagesParams{ChatID: int64(10)},
+				&bot.SendMessageParams{ChatID: int64(10), Text: "folder"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envs, err := split(tt.answer)
+			require.NoError(t, err)
+			require.Len(t, envs, len(tt.want))
+
+			for i, env := range envs {
+				raw, err := json.Marshal(env)
+				require.NoError(t, err)
+
+				var stored envelope
+				require.NoError(t, json.Unmarshal(raw, &stored))
+				assert.Equal(t, tt.answer.UserID, stored.UserID)
+				assert.Equal(t, tt.answer.DeleteAfter, stored.DeleteAfter)
+
+				params, err := decode(&stored)
+				require.NoError(t, err)
+				assert.Equal(t, tt.want[i], params)
+			}
+		})
+	}
+}
+
+func TestSplitDoesNotModifyAnswer(t *testing.T) {
+	params := &bot.SendPhotoParams{ChatID: int64(1), Photo: &models.InputFileString{Data: "f"}}
+
+	_, err := split(&entities.Answer{SendPhoto: params})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), params.ChatID)
+	assert.Equal(t, &models.InputFileString{Data: "f"}, params.Photo)
+}
+
+func TestSplitKeepsUploads(t *testing.T) {
+	envs, err := split(&entities.Answer{
+		SendDocument: &bot.SendDocumentParams{
+			ChatID:   int64(1),
+			Document: &models.InputFileUpload{Filename: "a.csv", Data: strings.NewReader("a,b")},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, envs, 1)
+
+	raw, err := json.Marshal(envs[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "a,b")
+
+	params, err := decode(envs[0])
+	require.NoError(t, err)
+	file := params.(*bot.SendDocumentParams).Document.(*models.InputFileUpload)
+	assert.Equal(t, "a.csv", file.Filename)
+	data, err := io.ReadAll(file.Data)
+	require.NoError(t, err)
+	assert.Equal(t, "a,b", string(data))
+}
@@ -0,0 +1,602 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"archive_bot/internal/entities"
+	"archive_bot/internal/metrics"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
+)
+
+const (
+	queuePrefix string = "send:queue:"
+	chatsKey    string = "send:chats"
+
+	pollInterval  time.Duration = 250 * time.Millisecond
+	pruneInterval time.Duration = time.Minute
+	idleTimeout   time.Duration = 5 * time.Minute
+	baseBackoff   time.Duration = time.Second
+	maxBackoff    time.Duration = time.Minute
+
+	defaultGlobalRate  float64 = 30
+	defaultChatRate    float64 = 1
+	defaultChatBurst   int     = 3
+	defaultWorkers     int     = 8
+	defaultMaxAttempts int     = 5
+)
+
+var (
+	errBroken     = errors.New("broken call")
+	errLostUpload = errors.New("the uploaded file is lost")
+	errNotStarted = errors.New("the sender is not started")
+)
+
+// API is the part of the Bot API used to deliver the answers.
+type API interface {
+	SendMessage(ctx context.Context, params *bot.SendMessageParams) (*models.Message, error)
+	SendPhoto(ctx context.Context, params *bot.SendPhotoParams) (*models.Message, error)
+	SendDocument(ctx context.Context, params *bot.SendDocumentParams) (*models.Message, error)
+	SendVideo(ctx context.Context, params *bot.SendVideoParams) (*models.Message, error)
+	SendAudio(ctx context.Context, params *bot.SendAudioParams) (*models.Message, error)
+	SendAnimation(ctx context.Context, params *bot.SendAnimationParams) (*models.Message, error)
+	SendVoice(ctx context.Context, params *bot.SendVoiceParams) (*models.Message, error)
+	SendMediaGroup(ctx context.Context, params *bot.SendMediaGroupParams) ([]*models.Message, error)
+	EditMessageText(ctx context.Context, params *bot.EditMessageTextParams) (*models.Message, error)
+	EditMessageCaption(ctx context.Context, params *bot.EditMessageCaptionParams) (*models.Message, error)
+	EditMessageMedia(ctx context.Context, params *bot.EditMessageMediaParams) (*models.Message, error)
+	EditMessageReplyMarkup(ctx context.Context, params *bot.EditMessageReplyMarkupParams) (*models.Message, error)
+	CopyMessage(ctx context.Context, params *bot.CopyMessageParams) (*models.MessageID, error)
+	DeleteMessages(ctx context.Context, params *bot.DeleteMessagesParams) (bool, error)
+}
+
+// Tracker remembers the delivered messages, the ones sent with DeleteAfter
+// are removed on the next screen and the others are the folders list.
+type Tracker interface {
+	AddMessageID(ctx context.Context, userID int64, messageID int)
+	MessageIDs(ctx context.Context, userID int64) []int
+	SetFolderMsgID(ctx context.Context, userID int64, messageID int)
+}
+
+type Config struct {
+	GlobalRate  float64
+	ChatRate    float64
+	ChatBurst   int
+	Workers     int
+	MaxAttempts int
+}
+
+type chatState struct {
+	limiter   *rate.Limiter
+	busy      bool
+	notBefore time.Time
+	lastUsed  time.Time
+}
+
+// sender is the outbound queue. Calls are stored per chat in redis and
+// delivered in order, respecting the global and per chat rate limits.
+// While redis is unavailable the calls wait in memory, still in order.
+type sender struct {
+	log     *logger.Logger
+	db      *redis.Client
+	tracker Tracker
+	cfg     Config
+	// uploadPrefix tells the uploads of this process from the ones of the
+	// calls queued before the restart.
+	uploadPrefix string
+
+	global  *rate.Limiter
+	slots   chan struct{}
+	wake    chan struct{}
+	running sync.WaitGroup
+
+	mu         sync.Mutex
+	api        API
+	chats      map[int64]*chatState
+	direct     map[int64][]*envelope
+	uploads    map[string]map[string]upload
+	lastUpload uint64
+}
+
+func New(log *logger.Logger, db *redis.Client, tracker Tracker, cfg Config) *sender {
+	if cfg.GlobalRate <= 0 {
+		cfg.GlobalRate = defaultGlobalRate
+	}
+	if cfg.ChatRate <= 0 {
+		cfg.ChatRate = defaultChatRate
+	}
+	if cfg.ChatBurst <= 0 {
+		cfg.ChatBurst = defaultChatBurst
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultWorkers
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+
+	return &sender{
+		log:          log,
+		db:           db,
+		tracker:      tracker,
+		cfg:          cfg,
+		uploadPrefix: strconv.FormatInt(time.Now().UnixNano(), 36) + ":",
+		global:       rate.NewLimiter(rate.Limit(cfg.GlobalRate), int(cfg.GlobalRate)),
+		slots:        make(chan struct{}, cfg.Workers),
+		wake:         make(chan struct{}, 1),
+		chats:        make(map[int64]*chatState),
+		direct:       make(map[int64][]*envelope),
+		uploads:      make(map[string]map[string]upload),
+	}
+}
+
+// Send queues the answers. Callback query answers must be sent by the caller.
+func (s *sender) Send(ctx context.Context, answers []*entities.Answer) {
+	log := logger.L(ctx).With(logger.String("operation", "sender.Send"))
+
+	for _, ans := range answers {
+		envs, err := split(ans)
+		if err != nil {
+			log.Error("failed to encode answer", logger.ErrAttr(err))
+			continue
+		}
+
+		for _, env := range envs {
+			s.keepUploads(env)
+			if err := s.push(ctx, env); err != nil {
+				log.Error("failed to queue answer, sending directly", logger.ErrAttr(err))
+				s.sendDirectly(context.WithoutCancel(ctx), env)
+			}
+		}
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start delivers the queued calls until ctx is done.
+// The calls left in redis are delivered after the restart.
+func (s *sender) Start(ctx context.Context, api API) {
+	s.mu.Lock()
+	s.api = api
+	s.mu.Unlock()
+
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+	prune := time.NewTicker(pruneInterval)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-prune.C:
+			s.prune()
+			continue
+		case <-poll.C:
+		case <-s.wake:
+		}
+
+		s.schedule(ctx)
+	}
+}
+
+// Drain delivers the queued calls after Start returned, until the queue
+// is empty or ctx is done. The calls left are delivered after the restart.
+func (s *sender) Drain(ctx context.Context) error {
+	defer s.running.Wait()
+
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+
+	for {
+		s.schedule(ctx)
+
+		pending, err := s.db.SCard(ctx, chatsKey).Result()
+		if err != nil {
+			return err
+		}
+		if pending == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-poll.C:
+		}
+	}
+}
+
+func (s *sender) push(ctx context.Context, env *envelope) error {
+	raw, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	pipe := s.db.TxPipeline()
+	pipe.RPush(ctx, queueKey(env.ChatID), raw)
+	pipe.SAdd(ctx, chatsKey, env.ChatID)
+	_, err = pipe.Exec(ctx)
+
+	return err
+}
+
+// schedule starts draining every pending chat that is not busy or waiting.
+func (s *sender) schedule(ctx context.Context) {
+	log := s.log.With(logger.String("operation", "sender.schedule"))
+
+	members, err := s.db.SMembers(ctx, chatsKey).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error("failed to get chats", logger.ErrAttr(err))
+		}
+		return
+	}
+
+	for _, member := range members {
+		chatID, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			s.db.SRem(ctx, chatsKey, member)
+			continue
+		}
+		if !s.acquire(chatID) {
+			continue
+		}
+
+		select {
+		case s.slots <- struct{}{}:
+		case <-ctx.Done():
+			s.release(chatID)
+			return
+		}
+
+		s.running.Add(1)
+		go func() {
+			defer s.running.Done()
+			defer func() { <-s.slots }()
+			defer s.release(chatID)
+			s.drain(ctx, chatID)
+		}()
+	}
+}
+
+// drain delivers the calls of the chat in order until the queue is empty
+// or the chat has to wait.
+func (s *sender) drain(ctx context.Context, chatID int64) {
+	log := s.log.With(
+		logger.String("operation", "sender.drain"),
+		logger.Int64("chat_id", chatID),
+	)
+	key := queueKey(chatID)
+
+	for ctx.Err() == nil {
+		raw, err := s.db.LIndex(ctx, key, 0).Bytes()
+		if err == redis.Nil {
+			s.db.SRem(ctx, chatsKey, chatID)
+			if n, err := s.db.LLen(ctx, key).Result(); err == nil && n > 0 {
+				s.db.SAdd(ctx, chatsKey, chatID)
+			}
+			return
+		}
+		if err != nil {
+			log.Error("failed to peek queue", logger.ErrAttr(err))
+			return
+		}
+
+		var env envelope
+		if err := json.Unmarshal(raw, &env); err != nil {
+			log.Error("dropping broken call", logger.ErrAttr(err))
+			s.db.LPop(ctx, key)
+			continue
+		}
+
+		if err := s.wait(ctx, chatID); err != nil {
+			return
+		}
+
+		err = s.deliver(ctx, &env)
+		retryAfter, retry := s.retryAfter(&env, err)
+		if retry {
+			env.Attempts++
+			if raw, err := json.Marshal(&env); err == nil {
+				s.db.LSet(ctx, key, 0, raw)
+			}
+			s.delay(chatID, retryAfter)
+			log.Warn(
+				"delivery postponed",
+				logger.String("method", env.Method),
+				logger.Int("attempts", env.Attempts),
+				logger.Duration("retry_after", retryAfter),
+				logger.ErrAttr(err),
+			)
+			return
+		}
+
+		if err != nil {
+			metrics.SendDropped(env.Method)
+			log.Error("delivery failed", logger.String("method", env.Method), logger.ErrAttr(err))
+		}
+		s.db.LPop(ctx, key)
+		s.forgetUploads(&env)
+	}
+}
+
+// wait blocks until the global and the chat limiters allow the call.
+func (s *sender) wait(ctx context.Context, chatID int64) error {
+	s.mu.Lock()
+	limiter := s.chats[chatID].limiter
+	s.mu.Unlock()
+
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	return s.global.Wait(ctx)
+}
+
+// retryAfter decides whether the failed call is retried and when.
+func (s *sender) retryAfter(env *envelope, err error) (time.Duration, bool) {
+	if err == nil || env.Attempts+1 >= s.cfg.MaxAttempts {
+		return 0, false
+	}
+
+	var tooMany *bot.TooManyRequestsError
+	switch {
+	case errors.As(err, &tooMany):
+		return time.Duration(tooMany.RetryAfter) * time.Second, true
+	case errors.Is(err, errBroken),
+		errors.Is(err, bot.ErrorBadRequest),
+		errors.Is(err, bot.ErrorForbidden),
+		errors.Is(err, bot.ErrorUnauthorized),
+		errors.Is(err, bot.ErrorNotFound),
+		errors.Is(err, bot.ErrorConflict),
+		bot.IsMigrateError(err):
+		return 0, false
+	}
+
+	return min(baseBackoff<<env.Attempts, maxBackoff), true
+}
+
+func (s *sender) deliver(ctx context.Context, env *envelope) error {
+	if env.Upload != "" {
+		s.mu.Lock()
+		env.uploads = s.uploads[env.Upload]
+		s.mu.Unlock()
+		if env.uploads == nil {
+			return errors.Join(errBroken, errLostUpload)
+		}
+	}
+
+	params, err := decode(env)
+	if err != nil {
+		return errors.Join(errBroken, err)
+	}
+
+	s.mu.Lock()
+	api := s.api
+	s.mu.Unlock()
+
+	var msgIDs []int
+	switch p := params.(type) {
+	case *bot.DeleteMessagesParams:
+		return s.deleteMessages(ctx, api, env, p)
+	case *bot.SendMessageParams:
+		msgIDs, err = messageID(api.SendMessage(ctx, p))
+	case *bot.SendPhotoParams:
+		msgIDs, err = messageID(api.SendPhoto(ctx, p))
+	case *bot.SendDocumentParams:
+		msgIDs, err = messageID(api.SendDocument(ctx, p))
+	case *bot.SendVideoParams:
+		msgIDs, err = messageID(api.SendVideo(ctx, p))
+	case *bot.SendAudioParams:
+		msgIDs, err = messageID(api.SendAudio(ctx, p))
+	case *bot.SendAnimationParams:
+		msgIDs, err = messageID(api.SendAnimation(ctx, p))
+	case *bot.SendVoiceParams:
+		msgIDs, err = messageID(api.SendVoice(ctx, p))
+	case *bot.SendMediaGroupParams:
+		var msgs []*models.Message
+		msgs, err = api.SendMediaGroup(ctx, p)
+		for _, msg := range msgs {
+			msgIDs = append(msgIDs, msg.ID)
+		}
+	case *bot.EditMessageTextParams:
+		msgIDs, err = messageID(api.EditMessageText(ctx, p))
+	case *bot.EditMessageCaptionParams:
+		msgIDs, err = messageID(api.EditMessageCaption(ctx, p))
+	case *bot.EditMessageMediaParams:
+		msgIDs, err = messageID(api.EditMessageMedia(ctx, p))
+	// the copies and the changed buttons are not tracked: the broadcast
+	// preview stays on the screen and the edited message is tracked already
+	case *bot.CopyMessageParams:
+		_, err = api.CopyMessage(ctx, p)
+	case *bot.EditMessageReplyMarkupParams:
+		_, err = api.EditMessageReplyMarkup(ctx, p)
+	}
+	metrics.TelegramRequest(env.Method, err)
+	if err != nil {
+		return err
+	}
+
+	for _, msgID := range msgIDs {
+		if env.DeleteAfter {
+			s.tracker.AddMessageID(ctx, env.UserID, msgID)
+		} else {
+			s.tracker.SetFolderMsgID(ctx, env.UserID, msgID)
+		}
+	}
+
+	return nil
+}
+
+// deleteMessages removes the messages of the call or, when it has none,
+// the tracked ones. The calls queued before it are delivered and tracked
+// by then. The tracked IDs are kept in the call for the retry.
+func (s *sender) deleteMessages(ctx context.Context, api API, env *envelope, p *bot.DeleteMessagesParams) error {
+	if len(p.MessageIDs) == 0 {
+		p.MessageIDs = s.tracker.MessageIDs(ctx, env.UserID)
+		if len(p.MessageIDs) == 0 {
+			return nil
+		}
+	}
+
+	_, err := api.DeleteMessages(ctx, p)
+	metrics.TelegramRequest(env.Method, err)
+	if err != nil {
+		if raw, mErr := json.Marshal(p); mErr == nil {
+			env.Params = raw
+		}
+	}
+
+	return err
+}
+
+// sendDirectly is the fallback used when redis is unavailable. The calls
+// of the chat wait in memory and one goroutine delivers them in order.
+func (s *sender) sendDirectly(ctx context.Context, env *envelope) {
+	s.mu.Lock()
+	pending := s.direct[env.ChatID]
+	s.direct[env.ChatID] = append(pending, env)
+	s.mu.Unlock()
+	if len(pending) > 0 {
+		return
+	}
+
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.drainDirect(ctx, env.ChatID)
+	}()
+}
+
+// drainDirect delivers the calls of the chat kept in memory until there
+// are none left. A call stays first in the list until it is delivered, so
+// the calls added meanwhile wait for it.
+func (s *sender) drainDirect(ctx context.Context, chatID int64) {
+	log := s.log.With(
+		logger.String("operation", "sender.drainDirect"),
+		logger.Int64("chat_id", chatID),
+	)
+
+	for {
+		s.mu.Lock()
+		pending := s.direct[chatID]
+		if len(pending) == 0 {
+			delete(s.direct, chatID)
+			s.mu.Unlock()
+			return
+		}
+		env, ready := pending[0], s.api != nil
+		s.mu.Unlock()
+
+		err := errNotStarted
+		if ready {
+			if err = s.global.Wait(ctx); err == nil {
+				err = s.deliver(ctx, env)
+			}
+		}
+		if err != nil {
+			metrics.SendDropped(env.Method)
+			log.Error("direct delivery failed", logger.String("method", env.Method), logger.ErrAttr(err))
+		}
+		s.forgetUploads(env)
+
+		s.mu.Lock()
+		s.direct[chatID] = s.direct[chatID][1:]
+		s.mu.Unlock()
+	}
+}
+
+// keepUploads moves the uploaded files of the call to memory, the queue
+// only stores their key.
+func (s *sender) keepUploads(env *envelope) {
+	if len(env.uploads) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastUpload++
+	env.Upload = s.uploadPrefix + strconv.FormatUint(s.lastUpload, 10)
+	s.uploads[env.Upload] = env.uploads
+}
+
+func (s *sender) forgetUploads(env *envelope) {
+	if env.Upload == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.uploads, env.Upload)
+}
+
+func (s *sender) acquire(chatID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.chats[chatID]
+	if !ok {
+		state = &chatState{limiter: rate.NewLimiter(rate.Limit(s.cfg.ChatRate), s.cfg.ChatBurst)}
+		s.chats[chatID] = state
+	}
+	if state.busy || time.Now().Before(state.notBefore) {
+		return false
+	}
+
+	state.busy = true
+	state.lastUsed = time.Now()
+	return true
+}
+
+func (s *sender) release(chatID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chats[chatID].busy = false
+}
+
+func (s *sender) delay(chatID int64, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chats[chatID].notBefore = time.Now().Add(d)
+}
+
+// prune forgets the limiters of idle chats.
+func (s *sender) prune() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for chatID, state := range s.chats {
+		if !state.busy && time.Since(state.lastUsed) > idleTimeout {
+			delete(s.chats, chatID)
+		}
+	}
+}
+
+func queueKey(chatID int64) string {
+	return queuePrefix + strconv.FormatInt(chatID, 10)
+}
+
+func messageID(msg *models.Message, err error) ([]int, error) {
+	if err != nil || msg == nil {
+		return nil, err
+	}
+	return []int{msg.ID}, nil
+}
@@ -0,0 +1,127 @@
+package sender
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"archive_bot/internal/entities"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAPI struct {
+	API
+
+	mu      sync.Mutex
+	sent    []string
+	deleted []int
+}
+
+func (a *fakeAPI) SendMessage(ctx context.Context, p *bot.SendMessageParams) (*models.Message, error) {
+	// the later calls would overtake the slow first one if run concurrently
+	if p.Text == "0" {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.sent = append(a.sent, p.Text)
+	return &models.Message{ID: len(a.sent)}, nil
+}
+
+func (a *fakeAPI) DeleteMessages(ctx context.Context, p *bot.DeleteMessagesParams) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.deleted = append(a.deleted, p.MessageIDs...)
+	return true, nil
+}
+
+type fakeTracker struct {
+	mu  sync.Mutex
+	ids []int
+}
+
+func (t *fakeTracker) AddMessageID(ctx context.Context, userID int64, messageID int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.ids = append(t.ids, messageID)
+}
+
+func (t *fakeTracker) MessageIDs(ctx context.Context, userID int64) []int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ids := t.ids
+	t.ids = nil
+	return ids
+}
+
+func (t *fakeTracker) SetFolderMsgID(ctx context.Context, userID int64, messageID int) {}
+
+func newTestSender(api API, tracker Tracker) *sender {
+	log := logger.NewLogger(logger.WithWriter(io.Discard), logger.WithSetDefault(false))
+	s := New(log, nil, tracker, Config{GlobalRate: 1000})
+	s.api = api
+
+	return s
+}
+
+func queueDirectly(t *testing.T, s *sender, answers ...*entities.Answer) {
+	for _, ans := range answers {
+		envs, err := split(ans)
+		require.NoError(t, err)
+		for _, env := range envs {
+			s.keepUploads(env)
+			s.sendDirectly(context.Background(), env)
+		}
+	}
+}
+
+func TestSendDirectlyKeepsOrder(t *testing.T) {
+	api := &fakeAPI{}
+	s := newTestSender(api, &fakeTracker{})
+
+	want := make([]string, 0, 5)
+	for i := range 5 {
+		want = append(want, strconv.Itoa(i))
+		queueDirectly(t, s, &entities.Answer{
+			SendMessage: &bot.SendMessageParams{ChatID: int64(1), Text: strconv.Itoa(i)},
+		})
+	}
+	s.running.Wait()
+
+	assert.Equal(t, want, api.sent)
+	assert.Empty(t, s.direct)
+}
+
+func TestDeleteMessagesRemovesDelivered(t *testing.T) {
+	api := &fakeAPI{}
+	s := newTestSender(api, &fakeTracker{})
+
+	queueDirectly(t, s,
+		&entities.Answer{
+			DeleteAfter: true,
+			SendMessage: &bot.SendMessageParams{ChatID: int64(1), Text: "0"},
+		},
+		&entities.Answer{
+			DeleteAfter: true,
+			SendMessage: &bot.SendMessageParams{ChatID: int64(1), Text: "1"},
+		},
+		&entities.Answer{DeleteMessages: &bot.DeleteMessagesParams{ChatID: int64(1)}},
+	)
+	s.running.Wait()
+
+	assert.Equal(t, []int{1, 2}, api.deleted)
+}
@@ -0,0 +1,85 @@
+package stats
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Table is a section of the admin statistics, the Title is a message key
+// rendered in the language of the admin.
+type Table struct {
+	Title  string
+	Header []string
+	Rows   [][]string
+}
+
+// Text renders the table with the columns padded to the same width,
+// it is meant to be shown in a monospace block.
+func (t *Table) Text() string {
+	widths := make([]int, len(t.Header))
+	for i, h := range t.Header {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+	for _, row := range t.Rows {
+		for i, cell := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+			}
+		}
+	}
+
+	b := &strings.Builder{}
+	b.WriteString(t.Title)
+	b.WriteString("\n")
+	writeRow(b, t.Header, widths)
+
+	sep := make([]string, len(widths))
+	for i, w := range widths {
+		sep[i] = strings.Repeat("-", w)
+	}
+	writeRow(b, sep, widths)
+
+	for _, row := range t.Rows {
+		writeRow(b, row, widths)
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func writeRow(b *strings.Builder, row []string, widths []int) {
+	for i, w := range widths {
+		cell := ""
+		if i < len(row) {
+			cell = row[i]
+		}
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		b.WriteString(cell)
+		if i < len(widths)-1 {
+			b.WriteString(strings.Repeat(" ", w-utf8.RuneCountInString(cell)))
+		}
+	}
+	b.WriteString("\n")
+}
+
+// WriteCSV writes the tables one after another, every row starts with
+// the title of its table and the header row of each table is kept.
+func WriteCSV(w io.Writer, tables []*Table) error {
+	cw := csv.NewWriter(w)
+	for _, t := range tables {
+		if err := cw.Write(append([]string{"table"}, t.Header...)); err != nil {
+			return err
+		}
+		for _, row := range t.Rows {
+			if err := cw.Write(append([]string{t.Title}, row...)); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
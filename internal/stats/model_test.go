@@ -0,0 +1,43 @@
+package stats
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableText(t *testing.T) {
+	t.Parallel()
+	table := &Table{
+		Title:  "Active users",
+		Header: []string{"period", "users"},
+		Rows: [][]string{
+			{"day", "5"},
+			{"месяц", "120"},
+		},
+	}
+
+	want := "Active users\n" +
+		"period  users\n" +
+		"------  -----\n" +
+		"day     5\n" +
+		"месяц   120"
+	assert.Equal(t, want, table.Text())
+}
+
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+	tables := []*Table{
+		{Title: "Users", Header: []string{"period", "users"}, Rows: [][]string{{"day", "5"}}},
+		{Title: "Top folders", Header: []string{"folder", "notes"}, Rows: [][]string{{"a, b", "2"}}},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteCSV(buf, tables))
+	assert.Equal(t,
+		"table,period,users\nUsers,day,5\ntable,folder,notes\nTop folders,\"a, b\",2\n",
+		buf.String(),
+	)
+}
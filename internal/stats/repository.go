@@ -0,0 +1,229 @@
+package stats
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Activity is the number of users seen in the periods.
+type Activity struct {
+	Total int
+	Day   int
+	Week  int
+	Month int
+}
+
+type DayCount struct {
+	Day   time.Time
+	Count int
+}
+
+type TypeCount struct {
+	Day   time.Time
+	Type  string
+	Count int
+}
+
+// Archive is the size of the archive of a user.
+type Archive struct {
+	UserID   int64
+	Username string
+	Notes    int
+	Folders  int
+	Files    int
+}
+
+type FolderCount struct {
+	Name  string
+	Users int
+	Notes int
+}
+
+type NameCount struct {
+	Name  string
+	Count int
+}
+
+var (
+	instance *pgRepository
+	once     sync.Once
+)
+
+type pgRepository struct {
+	log *logger.Logger
+	db  *pgxpool.Pool
+}
+
+// NewRepository creates new stats repository.
+func NewRepository(ctx context.Context, log *logger.Logger, db *pgxpool.Pool) (*pgRepository, error) {
+	once.Do(func() {
+		instance = &pgRepository{log: log, db: db}
+	})
+
+	return instance, nil
+}
+
+func (repo *pgRepository) Activity(ctx context.Context) (*Activity, error) {
+	const op string = "stats.repository.Activity"
+
+	var a Activity
+	if err := repo.db.QueryRow(ctx,
+		`SELECT COUNT(*),
+		COUNT(*) FILTER (WHERE last_seen_at > CURRENT_TIMESTAMP - INTERVAL '1 day'),
+		COUNT(*) FILTER (WHERE last_seen_at > CURRENT_TIMESTAMP - INTERVAL '7 days'),
+		COUNT(*) FILTER (WHERE last_seen_at > CURRENT_TIMESTAMP - INTERVAL '30 days')
+		FROM users;`).Scan(&a.Total, &a.Day, &a.Week, &a.Month); err != nil {
+		return nil, er.New("unable to count active users", op, err)
+	}
+
+	return &a, nil
+}
+
+// Signups returns the new users per day for the last days, today included.
+func (repo *pgRepository) Signups(ctx context.Context, days int) ([]DayCount, error) {
+	const op string = "stats.repository.Signups"
+
+	rows, err := repo.db.Query(ctx,
+		`SELECT d.day, COUNT(u.id)
+		FROM generate_series(CURRENT_DATE - ($1::int - 1), CURRENT_DATE, INTERVAL '1 day') AS d(day)
+		LEFT JOIN users u ON u.created_at >= d.day AND u.created_at < d.day + INTERVAL '1 day'
+		GROUP BY d.day
+		ORDER BY d.day;`, days)
+	if err != nil {
+		return nil, er.New("unable to count signups", op, err)
+	}
+
+	res, err := pgx.CollectRows(rows, pgx.RowToStructByPos[DayCount])
+	if err != nil {
+		return nil, er.New("unable to scan signups", op, err)
+	}
+
+	return res, nil
+}
+
+// NotesByType returns the notes saved per day and type for the last days.
+func (repo *pgRepository) NotesByType(ctx context.Context, days int) ([]TypeCount, error) {
+	const op string = "stats.repository.NotesByType"
+
+	rows, err := repo.db.Query(ctx,
+		`SELECT date_trunc('day', created_at), type::text, COUNT(*)
+		FROM texts
+		WHERE created_at >= CURRENT_DATE - ($1::int - 1)
+		GROUP BY 1, 2
+		ORDER BY 1, 2;`, days)
+	if err != nil {
+		return nil, er.New("unable to count notes", op, err)
+	}
+
+	res, err := pgx.CollectRows(rows, pgx.RowToStructByPos[TypeCount])
+	if err != nil {
+		return nil, er.New("unable to scan notes", op, err)
+	}
+
+	return res, nil
+}
+
+// Averages returns the average number of folders and notes per user.
+func (repo *pgRepository) Averages(ctx context.Context) (float64, float64, error) {
+	const op string = "stats.repository.Averages"
+
+	var folders, notes float64
+	if err := repo.db.QueryRow(ctx,
+		`SELECT COALESCE((SELECT COUNT(*) FROM folders)::float / NULLIF(COUNT(*), 0), 0),
+		COALESCE((SELECT COUNT(*) FROM texts)::float / NULLIF(COUNT(*), 0), 0)
+		FROM users;`).Scan(&folders, &notes); err != nil {
+		return 0, 0, er.New("unable to count averages", op, err)
+	}
+
+	return folders, notes, nil
+}
+
+// LargestArchives returns the users with the most notes.
+func (repo *pgRepository) LargestArchives(ctx context.Context, limit int) ([]Archive, error) {
+	const op string = "stats.repository.LargestArchives"
+
+	rows, err := repo.db.Query(ctx,
+		`SELECT u.id, COALESCE(u.username, ''), COUNT(t.id),
+		COUNT(DISTINCT t.folder_id), COALESCE(SUM(m.files), 0)
+		FROM users u
+		JOIN texts t ON t.user_id = u.id
+		LEFT JOIN (
+			SELECT texts_id, COUNT(*) AS files FROM (
+				SELECT texts_id FROM photos
+				UNION ALL SELECT texts_id FROM documents
+				UNION ALL SELECT texts_id FROM videos
+				UNION ALL SELECT texts_id FROM audios
+				UNION ALL SELECT texts_id FROM animations
+				UNION ALL SELECT texts_id FROM voices
+			) AS media GROUP BY texts_id
+		) AS m ON m.texts_id = t.id
+		GROUP BY u.id
+		ORDER BY 3 DESC
+		LIMIT $1;`, limit)
+	if err != nil {
+		return nil, er.New("unable to get largest archives", op, err)
+	}
+
+	res, err := pgx.CollectRows(rows, pgx.RowToStructByPos[Archive])
+	if err != nil {
+		return nil, er.New("unable to scan archives", op, err)
+	}
+
+	return res, nil
+}
+
+// TopFolders returns the folder names with the most notes across the users.
+func (repo *pgRepository) TopFolders(ctx context.Context, limit int) ([]FolderCount, error) {
+	const op string = "stats.repository.TopFolders"
+
+	rows, err := repo.db.Query(ctx,
+		`SELECT f.name, COUNT(DISTINCT f.user_id), COUNT(t.id)
+		FROM folders f
+		JOIN texts t ON t.folder_id = f.id
+		WHERE f.name <> 'default'
+		GROUP BY f.name
+		ORDER BY 3 DESC
+		LIMIT $1;`, limit)
+	if err != nil {
+		return nil, er.New("unable to get top folders", op, err)
+	}
+
+	res, err := pgx.CollectRows(rows, pgx.RowToStructByPos[FolderCount])
+	if err != nil {
+		return nil, er.New("unable to scan folders", op, err)
+	}
+
+	return res, nil
+}
+
+// Storage returns the number of stored rows by kind.
+func (repo *pgRepository) Storage(ctx context.Context) ([]NameCount, error) {
+	const op string = "stats.repository.Storage"
+
+	rows, err := repo.db.Query(ctx,
+		`SELECT 'notes', COUNT(*) FROM texts
+		UNION ALL SELECT 'folders', COUNT(*) FROM folders
+		UNION ALL SELECT 'photos', COUNT(*) FROM photos
+		UNION ALL SELECT 'documents', COUNT(*) FROM documents
+		UNION ALL SELECT 'videos', COUNT(*) FROM videos
+		UNION ALL SELECT 'audios', COUNT(*) FROM audios
+		UNION ALL SELECT 'animations', COUNT(*) FROM animations
+		UNION ALL SELECT 'voices', COUNT(*) FROM voices;`)
+	if err != nil {
+		return nil, er.New("unable to count storage", op, err)
+	}
+
+	res, err := pgx.CollectRows(rows, pgx.RowToStructByPos[NameCount])
+	if err != nil {
+		return nil, er.New("unable to scan storage", op, err)
+	}
+
+	return res, nil
+}
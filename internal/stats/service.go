@@ -0,0 +1,241 @@
+package stats
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"archive_bot/internal/const/messages"
+	"archive_bot/internal/metrics"
+
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+const (
+	signupDays int = 14
+	notesDays  int = 7
+	topLimit   int = 10
+
+	dayLayout string = "02.01"
+)
+
+// noteTypes are the values of the note_type enum in the column order.
+var noteTypes = []string{"message", "photo", "doc", "video", "audio", "animation", "voice"}
+
+type Repository interface {
+	Activity(ctx context.Context) (*Activity, error)
+	Signups(ctx context.Context, days int) ([]DayCount, error)
+	NotesByType(ctx context.Context, days int) ([]TypeCount, error)
+	Averages(ctx context.Context) (float64, float64, error)
+	LargestArchives(ctx context.Context, limit int) ([]Archive, error)
+	TopFolders(ctx context.Context, limit int) ([]FolderCount, error)
+	Storage(ctx context.Context) ([]NameCount, error)
+}
+
+type service struct {
+	log  *logger.Logger
+	repo Repository
+}
+
+func NewService(ctx context.Context, log *logger.Logger, repo Repository) *service {
+	return &service{log: log, repo: repo}
+}
+
+// Report collects the admin statistics. The error rates are taken from
+// the metrics of the process, so they are counted since its start.
+func (s *service) Report(ctx context.Context) ([]*Table, error) {
+	ctx, span := tracing.Start(ctx, "stats.service.Report")
+	defer span.End()
+
+	builders := []func(ctx context.Context) (*Table, error){
+		s.activity,
+		s.signups,
+		s.notes,
+		s.averages,
+		s.storage,
+		s.archives,
+		s.folders,
+	}
+
+	tables := make([]*Table, 0, len(builders)+1)
+	for _, build := range builders {
+		t, err := build(ctx)
+		if err != nil {
+			logger.L(ctx).Error("failed to build stats", logger.ErrAttr(err))
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+
+	return append(tables, errorRates(metrics.Snapshot())), nil
+}
+
+func (s *service) activity(ctx context.Context) (*Table, error) {
+	a, err := s.repo.Activity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Table{
+		Title:  messages.StatsActivity,
+		Header: []string{"period", "users"},
+		Rows: [][]string{
+			{"day", strconv.Itoa(a.Day)},
+			{"week", strconv.Itoa(a.Week)},
+			{"month", strconv.Itoa(a.Month)},
+			{"total", strconv.Itoa(a.Total)},
+		},
+	}, nil
+}
+
+func (s *service) signups(ctx context.Context) (*Table, error) {
+	counts, err := s.repo.Signups(ctx, signupDays)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Table{Title: messages.StatsSignups, Header: []string{"day", "users"}}
+	for _, c := range counts {
+		t.Rows = append(t.Rows, []string{c.Day.Format(dayLayout), strconv.Itoa(c.Count)})
+	}
+
+	return t, nil
+}
+
+func (s *service) notes(ctx context.Context) (*Table, error) {
+	counts, err := s.repo.NotesByType(ctx, notesDays)
+	if err != nil {
+		return nil, err
+	}
+
+	return notesTable(counts, time.Now(), notesDays), nil
+}
+
+// notesTable pivots the counts into a row per day and a column per type,
+// the days without notes are kept.
+func notesTable(counts []TypeCount, now time.Time, days int) *Table {
+	column := make(map[string]int, len(noteTypes))
+	for i, nt := range noteTypes {
+		column[nt] = i
+	}
+
+	perDay := make(map[string][]int, days)
+	for _, c := range counts {
+		day := c.Day.Format(dayLayout)
+		if perDay[day] == nil {
+			perDay[day] = make([]int, len(noteTypes))
+		}
+		if i, ok := column[c.Type]; ok {
+			perDay[day][i] += c.Count
+		}
+	}
+
+	t := &Table{Title: messages.StatsNotes, Header: append([]string{"day"}, noteTypes...)}
+	for i := days - 1; i >= 0; i-- {
+		day := now.AddDate(0, 0, -i).Format(dayLayout)
+		row := []string{day}
+		for j := range noteTypes {
+			n := 0
+			if perDay[day] != nil {
+				n = perDay[day][j]
+			}
+			row = append(row, strconv.Itoa(n))
+		}
+		t.Rows = append(t.Rows, row)
+	}
+
+	return t
+}
+
+func (s *service) averages(ctx context.Context) (*Table, error) {
+	folders, notes, err := s.repo.Averages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Table{
+		Title:  messages.StatsPerUser,
+		Header: []string{"average", "count"},
+		Rows: [][]string{
+			{"folders", strconv.FormatFloat(folders, 'f', 1, 64)},
+			{"notes", strconv.FormatFloat(notes, 'f', 1, 64)},
+		},
+	}, nil
+}
+
+func (s *service) storage(ctx context.Context) (*Table, error) {
+	counts, err := s.repo.Storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Table{Title: messages.StatsStorage, Header: []string{"kind", "rows"}}
+	for _, c := range counts {
+		t.Rows = append(t.Rows, []string{c.Name, strconv.Itoa(c.Count)})
+	}
+
+	return t, nil
+}
+
+func (s *service) archives(ctx context.Context) (*Table, error) {
+	archives, err := s.repo.LargestArchives(ctx, topLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Table{
+		Title:  messages.StatsLargest,
+		Header: []string{"user", "username", "notes", "folders", "files"},
+	}
+	for _, a := range archives {
+		t.Rows = append(t.Rows, []string{
+			strconv.FormatInt(a.UserID, 10),
+			a.Username,
+			strconv.Itoa(a.Notes),
+			strconv.Itoa(a.Folders),
+			strconv.Itoa(a.Files),
+		})
+	}
+
+	return t, nil
+}
+
+func (s *service) folders(ctx context.Context) (*Table, error) {
+	folders, err := s.repo.TopFolders(ctx, topLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Table{Title: messages.StatsFolders, Header: []string{"folder", "users", "notes"}}
+	for _, f := range folders {
+		t.Rows = append(t.Rows, []string{f.Name, strconv.Itoa(f.Users), strconv.Itoa(f.Notes)})
+	}
+
+	return t, nil
+}
+
+func errorRates(totals metrics.Totals) *Table {
+	row := func(name string, total float64, failed float64) []string {
+		rate := "-"
+		if total > 0 {
+			rate = strconv.FormatFloat(failed/total*100, 'f', 2, 64) + "%"
+		}
+		return []string{
+			name,
+			strconv.FormatFloat(total, 'f', 0, 64),
+			strconv.FormatFloat(failed, 'f', 0, 64),
+			rate,
+		}
+	}
+
+	return &Table{
+		Title:  messages.StatsErrors,
+		Header: []string{"stage", "total", "errors", "rate"},
+		Rows: [][]string{
+			row("updates dropped", totals.Updates, totals.UpdatesDropped),
+			row("handler panics", totals.Handled, totals.HandlerPanics),
+			row("bot api", totals.TelegramRequests, totals.TelegramErrors),
+		},
+	}
+}
@@ -0,0 +1,27 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotesTable(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	counts := []TypeCount{
+		{Day: now.AddDate(0, 0, -2), Type: "message", Count: 3},
+		{Day: now.AddDate(0, 0, -2), Type: "photo", Count: 1},
+		{Day: now, Type: "voice", Count: 2},
+		{Day: now, Type: "unknown", Count: 5},
+	}
+
+	table := notesTable(counts, now, 3)
+	assert.Equal(t, append([]string{"day"}, noteTypes...), table.Header)
+	assert.Equal(t, [][]string{
+		{"17.10", "3", "1", "0", "0", "0", "0", "0"},
+		{"18.10", "0", "0", "0", "0", "0", "0", "0"},
+		{"19.10", "0", "0", "0", "0", "0", "0", "2"},
+	}, table.Rows)
+}
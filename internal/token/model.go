@@ -0,0 +1,34 @@
+package token
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Token struct {
+	ID         int
+	UserID     int64
+	Hash       string
+	Prefix     string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+func (t *Token) String() string {
+	b := &strings.Builder{}
+
+	b.WriteString("Token{ID: ")
+	b.WriteString(strconv.Itoa(t.ID))
+	b.WriteString(", UserID: ")
+	b.WriteString(strconv.FormatInt(t.UserID, 10))
+	b.WriteString(", Prefix: ")
+	b.WriteString(t.Prefix)
+	b.WriteString(", CreatedAt: ")
+	b.WriteString(t.CreatedAt.String())
+	b.WriteString(", LastUsedAt: ")
+	b.WriteString(t.LastUsedAt.String())
+	b.WriteRune('}')
+
+	return b.String()
+}
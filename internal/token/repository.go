@@ -0,0 +1,124 @@
+package token
+
+import (
+	"context"
+	"sync"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrNoToken = er.New("the token does not exist", "", nil)
+
+// usedInterval limits the writes of the last use of the token.
+const usedInterval string = "5 minutes"
+
+var (
+	instance *pgRepository
+	once     sync.Once
+)
+
+type pgRepository struct {
+	log *logger.Logger
+	db  *pgxpool.Pool
+}
+
+// NewRepository creates new token repository.
+func NewRepository(ctx context.Context, log *logger.Logger, db *pgxpool.Pool) (*pgRepository, error) {
+	once.Do(func() {
+		instance = &pgRepository{log: log, db: db}
+	})
+
+	return instance, nil
+}
+
+// Save saves the hash of a new token.
+func (repo *pgRepository) Save(ctx context.Context, t *Token) (int, error) {
+	const op string = "token.repository.Save"
+
+	var id int
+	if err := repo.db.QueryRow(ctx,
+		`INSERT INTO api_tokens (user_id, token_hash, prefix)
+		VALUES ($1, $2, $3)
+		RETURNING id;`,
+		t.UserID, t.Hash, t.Prefix).Scan(&id); err != nil {
+		return 0, er.New("unable to save token", op, err)
+	}
+
+	return id, nil
+}
+
+// FindByHash finds an active token and marks it as used, at most once in
+// usedInterval.
+func (repo *pgRepository) FindByHash(ctx context.Context, hash string) (*Token, error) {
+	const op string = "token.repository.FindByHash"
+
+	t := Token{Hash: hash}
+	if err := repo.db.QueryRow(ctx,
+		`WITH t AS (
+			SELECT id, user_id, prefix, created_at, last_used_at
+			FROM api_tokens
+			WHERE token_hash = $1 AND revoked_at IS NULL
+		), used AS (
+			UPDATE api_tokens SET last_used_at = CURRENT_TIMESTAMP
+			FROM t
+			WHERE api_tokens.id = t.id
+				AND (t.last_used_at IS NULL OR t.last_used_at < CURRENT_TIMESTAMP - $2::interval)
+		)
+		SELECT id, user_id, prefix, created_at FROM t;`,
+		hash, usedInterval).Scan(&t.ID, &t.UserID, &t.Prefix, &t.CreatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNoToken
+		}
+		return nil, er.New("unable to find token", op, err)
+	}
+
+	return &t, nil
+}
+
+func (repo *pgRepository) All(ctx context.Context, userID int64) ([]*Token, error) {
+	const op string = "token.repository.All"
+
+	rows, err := repo.db.Query(ctx,
+		`SELECT id, prefix, created_at, COALESCE(last_used_at, 'epoch')
+		FROM api_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY id;`, userID)
+	if err != nil {
+		return nil, er.New("unable to get tokens", op, err)
+	}
+	defer rows.Close()
+
+	tokens := []*Token{}
+	for rows.Next() {
+		t := Token{UserID: userID}
+		if err := rows.Scan(&t.ID, &t.Prefix, &t.CreatedAt, &t.LastUsedAt); err != nil {
+			return nil, er.New("unable to scan data", op, err)
+		}
+		tokens = append(tokens, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, er.New("error in rows", op, err)
+	}
+
+	return tokens, nil
+}
+
+// Revoke revokes the token of the user, id 0 revokes all tokens of the user.
+func (repo *pgRepository) Revoke(ctx context.Context, t *Token) (int, error) {
+	const op string = "token.repository.Revoke"
+
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE api_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND ($2 = 0 OR id = $2) AND revoked_at IS NULL;`,
+		t.UserID, t.ID)
+	if err != nil {
+		return 0, er.New("unable to revoke token", op, err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
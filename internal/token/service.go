@@ -0,0 +1,109 @@
+package token
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+const (
+	tokenPrefix string = "ab_"
+	tokenBytes  int    = 32
+	prefixLen   int    = 8
+	maxTokens   int    = 10
+)
+
+var ErrTooManyTokens = er.New("the tokens limit is reached", "", nil)
+
+type Repository interface {
+	Save(ctx context.Context, t *Token) (int, error)
+	FindByHash(ctx context.Context, hash string) (*Token, error)
+	All(ctx context.Context, userID int64) ([]*Token, error)
+	Revoke(ctx context.Context, t *Token) (int, error)
+}
+
+type service struct {
+	log  *logger.Logger
+	repo Repository
+}
+
+func NewService(ctx context.Context, log *logger.Logger, repo Repository) *service {
+	return &service{log: log, repo: repo}
+}
+
+// Issue creates a new token for the user. Only the hash of the token is
+// stored, so the returned plain token can't be shown again. The user has
+// at most maxTokens active tokens.
+func (s *service) Issue(ctx context.Context, userID int64) (string, error) {
+	ctx, span := tracing.Start(ctx, "token.service.Issue")
+	defer span.End()
+
+	const op string = "token.service.Issue"
+
+	active, err := s.repo.All(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if len(active) >= maxTokens {
+		return "", ErrTooManyTokens
+	}
+
+	raw := make([]byte, tokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", er.New("unable to generate token", op, err)
+	}
+	plain := tokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	if _, err := s.repo.Save(ctx, &Token{
+		UserID: userID,
+		Hash:   Hash(plain),
+		Prefix: plain[:len(tokenPrefix)+prefixLen],
+	}); err != nil {
+		return "", err
+	}
+
+	return plain, nil
+}
+
+// Authenticate returns the active token matching the plain token.
+func (s *service) Authenticate(ctx context.Context, plain string) (*Token, error) {
+	ctx, span := tracing.Start(ctx, "token.service.Authenticate")
+	defer span.End()
+
+	return s.repo.FindByHash(ctx, Hash(plain))
+}
+
+func (s *service) All(ctx context.Context, userID int64) ([]*Token, error) {
+	ctx, span := tracing.Start(ctx, "token.service.All")
+	defer span.End()
+
+	return s.repo.All(ctx, userID)
+}
+
+// Revoke revokes the token with the id, id 0 revokes all tokens of the user.
+func (s *service) Revoke(ctx context.Context, userID int64, id int) (int, error) {
+	ctx, span := tracing.Start(ctx, "token.service.Revoke")
+	defer span.End()
+
+	log := logger.L(ctx).With(logger.String("operation", "token.service.Revoke"))
+
+	count, err := s.repo.Revoke(ctx, &Token{ID: id, UserID: userID})
+	if err != nil {
+		log.Error("failed to revoke token", logger.ErrAttr(err))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Hash returns the hex encoded SHA-256 of the token.
+func Hash(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
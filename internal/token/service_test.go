@@ -0,0 +1,93 @@
+package token
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRepository struct {
+	tokens []*Token
+}
+
+func (repo *testRepository) Save(ctx context.Context, t *Token) (int, error) {
+	t.ID = len(repo.tokens) + 1
+	repo.tokens = append(repo.tokens, t)
+	return t.ID, nil
+}
+
+func (repo *testRepository) FindByHash(ctx context.Context, hash string) (*Token, error) {
+	for _, t := range repo.tokens {
+		if t.Hash == hash {
+			return t, nil
+		}
+	}
+	return nil, ErrNoToken
+}
+
+func (repo *testRepository) All(ctx context.Context, userID int64) ([]*Token, error) {
+	res := []*Token{}
+	for _, t := range repo.tokens {
+		if t.UserID == userID {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
+
+func (repo *testRepository) Revoke(ctx context.Context, t *Token) (int, error) {
+	kept := repo.tokens[:0]
+	count := 0
+	for _, stored := range repo.tokens {
+		if stored.UserID == t.UserID && (t.ID == 0 || stored.ID == t.ID) {
+			count++
+			continue
+		}
+		kept = append(kept, stored)
+	}
+	repo.tokens = kept
+	return count, nil
+}
+
+func TestIssue(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := NewService(ctx, nil, &testRepository{})
+
+	plain, err := s.Issue(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(plain, tokenPrefix))
+
+	found, err := s.Authenticate(ctx, plain)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), found.UserID)
+	assert.Equal(t, plain[:len(tokenPrefix)+prefixLen], found.Prefix)
+	assert.NotContains(t, found.Hash, plain)
+
+	_, err = s.Authenticate(ctx, plain+"x")
+	assert.Equal(t, ErrNoToken, err)
+}
+
+func TestIssueLimit(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := NewService(ctx, nil, &testRepository{})
+
+	for range maxTokens {
+		_, err := s.Issue(ctx, 1)
+		require.NoError(t, err)
+	}
+	_, err := s.Issue(ctx, 1)
+	assert.Equal(t, ErrTooManyTokens, err)
+
+	// the limit is per user and the revoked tokens don't count
+	_, err = s.Issue(ctx, 2)
+	assert.NoError(t, err)
+	_, err = s.Revoke(ctx, 1, 1)
+	require.NoError(t, err)
+	_, err = s.Issue(ctx, 1)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,35 @@
+package transcription
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// Fake is the transcriber for the tests. It reads the audio and returns
+// Text or Err, Audio is the last audio read.
+type Fake struct {
+	Text string
+	Err  error
+
+	mu    sync.Mutex
+	Audio []byte
+	Calls int
+}
+
+func (f *Fake) Transcribe(ctx context.Context, audio io.Reader, name string) (string, error) {
+	data, err := io.ReadAll(audio)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.Audio = data
+	f.Calls++
+	if f.Err != nil {
+		return "", f.Err
+	}
+	return f.Text, nil
+}
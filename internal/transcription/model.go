@@ -0,0 +1,35 @@
+package transcription
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Job transcribes the voice or the audio of the note. A failed attempt is
+// retried later, the job is marked failed after the last attempt and the
+// note stays without a transcript.
+type Job struct {
+	ID        int64
+	TextsID   int
+	FileID    string
+	Attempts  int
+	LastError string
+}
+
+func (j *Job) String() string {
+	b := &strings.Builder{}
+
+	b.WriteString("Job{ID: ")
+	b.WriteString(strconv.FormatInt(j.ID, 10))
+	b.WriteString(", TextsID: ")
+	b.WriteString(strconv.Itoa(j.TextsID))
+	b.WriteString(", FileID: ")
+	b.WriteString(j.FileID)
+	b.WriteString(", Attempts: ")
+	b.WriteString(strconv.Itoa(j.Attempts))
+	b.WriteString(", LastError: ")
+	b.WriteString(j.LastError)
+	b.WriteRune('}')
+
+	return b.String()
+}
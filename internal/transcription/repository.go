@@ -0,0 +1,145 @@
+package transcription
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lease is how long a claimed job is hidden from the other instances,
+// the job is retried after it when the instance stops in the middle.
+const lease string = "15 minutes"
+
+var (
+	instance *pgRepository
+	once     sync.Once
+)
+
+type pgRepository struct {
+	log *logger.Logger
+	db  *pgxpool.Pool
+}
+
+// NewRepository creates new transcription repository.
+func NewRepository(ctx context.Context, log *logger.Logger, db *pgxpool.Pool) (*pgRepository, error) {
+	once.Do(func() {
+		instance = &pgRepository{log: log, db: db}
+	})
+
+	return instance, nil
+}
+
+// Enqueue adds the job for the note, a note has at most one job.
+func (repo *pgRepository) Enqueue(ctx context.Context, textsID int, fileID string) error {
+	const op string = "transcription.repository.Enqueue"
+
+	if _, err := repo.db.Exec(ctx,
+		`INSERT INTO transcriptions (texts_id, file_id)
+		VALUES ($1, $2)
+		ON CONFLICT (texts_id) DO NOTHING;`,
+		textsID, fileID); err != nil {
+		return er.New("unable to enqueue transcription", op, err)
+	}
+
+	return nil
+}
+
+// Claim returns the due jobs and postpones them by the lease, so the other
+// instances skip them.
+func (repo *pgRepository) Claim(ctx context.Context, limit int) ([]*Job, error) {
+	const op string = "transcription.repository.Claim"
+
+	rows, err := repo.db.Query(ctx,
+		`UPDATE transcriptions SET next_attempt_at = CURRENT_TIMESTAMP + $2::interval
+		WHERE id IN (
+			SELECT id FROM transcriptions
+			WHERE NOT failed AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, texts_id, file_id, attempts, last_error;`,
+		limit, lease)
+	if err != nil {
+		return nil, er.New("unable to claim transcriptions", op, err)
+	}
+	defer rows.Close()
+
+	jobs := []*Job{}
+	for rows.Next() {
+		var j Job
+		if err := rows.Scan(&j.ID, &j.TextsID, &j.FileID, &j.Attempts, &j.LastError); err != nil {
+			return nil, er.New("unable to scan data", op, err)
+		}
+		jobs = append(jobs, &j)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, er.New("error in rows", op, err)
+	}
+
+	return jobs, nil
+}
+
+// Done saves the transcript on the note and removes the job.
+func (repo *pgRepository) Done(ctx context.Context, j *Job, transcript string) error {
+	const op string = "transcription.repository.Done"
+
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return er.New("unable to begin transaction", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE texts SET transcript = $2 WHERE id = $1;`,
+		j.TextsID, transcript); err != nil {
+		return er.New("unable to save transcript", op, err)
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM transcriptions WHERE id = $1;`, j.ID); err != nil {
+		return er.New("unable to remove transcription", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return er.New("unable to commit transaction", op, err)
+	}
+
+	return nil
+}
+
+// Retry saves the failed attempt, the job is due again after the delay.
+func (repo *pgRepository) Retry(ctx context.Context, j *Job, delay time.Duration) error {
+	const op string = "transcription.repository.Retry"
+
+	if _, err := repo.db.Exec(ctx,
+		`UPDATE transcriptions
+		SET attempts = $2, last_error = $3,
+			next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $4)
+		WHERE id = $1;`,
+		j.ID, j.Attempts, j.LastError, delay.Seconds()); err != nil {
+		return er.New("unable to retry transcription", op, err)
+	}
+
+	return nil
+}
+
+// Fail saves the last attempt, the job is not run again.
+func (repo *pgRepository) Fail(ctx context.Context, j *Job) error {
+	const op string = "transcription.repository.Fail"
+
+	if _, err := repo.db.Exec(ctx,
+		`UPDATE transcriptions
+		SET attempts = $2, last_error = $3, failed = TRUE
+		WHERE id = $1;`,
+		j.ID, j.Attempts, j.LastError); err != nil {
+		return er.New("unable to fail transcription", op, err)
+	}
+
+	return nil
+}
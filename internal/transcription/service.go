@@ -0,0 +1,249 @@
+package transcription
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"archive_bot/internal/metrics"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	batchSize          int           = 10
+	defaultMaxAttempts int           = 5
+	pollInterval       time.Duration = time.Minute
+	firstRetry         time.Duration = time.Minute
+	maxRetry           time.Duration = 6 * time.Hour
+	// MaxFileSize is the largest file the Bot API lets the bot download.
+	MaxFileSize int64 = 20 << 20
+)
+
+// Transcriber turns the speech of the audio into text, name is the name
+// of the file the format is guessed by.
+type Transcriber interface {
+	Transcribe(ctx context.Context, audio io.Reader, name string) (string, error)
+}
+
+type Repository interface {
+	Enqueue(ctx context.Context, textsID int, fileID string) error
+	Claim(ctx context.Context, limit int) ([]*Job, error)
+	Done(ctx context.Context, j *Job, transcript string) error
+	Retry(ctx context.Context, j *Job, delay time.Duration) error
+	Fail(ctx context.Context, j *Job) error
+}
+
+// API is the part of the Bot API used to download the files.
+type API interface {
+	GetFile(ctx context.Context, params *bot.GetFileParams) (*models.File, error)
+	FileDownloadLink(f *models.File) string
+}
+
+// service runs the transcription jobs. The jobs are stored, so the notes
+// saved while the backend is down or the bot is stopped are transcribed
+// later, a failed job is retried with a growing delay up to maxAttempts.
+type service struct {
+	log         *logger.Logger
+	repo        Repository
+	transcriber Transcriber
+	maxAttempts int
+	client      *http.Client
+	wake        chan struct{}
+	running     sync.WaitGroup
+}
+
+// NewService creates the transcription service, a nil transcriber
+// disables it.
+func NewService(
+	ctx context.Context, log *logger.Logger, repo Repository, transcriber Transcriber, maxAttempts int,
+) *service {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	return &service{
+		log:         log,
+		repo:        repo,
+		transcriber: transcriber,
+		maxAttempts: maxAttempts,
+		client:      &http.Client{Timeout: defaultTimeout},
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Enabled reports whether a speech-to-text backend is configured.
+func (s *service) Enabled() bool {
+	return s.transcriber != nil
+}
+
+// Enqueue adds the voice or the audio of the note to the queue. The files
+// the bot can't download are skipped.
+func (s *service) Enqueue(ctx context.Context, textsID int, fileID string, size int64) error {
+	ctx, span := tracing.Start(ctx, "transcription.service.Enqueue")
+	defer span.End()
+
+	if !s.Enabled() || size > MaxFileSize {
+		return nil
+	}
+
+	if err := s.repo.Enqueue(ctx, textsID, fileID); err != nil {
+		return err
+	}
+	metrics.Transcription("queued")
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Start runs the due jobs until ctx is done.
+func (s *service) Start(ctx context.Context, api API) {
+	if !s.Enabled() {
+		return
+	}
+
+	for {
+		s.process(ctx, api)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Wait waits for the job stopped by the end of Start to save its attempt.
+func (s *service) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// process runs the due jobs one by one until there are none left.
+func (s *service) process(ctx context.Context, api API) {
+	log := s.log.With(logger.String("operation", "transcription.service.process"))
+
+	for ctx.Err() == nil {
+		jobs, err := s.repo.Claim(ctx, batchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error("failed to claim transcriptions", logger.ErrAttr(err))
+			}
+			return
+		}
+		if len(jobs) == 0 {
+			return
+		}
+
+		for _, j := range jobs {
+			s.running.Add(1)
+			s.run(ctx, api, j)
+			s.running.Done()
+		}
+	}
+}
+
+// run transcribes the note and saves the result of the attempt.
+func (s *service) run(ctx context.Context, api API, j *Job) {
+	ctx, span := tracing.Start(ctx, "transcription.service.run")
+	defer span.End()
+
+	log := logger.L(ctx).With(
+		logger.String("operation", "transcription.service.run"),
+		logger.Int("texts_id", j.TextsID),
+	)
+
+	transcript, err := s.transcribe(ctx, api, j.FileID)
+	if err != nil && ctx.Err() != nil {
+		// the lease runs out and the job is run again after the restart
+		return
+	}
+	// the shutdown should not lose the result of the attempt
+	ctx = context.WithoutCancel(ctx)
+
+	if err == nil {
+		if err := s.repo.Done(ctx, j, transcript); err != nil {
+			log.Error("failed to save transcript", logger.ErrAttr(err))
+			return
+		}
+		metrics.Transcription("done")
+		return
+	}
+
+	tracing.Error(span, err)
+	j.Attempts++
+	j.LastError = err.Error()
+	if j.Attempts >= s.maxAttempts {
+		log.Warn("transcription failed", logger.String("job", j.String()))
+		if err := s.repo.Fail(ctx, j); err != nil {
+			log.Error("failed to save transcription", logger.ErrAttr(err))
+		}
+		metrics.Transcription("failed")
+		return
+	}
+
+	log.Info("transcription will be retried", logger.String("job", j.String()))
+	if err := s.repo.Retry(ctx, j, retryDelay(j.Attempts)); err != nil {
+		log.Error("failed to save transcription", logger.ErrAttr(err))
+	}
+	metrics.Transcription("retried")
+}
+
+// transcribe downloads the file and passes it to the transcriber.
+func (s *service) transcribe(ctx context.Context, api API, fileID string) (string, error) {
+	const op string = "transcription.service.transcribe"
+
+	file, err := api.GetFile(ctx, &bot.GetFileParams{FileID: fileID})
+	metrics.TelegramRequest("GetFile", err)
+	if err != nil {
+		return "", er.New("unable to get file", op, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.FileDownloadLink(file), nil)
+	if err != nil {
+		return "", er.New("unable to create request", op, err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", er.New("unable to download file", op, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", er.New("file download answered "+strconv.Itoa(resp.StatusCode), op, nil)
+	}
+
+	return s.transcriber.Transcribe(ctx, io.LimitReader(resp.Body, MaxFileSize), path.Base(file.FilePath))
+}
+
+// retryDelay doubles the delay after every failed attempt up to maxRetry.
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetry
+	for i := 1; i < attempts && delay < maxRetry; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetry)
+}
@@ -0,0 +1,154 @@
+package transcription
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	Repository
+	done       string
+	retryDelay time.Duration
+	failed     bool
+}
+
+func (r *fakeRepo) Done(ctx context.Context, j *Job, transcript string) error {
+	r.done = transcript
+	return nil
+}
+
+func (r *fakeRepo) Retry(ctx context.Context, j *Job, delay time.Duration) error {
+	r.retryDelay = delay
+	return nil
+}
+
+func (r *fakeRepo) Fail(ctx context.Context, j *Job) error {
+	r.failed = true
+	return nil
+}
+
+type fakeAPI struct {
+	url string
+}
+
+func (a *fakeAPI) GetFile(ctx context.Context, params *bot.GetFileParams) (*models.File, error) {
+	return &models.File{FileID: params.FileID, FilePath: "voice/" + params.FileID}, nil
+}
+
+func (a *fakeAPI) FileDownloadLink(f *models.File) string {
+	return a.url + "/" + f.FilePath
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/voice/ok.oga" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, "audio")
+	}))
+	t.Cleanup(files.Close)
+	log := logger.NewLogger(logger.WithWriter(io.Discard), logger.WithSetDefault(false))
+
+	testCases := []struct {
+		title     string
+		fileID    string
+		attempts  int
+		err       error
+		done      string
+		retry     time.Duration
+		failed    bool
+		attempted int
+	}{
+		{"done", "ok.oga", 0, nil, "hello", 0, false, 0},
+		{"retried", "ok.oga", 0, errors.New("backend is down"), "", time.Minute, false, 1},
+		{"retried later", "ok.oga", 2, errors.New("backend is down"), "", 4 * time.Minute, false, 3},
+		{"failed", "ok.oga", 2, errors.New("backend is down"), "", 0, true, 3},
+		{"not downloaded", "gone.oga", 0, nil, "", time.Minute, false, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			repo := &fakeRepo{}
+			fake := &Fake{Text: "hello", Err: tc.err}
+			maxAttempts := 5
+			if tc.failed {
+				maxAttempts = 3
+			}
+			s := NewService(context.Background(), log, repo, fake, maxAttempts)
+			j := &Job{ID: 1, TextsID: 2, FileID: tc.fileID, Attempts: tc.attempts}
+
+			s.run(context.Background(), &fakeAPI{url: files.URL}, j)
+
+			assert.Equal(t, tc.done, repo.done)
+			assert.Equal(t, tc.retry, repo.retryDelay)
+			assert.Equal(t, tc.failed, repo.failed)
+			if tc.done == "" {
+				assert.Equal(t, tc.attempted, j.Attempts)
+				assert.NotEmpty(t, j.LastError)
+			}
+			if tc.fileID == "ok.oga" {
+				assert.Equal(t, "audio", string(fake.Audio))
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{5, 16 * time.Minute},
+		{20, maxRetry},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, retryDelay(tc.attempts), tc.attempts)
+	}
+}
+
+func TestWhisper(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("language") == "xx" {
+			http.Error(w, "unknown language", http.StatusBadRequest)
+			return
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		audio, _ := io.ReadAll(file)
+		io.WriteString(w, `{"text": " `+header.Filename+` `+string(audio)+` `+r.FormValue("response_format")+`\n"}`)
+	}))
+	t.Cleanup(server.Close)
+
+	text, err := NewWhisper(server.URL, "small", "en", time.Second).
+		Transcribe(context.Background(), strings.NewReader("audio"), "file_1.oga")
+	require.NoError(t, err)
+	assert.Equal(t, "file_1.oga audio json", text)
+
+	_, err = NewWhisper(server.URL, "", "xx", time.Second).
+		Transcribe(context.Background(), strings.NewReader("audio"), "file_1.oga")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown language")
+}
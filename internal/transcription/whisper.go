@@ -0,0 +1,119 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/tracing"
+)
+
+const (
+	defaultTimeout time.Duration = 2 * time.Minute
+	// maxErrorBody bounds the part of the error response kept in the job.
+	maxErrorBody int64 = 512
+)
+
+// whisper sends the audio to a whisper HTTP server: the /inference endpoint
+// of whisper.cpp or an OpenAI compatible /v1/audio/transcriptions one like
+// faster-whisper-server. Both take the multipart "file" and answer
+// {"text": "..."} with response_format=json.
+type whisper struct {
+	url      string
+	model    string
+	language string
+	client   *http.Client
+}
+
+// NewWhisper creates the transcriber calling the server at url. The model
+// is sent to the OpenAI compatible servers, whisper.cpp ignores it, the
+// empty language is detected by the server.
+func NewWhisper(url string, model string, language string, timeout time.Duration) *whisper {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &whisper{
+		url:      url,
+		model:    model,
+		language: language,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+func (w *whisper) Transcribe(ctx context.Context, audio io.Reader, name string) (string, error) {
+	const op string = "transcription.whisper.Transcribe"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	body, contentType := w.form(audio, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, body)
+	if err != nil {
+		body.Close()
+		return "", er.New("unable to create request", op, err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		tracing.Error(span, err)
+		return "", er.New("unable to call whisper", op, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return "", er.New(
+			"whisper answered "+strconv.Itoa(resp.StatusCode)+": "+strings.TrimSpace(string(text)), op, nil,
+		)
+	}
+
+	var res struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", er.New("unable to decode whisper response", op, err)
+	}
+
+	return strings.TrimSpace(res.Text), nil
+}
+
+// form streams the multipart body, so the audio is not kept in memory.
+func (w *whisper) form(audio io.Reader, name string) (*io.PipeReader, string) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	go func() {
+		fields := [][2]string{{"response_format", "json"}}
+		if w.model != "" {
+			fields = append(fields, [2]string{"model", w.model})
+		}
+		if w.language != "" {
+			fields = append(fields, [2]string{"language", w.language})
+		}
+		for _, f := range fields {
+			if err := mw.WriteField(f[0], f[1]); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+
+		part, err := mw.CreateFormFile("file", name)
+		if err == nil {
+			_, err = io.Copy(part, audio)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return pr, mw.FormDataContentType()
+}
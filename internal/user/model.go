@@ -1,23 +1,88 @@
-package user
-
-import (
-	"strconv"
-	"strings"
-)
-
-type User struct {
-	ID       int64
-	Username string
-}
-
-func (u *User) String() string {
-	b := &strings.Builder{}
-
-	b.WriteString("User{ID: ")
-	b.WriteString(strconv.FormatInt(u.ID, 10))
-	b.WriteString(", Username: ")
-	b.WriteString(u.Username)
-	b.WriteRune('}')
-
-	return b.String()
-}
+package user
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type User struct {
+	ID       int64
+	Username string
+}
+
+func (u *User) String() string {
+	b := &strings.Builder{}
+
+	b.WriteString("User{ID: ")
+	b.WriteString(strconv.FormatInt(u.ID, 10))
+	b.WriteString(", Username: ")
+	b.WriteString(u.Username)
+	b.WriteRune('}')
+
+	return b.String()
+}
+
+// Limits limit the archive of the user, zero is no limit.
+type Limits struct {
+	NotesPerDay   int
+	MaxNotes      int
+	MaxFolders    int
+	MaxMediaBytes int64
+}
+
+// IsZero reports whether nothing is limited.
+func (l Limits) IsZero() bool {
+	return l == Limits{}
+}
+
+// Quota overrides the limits for the user, nil keeps the configured limit.
+type Quota struct {
+	NotesPerDay   *int
+	MaxNotes      *int
+	MaxFolders    *int
+	MaxMediaBytes *int64
+}
+
+// Apply returns the limits with the overrides of the quota.
+func (q *Quota) Apply(l Limits) Limits {
+	if q.NotesPerDay != nil {
+		l.NotesPerDay = *q.NotesPerDay
+	}
+	if q.MaxNotes != nil {
+		l.MaxNotes = *q.MaxNotes
+	}
+	if q.MaxFolders != nil {
+		l.MaxFolders = *q.MaxFolders
+	}
+	if q.MaxMediaBytes != nil {
+		l.MaxMediaBytes = *q.MaxMediaBytes
+	}
+
+	return l
+}
+
+// Usage is what the user keeps in the archive.
+type Usage struct {
+	NotesToday int
+	Notes      int
+	Folders    int
+	MediaBytes int64
+}
+
+// Profile is the user as the admins see it. Limits are the limits of the
+// user with the overrides of Quota applied.
+type Profile struct {
+	User
+	Usage
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	BannedAt   time.Time
+	BanReason  string
+	Quota      Quota
+	Limits     Limits
+}
+
+func (p *Profile) IsBanned() bool {
+	return !p.BannedAt.IsZero()
+}
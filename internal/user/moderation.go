@@ -0,0 +1,192 @@
+package user
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"archive_bot/pkg/er"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Profile returns the user found by ID or by username with the @ or without it.
+func (repo *pgRepository) Profile(ctx context.Context, id int64, username string) (*Profile, error) {
+	const op string = "user.repository.Profile"
+
+	var (
+		p          Profile
+		lastSeenAt *time.Time
+		bannedAt   *time.Time
+	)
+	if err := repo.db.QueryRow(ctx,
+		`SELECT u.id, COALESCE(u.username, ''), u.created_at, u.last_seen_at,
+		u.banned_at, u.ban_reason,
+		q.notes_per_day, q.max_notes, q.max_folders, q.max_media_bytes
+		FROM users u
+		LEFT JOIN user_quotas q ON q.user_id = u.id
+		WHERE u.id = $1 OR ($2 <> '' AND lower(u.username) = lower($2))
+		LIMIT 1;`, id, strings.TrimPrefix(username, "@")).Scan(
+		&p.ID, &p.Username, &p.CreatedAt, &lastSeenAt,
+		&bannedAt, &p.BanReason,
+		&p.Quota.NotesPerDay, &p.Quota.MaxNotes, &p.Quota.MaxFolders, &p.Quota.MaxMediaBytes,
+	); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, er.New("unable to get user profile", op, err)
+	}
+	if lastSeenAt != nil {
+		p.LastSeenAt = *lastSeenAt
+	}
+	if bannedAt != nil {
+		p.BannedAt = *bannedAt
+	}
+
+	usage, err := repo.Usage(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	p.Usage = *usage
+
+	return &p, nil
+}
+
+func (repo *pgRepository) IsBanned(ctx context.Context, id int64) (bool, error) {
+	const op string = "user.repository.IsBanned"
+
+	var banned bool
+	if err := repo.db.QueryRow(ctx,
+		`SELECT banned_at IS NOT NULL FROM users
+		WHERE id = $1;`, id).Scan(&banned); err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, er.New("unable to check ban", op, err)
+	}
+
+	return banned, nil
+}
+
+// SetBanned bans the user with the reason or lifts the ban.
+func (repo *pgRepository) SetBanned(ctx context.Context, id int64, banned bool, reason string) error {
+	const op string = "user.repository.SetBanned"
+
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE users
+		SET banned_at = CASE WHEN $2 THEN COALESCE(banned_at, CURRENT_TIMESTAMP) END,
+		ban_reason = $3
+		WHERE id = $1;`, id, banned, reason)
+	if err != nil {
+		return er.New("unable to set ban", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// Quota returns the overrides of the limits, the user without them gets
+// an empty quota.
+func (repo *pgRepository) Quota(ctx context.Context, id int64) (*Quota, error) {
+	const op string = "user.repository.Quota"
+
+	var q Quota
+	if err := repo.db.QueryRow(ctx,
+		`SELECT notes_per_day, max_notes, max_folders, max_media_bytes
+		FROM user_quotas WHERE user_id = $1;`, id).Scan(
+		&q.NotesPerDay, &q.MaxNotes, &q.MaxFolders, &q.MaxMediaBytes,
+	); err != nil {
+		if err == pgx.ErrNoRows {
+			return &q, nil
+		}
+		return nil, er.New("unable to get quota", op, err)
+	}
+
+	return &q, nil
+}
+
+// SetQuota replaces the overrides of the user.
+func (repo *pgRepository) SetQuota(ctx context.Context, id int64, q *Quota) error {
+	const op string = "user.repository.SetQuota"
+
+	tag, err := repo.db.Exec(ctx,
+		`INSERT INTO user_quotas (user_id, notes_per_day, max_notes, max_folders, max_media_bytes)
+		SELECT id, $2, $3, $4, $5 FROM users WHERE id = $1
+		ON CONFLICT (user_id) DO UPDATE
+		SET notes_per_day = $2, max_notes = $3, max_folders = $4, max_media_bytes = $5,
+		updated_at = CURRENT_TIMESTAMP;`,
+		id, q.NotesPerDay, q.MaxNotes, q.MaxFolders, q.MaxMediaBytes)
+	if err != nil {
+		return er.New("unable to set quota", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// Usage counts the notes of the last day and all the notes, folders and
+// media bytes of the user.
+func (repo *pgRepository) Usage(ctx context.Context, id int64) (*Usage, error) {
+	const op string = "user.repository.Usage"
+
+	var u Usage
+	if err := repo.db.QueryRow(ctx,
+		`SELECT COUNT(*) FILTER (WHERE created_at > CURRENT_TIMESTAMP - INTERVAL '1 day'),
+		COUNT(*), COALESCE(SUM(media_size), 0),
+		(SELECT COUNT(*) FROM folders WHERE user_id = $1)
+		FROM texts WHERE user_id = $1;`, id).Scan(
+		&u.NotesToday, &u.Notes, &u.MediaBytes, &u.Folders,
+	); err != nil {
+		return nil, er.New("unable to count usage", op, err)
+	}
+
+	return &u, nil
+}
+
+// SetMediaSize records the size of the media of the note.
+func (repo *pgRepository) SetMediaSize(ctx context.Context, noteID int, size int64) error {
+	const op string = "user.repository.SetMediaSize"
+
+	if _, err := repo.db.Exec(ctx,
+		`UPDATE texts SET media_size = $2 WHERE id = $1;`,
+		noteID, size); err != nil {
+		return er.New("unable to set media size", op, err)
+	}
+
+	return nil
+}
+
+// Wipe removes the archive of the user: the notes with their media, the
+// folders, the filing rules, the linked channels and the API tokens. The
+// user itself is kept with the ban and the quota.
+func (repo *pgRepository) Wipe(ctx context.Context, id int64) error {
+	const op string = "user.repository.Wipe"
+
+	tx, err := repo.db.Begin(ctx)
+	if err != nil {
+		return er.New("unable to begin transaction", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, query := range []string{
+		`DELETE FROM api_tokens WHERE user_id = $1;`,
+		`DELETE FROM channels WHERE user_id = $1;`,
+		`DELETE FROM rules WHERE user_id = $1;`,
+		`DELETE FROM texts WHERE user_id = $1;`,
+		`DELETE FROM folders WHERE user_id = $1;`,
+	} {
+		if _, err := tx.Exec(ctx, query, id); err != nil {
+			return er.New("unable to wipe user", op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return er.New("unable to commit wipe", op, err)
+	}
+
+	return nil
+}
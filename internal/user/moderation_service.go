@@ -0,0 +1,227 @@
+package user
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+// banTTL is how long a ban is cached.
+const banTTL time.Duration = 5 * time.Minute
+
+var (
+	ErrQuotaNotes   error = er.New("the notes quota is exceeded", "", nil)
+	ErrQuotaDaily   error = er.New("the daily notes quota is exceeded", "", nil)
+	ErrQuotaMedia   error = er.New("the media quota is exceeded", "", nil)
+	ErrQuotaFolders error = er.New("the folders quota is exceeded", "", nil)
+)
+
+// Profile finds the user by the ID or the username.
+func (s *service) Profile(ctx context.Context, query string) (*Profile, error) {
+	ctx, span := tracing.Start(ctx, "user.service.Profile")
+	defer span.End()
+
+	query = strings.TrimSpace(query)
+	var (
+		p   *Profile
+		err error
+	)
+	if id, parseErr := strconv.ParseInt(query, 10, 64); parseErr == nil {
+		p, err = s.repo.Profile(ctx, id, "")
+	} else {
+		p, err = s.repo.Profile(ctx, 0, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.Limits = p.Quota.Apply(s.Limits())
+	return p, nil
+}
+
+// IsBanned is checked on every update. The bans are cached for banTTL,
+// so the unban made by another instance applies within it. The user is
+// let in when the check fails.
+func (s *service) IsBanned(ctx context.Context, userID int64) bool {
+	if s.cachedBan(userID, time.Now()) {
+		return true
+	}
+
+	ctx, span := tracing.Start(ctx, "user.service.IsBanned")
+	defer span.End()
+
+	banned, err := s.repo.IsBanned(ctx, userID)
+	if err != nil {
+		logger.L(ctx).Error("failed to check ban", logger.ErrAttr(err))
+		return false
+	}
+	if banned {
+		s.cacheBan(userID, time.Now())
+	}
+
+	return banned
+}
+
+// cachedBan reports whether the user has an unexpired cached ban, the
+// expired one is dropped.
+func (s *service) cachedBan(userID int64, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until, ok := s.banned[userID]
+	if ok && !now.Before(until) {
+		delete(s.banned, userID)
+		return false
+	}
+
+	return ok
+}
+
+func (s *service) cacheBan(userID int64, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.banned[userID] = now.Add(banTTL)
+}
+
+func (s *service) Ban(ctx context.Context, userID int64, reason string) error {
+	ctx, span := tracing.Start(ctx, "user.service.Ban")
+	defer span.End()
+
+	return s.setBanned(ctx, userID, true, reason)
+}
+
+func (s *service) Unban(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "user.service.Unban")
+	defer span.End()
+
+	return s.setBanned(ctx, userID, false, "")
+}
+
+func (s *service) setBanned(ctx context.Context, userID int64, banned bool, reason string) error {
+	if err := s.repo.SetBanned(ctx, userID, banned, reason); err != nil {
+		return err
+	}
+
+	if banned {
+		s.cacheBan(userID, time.Now())
+		return nil
+	}
+
+	s.mu.Lock()
+	delete(s.banned, userID)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// SetQuota replaces the overrides of the limits of the user.
+func (s *service) SetQuota(ctx context.Context, userID int64, q *Quota) error {
+	ctx, span := tracing.Start(ctx, "user.service.SetQuota")
+	defer span.End()
+
+	return s.repo.SetQuota(ctx, userID, q)
+}
+
+// Limits returns the limits of the users without a quota.
+func (s *service) Limits() Limits {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.limits
+}
+
+// SetLimits replaces the limits of the users without a quota, it is used
+// on config reload.
+func (s *service) SetLimits(l Limits) {
+	s.mu.Lock()
+	s.limits = l
+	s.mu.Unlock()
+}
+
+// CheckNote returns the quota error when the user can't save one more
+// note with size bytes of media.
+func (s *service) CheckNote(ctx context.Context, userID int64, size int64) error {
+	ctx, span := tracing.Start(ctx, "user.service.CheckNote")
+	defer span.End()
+
+	return s.CheckNotes(ctx, userID, 1, size)
+}
+
+// CheckNotes returns the quota error when the user can't save count more
+// notes with size bytes of media in total.
+func (s *service) CheckNotes(ctx context.Context, userID int64, count int, size int64) error {
+	ctx, span := tracing.Start(ctx, "user.service.CheckNotes")
+	defer span.End()
+
+	return s.check(ctx, userID, func(l Limits, u *Usage) error {
+		return noteExceeded(l, u, count, size)
+	})
+}
+
+// CheckFolders returns ErrQuotaFolders when the user can't create one more folder.
+func (s *service) CheckFolders(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "user.service.CheckFolders")
+	defer span.End()
+
+	return s.check(ctx, userID, func(l Limits, u *Usage) error {
+		if l.MaxFolders > 0 && u.Folders >= l.MaxFolders {
+			return ErrQuotaFolders
+		}
+		return nil
+	})
+}
+
+// SetMediaSize records the media size of the saved note, it counts
+// towards the media limit.
+func (s *service) SetMediaSize(ctx context.Context, noteID int, size int64) {
+	ctx, span := tracing.Start(ctx, "user.service.SetMediaSize")
+	defer span.End()
+
+	if err := s.repo.SetMediaSize(ctx, noteID, size); err != nil {
+		logger.L(ctx).Error("failed to set media size", logger.ErrAttr(err))
+	}
+}
+
+// check counts the usage only for the users with limits.
+func (s *service) check(ctx context.Context, userID int64, exceeded func(l Limits, u *Usage) error) error {
+	q, err := s.repo.Quota(ctx, userID)
+	if err != nil {
+		return err
+	}
+	limits := q.Apply(s.Limits())
+	if limits.IsZero() {
+		return nil
+	}
+
+	usage, err := s.repo.Usage(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return exceeded(limits, usage)
+}
+
+func noteExceeded(l Limits, u *Usage, count int, size int64) error {
+	switch {
+	case l.MaxNotes > 0 && u.Notes+count > l.MaxNotes:
+		return ErrQuotaNotes
+	case l.NotesPerDay > 0 && u.NotesToday+count > l.NotesPerDay:
+		return ErrQuotaDaily
+	case l.MaxMediaBytes > 0 && size > 0 && u.MediaBytes+size > l.MaxMediaBytes:
+		return ErrQuotaMedia
+	default:
+		return nil
+	}
+}
+
+func (s *service) Wipe(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "user.service.Wipe")
+	defer span.End()
+
+	return s.repo.Wipe(ctx, userID)
+}
@@ -0,0 +1,63 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaApply(t *testing.T) {
+	t.Parallel()
+	zero, notes := 0, 10
+	defaults := Limits{NotesPerDay: 100, MaxNotes: 1000, MaxFolders: 20, MaxMediaBytes: 1 << 30}
+
+	assert.Equal(t, defaults, (&Quota{}).Apply(defaults))
+	assert.Equal(t,
+		Limits{NotesPerDay: 0, MaxNotes: 10, MaxFolders: 20, MaxMediaBytes: 1 << 30},
+		(&Quota{NotesPerDay: &zero, MaxNotes: &notes}).Apply(defaults),
+	)
+}
+
+func TestNoteExceeded(t *testing.T) {
+	t.Parallel()
+	limits := Limits{NotesPerDay: 5, MaxNotes: 10, MaxMediaBytes: 100}
+
+	testCases := []struct {
+		title  string
+		limits Limits
+		usage  Usage
+		count  int
+		size   int64
+		want   error
+	}{
+		{"within limits", limits, Usage{NotesToday: 4, Notes: 9, MediaBytes: 50}, 1, 50, nil},
+		{"total", limits, Usage{NotesToday: 1, Notes: 10}, 1, 0, ErrQuotaNotes},
+		{"daily", limits, Usage{NotesToday: 5, Notes: 5}, 1, 0, ErrQuotaDaily},
+		{"media", limits, Usage{MediaBytes: 60}, 1, 50, ErrQuotaMedia},
+		{"text over media", limits, Usage{MediaBytes: 200}, 1, 0, nil},
+		{"copy over total", limits, Usage{Notes: 6}, 5, 0, ErrQuotaNotes},
+		{"copy over daily", limits, Usage{NotesToday: 1, Notes: 1}, 5, 0, ErrQuotaDaily},
+		{"copy within limits", limits, Usage{NotesToday: 1, Notes: 5}, 4, 100, nil},
+		{"no limits", Limits{}, Usage{NotesToday: 100, Notes: 100, MediaBytes: 100}, 1, 100, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			assert.Equal(t, tc.want, noteExceeded(tc.limits, &tc.usage, tc.count, tc.size))
+		})
+	}
+}
+
+func TestCachedBan(t *testing.T) {
+	t.Parallel()
+	s := &service{banned: make(map[int64]time.Time)}
+	now := time.Now()
+
+	assert.False(t, s.cachedBan(1, now))
+
+	s.cacheBan(1, now)
+	assert.True(t, s.cachedBan(1, now.Add(banTTL-time.Second)))
+	assert.False(t, s.cachedBan(1, now.Add(banTTL)))
+	assert.Empty(t, s.banned, "expired ban should be dropped")
+}
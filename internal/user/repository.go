@@ -1,76 +1,148 @@
-package user
-
-import (
-	"context"
-	"sync"
-
-	"archive_bot/pkg/er"
-	"archive_bot/pkg/logger"
-
-	"github.com/jackc/pgx/v5/pgxpool"
-)
-
-var ErrUserNotFound error = er.New("user not found", "", nil)
-
-var (
-	instance *pgRepository
-	once     sync.Once
-)
-
-type pgRepository struct {
-	log *logger.Logger
-	db  *pgxpool.Pool
-}
-
-// NewRepository creates new user repository.
-func NewRepository(ctx context.Context, log *logger.Logger, db *pgxpool.Pool) (*pgRepository, error) {
-	once.Do(func() {
-		instance = &pgRepository{log: log, db: db}
-	})
-
-	return instance, nil
-}
-
-// Save saves user to database.
-func (repo *pgRepository) Save(ctx context.Context, u *User) error {
-	const op string = "user.repository.Save"
-
-	if _, err := repo.db.Exec(ctx,
-		`INSERT INTO users (id, username)
-		VALUES ($1, $2);`, u.ID, u.Username); err != nil {
-		return er.New("unable to save user", op, err)
-	}
-
-	return nil
-}
-
-// Check that user is exists.
-func (repo *pgRepository) IsExists(ctx context.Context, id int64) error {
-	const op string = "user.repository.IsExists"
-
-	var isExists bool
-	if err := repo.db.QueryRow(ctx,
-		`SELECT EXISTS(SELECT 1 FROM users
-		WHERE id = $1);`, id).Scan(&isExists); err != nil {
-		return er.New("unable to get user", op, err)
-	}
-
-	if !isExists {
-		return ErrUserNotFound
-	}
-
-	return nil
-}
-
-// Check that user is exists.
-func (repo *pgRepository) CountUsers(ctx context.Context) (int, error) {
-	const op string = "user.repository.CountUsers"
-
-	var count int
-	if err := repo.db.QueryRow(ctx,
-		`SELECT COUNT(*) FROM users;`).Scan(&count); err != nil {
-		return 0, er.New("unable to get count of users", op, err)
-	}
-
-	return count, nil
-}
+package user
+
+import (
+	"context"
+	"sync"
+
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrUserNotFound error = er.New("user not found", "", nil)
+
+// seenInterval limits the writes of the activity, the stats count days.
+const seenInterval string = "10 minutes"
+
+var (
+	instance *pgRepository
+	once     sync.Once
+)
+
+type pgRepository struct {
+	log *logger.Logger
+	db  *pgxpool.Pool
+}
+
+// NewRepository creates new user repository.
+func NewRepository(ctx context.Context, log *logger.Logger, db *pgxpool.Pool) (*pgRepository, error) {
+	once.Do(func() {
+		instance = &pgRepository{log: log, db: db}
+	})
+
+	return instance, nil
+}
+
+// Save saves user to database.
+func (repo *pgRepository) Save(ctx context.Context, u *User) error {
+	const op string = "user.repository.Save"
+
+	if _, err := repo.db.Exec(ctx,
+		`INSERT INTO users (id, username)
+		VALUES ($1, $2);`, u.ID, u.Username); err != nil {
+		return er.New("unable to save user", op, err)
+	}
+
+	return nil
+}
+
+// Language returns the language chosen by the user, it is empty when the
+// user did not choose one.
+func (repo *pgRepository) Language(ctx context.Context, id int64) (string, error) {
+	const op string = "user.repository.Language"
+
+	var language string
+	if err := repo.db.QueryRow(ctx,
+		`SELECT language FROM users
+		WHERE id = $1;`, id).Scan(&language); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", ErrUserNotFound
+		}
+		return "", er.New("unable to get user", op, err)
+	}
+
+	return language, nil
+}
+
+// SetLanguage saves the language chosen by the user.
+func (repo *pgRepository) SetLanguage(ctx context.Context, id int64, language string) error {
+	const op string = "user.repository.SetLanguage"
+
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE users SET language = $2
+		WHERE id = $1;`, id, language)
+	if err != nil {
+		return er.New("unable to set language", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// Transcribe returns whether the user turned on the transcription.
+func (repo *pgRepository) Transcribe(ctx context.Context, id int64) (bool, error) {
+	const op string = "user.repository.Transcribe"
+
+	var on bool
+	if err := repo.db.QueryRow(ctx,
+		`SELECT transcribe FROM users
+		WHERE id = $1;`, id).Scan(&on); err != nil {
+		if err == pgx.ErrNoRows {
+			return false, ErrUserNotFound
+		}
+		return false, er.New("unable to get user", op, err)
+	}
+
+	return on, nil
+}
+
+// SetTranscribe turns the transcription of the user on or off.
+func (repo *pgRepository) SetTranscribe(ctx context.Context, id int64, on bool) error {
+	const op string = "user.repository.SetTranscribe"
+
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE users SET transcribe = $2
+		WHERE id = $1;`, id, on)
+	if err != nil {
+		return er.New("unable to set transcribe", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// Seen updates the last activity of the user, at most once in seenInterval.
+// The user writing to the bot has unblocked it.
+func (repo *pgRepository) Seen(ctx context.Context, id int64) error {
+	const op string = "user.repository.Seen"
+
+	if _, err := repo.db.Exec(ctx,
+		`UPDATE users SET last_seen_at = CURRENT_TIMESTAMP, blocked_at = NULL
+		WHERE id = $1
+			AND (last_seen_at IS NULL OR last_seen_at < CURRENT_TIMESTAMP - $2::interval
+				OR blocked_at IS NOT NULL);`,
+		id, seenInterval); err != nil {
+		return er.New("unable to update last seen", op, err)
+	}
+
+	return nil
+}
+
+// Check that user is exists.
+func (repo *pgRepository) CountUsers(ctx context.Context) (int, error) {
+	const op string = "user.repository.CountUsers"
+
+	var count int
+	if err := repo.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM users;`).Scan(&count); err != nil {
+		return 0, er.New("unable to get count of users", op, err)
+	}
+
+	return count, nil
+}
@@ -1,52 +1,125 @@
-package user
-
-import (
-	"context"
-
-	"archive_bot/internal/entities"
-	"archive_bot/pkg/er"
-	"archive_bot/pkg/logger"
-)
-
-var ErrUserNotExists error = er.New("user is not exists", "", nil)
-
-type Repository interface {
-	Save(ctx context.Context, u *User) error
-	IsExists(ctx context.Context, id int64) error
-	CountUsers(ctx context.Context) (int, error)
-}
-
-type service struct {
-	log  *logger.Logger
-	repo Repository
-}
-
-func NewService(ctx context.Context, log *logger.Logger, repo Repository) *service {
-	return &service{log: log, repo: repo}
-}
-
-func (s *service) Save(ctx context.Context, event *entities.Event) error {
-	u := &User{ID: event.Meta.UserID, Username: event.Meta.UserName}
-	if err := s.repo.Save(ctx, u); err != nil {
-		s.log.Error("failed to save user", logger.ErrAttr(err))
-		return err
-	}
-	return nil
-}
-
-func (s *service) IsExists(ctx context.Context, event *entities.Event) error {
-	if err := s.repo.IsExists(ctx, event.Meta.UserID); err != nil {
-		if err == ErrUserNotFound {
-			s.log.Info("new user")
-			return ErrUserNotExists
-		}
-		s.log.Error("failed to get user", logger.ErrAttr(err))
-		return err
-	}
-
-	return nil
-}
-
-func (s *service) CountUsers(ctx context.Context) (int, error) {
-	return s.repo.CountUsers(ctx)
-}
+package user
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"archive_bot/internal/entities"
+	"archive_bot/pkg/er"
+	"archive_bot/pkg/logger"
+	"archive_bot/pkg/tracing"
+)
+
+var ErrUserNotExists error = er.New("user is not exists", "", nil)
+
+type Repository interface {
+	Save(ctx context.Context, u *User) error
+	Language(ctx context.Context, id int64) (string, error)
+	SetLanguage(ctx context.Context, id int64, language string) error
+	Transcribe(ctx context.Context, id int64) (bool, error)
+	SetTranscribe(ctx context.Context, id int64, on bool) error
+	Seen(ctx context.Context, id int64) error
+	Profile(ctx context.Context, id int64, username string) (*Profile, error)
+	IsBanned(ctx context.Context, id int64) (bool, error)
+	SetBanned(ctx context.Context, id int64, banned bool, reason string) error
+	Quota(ctx context.Context, id int64) (*Quota, error)
+	SetQuota(ctx context.Context, id int64, q *Quota) error
+	Usage(ctx context.Context, id int64) (*Usage, error)
+	SetMediaSize(ctx context.Context, noteID int, size int64) error
+	Wipe(ctx context.Context, id int64) error
+	CountUsers(ctx context.Context) (int, error)
+}
+
+type service struct {
+	log  *logger.Logger
+	repo Repository
+
+	mu sync.Mutex
+	// banned holds the expiry of the cached bans, the users without a ban
+	// are not cached.
+	banned map[int64]time.Time
+	limits Limits
+}
+
+// NewService creates the user service, limits are the limits of the users
+// without a quota.
+func NewService(ctx context.Context, log *logger.Logger, repo Repository, limits Limits) *service {
+	return &service{log: log, repo: repo, banned: make(map[int64]time.Time), limits: limits}
+}
+
+func (s *service) Save(ctx context.Context, event *entities.Event) error {
+	ctx, span := tracing.Start(ctx, "user.service.Save")
+	defer span.End()
+
+	u := &User{ID: event.Meta.UserID, Username: event.Meta.UserName}
+	if err := s.repo.Save(ctx, u); err != nil {
+		logger.L(ctx).Error("failed to save user", logger.ErrAttr(err))
+		return err
+	}
+	return nil
+}
+
+// Language returns the language chosen by the user or an empty string,
+// ErrUserNotExists is returned for a new user.
+func (s *service) Language(ctx context.Context, event *entities.Event) (string, error) {
+	ctx, span := tracing.Start(ctx, "user.service.Language")
+	defer span.End()
+
+	language, err := s.repo.Language(ctx, event.Meta.UserID)
+	if err != nil {
+		if err == ErrUserNotFound {
+			logger.L(ctx).Info("new user")
+			return "", ErrUserNotExists
+		}
+		logger.L(ctx).Error("failed to get user", logger.ErrAttr(err))
+		return "", err
+	}
+
+	return language, nil
+}
+
+func (s *service) SetLanguage(ctx context.Context, userID int64, language string) error {
+	ctx, span := tracing.Start(ctx, "user.service.SetLanguage")
+	defer span.End()
+
+	return s.repo.SetLanguage(ctx, userID, language)
+}
+
+// Transcribe returns whether the voice and audio notes of the user are
+// transcribed, it is off when it can't be checked.
+func (s *service) Transcribe(ctx context.Context, userID int64) bool {
+	ctx, span := tracing.Start(ctx, "user.service.Transcribe")
+	defer span.End()
+
+	on, err := s.repo.Transcribe(ctx, userID)
+	if err != nil {
+		logger.L(ctx).Error("failed to get transcribe", logger.ErrAttr(err))
+		return false
+	}
+
+	return on
+}
+
+func (s *service) SetTranscribe(ctx context.Context, userID int64, on bool) error {
+	ctx, span := tracing.Start(ctx, "user.service.SetTranscribe")
+	defer span.End()
+
+	return s.repo.SetTranscribe(ctx, userID, on)
+}
+
+func (s *service) Seen(ctx context.Context, event *entities.Event) {
+	ctx, span := tracing.Start(ctx, "user.service.Seen")
+	defer span.End()
+
+	if err := s.repo.Seen(ctx, event.Meta.UserID); err != nil {
+		logger.L(ctx).Error("failed to update last seen", logger.ErrAttr(err))
+	}
+}
+
+func (s *service) CountUsers(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "user.service.CountUsers")
+	defer span.End()
+
+	return s.repo.CountUsers(ctx)
+}
@@ -0,0 +1,91 @@
+package webapp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"archive_bot/pkg/er"
+)
+
+var (
+	ErrInvalidInitData = er.New("invalid init data", "", nil)
+	ErrExpiredInitData = er.New("init data is expired", "", nil)
+)
+
+// User is the Telegram user who opened the Mini App.
+type User struct {
+	ID           int64  `json:"id"`
+	FirstName    string `json:"first_name"`
+	Username     string `json:"username"`
+	LanguageCode string `json:"language_code"`
+}
+
+// ValidateInitData checks the signature of the Mini App init data
+// (https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app)
+// and returns the user from it. Init data older than maxAge is rejected.
+func ValidateInitData(initData string, botToken string, maxAge time.Duration, now time.Time) (*User, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, ErrInvalidInitData
+	}
+
+	hash := values.Get("hash")
+	if hash == "" {
+		return nil, ErrInvalidInitData
+	}
+
+	expected, err := hex.DecodeString(hash)
+	if err != nil {
+		return nil, ErrInvalidInitData
+	}
+
+	if !hmac.Equal(signInitData(values, botToken), expected) {
+		return nil, ErrInvalidInitData
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, ErrInvalidInitData
+	}
+	if maxAge > 0 && now.Sub(time.Unix(authDate, 0)) > maxAge {
+		return nil, ErrExpiredInitData
+	}
+
+	var user User
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil || user.ID == 0 {
+		return nil, ErrInvalidInitData
+	}
+
+	return &user, nil
+}
+
+// signInitData signs the data check string built from all fields except hash.
+func signInitData(values url.Values, botToken string) []byte {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if key != "hash" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+
+	return mac.Sum(nil)
+}
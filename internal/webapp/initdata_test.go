@@ -0,0 +1,80 @@
+package webapp
+
+import (
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "123456:TEST-TOKEN"
+
+func signedInitData(token string, authDate time.Time, user string) string {
+	values := url.Values{}
+	values.Set("auth_date", strconv.FormatInt(authDate.Unix(), 10))
+	values.Set("query_id", "AAHdF6IQAAAAAN0XohDhrOrc")
+	values.Set("user", user)
+	values.Set("hash", hex.EncodeToString(signInitData(values, token)))
+
+	return values.Encode()
+}
+
+func TestValidateInitData(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	user := `{"id":42,"first_name":"Ann","username":"ann","language_code":"en"}`
+
+	tests := []struct {
+		name     string
+		initData string
+		token    string
+		wantID   int64
+		wantErr  error
+	}{
+		{
+			name:     "valid",
+			initData: signedInitData(testToken, now.Add(-time.Minute), user),
+			token:    testToken,
+			wantID:   42,
+		},
+		{
+			name:     "wrong token",
+			initData: signedInitData("654321:OTHER", now.Add(-time.Minute), user),
+			token:    testToken,
+			wantErr:  ErrInvalidInitData,
+		},
+		{
+			name:     "expired",
+			initData: signedInitData(testToken, now.Add(-48*time.Hour), user),
+			token:    testToken,
+			wantErr:  ErrExpiredInitData,
+		},
+		{
+			name:     "no hash",
+			initData: "auth_date=1&user=%7B%22id%22%3A42%7D",
+			token:    testToken,
+			wantErr:  ErrInvalidInitData,
+		},
+		{
+			name:     "no user",
+			initData: signedInitData(testToken, now, ""),
+			token:    testToken,
+			wantErr:  ErrInvalidInitData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateInitData(tt.initData, tt.token, 24*time.Hour, now)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, got.ID)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package webapp
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"archive_bot/internal/api"
+	"archive_bot/internal/folder"
+	"archive_bot/internal/i18n"
+	"archive_bot/internal/notes/texts"
+
+	"archive_bot/pkg/logger"
+)
+
+const (
+	Prefix string = "/webapp/"
+
+	initDataMaxAge time.Duration = 24 * time.Hour
+	pageSize       int           = 30
+	maxBulkSize    int           = 200
+)
+
+//go:embed static
+var static embed.FS
+
+type FolderService interface {
+	List(ctx context.Context, userID int64) ([]*folder.Folder, error)
+}
+
+type NoteService interface {
+	List(ctx context.Context, f *texts.Filter) ([]*texts.TextNote, error)
+	MoveMany(ctx context.Context, userID int64, ids []int, folderID int) (int, error)
+	RemoveMany(ctx context.Context, userID int64, ids []int) (int, error)
+}
+
+type BanService interface {
+	IsBanned(ctx context.Context, userID int64) bool
+}
+
+type server struct {
+	log      *logger.Logger
+	botToken string
+	folders  FolderService
+	notes    NoteService
+	bans     BanService
+}
+
+func New(log *logger.Logger, botToken string, folders FolderService, notes NoteService, bans BanService) *server {
+	return &server{log: log, botToken: botToken, folders: folders, notes: notes, bans: bans}
+}
+
+// Handler returns the handler serving the Mini App and its API under Prefix.
+func (s *server) Handler() http.Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+
+	routes := http.NewServeMux()
+	routes.HandleFunc("GET /webapp/api/folders", s.listFolders)
+	routes.HandleFunc("GET /webapp/api/notes", s.listNotes)
+	routes.HandleFunc("POST /webapp/api/notes/move", s.moveNotes)
+	routes.HandleFunc("POST /webapp/api/notes/delete", s.removeNotes)
+
+	mux := http.NewServeMux()
+	mux.Handle("/webapp/api/", s.authenticate(routes))
+	mux.Handle(Prefix, http.StripPrefix(Prefix, http.FileServerFS(files)))
+
+	return mux
+}
+
+type userCtx struct{}
+
+// authenticate validates the init data passed as "Authorization: tma <initData>".
+// The banned users are refused.
+func (s *server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		initData, ok := strings.CutPrefix(r.Header.Get("Authorization"), "tma ")
+		if !ok {
+			api.WriteError(w, http.StatusUnauthorized, "missing init data")
+			return
+		}
+
+		user, err := ValidateInitData(initData, s.botToken, initDataMaxAge, time.Now())
+		if err != nil {
+			api.WriteError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if s.bans.IsBanned(r.Context(), user.ID) {
+			api.WriteError(w, http.StatusForbidden, "user is banned")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userCtx{}, user)))
+	})
+}
+
+func currentUser(r *http.Request) *User {
+	return r.Context().Value(userCtx{}).(*User)
+}
+
+func userID(r *http.Request) int64 {
+	return currentUser(r).ID
+}
+
+type folderResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type noteResponse struct {
+	ID        int       `json:"id"`
+	FolderID  int       `json:"folder_id"`
+	Type      string    `json:"type"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type bulkRequest struct {
+	IDs      []int `json:"ids"`
+	FolderID int   `json:"folder_id"`
+}
+
+func (s *server) listFolders(w http.ResponseWriter, r *http.Request) {
+	folders, err := s.folders.List(r.Context(), userID(r))
+	if err != nil {
+		s.internalError(w, "webapp.listFolders", err)
+		return
+	}
+
+	lang := i18n.FromCode(currentUser(r).LanguageCode)
+	res := make([]folderResponse, 0, len(folders))
+	for _, f := range folders {
+		res = append(res, folderResponse{ID: f.ID, Name: folder.DisplayName(f, lang)})
+	}
+
+	api.WriteJSON(w, http.StatusOK, map[string]any{"folders": res})
+}
+
+// listNotes returns a page of notes. One extra note is requested
+// to tell whether there is a next page.
+func (s *server) listNotes(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	folderID, _ := strconv.Atoi(query.Get("folder_id"))
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 0 {
+		page = 0
+	}
+
+	notes, err := s.notes.List(r.Context(), &texts.Filter{
+		UserID:   userID(r),
+		FolderID: folderID,
+		Query:    query.Get("q"),
+		Limit:    pageSize + 1,
+		Offset:   page * pageSize,
+	})
+	if err != nil {
+		s.internalError(w, "webapp.listNotes", err)
+		return
+	}
+
+	hasMore := len(notes) > pageSize
+	if hasMore {
+		notes = notes[:pageSize]
+	}
+
+	res := make([]noteResponse, 0, len(notes))
+	for _, n := range notes {
+		res = append(res, noteResponse{
+			ID:        n.ID,
+			FolderID:  n.FolderID,
+			Type:      n.Type,
+			Text:      n.Description,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+
+	api.WriteJSON(w, http.StatusOK, map[string]any{"notes": res, "page": page, "has_more": hasMore})
+}
+
+func (s *server) moveNotes(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeBulk(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := s.notes.MoveMany(r.Context(), userID(r), req.IDs, req.FolderID)
+	if err != nil {
+		if err == texts.ErrNoFolder {
+			api.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		s.internalError(w, "webapp.moveNotes", err)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, map[string]int{"count": count})
+}
+
+func (s *server) removeNotes(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeBulk(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := s.notes.RemoveMany(r.Context(), userID(r), req.IDs)
+	if err != nil {
+		s.internalError(w, "webapp.removeNotes", err)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, map[string]int{"count": count})
+}
+
+func decodeBulk(w http.ResponseWriter, r *http.Request) (*bulkRequest, bool) {
+	var req bulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.IDs) == 0 {
+		api.WriteError(w, http.StatusBadRequest, "invalid body")
+		return nil, false
+	}
+	if len(req.IDs) > maxBulkSize {
+		api.WriteError(w, http.StatusBadRequest, "too many notes")
+		return nil, false
+	}
+
+	return &req, true
+}
+
+func (s *server) internalError(w http.ResponseWriter, op string, err error) {
+	s.log.Error("request failed", logger.String("operation", op), logger.ErrAttr(err))
+	api.WriteError(w, http.StatusInternalServerError, "internal error")
+}
@@ -0,0 +1,72 @@
+package webapp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"archive_bot/internal/folder"
+	"archive_bot/internal/notes/texts"
+
+	"archive_bot/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testFolders struct{}
+
+func (testFolders) List(ctx context.Context, userID int64) ([]*folder.Folder, error) {
+	return []*folder.Folder{{ID: 1, UserID: userID, Name: "Mine"}}, nil
+}
+
+type testNotes struct{}
+
+func (testNotes) List(ctx context.Context, f *texts.Filter) ([]*texts.TextNote, error) {
+	return nil, nil
+}
+
+func (testNotes) MoveMany(ctx context.Context, userID int64, ids []int, folderID int) (int, error) {
+	return len(ids), nil
+}
+
+func (testNotes) RemoveMany(ctx context.Context, userID int64, ids []int) (int, error) {
+	return len(ids), nil
+}
+
+type testBans map[int64]bool
+
+func (bs testBans) IsBanned(ctx context.Context, userID int64) bool {
+	return bs[userID]
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+	log := logger.NewLogger(logger.WithWriter(io.Discard), logger.WithSetDefault(false))
+	h := New(log, testToken, testFolders{}, testNotes{}, testBans{13: true}).Handler()
+
+	testCases := []struct {
+		title         string
+		authorization string
+		want          int
+	}{
+		{"no init data", "", http.StatusUnauthorized},
+		{"wrong token", "tma " + signedInitData("654321:OTHER", time.Now(), `{"id":42}`), http.StatusUnauthorized},
+		{"banned user", "tma " + signedInitData(testToken, time.Now(), `{"id":13}`), http.StatusForbidden},
+		{"valid user", "tma " + signedInitData(testToken, time.Now(), `{"id":42}`), http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/webapp/api/folders", nil)
+			if tc.authorization != "" {
+				r.Header.Set("Authorization", tc.authorization)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
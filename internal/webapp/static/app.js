@@ -0,0 +1,111 @@
+const tg = window.Telegram.WebApp;
+tg.ready();
+tg.expand();
+
+const state = { folderId: 0, query: "", page: 0, selected: new Set(), folders: [] };
+const $ = (id) => document.getElementById(id);
+
+async function call(path, options = {}) {
+  const res = await fetch("api/" + path, {
+    ...options,
+    headers: { "Authorization": "tma " + tg.initData, "Content-Type": "application/json" },
+  });
+  const body = await res.json();
+  if (!res.ok) throw new Error(body.error);
+  return body;
+}
+
+function option(value, text) {
+  const o = document.createElement("option");
+  o.value = value;
+  o.textContent = text;
+  return o;
+}
+
+async function loadFolders() {
+  const { folders } = await call("folders");
+  state.folders = folders;
+  $("folder").replaceChildren(option(0, "Все папки"), ...folders.map((f) => option(f.id, f.name)));
+  $("target").replaceChildren(...folders.map((f) => option(f.id, f.name)));
+}
+
+function renderNote(note) {
+  const row = document.createElement("label");
+  row.className = "note";
+
+  const check = document.createElement("input");
+  check.type = "checkbox";
+  check.checked = state.selected.has(note.id);
+  check.onchange = () => {
+    check.checked ? state.selected.add(note.id) : state.selected.delete(note.id);
+    renderActions();
+  };
+
+  const body = document.createElement("div");
+  const text = document.createElement("p");
+  text.textContent = note.text || "[" + note.type + "]";
+  const date = document.createElement("small");
+  date.textContent = new Date(note.created_at).toLocaleString();
+  body.append(text, date);
+
+  row.append(check, body);
+  return row;
+}
+
+async function loadNotes(reset) {
+  if (reset) {
+    state.page = 0;
+    $("notes").replaceChildren();
+  }
+  const params = new URLSearchParams({ folder_id: state.folderId, q: state.query, page: state.page });
+  const { notes, has_more } = await call("notes?" + params);
+
+  $("notes").append(...notes.map(renderNote));
+  if (reset && notes.length === 0) {
+    const empty = document.createElement("div");
+    empty.className = "empty";
+    empty.textContent = "Заметок нет";
+    $("notes").append(empty);
+  }
+  $("more").hidden = !has_more;
+}
+
+function renderActions() {
+  $("actions").hidden = state.selected.size === 0;
+  $("selected").textContent = state.selected.size;
+}
+
+async function bulk(action, body) {
+  try {
+    await call("notes/" + action, { method: "POST", body: JSON.stringify({ ids: [...state.selected], ...body }) });
+    state.selected.clear();
+    renderActions();
+    await loadNotes(true);
+  } catch (e) {
+    tg.showAlert(e.message);
+  }
+}
+
+let searchTimer;
+$("search").oninput = (e) => {
+  clearTimeout(searchTimer);
+  searchTimer = setTimeout(() => {
+    state.query = e.target.value;
+    loadNotes(true);
+  }, 300);
+};
+$("folder").onchange = (e) => {
+  state.folderId = Number(e.target.value);
+  loadNotes(true);
+};
+$("more").onclick = () => {
+  state.page++;
+  loadNotes(false);
+};
+$("move").onclick = () => bulk("move", { folder_id: Number($("target").value) });
+$("delete").onclick = () =>
+  tg.showConfirm("Удалить выбранные заметки?", (ok) => ok && bulk("delete", {}));
+
+loadFolders()
+  .then(() => loadNotes(true))
+  .catch((e) => tg.showAlert(e.message));
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS channels(
+		id BIGINT NOT NULL PRIMARY KEY,
+		user_id BIGINT NOT NULL,
+		folder_id BIGINT NOT NULL,
+		title VARCHAR(255) NOT NULL DEFAULT '',
+		username VARCHAR(100) NOT NULL DEFAULT '',
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+		ON DELETE CASCADE ON UPDATE CASCADE,
+		FOREIGN KEY (folder_id) REFERENCES folders (id)
+		ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS channel_posts(
+		channel_id BIGINT NOT NULL,
+		message_id BIGINT NOT NULL,
+		texts_id BIGINT NOT NULL,
+		PRIMARY KEY (channel_id, message_id),
+		FOREIGN KEY (channel_id) REFERENCES channels (id)
+		ON DELETE CASCADE ON UPDATE CASCADE,
+		FOREIGN KEY (texts_id) REFERENCES texts (id)
+		ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS
+    channel_posts,
+    channels;
+-- +goose StatementEnd
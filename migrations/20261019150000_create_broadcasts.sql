@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS broadcasts(
+		id BIGSERIAL NOT NULL PRIMARY KEY,
+		admin_id BIGINT NOT NULL,
+		from_chat_id BIGINT NOT NULL,
+		message_id INT NOT NULL,
+		status VARCHAR(16) NOT NULL DEFAULT 'draft',
+		last_user_id BIGINT NOT NULL DEFAULT 0,
+		delivered INT NOT NULL DEFAULT 0,
+		failed INT NOT NULL DEFAULT 0,
+		blocked INT NOT NULL DEFAULT 0,
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS broadcasts_status_idx ON broadcasts (status);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS broadcasts;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE users
+		ADD COLUMN IF NOT EXISTS created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP WITH TIME ZONE;
+
+-- the users created before have no date, the first note is the best guess
+UPDATE users SET created_at = first.created_at
+FROM (SELECT user_id, MIN(created_at) AS created_at FROM texts GROUP BY user_id) AS first
+WHERE first.user_id = users.id AND first.created_at < users.created_at;
+
+-- the last note is the last activity known, the users without notes stay
+-- unseen until they write, so the activity is not inflated on this day
+UPDATE users SET last_seen_at = last.created_at
+FROM (SELECT user_id, MAX(created_at) AS created_at FROM texts GROUP BY user_id) AS last
+WHERE last.user_id = users.id;
+
+ALTER TABLE users ALTER COLUMN last_seen_at SET DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS users_created_at_idx ON users (created_at);
+CREATE INDEX IF NOT EXISTS users_last_seen_at_idx ON users (last_seen_at);
+CREATE INDEX IF NOT EXISTS texts_created_at_idx ON texts (created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS texts_created_at_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS last_seen_at, DROP COLUMN IF EXISTS created_at;
+-- +goose StatementEnd
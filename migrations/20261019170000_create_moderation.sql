@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE users
+		ADD COLUMN IF NOT EXISTS banned_at TIMESTAMP WITH TIME ZONE,
+		ADD COLUMN IF NOT EXISTS ban_reason TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS user_quotas(
+		user_id BIGINT NOT NULL PRIMARY KEY,
+		max_notes INT NOT NULL DEFAULT 0,
+		max_folders INT NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+		ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS admin_audit(
+		id BIGSERIAL NOT NULL PRIMARY KEY,
+		admin_id BIGINT NOT NULL,
+		action VARCHAR(64) NOT NULL,
+		details TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS admin_audit_created_at_idx ON admin_audit (created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS admin_audit;
+DROP TABLE IF EXISTS user_quotas;
+ALTER TABLE users DROP COLUMN IF EXISTS ban_reason, DROP COLUMN IF EXISTS banned_at;
+-- +goose StatementEnd
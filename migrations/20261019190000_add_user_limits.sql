@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE user_quotas
+		ALTER COLUMN max_notes DROP NOT NULL,
+		ALTER COLUMN max_notes DROP DEFAULT,
+		ALTER COLUMN max_folders DROP NOT NULL,
+		ALTER COLUMN max_folders DROP DEFAULT,
+		ADD COLUMN IF NOT EXISTS notes_per_day INT,
+		ADD COLUMN IF NOT EXISTS max_media_bytes BIGINT;
+
+-- zero was no limit, now the missing override keeps the configured limit
+UPDATE user_quotas
+SET max_notes = NULLIF(max_notes, 0), max_folders = NULLIF(max_folders, 0);
+
+ALTER TABLE texts ADD COLUMN IF NOT EXISTS media_size BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS texts_user_id_created_at_idx ON texts (user_id, created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS texts_user_id_created_at_idx;
+ALTER TABLE texts DROP COLUMN IF EXISTS media_size;
+UPDATE user_quotas
+SET max_notes = COALESCE(max_notes, 0), max_folders = COALESCE(max_folders, 0);
+ALTER TABLE user_quotas
+		DROP COLUMN IF EXISTS max_media_bytes,
+		DROP COLUMN IF EXISTS notes_per_day,
+		ALTER COLUMN max_notes SET DEFAULT 0,
+		ALTER COLUMN max_notes SET NOT NULL,
+		ALTER COLUMN max_folders SET DEFAULT 0,
+		ALTER COLUMN max_folders SET NOT NULL;
+-- +goose StatementEnd
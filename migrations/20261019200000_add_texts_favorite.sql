@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE texts ADD COLUMN IF NOT EXISTS favorite BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS texts_favorite_idx ON texts (user_id) WHERE favorite;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS texts_favorite_idx;
+ALTER TABLE texts DROP COLUMN IF EXISTS favorite;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE folders
+		ADD COLUMN IF NOT EXISTS icon VARCHAR(32) NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0,
+		ADD COLUMN IF NOT EXISTS hidden BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- the folders keep the order of creation
+UPDATE folders f SET position = o.position
+FROM (
+		SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY id) AS position
+		FROM folders
+) o
+WHERE f.id = o.id;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE folders
+    DROP COLUMN IF EXISTS hidden,
+    DROP COLUMN IF EXISTS position,
+    DROP COLUMN IF EXISTS icon;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS rules(
+		id BIGSERIAL NOT NULL PRIMARY KEY,
+		user_id BIGINT NOT NULL,
+		position INT NOT NULL,
+		conditions JSONB NOT NULL,
+		folder_id BIGINT,
+		tags TEXT[] NOT NULL DEFAULT '{}',
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+		ON DELETE CASCADE ON UPDATE CASCADE,
+		FOREIGN KEY (folder_id) REFERENCES folders (id)
+		ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS rules_user_id_position_idx ON rules (user_id, position);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS rules;
+-- +goose StatementEnd
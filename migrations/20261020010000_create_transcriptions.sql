@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE texts ADD COLUMN IF NOT EXISTS transcript TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS transcribe BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS transcriptions(
+		id BIGSERIAL NOT NULL PRIMARY KEY,
+		texts_id BIGINT NOT NULL UNIQUE,
+		file_id TEXT NOT NULL,
+		attempts INT NOT NULL DEFAULT 0,
+		next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		last_error TEXT NOT NULL DEFAULT '',
+		failed BOOLEAN NOT NULL DEFAULT FALSE,
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (texts_id) REFERENCES texts (id)
+		ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS transcriptions_next_attempt_at_idx ON transcriptions (next_attempt_at) WHERE NOT failed;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS transcriptions;
+ALTER TABLE users DROP COLUMN IF EXISTS transcribe;
+ALTER TABLE texts DROP COLUMN IF EXISTS transcript;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationsEmbedded(t *testing.T) {
+	names, err := fs.Glob(FS, "*.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, names)
+
+	versions := make(map[string]string, len(names))
+	for _, name := range names {
+		version, _, ok := strings.Cut(name, "_")
+		require.True(t, ok, name)
+		assert.NotContains(t, versions, version, "duplicate version of %s", name)
+		versions[version] = name
+
+		data, err := FS.ReadFile(name)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "-- +goose Up", name)
+		assert.Contains(t, string(data), "-- +goose Down", name)
+	}
+}
@@ -1,75 +1,155 @@
-package closer
-
-import (
-	"log"
-	"os"
-	"os/signal"
-	"sync"
-)
-
-var globalCloser = New()
-
-func Add(closeFunc ...closeFunction) { globalCloser.add(closeFunc...) }
-
-func Wait() { globalCloser.wait() }
-
-// CloseAll - wrapper for Closer.closeAll. Calls all closer functions.
-func CloseAll() { globalCloser.closeAll() }
-
-type closeFunction func() error
-
-type Closer struct {
-	mu    sync.Mutex
-	once  sync.Once
-	done  chan struct{}
-	funcs []closeFunction
-}
-
-func New(sig ...os.Signal) *Closer {
-	c := &Closer{done: make(chan struct{})}
-	if len(sig) > 0 {
-		go func() {
-			ch := make(chan os.Signal, 1)
-			signal.Notify(ch, sig...)
-			<-ch
-			signal.Stop(ch)
-			c.closeAll()
-		}()
-	}
-
-	return c
-}
-
-// closeAll calls all closer functions.
-func (c *Closer) closeAll() {
-	c.once.Do(func() {
-		defer close(c.done)
-
-		c.mu.Lock()
-		funcs := c.funcs
-		c.funcs = nil
-		c.mu.Unlock()
-
-		// call all funcs async
-		errs := make(chan error, len(funcs))
-		for _, f := range funcs {
-			go func(f closeFunction) {
-				errs <- f()
-			}(f)
-		}
-
-		for i := 0; i < cap(errs); i++ {
-			if err := <-errs; err != nil {
-				log.Println("error returned from Closer", err)
-			}
-		}
-	})
-}
-
-func (c *Closer) add(closeFunc ...closeFunction) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.funcs = append(c.funcs, closeFunc...)
-}
-
-func (c *Closer) wait() { <-c.done }
+package closer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+
+	"archive_bot/pkg/er"
+)
+
+var globalCloser = New()
+
+// Add - wrapper for Closer.add. Registers the close function of the resource.
+func Add(priority int, name string, closeFunc CloseFunc) {
+	globalCloser.add(priority, name, closeFunc)
+}
+
+func Wait() { globalCloser.wait() }
+
+// CloseAll - wrapper for Closer.closeAll. Calls all close functions.
+func CloseAll(ctx context.Context) error { return globalCloser.closeAll(ctx) }
+
+// CloseFunc releases the resource, it should return when ctx is done.
+type CloseFunc func(ctx context.Context) error
+
+type closer struct {
+	priority int
+	name     string
+	close    CloseFunc
+}
+
+type result struct {
+	name string
+	err  error
+}
+
+type Closer struct {
+	mu      sync.Mutex
+	once    sync.Once
+	done    chan struct{}
+	err     error
+	closers []closer
+}
+
+func New(sig ...os.Signal) *Closer {
+	c := &Closer{done: make(chan struct{})}
+	if len(sig) > 0 {
+		go func() {
+			ch := make(chan os.Signal, 1)
+			signal.Notify(ch, sig...)
+			<-ch
+			signal.Stop(ch)
+			if err := c.closeAll(context.Background()); err != nil {
+				log.Println("error returned from Closer", err)
+			}
+		}()
+	}
+
+	return c
+}
+
+// closeAll calls the close functions in ascending order of priority,
+// the functions of one priority concurrently. When ctx is done the
+// running functions are abandoned and the rest are skipped.
+// The returned error lists every resource that did not close.
+func (c *Closer) closeAll(ctx context.Context) error {
+	const op = "closer.CloseAll"
+
+	c.once.Do(func() {
+		defer close(c.done)
+
+		c.mu.Lock()
+		closers := c.closers
+		c.closers = nil
+		c.mu.Unlock()
+
+		sort.SliceStable(closers, func(i, j int) bool {
+			return closers[i].priority < closers[j].priority
+		})
+
+		var errs []error
+		for start := 0; start < len(closers); {
+			end := start
+			for end < len(closers) && closers[end].priority == closers[start].priority {
+				end++
+			}
+
+			for _, res := range closeGroup(ctx, closers[start:end]) {
+				errs = append(errs, er.New(res.name+" did not close", op, res.err))
+			}
+			start = end
+		}
+
+		c.err = errors.Join(errs...)
+	})
+
+	return c.err
+}
+
+// closeGroup returns the failed closers of the group.
+func closeGroup(ctx context.Context, group []closer) []result {
+	if err := ctx.Err(); err != nil {
+		failed := make([]result, 0, len(group))
+		for _, cl := range group {
+			failed = append(failed, result{name: cl.name, err: err})
+		}
+		return failed
+	}
+
+	results := make(chan result, len(group))
+	for _, cl := range group {
+		go func(cl closer) {
+			results <- result{name: cl.name, err: cl.close(ctx)}
+		}(cl)
+	}
+
+	var failed []result
+	pending := make(map[string]int, len(group))
+	for _, cl := range group {
+		pending[cl.name]++
+	}
+
+	for len(pending) > 0 {
+		select {
+		case res := <-results:
+			if pending[res.name]--; pending[res.name] == 0 {
+				delete(pending, res.name)
+			}
+			if res.err != nil {
+				failed = append(failed, res)
+			}
+		case <-ctx.Done():
+			for name, n := range pending {
+				for i := 0; i < n; i++ {
+					failed = append(failed, result{name: name, err: ctx.Err()})
+				}
+			}
+			return failed
+		}
+	}
+
+	return failed
+}
+
+func (c *Closer) add(priority int, name string, closeFunc CloseFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closers = append(c.closers, closer{priority: priority, name: name, close: closeFunc})
+}
+
+func (c *Closer) wait() { <-c.done }
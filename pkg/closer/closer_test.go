@@ -0,0 +1,71 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloseAllOrder(t *testing.T) {
+	t.Parallel()
+	c := New()
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	add := func(priority int, name string) {
+		c.add(priority, name, func(ctx context.Context) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		})
+	}
+	add(2, "db")
+	add(0, "server")
+	add(1, "queue")
+
+	require.NoError(t, c.closeAll(context.Background()))
+	assert.Equal(t, []string{"server", "queue", "db"}, order)
+}
+
+func TestCloseAllReport(t *testing.T) {
+	t.Parallel()
+	c := New()
+
+	errClose := errors.New("close failed")
+	var skipped bool
+	c.add(0, "ok", func(ctx context.Context) error { return nil })
+	c.add(0, "broken", func(ctx context.Context) error { return errClose })
+	c.add(1, "stuck", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	c.add(2, "late", func(ctx context.Context) error {
+		skipped = false
+		return nil
+	})
+	skipped = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := c.closeAll(ctx)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errClose)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "broken did not close")
+	assert.Contains(t, err.Error(), "stuck did not close")
+	assert.Contains(t, err.Error(), "late did not close")
+	assert.NotContains(t, err.Error(), "ok did not close")
+	assert.True(t, skipped)
+
+	// the second call returns the same report
+	assert.Equal(t, err, c.closeAll(context.Background()))
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+)
+
+var ErrSchemaBehind = errors.New("database schema is behind the migrations")
+
+// Migrator applies the goose migrations of fsys to the database.
+type Migrator struct {
+	provider *goose.Provider
+}
+
+func NewMigrator(pool *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	const op string = "database.postgres.NewMigrator"
+
+	db := stdlib.OpenDBFromPool(pool)
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, fsys)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s - unable to load the migrations: %w", op, err)
+	}
+
+	return &Migrator{provider: provider}, nil
+}
+
+// Up applies all pending migrations and returns the applied versions.
+func (m *Migrator) Up(ctx context.Context) ([]int64, error) {
+	const op string = "database.postgres.Migrator.Up"
+
+	results, err := m.provider.Up(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s - unable to apply the migrations: %w", op, err)
+	}
+
+	return versions(results), nil
+}
+
+// Down rolls back the latest applied migration and returns its version,
+// 0 when nothing was applied.
+func (m *Migrator) Down(ctx context.Context) (int64, error) {
+	const op string = "database.postgres.Migrator.Down"
+
+	result, err := m.provider.Down(ctx)
+	if errors.Is(err, goose.ErrNoNextVersion) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s - unable to roll back the migration: %w", op, err)
+	}
+
+	return result.Source.Version, nil
+}
+
+// Status writes the state of every migration.
+func (m *Migrator) Status(ctx context.Context, w io.Writer) error {
+	const op string = "database.postgres.Migrator.Status"
+
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("%s - unable to get the status: %w", op, err)
+	}
+
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.State == goose.StateApplied {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%-20s %-40s %s\n", appliedAt, s.Source.Path, s.State)
+	}
+
+	return nil
+}
+
+// Check returns ErrSchemaBehind when the database misses some of the migrations.
+func (m *Migrator) Check(ctx context.Context) (current int64, latest int64, err error) {
+	const op string = "database.postgres.Migrator.Check"
+
+	current, latest, err = m.provider.GetVersions(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s - unable to get the schema version: %w", op, err)
+	}
+
+	hasPending, err := m.provider.HasPending(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s - unable to check the migrations: %w", op, err)
+	}
+	if hasPending {
+		return current, latest, ErrSchemaBehind
+	}
+
+	return current, latest, nil
+}
+
+// Close releases the connection, the pool stays open.
+func (m *Migrator) Close() error {
+	return m.provider.Close()
+}
+
+func versions(results []*goose.MigrationResult) []int64 {
+	applied := make([]int64, 0, len(results))
+	for _, r := range results {
+		applied = append(applied, r.Source.Version)
+	}
+	return applied
+}
@@ -1,21 +1,25 @@
-package redis
-
-import (
-	"context"
-	"fmt"
-
-	"github.com/redis/go-redis/v9"
-)
-
-func NewConnect(ctx context.Context, opts *redis.Options) (*redis.Client, error) {
-	const op string = "database.redis.NewConnect"
-
-	db := redis.NewClient(opts)
-	if err := db.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("%s - failed to connect to redis server: %w", op, err)
-	}
-
-	return db, nil
-}
-
-
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+func NewConnect(ctx context.Context, opts *redis.Options) (*redis.Client, error) {
+	const op string = "database.redis.NewConnect"
+
+	db := redis.NewClient(opts)
+	if err := redisotel.InstrumentTracing(db); err != nil {
+		return nil, fmt.Errorf("%s - failed to instrument redis client: %w", op, err)
+	}
+	if err := db.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("%s - failed to connect to redis server: %w", op, err)
+	}
+
+	return db, nil
+}
+
+
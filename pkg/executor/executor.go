@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrClosed    = errors.New("executor is closed")
+	ErrQueueFull = errors.New("executor queue is full")
+)
+
+// Executor runs tasks on a fixed number of workers. Tasks with the same key
+// run one at a time in the order they were submitted, tasks with different
+// keys run concurrently. A key holds at most queueSize tasks, 0 is no limit.
+type Executor struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	queues    map[int64][]func()
+	ready     []int64
+	queueSize int
+	closed    bool
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+func New(workers int, queueSize int) *Executor {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	e := &Executor{
+		queues:    make(map[int64][]func()),
+		queueSize: max(queueSize, 0),
+		done:      make(chan struct{}),
+	}
+	e.cond = sync.NewCond(&e.mu)
+
+	e.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go e.work()
+	}
+	go func() {
+		e.wg.Wait()
+		close(e.done)
+	}()
+
+	return e
+}
+
+// Submit queues the task after the previous tasks with the same key.
+// The task is rejected when the key has queueSize tasks, the running one
+// included.
+func (e *Executor) Submit(key int64, task func()) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return ErrClosed
+	}
+
+	queue, active := e.queues[key]
+	if e.queueSize > 0 && len(queue) >= e.queueSize {
+		return ErrQueueFull
+	}
+	e.queues[key] = append(queue, task)
+	if !active {
+		e.ready = append(e.ready, key)
+		e.cond.Signal()
+	}
+
+	return nil
+}
+
+// Shutdown stops accepting tasks and waits until the queued ones are done
+// or ctx is done.
+func (e *Executor) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	e.closed = true
+	e.cond.Broadcast()
+	e.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pending returns the number of queued and running tasks.
+func (e *Executor) Pending() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	count := 0
+	for _, queue := range e.queues {
+		count += len(queue)
+	}
+
+	return count
+}
+
+func (e *Executor) work() {
+	defer e.wg.Done()
+
+	for {
+		e.mu.Lock()
+		for len(e.ready) == 0 && !e.closed {
+			e.cond.Wait()
+		}
+		if len(e.ready) == 0 {
+			e.mu.Unlock()
+			return
+		}
+
+		key := e.ready[0]
+		e.ready = e.ready[1:]
+		task := e.queues[key][0]
+		e.mu.Unlock()
+
+		task()
+
+		e.mu.Lock()
+		// the key stays in queues while its task runs, so Submit
+		// doesn't make it ready for another worker.
+		if queue := e.queues[key][1:]; len(queue) > 0 {
+			e.queues[key] = queue
+			e.ready = append(e.ready, key)
+			e.cond.Signal()
+		} else {
+			delete(e.queues, key)
+		}
+		e.mu.Unlock()
+	}
+}
@@ -0,0 +1,116 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorKeepsOrderPerKey(t *testing.T) {
+	e := New(4, 0)
+
+	var (
+		mu  sync.Mutex
+		got = make(map[int64][]int)
+	)
+	for i := 0; i < 100; i++ {
+		for key := int64(1); key <= 3; key++ {
+			i, key := i, key
+			require.NoError(t, e.Submit(key, func() {
+				mu.Lock()
+				got[key] = append(got[key], i)
+				mu.Unlock()
+			}))
+		}
+	}
+
+	require.NoError(t, e.Shutdown(context.Background()))
+
+	for key := int64(1); key <= 3; key++ {
+		require.Len(t, got[key], 100)
+		for i, val := range got[key] {
+			assert.Equal(t, i, val)
+		}
+	}
+}
+
+func TestExecutorSerializesKey(t *testing.T) {
+	e := New(4, 0)
+
+	var running, maxRunning atomic.Int32
+	for i := 0; i < 20; i++ {
+		require.NoError(t, e.Submit(1, func() {
+			n := running.Add(1)
+			if n > maxRunning.Load() {
+				maxRunning.Store(n)
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+		}))
+	}
+
+	require.NoError(t, e.Shutdown(context.Background()))
+	assert.Equal(t, int32(1), maxRunning.Load())
+}
+
+func TestExecutorBoundsConcurrency(t *testing.T) {
+	const workers = 3
+	e := New(workers, 0)
+
+	var running, maxRunning atomic.Int32
+	var mu sync.Mutex
+	for key := int64(0); key < 30; key++ {
+		require.NoError(t, e.Submit(key, func() {
+			n := running.Add(1)
+			mu.Lock()
+			if n > maxRunning.Load() {
+				maxRunning.Store(n)
+			}
+			mu.Unlock()
+			time.Sleep(2 * time.Millisecond)
+			running.Add(-1)
+		}))
+	}
+
+	require.NoError(t, e.Shutdown(context.Background()))
+	assert.LessOrEqual(t, maxRunning.Load(), int32(workers))
+	assert.Greater(t, maxRunning.Load(), int32(1))
+}
+
+func TestExecutorShutdown(t *testing.T) {
+	e := New(1, 0)
+
+	release := make(chan struct{})
+	var done atomic.Bool
+	require.NoError(t, e.Submit(1, func() {
+		<-release
+		done.Store(true)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, e.Shutdown(ctx), context.DeadlineExceeded)
+	assert.ErrorIs(t, e.Submit(1, func() {}), ErrClosed)
+
+	close(release)
+	require.NoError(t, e.Shutdown(context.Background()))
+	assert.True(t, done.Load())
+}
+
+func TestExecutorQueueFull(t *testing.T) {
+	e := New(1, 2)
+
+	release := make(chan struct{})
+	require.NoError(t, e.Submit(1, func() { <-release }))
+	require.NoError(t, e.Submit(1, func() {}))
+	assert.ErrorIs(t, e.Submit(1, func() {}), ErrQueueFull)
+	assert.NoError(t, e.Submit(2, func() {}), "the other keys have their own queues")
+
+	close(release)
+	require.NoError(t, e.Shutdown(context.Background()))
+}
@@ -1,91 +1,125 @@
-package logger
-
-import (
-	"context"
-	"log/slog"
-	"testing"
-)
-
-func TestNewLogger(t *testing.T) {
-	ctx := context.Background()
-
-	logger := NewLogger()
-
-	if logger == nil {
-		t.Errorf("logger should not be nil")
-	}
-
-	if Default() != logger {
-		t.Errorf("logger should be default logger")
-	}
-
-	if slog.Default() != Default() {
-		t.Errorf("logger should be default logger")
-	}
-
-	if logger != Default() {
-		t.Errorf("logger should be default logger")
-	}
-
-	logger = NewLogger(WithIsJSON(true))
-	if Default() != logger {
-		t.Errorf("logger should be default logger")
-	}
-
-	_ = NewLogger(WithIsJSON(false))
-	if Default() == logger {
-		t.Errorf("logger should NOT be default logger")
-	}
-
-	logger = NewLogger(WithLevel("info"))
-	if logger.Handler().Enabled(ctx, LevelDebug) {
-		t.Errorf("logger should NOT be enabled for debug level")
-	}
-
-	logger = NewLogger(WithLevel("warn"))
-	if logger.Handler().Enabled(ctx, LevelDebug) {
-		t.Errorf("logger should NOT be enabled for debug level")
-	}
-
-	logger = NewLogger(WithLevel("debug"))
-	enabled := []Level{LevelDebug, LevelInfo, LevelWarn, LevelError}
-	for _, level := range enabled {
-		if !logger.Handler().Enabled(ctx, level) {
-			t.Errorf("logger should be enabled for all levels")
-		}
-	}
-
-	logger = NewLogger(WithLevel("abcdef"))
-	if !logger.Handler().Enabled(ctx, LevelInfo) {
-		t.Errorf("logger should be enabled for info level")
-	}
-
-	if logger.Handler().Enabled(ctx, LevelDebug) {
-		t.Errorf("logger should NOT be enabled for info level")
-	}
-
-	logger = NewLogger(WithAddSource(true))
-	if Default() != logger {
-		t.Errorf("logger should be default logger")
-	}
-
-	logger = NewLogger(WithSetDefault(false))
-	if Default() == logger {
-		t.Errorf("logger should NOT be default logger")
-	}
-
-	if logger == Default() {
-		t.Errorf("logger should NOT be default logger")
-	}
-
-	if L(ctx) == logger {
-		t.Errorf("logger should NOT be from context logger")
-	}
-
-	logger = NewLogger(WithAddSource(false), WithIsJSON(false))
-	ctx = ContextWithLogger(ctx, logger)
-
-	if L(ctx) != logger {
-		t.Errorf("logger should be from context logger")
-	}
-}
\ No newline at end of file
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewLogger(t *testing.T) {
+	ctx := context.Background()
+
+	logger := NewLogger()
+
+	if logger == nil {
+		t.Errorf("logger should not be nil")
+	}
+
+	if Default() != logger {
+		t.Errorf("logger should be default logger")
+	}
+
+	if slog.Default() != Default() {
+		t.Errorf("logger should be default logger")
+	}
+
+	if logger != Default() {
+		t.Errorf("logger should be default logger")
+	}
+
+	logger = NewLogger(WithIsJSON(true))
+	if Default() != logger {
+		t.Errorf("logger should be default logger")
+	}
+
+	_ = NewLogger(WithIsJSON(false))
+	if Default() == logger {
+		t.Errorf("logger should NOT be default logger")
+	}
+
+	logger = NewLogger(WithLevel("info"))
+	if logger.Handler().Enabled(ctx, LevelDebug) {
+		t.Errorf("logger should NOT be enabled for debug level")
+	}
+
+	logger = NewLogger(WithLevel("warn"))
+	if logger.Handler().Enabled(ctx, LevelDebug) {
+		t.Errorf("logger should NOT be enabled for debug level")
+	}
+
+	logger = NewLogger(WithLevel("debug"))
+	enabled := []Level{LevelDebug, LevelInfo, LevelWarn, LevelError}
+	for _, level := range enabled {
+		if !logger.Handler().Enabled(ctx, level) {
+			t.Errorf("logger should be enabled for all levels")
+		}
+	}
+
+	logger = NewLogger(WithLevel("abcdef"))
+	if !logger.Handler().Enabled(ctx, LevelInfo) {
+		t.Errorf("logger should be enabled for info level")
+	}
+
+	if logger.Handler().Enabled(ctx, LevelDebug) {
+		t.Errorf("logger should NOT be enabled for info level")
+	}
+
+	level := new(LevelVar)
+	logger = NewLogger(WithLevelVar(level))
+	if logger.Handler().Enabled(ctx, LevelDebug) {
+		t.Errorf("logger should NOT be enabled for debug level")
+	}
+	level.Set(LevelDebug)
+	if !logger.Handler().Enabled(ctx, LevelDebug) {
+		t.Errorf("logger should be enabled for debug level after the change")
+	}
+
+	logger = NewLogger(WithAddSource(true))
+	if Default() != logger {
+		t.Errorf("logger should be default logger")
+	}
+
+	logger = NewLogger(WithSetDefault(false))
+	if Default() == logger {
+		t.Errorf("logger should NOT be default logger")
+	}
+
+	if logger == Default() {
+		t.Errorf("logger should NOT be default logger")
+	}
+
+	if L(ctx) == logger {
+		t.Errorf("logger should NOT be from context logger")
+	}
+
+	logger = NewLogger(WithAddSource(false), WithIsJSON(false))
+	ctx = ContextWithLogger(ctx, logger)
+
+	if L(ctx) != logger {
+		t.Errorf("logger should be from context logger")
+	}
+}
+
+func TestLWithSpan(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewLogger(WithWriter(buf), WithSetDefault(false))
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+	ctx := trace.ContextWithSpanContext(ContextWithLogger(context.Background(), logger), sc)
+
+	L(ctx).Info("message")
+
+	if !strings.Contains(buf.String(), "trace_id="+sc.TraceID().String()) {
+		t.Errorf("log should contain trace_id, got %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "span_id="+sc.SpanID().String()) {
+		t.Errorf("log should contain span_id, got %q", buf.String())
+	}
+}
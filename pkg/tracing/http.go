@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// HTTPClient is the client used by the bot.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// BotAPIClient traces the Bot API requests. The span is named after the
+// called method, the URL isn't recorded because it contains the bot token.
+type BotAPIClient struct {
+	client HTTPClient
+	skip   map[string]bool
+}
+
+// NewBotAPIClient wraps the client, the skipped methods are not traced.
+func NewBotAPIClient(client HTTPClient, skip ...string) *BotAPIClient {
+	c := &BotAPIClient{client: client, skip: make(map[string]bool, len(skip))}
+	for _, method := range skip {
+		c.skip[method] = true
+	}
+
+	return c
+}
+
+func (c *BotAPIClient) Do(req *http.Request) (*http.Response, error) {
+	method := path.Base(req.URL.Path)
+	if c.skip[method] {
+		return c.client.Do(req)
+	}
+
+	ctx, span := Start(req.Context(), "telegram."+method,
+		attribute.String("telegram.method", method),
+	)
+	defer span.End()
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		Error(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, strconv.Itoa(resp.StatusCode))
+	}
+
+	return resp, nil
+}